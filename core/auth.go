@@ -0,0 +1,316 @@
+package core
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// ErrTokenNotFound is returned by a TokenStore when no token is saved under the key
+var ErrTokenNotFound = errors.New("token not found")
+
+// TokenStore persists OAuth2 tokens between runs, keyed by an application-chosen
+// string (e.g. the account's email address)
+type TokenStore interface {
+	// Load returns the token saved under key, or ErrTokenNotFound if there is none
+	Load(key string) (*oauth2.Token, error)
+	// Save stores token under key, replacing any previous token
+	Save(key string, token *oauth2.Token) error
+}
+
+// FileTokenStore is a TokenStore that keeps each token as a JSON file named
+// <key>.json in Dir. Files are written with 0600 permissions.
+type FileTokenStore struct {
+	Dir string
+}
+
+// Load reads the token saved under key
+func (s *FileTokenStore) Load(key string) (*oauth2.Token, error) {
+	data, err := os.ReadFile(s.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrTokenNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token file: %w", err)
+	}
+
+	var token oauth2.Token
+	if err := json.Unmarshal(data, &token); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal token: %w", err)
+	}
+	return &token, nil
+}
+
+// Save writes token to the file for key
+func (s *FileTokenStore) Save(key string, token *oauth2.Token) error {
+	data, err := json.MarshalIndent(token, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal token: %w", err)
+	}
+	if err := os.WriteFile(s.path(key), data, 0600); err != nil {
+		return fmt.Errorf("failed to write token file: %w", err)
+	}
+	return nil
+}
+
+func (s *FileTokenStore) path(key string) string {
+	return filepath.Join(s.Dir, filepath.Base(key)+".json")
+}
+
+// NotifyingTokenSource wraps src so that notify is called with every new token it
+// returns, such as one minted by an automatic refresh. A token counts as new when its
+// access token differs from the previous one; current is the token src starts from
+// and is not reported. notify is called outside any lock, from the goroutine that
+// asked for the token
+func NotifyingTokenSource(src oauth2.TokenSource, current *oauth2.Token, notify func(*oauth2.Token)) oauth2.TokenSource {
+	source := &notifyingTokenSource{src: src, notify: notify}
+	if current != nil {
+		source.accessToken = current.AccessToken
+	}
+	return source
+}
+
+// notifyingTokenSource reports tokens whose access token changed since the last call
+type notifyingTokenSource struct {
+	src    oauth2.TokenSource
+	notify func(*oauth2.Token)
+
+	mu          sync.Mutex // guards accessToken
+	accessToken string
+}
+
+// Token returns the token from the wrapped source, reporting it if it is new
+func (s *notifyingTokenSource) Token() (*oauth2.Token, error) {
+	token, err := s.src.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	changed := token.AccessToken != s.accessToken
+	s.accessToken = token.AccessToken
+	s.mu.Unlock()
+
+	if changed && s.notify != nil {
+		s.notify(token)
+	}
+	return token, nil
+}
+
+// tokenExpiryDelta is how long before its expiry a token is treated as expired, so it
+// is not used for a request that reaches the server after it expired. It matches the
+// delta oauth2 applies
+const tokenExpiryDelta = 10 * time.Second
+
+// ClockTokenSource returns a source that returns token while it is valid according to
+// clock and otherwise calls refresh with the current token to mint a new one. Unlike
+// oauth2.ReuseTokenSource, which checks expiry against the wall clock, expiry follows
+// the injected Clock, so refreshes can be tested with a FakeClock
+func ClockTokenSource(clock Clock, token *oauth2.Token, refresh func(current *oauth2.Token) (*oauth2.Token, error)) oauth2.TokenSource {
+	return &clockTokenSource{clock: clock, token: token, refresh: refresh}
+}
+
+// clockTokenSource caches a token until clock reports it expired
+type clockTokenSource struct {
+	clock   Clock
+	refresh func(*oauth2.Token) (*oauth2.Token, error)
+
+	mu    sync.Mutex // guards token
+	token *oauth2.Token
+}
+
+// Token returns the cached token, refreshing it first if it has expired
+func (s *clockTokenSource) Token() (*oauth2.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.valid() {
+		return s.token, nil
+	}
+	current := s.token
+	if current == nil {
+		current = &oauth2.Token{}
+	}
+	token, err := s.refresh(current)
+	if err != nil {
+		return nil, err
+	}
+	s.token = token
+	return token, nil
+}
+
+// valid reports whether the cached token has an access token that has not expired
+func (s *clockTokenSource) valid() bool {
+	if s.token == nil || s.token.AccessToken == "" {
+		return false
+	}
+	if s.token.Expiry.IsZero() {
+		return true
+	}
+	return s.clock.Now().Before(s.token.Expiry.Round(0).Add(-tokenExpiryDelta))
+}
+
+// AuthClient is the part of a provider client used by RunInteractiveAuth.
+// Both *gmail.Client and *outlook.Client implement it.
+type AuthClient interface {
+	GetAuthURL(state string) string
+	ConnectWithAuthCode(ctx context.Context, authCode string) error
+	ConnectWithToken(ctx context.Context, token *oauth2.Token) error
+	GetToken() *oauth2.Token
+}
+
+// RunInteractiveAuth connects client using the token saved under key in store, or,
+// if there is none, runs the OAuth2 authorization code flow: it generates a random
+// state, calls prompt with the URL the user must visit to authorize the app (print
+// it or open a browser), waits for the provider to redirect to a local callback
+// server, exchanges the code and saves the new token. prompt is required.
+//
+// The callback server listens on the host, port and path of the client's configured
+// redirect URL, which must therefore be a local http:// URL such as
+// http://localhost:8080/callback. The flow is aborted when ctx is done.
+func RunInteractiveAuth(ctx context.Context, client AuthClient, store TokenStore, key string, prompt func(authURL string)) error {
+	if prompt == nil {
+		return fmt.Errorf("auth prompt is required")
+	}
+
+	token, err := store.Load(key)
+	switch {
+	case err == nil:
+		if err := client.ConnectWithToken(ctx, token); err != nil {
+			return fmt.Errorf("failed to connect with saved token: %w", err)
+		}
+		return nil
+	case !errors.Is(err, ErrTokenNotFound):
+		return fmt.Errorf("failed to load token: %w", err)
+	}
+
+	state, err := newAuthState()
+	if err != nil {
+		return err
+	}
+
+	authURL := client.GetAuthURL(state)
+	redirect, err := redirectURL(authURL)
+	if err != nil {
+		return err
+	}
+
+	code, err := awaitAuthCode(ctx, redirect, state, authURL, prompt)
+	if err != nil {
+		return err
+	}
+
+	if err := client.ConnectWithAuthCode(ctx, code); err != nil {
+		return err
+	}
+
+	if err := store.Save(key, client.GetToken()); err != nil {
+		return fmt.Errorf("failed to save token: %w", err)
+	}
+	return nil
+}
+
+// newAuthState returns a random state value for CSRF protection
+func newAuthState() (string, error) {
+	b := make([]byte, 16)
+	if _, err := io.ReadFull(rand.Reader, b); err != nil {
+		return "", fmt.Errorf("failed to generate state: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// redirectURL extracts the redirect_uri parameter from an authorization URL
+func redirectURL(authURL string) (*url.URL, error) {
+	parsed, err := url.Parse(authURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse auth URL: %w", err)
+	}
+
+	redirect, err := url.Parse(parsed.Query().Get("redirect_uri"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse redirect URL: %w", err)
+	}
+	if redirect.Scheme != "http" || redirect.Host == "" {
+		return nil, fmt.Errorf("redirect URL %q must be a local http:// URL", redirect.String())
+	}
+	return redirect, nil
+}
+
+type authResult struct {
+	code string
+	err  error
+}
+
+// awaitAuthCode serves the redirect URL until the provider calls back with an
+// authorization code, and returns the code. prompt is called with authURL once the
+// server is listening
+func awaitAuthCode(ctx context.Context, redirect *url.URL, state, authURL string, prompt func(authURL string)) (string, error) {
+	addr := redirect.Host
+	if redirect.Port() == "" {
+		addr = net.JoinHostPort(redirect.Hostname(), "80")
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return "", fmt.Errorf("failed to start callback server: %w", err)
+	}
+
+	path := redirect.Path
+	if path == "" {
+		path = "/"
+	}
+
+	results := make(chan authResult, 1)
+	mux := http.NewServeMux()
+	mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		var result authResult
+		switch {
+		case query.Get("state") != state:
+			result.err = fmt.Errorf("invalid state parameter in callback")
+		case query.Get("error") != "":
+			result.err = fmt.Errorf("authorization failed: %s - %s", query.Get("error"), query.Get("error_description"))
+		case query.Get("code") == "":
+			result.err = fmt.Errorf("no authorization code in callback")
+		default:
+			result.code = query.Get("code")
+		}
+
+		if result.err != nil {
+			http.Error(w, result.err.Error(), http.StatusBadRequest)
+		} else {
+			_, _ = fmt.Fprint(w, "Authorization successful. You can close this window.")
+		}
+
+		select {
+		case results <- result:
+		default:
+		}
+	})
+
+	server := &http.Server{Handler: mux}
+	go func() { _ = server.Serve(listener) }()
+	defer func() { _ = server.Close() }()
+
+	prompt(authURL)
+
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case result := <-results:
+		return result.code, result.err
+	}
+}
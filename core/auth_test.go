@@ -0,0 +1,319 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+// fakeAuthClient simulates a provider client whose redirect URL points at redirect
+type fakeAuthClient struct {
+	redirect    string
+	state       string
+	code        string
+	token       *oauth2.Token
+	connectedBy *oauth2.Token
+}
+
+func (f *fakeAuthClient) GetAuthURL(state string) string {
+	f.state = state
+	return "https://auth.example.com/authorize?" + url.Values{
+		"state":        {state},
+		"redirect_uri": {f.redirect},
+	}.Encode()
+}
+
+func (f *fakeAuthClient) ConnectWithAuthCode(ctx context.Context, code string) error {
+	f.code = code
+	f.token = &oauth2.Token{AccessToken: "token-for-" + code}
+	return nil
+}
+
+func (f *fakeAuthClient) ConnectWithToken(ctx context.Context, token *oauth2.Token) error {
+	f.connectedBy = token
+	f.token = token
+	return nil
+}
+
+func (f *fakeAuthClient) GetToken() *oauth2.Token {
+	return f.token
+}
+
+// memoryTokenStore is an in-memory TokenStore
+type memoryTokenStore map[string]*oauth2.Token
+
+func (m memoryTokenStore) Load(key string) (*oauth2.Token, error) {
+	token, ok := m[key]
+	if !ok {
+		return nil, ErrTokenNotFound
+	}
+	return token, nil
+}
+
+func (m memoryTokenStore) Save(key string, token *oauth2.Token) error {
+	m[key] = token
+	return nil
+}
+
+// freeRedirectURL returns a callback URL on a free local port
+func freeRedirectURL(t *testing.T) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	require.NoError(t, listener.Close())
+	return "http://" + addr + "/callback"
+}
+
+// visitCallback simulates the browser being redirected back after authorization
+func visitCallback(t *testing.T, authURL string, params url.Values) {
+	t.Helper()
+	parsed, err := url.Parse(authURL)
+	require.NoError(t, err)
+
+	go func() {
+		resp, err := http.Get(parsed.Query().Get("redirect_uri") + "?" + params.Encode())
+		if err == nil {
+			_ = resp.Body.Close()
+		}
+	}()
+}
+
+func TestRunInteractiveAuth(t *testing.T) {
+	client := &fakeAuthClient{redirect: freeRedirectURL(t)}
+	store := memoryTokenStore{}
+
+	var prompted string
+	prompt := func(authURL string) {
+		prompted = authURL
+		visitCallback(t, authURL, url.Values{"code": {"auth-code"}, "state": {client.state}})
+	}
+
+	err := RunInteractiveAuth(context.Background(), client, store, "user@example.com", prompt)
+
+	require.NoError(t, err)
+	assert.Contains(t, prompted, "https://auth.example.com/authorize")
+	assert.Len(t, client.state, 32, "state is 16 random bytes, hex encoded")
+	assert.Equal(t, "auth-code", client.code)
+	require.Contains(t, store, "user@example.com")
+	assert.Equal(t, "token-for-auth-code", store["user@example.com"].AccessToken)
+}
+
+func TestRunInteractiveAuth_SavedToken(t *testing.T) {
+	client := &fakeAuthClient{redirect: freeRedirectURL(t)}
+	saved := &oauth2.Token{AccessToken: "saved"}
+	store := memoryTokenStore{"user@example.com": saved}
+
+	prompt := func(string) {
+		t.Fatal("the browser flow must not run when a token is saved")
+	}
+
+	err := RunInteractiveAuth(context.Background(), client, store, "user@example.com", prompt)
+
+	require.NoError(t, err)
+	assert.Same(t, saved, client.connectedBy)
+	assert.Empty(t, client.code)
+}
+
+func TestRunInteractiveAuth_InvalidState(t *testing.T) {
+	client := &fakeAuthClient{redirect: freeRedirectURL(t)}
+	store := memoryTokenStore{}
+
+	prompt := func(authURL string) {
+		visitCallback(t, authURL, url.Values{"code": {"auth-code"}, "state": {"forged"}})
+	}
+
+	err := RunInteractiveAuth(context.Background(), client, store, "user@example.com", prompt)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid state")
+	assert.Empty(t, client.code)
+	assert.Empty(t, store)
+}
+
+func TestRunInteractiveAuth_Denied(t *testing.T) {
+	client := &fakeAuthClient{redirect: freeRedirectURL(t)}
+
+	prompt := func(authURL string) {
+		visitCallback(t, authURL, url.Values{"error": {"access_denied"}, "state": {client.state}})
+	}
+
+	err := RunInteractiveAuth(context.Background(), client, memoryTokenStore{}, "user@example.com", prompt)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "access_denied")
+}
+
+func TestRunInteractiveAuth_ContextCanceled(t *testing.T) {
+	client := &fakeAuthClient{redirect: freeRedirectURL(t)}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	prompt := func(string) { cancel() }
+
+	err := RunInteractiveAuth(ctx, client, memoryTokenStore{}, "user@example.com", prompt)
+
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestRunInteractiveAuth_NonLocalRedirect(t *testing.T) {
+	client := &fakeAuthClient{redirect: "https://app.example.com/callback"}
+
+	err := RunInteractiveAuth(context.Background(), client, memoryTokenStore{}, "user@example.com", func(string) {})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "must be a local http:// URL")
+}
+
+func TestRunInteractiveAuth_NoPrompt(t *testing.T) {
+	client := &fakeAuthClient{redirect: freeRedirectURL(t)}
+
+	err := RunInteractiveAuth(context.Background(), client, memoryTokenStore{}, "user@example.com", nil)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "auth prompt is required")
+	assert.Empty(t, client.state, "the flow must not start without a prompt")
+}
+
+func TestFileTokenStore(t *testing.T) {
+	store := &FileTokenStore{Dir: t.TempDir()}
+
+	_, err := store.Load("user@example.com")
+	assert.True(t, errors.Is(err, ErrTokenNotFound))
+
+	token := &oauth2.Token{AccessToken: "access", RefreshToken: "refresh"}
+	require.NoError(t, store.Save("user@example.com", token))
+
+	loaded, err := store.Load("user@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "access", loaded.AccessToken)
+	assert.Equal(t, "refresh", loaded.RefreshToken)
+}
+
+// expiringTokenSource mints a new token on every call, each one already expired
+type expiringTokenSource struct {
+	minted int
+	err    error
+}
+
+func (s *expiringTokenSource) Token() (*oauth2.Token, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	s.minted++
+	return &oauth2.Token{
+		AccessToken:  fmt.Sprintf("access-%d", s.minted),
+		RefreshToken: "refresh",
+		Expiry:       time.Now(),
+	}, nil
+}
+
+func TestNotifyingTokenSource(t *testing.T) {
+	initial := &oauth2.Token{AccessToken: "access-0", Expiry: time.Now().Add(-time.Minute)}
+	fake := &expiringTokenSource{}
+	var notified []string
+
+	source := NotifyingTokenSource(oauth2.ReuseTokenSource(initial, fake), initial, func(token *oauth2.Token) {
+		notified = append(notified, token.AccessToken)
+	})
+
+	for range 2 {
+		_, err := source.Token()
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, []string{"access-1", "access-2"}, notified, "each refresh is reported once")
+}
+
+func TestNotifyingTokenSource_UnchangedToken(t *testing.T) {
+	current := &oauth2.Token{AccessToken: "access-0", Expiry: time.Now().Add(time.Hour)}
+	calls := 0
+
+	source := NotifyingTokenSource(oauth2.StaticTokenSource(current), current, func(*oauth2.Token) { calls++ })
+	token, err := source.Token()
+
+	require.NoError(t, err)
+	assert.Same(t, current, token)
+	assert.Zero(t, calls, "the starting token is not reported")
+}
+
+func TestNotifyingTokenSource_Error(t *testing.T) {
+	calls := 0
+	source := NotifyingTokenSource(&expiringTokenSource{err: errors.New("refresh failed")}, nil, func(*oauth2.Token) { calls++ })
+
+	_, err := source.Token()
+
+	assert.EqualError(t, err, "refresh failed")
+	assert.Zero(t, calls)
+}
+
+func TestClockTokenSource_RefreshesOnClockExpiry(t *testing.T) {
+	clock := NewFakeClock(time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC))
+	initial := &oauth2.Token{AccessToken: "access-0", RefreshToken: "refresh", Expiry: clock.Now().Add(time.Hour)}
+	var refreshedFrom []string
+
+	source := ClockTokenSource(clock, initial, func(current *oauth2.Token) (*oauth2.Token, error) {
+		refreshedFrom = append(refreshedFrom, current.AccessToken)
+		return &oauth2.Token{AccessToken: "access-1", RefreshToken: "refresh", Expiry: clock.Now().Add(time.Hour)}, nil
+	})
+
+	token, err := source.Token()
+	require.NoError(t, err)
+	assert.Same(t, initial, token, "a token valid by the clock is reused")
+
+	clock.Advance(time.Hour - 5*time.Second)
+	token, err = source.Token()
+	require.NoError(t, err)
+	assert.Equal(t, "access-1", token.AccessToken, "a token inside the expiry delta is refreshed")
+
+	token, err = source.Token()
+	require.NoError(t, err)
+	assert.Equal(t, "access-1", token.AccessToken)
+	assert.Equal(t, []string{"access-0"}, refreshedFrom)
+}
+
+func TestClockTokenSource_IgnoresWallClock(t *testing.T) {
+	// Expired by the wall clock, still valid by the injected clock
+	clock := NewFakeClock(time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC))
+	initial := &oauth2.Token{AccessToken: "access-0", Expiry: clock.Now().Add(time.Hour)}
+
+	source := ClockTokenSource(clock, initial, func(*oauth2.Token) (*oauth2.Token, error) {
+		t.Fatal("unexpected refresh")
+		return nil, nil
+	})
+	token, err := source.Token()
+
+	require.NoError(t, err)
+	assert.Same(t, initial, token)
+}
+
+func TestClockTokenSource_NoExpiry(t *testing.T) {
+	initial := &oauth2.Token{AccessToken: "access-0"}
+
+	source := ClockTokenSource(NewFakeClock(time.Now()), initial, func(*oauth2.Token) (*oauth2.Token, error) {
+		t.Fatal("unexpected refresh")
+		return nil, nil
+	})
+	token, err := source.Token()
+
+	require.NoError(t, err)
+	assert.Same(t, initial, token)
+}
+
+func TestClockTokenSource_RefreshError(t *testing.T) {
+	source := ClockTokenSource(SystemClock, nil, func(*oauth2.Token) (*oauth2.Token, error) {
+		return nil, errors.New("refresh failed")
+	})
+
+	_, err := source.Token()
+
+	assert.EqualError(t, err, "refresh failed")
+}
@@ -0,0 +1,247 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Calendar methods (iTIP, RFC 5546) carried by invitation messages
+const (
+	CalendarMethodRequest = "REQUEST"
+	CalendarMethodCancel  = "CANCEL"
+	CalendarMethodReply   = "REPLY"
+)
+
+// Event is a calendar event parsed from an iCalendar (.ics) attachment
+type Event struct {
+	UID         string          `json:"uid"`
+	Method      string          `json:"method,omitempty"` // REQUEST, CANCEL, REPLY, ... (empty if not set)
+	Summary     string          `json:"summary,omitempty"`
+	Description string          `json:"description,omitempty"`
+	Location    string          `json:"location,omitempty"`
+	Start       time.Time       `json:"start"`
+	End         time.Time       `json:"end"`
+	AllDay      bool            `json:"all_day,omitempty"`
+	Organizer   EmailAddress    `json:"organizer"`
+	Attendees   []EventAttendee `json:"attendees,omitempty"`
+}
+
+// EventAttendee is an attendee of a calendar event
+type EventAttendee struct {
+	EmailAddress
+	Status string `json:"status,omitempty"` // PARTSTAT: NEEDS-ACTION, ACCEPTED, DECLINED, TENTATIVE
+}
+
+// ErrCalendarNotDownloaded is returned by CalendarEvent when the message has a
+// calendar attachment whose content has not been downloaded
+var ErrCalendarNotDownloaded = errors.New("calendar attachment not downloaded")
+
+// CalendarEvent returns the message's calendar event: Event when the provider set it,
+// otherwise the first calendar attachment (text/calendar, application/ics or a .ics
+// file, including the unnamed text/calendar part of an invitation) parsed into an
+// Event. It returns nil and no error when the message has neither. Attachments only
+// carry metadata by default, so the attachment's Data must have been downloaded
+// first; otherwise ErrCalendarNotDownloaded is returned.
+func (e *Email) CalendarEvent() (*Event, error) {
+	if e.Event != nil {
+		return e.Event, nil
+	}
+	for _, att := range e.Attachments {
+		if !isCalendarAttachment(att) {
+			continue
+		}
+		if len(att.Data) == 0 {
+			return nil, ErrCalendarNotDownloaded
+		}
+		return ParseCalendarEvent(att.Data)
+	}
+	return nil, nil
+}
+
+// isCalendarAttachment reports whether the attachment holds iCalendar data
+func isCalendarAttachment(att Attachment) bool {
+	mimeType := strings.ToLower(att.MimeType)
+	if i := strings.Index(mimeType, ";"); i != -1 {
+		mimeType = strings.TrimSpace(mimeType[:i])
+	}
+	return mimeType == "text/calendar" || mimeType == "application/ics" ||
+		strings.HasSuffix(strings.ToLower(att.Filename), ".ics")
+}
+
+// icsProperty is a content line of an iCalendar object: NAME;PARAM=VALUE:VALUE
+type icsProperty struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// ParseCalendarEvent parses the first VEVENT of an iCalendar object (RFC 5545)
+func ParseCalendarEvent(data []byte) (*Event, error) {
+	var event *Event
+	var method string
+	inEvent := false
+
+	for _, line := range unfoldICSLines(string(data)) {
+		prop := parseICSProperty(line)
+		switch {
+		case prop.name == "METHOD" && !inEvent:
+			method = strings.ToUpper(prop.value)
+		case prop.name == "BEGIN" && strings.EqualFold(prop.value, "VEVENT") && event == nil:
+			event = &Event{}
+			inEvent = true
+		case prop.name == "END" && strings.EqualFold(prop.value, "VEVENT") && inEvent:
+			inEvent = false
+		case inEvent:
+			if err := applyICSProperty(event, prop); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if event == nil {
+		return nil, fmt.Errorf("no VEVENT in calendar data")
+	}
+	event.Method = method
+	return event, nil
+}
+
+// applyICSProperty sets the Event field for a VEVENT property
+func applyICSProperty(event *Event, prop icsProperty) error {
+	var err error
+	switch prop.name {
+	case "UID":
+		event.UID = prop.value
+	case "SUMMARY":
+		event.Summary = unescapeICSText(prop.value)
+	case "DESCRIPTION":
+		event.Description = unescapeICSText(prop.value)
+	case "LOCATION":
+		event.Location = unescapeICSText(prop.value)
+	case "DTSTART":
+		event.Start, event.AllDay, err = parseICSTime(prop)
+		if err != nil {
+			return fmt.Errorf("failed to parse DTSTART: %w", err)
+		}
+	case "DTEND":
+		event.End, _, err = parseICSTime(prop)
+		if err != nil {
+			return fmt.Errorf("failed to parse DTEND: %w", err)
+		}
+	case "ORGANIZER":
+		event.Organizer = icsAddress(prop)
+	case "ATTENDEE":
+		event.Attendees = append(event.Attendees, EventAttendee{
+			EmailAddress: icsAddress(prop),
+			Status:       strings.ToUpper(prop.params["PARTSTAT"]),
+		})
+	}
+	return nil
+}
+
+// unfoldICSLines splits iCalendar data into content lines, joining folded
+// continuation lines (lines starting with a space or tab)
+func unfoldICSLines(data string) []string {
+	data = strings.ReplaceAll(data, "\r\n", "\n")
+	var lines []string
+	for _, line := range strings.Split(data, "\n") {
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// parseICSProperty splits a content line into name, parameters and value.
+// Parameter values may be quoted, and quoted values may contain ':' and ';'.
+func parseICSProperty(line string) icsProperty {
+	prop := icsProperty{params: map[string]string{}}
+
+	inQuotes := false
+	valueStart := -1
+	for i, r := range line {
+		if r == '"' {
+			inQuotes = !inQuotes
+		} else if r == ':' && !inQuotes {
+			valueStart = i
+			break
+		}
+	}
+	if valueStart == -1 {
+		prop.name = strings.ToUpper(line)
+		return prop
+	}
+	prop.value = line[valueStart+1:]
+
+	segments := splitUnquoted(line[:valueStart], ';')
+	prop.name = strings.ToUpper(segments[0])
+	for _, segment := range segments[1:] {
+		key, value, ok := strings.Cut(segment, "=")
+		if !ok {
+			continue
+		}
+		prop.params[strings.ToUpper(key)] = strings.Trim(value, `"`)
+	}
+	return prop
+}
+
+// splitUnquoted splits s at sep, ignoring separators inside double quotes
+func splitUnquoted(s string, sep rune) []string {
+	var parts []string
+	inQuotes := false
+	start := 0
+	for i, r := range s {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+		case r == sep && !inQuotes:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// parseICSTime parses a DATE or DATE-TIME value, honoring the TZID parameter.
+// The boolean reports whether the value is a date (an all-day event).
+func parseICSTime(prop icsProperty) (time.Time, bool, error) {
+	value := prop.value
+	if prop.params["VALUE"] == "DATE" || len(value) == len("20060102") {
+		t, err := time.Parse("20060102", value)
+		return t, true, err
+	}
+
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse("20060102T150405Z", value)
+		return t, false, err
+	}
+
+	loc := time.UTC
+	if tzid := prop.params["TZID"]; tzid != "" {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			loc = l
+		}
+	}
+	t, err := time.ParseInLocation("20060102T150405", value, loc)
+	return t, false, err
+}
+
+// icsAddress reads the address and CN parameter of an ORGANIZER or ATTENDEE property
+func icsAddress(prop icsProperty) EmailAddress {
+	address := prop.value
+	if len(address) >= len("mailto:") && strings.EqualFold(address[:len("mailto:")], "mailto:") {
+		address = address[len("mailto:"):]
+	}
+	return EmailAddress{Email: address, Name: prop.params["CN"]}
+}
+
+// unescapeICSText reverses the TEXT value escaping of RFC 5545
+func unescapeICSText(s string) string {
+	replacer := strings.NewReplacer(`\n`, "\n", `\N`, "\n", `\,`, ",", `\;`, ";", `\\`, `\`)
+	return replacer.Replace(s)
+}
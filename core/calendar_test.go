@@ -0,0 +1,103 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sampleInvite = "BEGIN:VCALENDAR\r\n" +
+	"PRODID:-//Google Inc//Google Calendar 70.9054//EN\r\n" +
+	"VERSION:2.0\r\n" +
+	"METHOD:REQUEST\r\n" +
+	"BEGIN:VTIMEZONE\r\n" +
+	"TZID:Europe/Berlin\r\n" +
+	"END:VTIMEZONE\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"DTSTART;TZID=Europe/Berlin:20240315T100000\r\n" +
+	"DTEND;TZID=Europe/Berlin:20240315T110000\r\n" +
+	"UID:abc123@google.com\r\n" +
+	"ORGANIZER;CN=\"Doe, Jane\":mailto:jane@example.com\r\n" +
+	"ATTENDEE;CUTYPE=INDIVIDUAL;ROLE=REQ-PARTICIPANT;PARTSTAT=ACCEPTED;CN=Jane D\r\n" +
+	" oe:mailto:jane@example.com\r\n" +
+	"ATTENDEE;CUTYPE=INDIVIDUAL;PARTSTAT=NEEDS-ACTION;CN=bob@example.com:mailto:\r\n" +
+	" bob@example.com\r\n" +
+	"SUMMARY:Quarterly planning\\, Q2\r\n" +
+	"DESCRIPTION:Agenda:\\n1. Budget\\n2. Hiring\r\n" +
+	"LOCATION:Room 4\\; Building B\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestParseCalendarEvent(t *testing.T) {
+	event, err := ParseCalendarEvent([]byte(sampleInvite))
+	require.NoError(t, err)
+
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	assert.Equal(t, "abc123@google.com", event.UID)
+	assert.Equal(t, CalendarMethodRequest, event.Method)
+	assert.Equal(t, "Quarterly planning, Q2", event.Summary)
+	assert.Equal(t, "Agenda:\n1. Budget\n2. Hiring", event.Description)
+	assert.Equal(t, "Room 4; Building B", event.Location)
+	assert.True(t, event.Start.Equal(time.Date(2024, 3, 15, 10, 0, 0, 0, berlin)))
+	assert.True(t, event.End.Equal(time.Date(2024, 3, 15, 11, 0, 0, 0, berlin)))
+	assert.False(t, event.AllDay)
+	assert.Equal(t, EmailAddress{Email: "jane@example.com", Name: "Doe, Jane"}, event.Organizer)
+	assert.Equal(t, []EventAttendee{
+		{EmailAddress: EmailAddress{Email: "jane@example.com", Name: "Jane Doe"}, Status: "ACCEPTED"},
+		{EmailAddress: EmailAddress{Email: "bob@example.com", Name: "bob@example.com"}, Status: "NEEDS-ACTION"},
+	}, event.Attendees)
+}
+
+func TestParseCalendarEvent_CancelUTCAndAllDay(t *testing.T) {
+	cancel := "BEGIN:VCALENDAR\nMETHOD:CANCEL\nBEGIN:VEVENT\nUID:1\n" +
+		"DTSTART:20240315T090000Z\nDTEND:20240315T100000Z\nEND:VEVENT\nEND:VCALENDAR\n"
+	event, err := ParseCalendarEvent([]byte(cancel))
+	require.NoError(t, err)
+	assert.Equal(t, CalendarMethodCancel, event.Method)
+	assert.Equal(t, time.Date(2024, 3, 15, 9, 0, 0, 0, time.UTC), event.Start)
+
+	allDay := "BEGIN:VCALENDAR\nBEGIN:VEVENT\nUID:2\n" +
+		"DTSTART;VALUE=DATE:20240401\nDTEND;VALUE=DATE:20240402\nEND:VEVENT\nEND:VCALENDAR\n"
+	event, err = ParseCalendarEvent([]byte(allDay))
+	require.NoError(t, err)
+	assert.Empty(t, event.Method)
+	assert.True(t, event.AllDay)
+	assert.Equal(t, time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC), event.Start)
+}
+
+func TestParseCalendarEvent_Invalid(t *testing.T) {
+	_, err := ParseCalendarEvent([]byte("BEGIN:VCALENDAR\nEND:VCALENDAR\n"))
+	assert.ErrorContains(t, err, "no VEVENT")
+
+	_, err = ParseCalendarEvent([]byte("BEGIN:VEVENT\nDTSTART:tomorrow\nEND:VEVENT\n"))
+	assert.ErrorContains(t, err, "DTSTART")
+}
+
+func TestEmail_CalendarEvent(t *testing.T) {
+	t.Run("no calendar attachment", func(t *testing.T) {
+		email := &Email{Attachments: []Attachment{{Filename: "report.pdf", MimeType: "application/pdf"}}}
+		event, err := email.CalendarEvent()
+		assert.NoError(t, err)
+		assert.Nil(t, event)
+	})
+
+	t.Run("not downloaded", func(t *testing.T) {
+		email := &Email{Attachments: []Attachment{{ID: "att-1", Filename: "invite.ics", MimeType: "application/ics"}}}
+		_, err := email.CalendarEvent()
+		assert.ErrorIs(t, err, ErrCalendarNotDownloaded)
+	})
+
+	t.Run("downloaded", func(t *testing.T) {
+		email := &Email{Attachments: []Attachment{
+			{Filename: "report.pdf", MimeType: "application/pdf", Data: []byte("%PDF")},
+			{MimeType: "text/calendar; method=REQUEST; charset=UTF-8", Data: []byte(sampleInvite)},
+		}}
+		event, err := email.CalendarEvent()
+		require.NoError(t, err)
+		assert.Equal(t, "abc123@google.com", event.UID)
+	})
+}
@@ -0,0 +1,55 @@
+package core
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock provides the current time. It is injected through the provider configs so
+// time-dependent behavior (token expiry, Message-ID timestamps) can be tested deterministically.
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock is the Clock backed by time.Now.
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+// Now returns the current local time.
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// FakeClock is a Clock whose time only changes when set or advanced explicitly.
+// It is safe for concurrent use.
+type FakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewFakeClock returns a FakeClock set to now.
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+// Now returns the fake current time.
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Advance moves the fake time forward by d.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// Set sets the fake time to now.
+func (c *FakeClock) Set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = now
+}
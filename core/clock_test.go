@@ -0,0 +1,29 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSystemClock(t *testing.T) {
+	before := time.Now()
+	now := SystemClock.Now()
+	assert.False(t, now.Before(before))
+}
+
+func TestFakeClock(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+
+	assert.Equal(t, start, clock.Now())
+	assert.Equal(t, start, clock.Now(), "time does not move on its own")
+
+	clock.Advance(time.Hour)
+	assert.Equal(t, start.Add(time.Hour), clock.Now())
+
+	later := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	clock.Set(later)
+	assert.Equal(t, later, clock.Now())
+}
@@ -0,0 +1,55 @@
+package core
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// Diagnostics is a point-in-time health report for a client, suitable for support bundles.
+// It never contains token values or client secrets.
+type Diagnostics struct {
+	Provider        string        `json:"provider"`
+	Connected       bool          `json:"connected"`
+	HasToken        bool          `json:"has_token"`
+	HasRefreshToken bool          `json:"has_refresh_token"`
+	TokenExpiry     time.Time     `json:"token_expiry,omitempty"`
+	TokenExpired    bool          `json:"token_expired"`
+	Scopes          []string      `json:"scopes,omitempty"`  // Granted scopes, when the token exposes them
+	Latency         time.Duration `json:"latency,omitempty"` // Duration of a sample API round trip
+	Error           string        `json:"error,omitempty"`   // Error from the sample API round trip, if any
+}
+
+// GrantedScopes returns the scopes granted to an access token.
+// The scope field returned by the token endpoint takes precedence; otherwise the
+// "scp" or "scope" claim is read from the access token when it is a JWT.
+// The token signature is not verified.
+func GrantedScopes(scopeField, accessToken string) []string {
+	if scopes := strings.Fields(scopeField); len(scopes) > 0 {
+		return scopes
+	}
+
+	parts := strings.Split(accessToken, ".")
+	if len(parts) != 3 {
+		return nil
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil
+	}
+
+	var claims struct {
+		Scp   string `json:"scp"`
+		Scope string `json:"scope"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil
+	}
+
+	if scopes := strings.Fields(claims.Scp); len(scopes) > 0 {
+		return scopes
+	}
+	return strings.Fields(claims.Scope)
+}
@@ -0,0 +1,54 @@
+package core
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGrantedScopes(t *testing.T) {
+	jwt := func(payload string) string {
+		return "eyJhbGciOiJub25lIn0." + base64.RawURLEncoding.EncodeToString([]byte(payload)) + ".sig"
+	}
+
+	tests := []struct {
+		name        string
+		scopeField  string
+		accessToken string
+		expected    []string
+	}{
+		{
+			name:        "scope field takes precedence",
+			scopeField:  "https://www.googleapis.com/auth/gmail.readonly openid",
+			accessToken: jwt(`{"scp":"Mail.Read"}`),
+			expected:    []string{"https://www.googleapis.com/auth/gmail.readonly", "openid"},
+		},
+		{
+			name:        "scp claim",
+			accessToken: jwt(`{"scp":"Mail.Read Mail.Send"}`),
+			expected:    []string{"Mail.Read", "Mail.Send"},
+		},
+		{
+			name:        "scope claim",
+			accessToken: jwt(`{"scope":"Mail.Read"}`),
+			expected:    []string{"Mail.Read"},
+		},
+		{
+			name:        "opaque token",
+			accessToken: "ya29.opaque-token",
+			expected:    nil,
+		},
+		{
+			name:        "malformed payload",
+			accessToken: "a.!!!.c",
+			expected:    nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, GrantedScopes(tt.scopeField, tt.accessToken))
+		})
+	}
+}
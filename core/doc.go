@@ -26,7 +26,9 @@
 //	    Cc          []EmailAddress
 //	    Bcc         []EmailAddress
 //	    ReplyTo     []EmailAddress
-//	    Date        time.Time
+//	    Date        time.Time       // Received time (sent time if unknown)
+//	    SentDate    time.Time
+//	    ReceivedDate time.Time
 //	    Body        EmailBody       // Text and/or HTML
 //	    Snippet     string          // Short preview
 //	    Attachments []Attachment    // Metadata only (lazy loading)
@@ -34,6 +36,8 @@
 //	    IsRead      bool
 //	    IsStarred   bool
 //	    IsDraft     bool
+//	    Focused     bool              // Focused Inbox (Outlook only)
+//	    RawHeaders  map[string][]string // All headers, when provided
 //	}
 //
 // ListOptions - Options for listing messages:
@@ -43,6 +47,9 @@
 //	    PageToken  string     // For pagination
 //	    Query      string     // Provider-specific search query
 //	    Labels     []string   // Filter by labels/folders
+//	    HasAttachments *bool  // nil = any, true/false = with/without attachments
+//	    ExcludeFromSelf bool  // Only messages not sent by the authenticated user
+//	    Focused        *bool  // nil = any, true/false = Focused/Other (Outlook only)
 //	}
 //
 // ListResponse - Response from list operations:
@@ -110,12 +117,12 @@
 //
 // Provider Independence:
 //
-// Code written against core types works with any provider:
+// Code written against core types works with any provider. Both *gmail.Client and
+// *outlook.Client implement the Provider interface (listing, fetching, attachments,
+// read state, deletion and Close), so worker code can accept a core.Provider:
 //
 //	// Function that works with any provider
-//	func ProcessEmails(client interface{
-//	    ListMessages(ctx context.Context, opts *core.ListOptions) (*core.ListResponse, error)
-//	}) error {
+//	func ProcessEmails(client core.Provider) error {
 //	    response, err := client.ListMessages(ctx, &core.ListOptions{
 //	        MaxResults: 10,
 //	    })
@@ -202,11 +209,12 @@
 // To add a new email provider:
 //  1. Create a new package (e.g., yahoo/)
 //  2. Implement client with provider-specific authentication
-//  3. Implement methods that return core types:
+//  3. Implement the Provider interface, whose methods return core types:
 //     - ListMessages(ctx, *core.ListOptions) (*core.ListResponse, error)
 //     - GetMessage(ctx, messageID) (*core.Email, error)
 //     - GetAttachment(ctx, messageID, attachmentID) (*core.Attachment, error)
 //     - And other operations...
+//     Assert it in a test: var _ core.Provider = (*Client)(nil)
 //  4. Write conversion functions from provider types to core types
 //  5. Add tests using the same patterns as existing providers
 //
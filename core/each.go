@@ -0,0 +1,227 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultEachPageSize is the page size EachMessage requests when opts sets no MaxResults
+const DefaultEachPageSize = 100
+
+const (
+	// DefaultMinPageSize is the smallest page size a MessageIterator shrinks to when throttled
+	DefaultMinPageSize = 10
+	// DefaultThrottleBackoff is how long a MessageIterator first waits after a throttled page;
+	// the wait doubles for each consecutive throttled attempt
+	DefaultThrottleBackoff = time.Second
+	// DefaultMaxThrottleRetries is how many consecutive throttled attempts a MessageIterator
+	// retries before returning the error
+	DefaultMaxThrottleRetries = 5
+)
+
+// ListFunc lists one page of messages, such as a client's ListMessages
+type ListFunc func(ctx context.Context, opts *ListOptions) (*ListResponse, error)
+
+// ErrIteratorDone is returned by MessageIterator.Next when every message has been yielded
+var ErrIteratorDone = errors.New("no more messages")
+
+// MessageIterator pages through messages like EachMessage and adapts to throttling.
+// When list fails with ErrRateLimited, it waits, halves the page size (down to
+// MinPageSize) and retries the same page. After each successful page the page size
+// doubles again, up to the requested one. Messages are visited either with Each or one
+// at a time with Next; do not mix the two. Set the exported fields before iterating.
+type MessageIterator struct {
+	MinPageSize        int64
+	ThrottleBackoff    time.Duration
+	MaxThrottleRetries int
+
+	list     ListFunc
+	opts     ListOptions
+	pageSize atomic.Int64
+
+	ctx     context.Context // bounds the whole iteration when set by IterateMessages
+	limit   int64           // messages to yield at most; 0 = no limit
+	yielded int64
+	page    []*Email // fetched messages not yet returned by Next
+	token   string   // page token of the next page to fetch
+	done    bool     // the last page has been fetched
+}
+
+// NewMessageIterator returns an iterator over the messages matching opts, using the
+// default throttling settings. A MaxResults of 0 uses DefaultEachPageSize. opts is copied.
+func NewMessageIterator(list ListFunc, opts *ListOptions) *MessageIterator {
+	it := &MessageIterator{
+		MinPageSize:        DefaultMinPageSize,
+		ThrottleBackoff:    DefaultThrottleBackoff,
+		MaxThrottleRetries: DefaultMaxThrottleRetries,
+		list:               list,
+	}
+	if opts != nil {
+		it.opts = *opts
+	}
+	if it.opts.MaxResults <= 0 {
+		it.opts.MaxResults = DefaultEachPageSize
+	}
+	it.pageSize.Store(it.opts.MaxResults)
+	it.token = it.opts.PageToken
+	return it
+}
+
+// IterateMessages returns an iterator that yields the messages matching opts one at a
+// time through Next, fetching pages with list as needed. Unlike NewMessageIterator,
+// opts.MaxResults caps the total number of messages yielded rather than the page size;
+// pages hold up to DefaultEachPageSize messages. Cancelling ctx ends the iteration.
+// opts is copied.
+func IterateMessages(ctx context.Context, list ListFunc, opts *ListOptions) *MessageIterator {
+	var pageOpts ListOptions
+	if opts != nil {
+		pageOpts = *opts
+	}
+	limit := max(pageOpts.MaxResults, 0)
+	pageOpts.MaxResults = DefaultEachPageSize
+	if limit > 0 {
+		pageOpts.MaxResults = min(limit, DefaultEachPageSize)
+	}
+
+	it := NewMessageIterator(list, &pageOpts)
+	it.ctx = ctx
+	it.limit = limit
+	return it
+}
+
+// PageSize returns the page size currently requested, which drops below the
+// requested one while the provider is throttling. It is safe to call from fn
+// or from another goroutine.
+func (it *MessageIterator) PageSize() int64 {
+	return it.pageSize.Load()
+}
+
+// Each calls fn once per message. Iteration stops at the last page, at the first
+// error returned by fn or list (which is returned as is), when throttling outlasts
+// MaxThrottleRetries, or when ctx is cancelled.
+func (it *MessageIterator) Each(ctx context.Context, fn func(*Email) error) error {
+	for {
+		if it.limitReached() {
+			return nil
+		}
+		if len(it.page) == 0 {
+			if it.done {
+				return nil
+			}
+			if err := it.fetchPage(ctx); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		email := it.page[0]
+		it.page = it.page[1:]
+		it.yielded++
+		if err := fn(email); err != nil {
+			return err
+		}
+	}
+}
+
+// Next returns the next message, fetching the next page when the current one is
+// exhausted. It returns ErrIteratorDone after the last message (or once the limit set
+// by IterateMessages is reached), and the context's error once ctx, or the context the
+// iterator was created with, is cancelled. After a list error, calling Next again
+// retries the same page.
+func (it *MessageIterator) Next(ctx context.Context) (*Email, error) {
+	if it.ctx != nil {
+		if err := it.ctx.Err(); err != nil {
+			return nil, err
+		}
+		// Let cancelling the iterator's context also abort a page fetch in progress
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithCancel(ctx)
+		defer cancel()
+		stop := context.AfterFunc(it.ctx, cancel)
+		defer stop()
+	}
+
+	for len(it.page) == 0 {
+		if it.done || it.limitReached() {
+			return nil, ErrIteratorDone
+		}
+		if err := it.fetchPage(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if it.limitReached() {
+		return nil, ErrIteratorDone
+	}
+	email := it.page[0]
+	it.page = it.page[1:]
+	it.yielded++
+	return email, nil
+}
+
+// limitReached reports whether the iterator has yielded as many messages as its limit
+func (it *MessageIterator) limitReached() bool {
+	return it.limit > 0 && it.yielded >= it.limit
+}
+
+// fetchPage lists the page at it.token into it.page, retrying throttled attempts with
+// a smaller page size, and advances it.token to the following page
+func (it *MessageIterator) fetchPage(ctx context.Context) error {
+	pageOpts := it.opts
+	pageOpts.PageToken = it.token
+
+	for throttled := 0; ; throttled++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		pageOpts.MaxResults = it.PageSize()
+		resp, err := it.list(ctx, &pageOpts)
+		if err != nil {
+			if !errors.Is(err, ErrRateLimited) || throttled >= it.MaxThrottleRetries {
+				return err
+			}
+			if err := it.backoff(ctx, throttled); err != nil {
+				return err
+			}
+			it.pageSize.Store(max(it.PageSize()/2, min(it.MinPageSize, it.opts.MaxResults), 1))
+			continue
+		}
+		it.pageSize.Store(min(it.PageSize()*2, it.opts.MaxResults))
+
+		it.page = resp.Emails
+		if resp.NextPageToken == "" || resp.NextPageToken == it.token {
+			it.done = true
+		} else {
+			it.token = resp.NextPageToken
+		}
+		return nil
+	}
+}
+
+// backoff waits before retrying a throttled page, doubling the wait per attempt
+func (it *MessageIterator) backoff(ctx context.Context, attempt int) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(it.ThrottleBackoff << attempt):
+		return nil
+	}
+}
+
+// EachMessage pages through list and calls fn once per message, so huge mailboxes
+// can be processed without holding every email in memory. Iteration starts at
+// opts.PageToken and stops at the last page, at the first error returned by fn or
+// list (which is returned as is), or when ctx is cancelled. Throttled pages are
+// retried with a smaller page size, as described on MessageIterator. opts is not modified.
+func EachMessage(ctx context.Context, list ListFunc, opts *ListOptions, fn func(*Email) error) error {
+	return NewMessageIterator(list, opts).Each(ctx, fn)
+}
@@ -0,0 +1,297 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pagedList returns a ListFunc serving the given pages, keyed by page token
+func pagedList(pages map[string]*ListResponse, seen *[]*ListOptions) ListFunc {
+	return func(ctx context.Context, opts *ListOptions) (*ListResponse, error) {
+		copied := *opts
+		*seen = append(*seen, &copied)
+		return pages[opts.PageToken], nil
+	}
+}
+
+func TestEachMessage(t *testing.T) {
+	pages := map[string]*ListResponse{
+		"":   {Emails: []*Email{{ID: "1"}, {ID: "2"}}, NextPageToken: "p2"},
+		"p2": {Emails: []*Email{{ID: "3"}}},
+	}
+
+	t.Run("visits every message across pages", func(t *testing.T) {
+		var seen []*ListOptions
+		opts := &ListOptions{Query: "is:unread"}
+		var ids []string
+
+		err := EachMessage(context.Background(), pagedList(pages, &seen), opts, func(email *Email) error {
+			ids = append(ids, email.ID)
+			return nil
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"1", "2", "3"}, ids)
+		require.Len(t, seen, 2)
+		assert.Equal(t, int64(DefaultEachPageSize), seen[0].MaxResults)
+		assert.Equal(t, "is:unread", seen[1].Query)
+		assert.Equal(t, "p2", seen[1].PageToken)
+		assert.Empty(t, opts.PageToken, "caller's options are not modified")
+	})
+
+	t.Run("fn error stops iteration", func(t *testing.T) {
+		var seen []*ListOptions
+		stop := errors.New("stop")
+		calls := 0
+
+		err := EachMessage(context.Background(), pagedList(pages, &seen), nil, func(email *Email) error {
+			calls++
+			return stop
+		})
+
+		assert.ErrorIs(t, err, stop)
+		assert.Equal(t, 1, calls)
+		assert.Len(t, seen, 1)
+	})
+
+	t.Run("list error is returned", func(t *testing.T) {
+		listErr := errors.New("list failed")
+		list := func(ctx context.Context, opts *ListOptions) (*ListResponse, error) { return nil, listErr }
+
+		err := EachMessage(context.Background(), list, nil, func(*Email) error { return nil })
+
+		assert.ErrorIs(t, err, listErr)
+	})
+
+	t.Run("cancelled context", func(t *testing.T) {
+		var seen []*ListOptions
+		ctx, cancel := context.WithCancel(context.Background())
+		calls := 0
+
+		err := EachMessage(ctx, pagedList(pages, &seen), nil, func(*Email) error {
+			calls++
+			cancel()
+			return nil
+		})
+
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, 1, calls)
+	})
+}
+
+func TestMessageIterator_ShrinksPageSizeWhenThrottled(t *testing.T) {
+	var requested []int64
+	var sizeDuringPage []int64
+	calls := 0
+	list := func(ctx context.Context, opts *ListOptions) (*ListResponse, error) {
+		requested = append(requested, opts.MaxResults)
+		calls++
+		switch calls {
+		case 1:
+			return &ListResponse{Emails: []*Email{{ID: "1"}}, NextPageToken: "p2"}, nil
+		case 2:
+			return nil, fmt.Errorf("failed to list messages: %w", ErrRateLimited)
+		case 3:
+			return &ListResponse{Emails: []*Email{{ID: "2"}}, NextPageToken: "p3"}, nil
+		default:
+			return &ListResponse{Emails: []*Email{{ID: "3"}}}, nil
+		}
+	}
+
+	it := NewMessageIterator(list, &ListOptions{MaxResults: 40})
+	it.ThrottleBackoff = time.Millisecond
+	var ids []string
+
+	err := it.Each(context.Background(), func(email *Email) error {
+		ids = append(ids, email.ID)
+		sizeDuringPage = append(sizeDuringPage, it.PageSize())
+		return nil
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1", "2", "3"}, ids)
+	assert.Equal(t, []int64{40, 40, 20, 40}, requested, "throttled page is retried at half size, then recovers")
+	assert.Equal(t, []int64{40, 40, 40}, sizeDuringPage)
+	assert.Equal(t, int64(40), it.PageSize())
+}
+
+func TestMessageIterator_PageSizeFloor(t *testing.T) {
+	var requested []int64
+	list := func(ctx context.Context, opts *ListOptions) (*ListResponse, error) {
+		requested = append(requested, opts.MaxResults)
+		return nil, ErrRateLimited
+	}
+
+	it := NewMessageIterator(list, &ListOptions{MaxResults: 40})
+	it.ThrottleBackoff = time.Millisecond
+	it.MinPageSize = 15
+	it.MaxThrottleRetries = 3
+
+	err := it.Each(context.Background(), func(*Email) error { return nil })
+
+	assert.ErrorIs(t, err, ErrRateLimited)
+	assert.Equal(t, []int64{40, 20, 15, 15}, requested)
+	assert.Equal(t, int64(15), it.PageSize())
+}
+
+func TestMessageIterator_OtherErrorsNotRetried(t *testing.T) {
+	calls := 0
+	listErr := errors.New("boom")
+	list := func(ctx context.Context, opts *ListOptions) (*ListResponse, error) {
+		calls++
+		return nil, listErr
+	}
+
+	err := NewMessageIterator(list, nil).Each(context.Background(), func(*Email) error { return nil })
+
+	assert.Same(t, listErr, err)
+	assert.Equal(t, 1, calls)
+}
+
+func TestMessageIterator_CancelDuringBackoff(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	list := func(ctx context.Context, opts *ListOptions) (*ListResponse, error) {
+		cancel()
+		return nil, ErrRateLimited
+	}
+
+	it := NewMessageIterator(list, nil)
+	it.ThrottleBackoff = time.Hour
+
+	err := it.Each(ctx, func(*Email) error { return nil })
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, int64(DefaultEachPageSize), it.PageSize())
+}
+
+func TestIterateMessages_Next(t *testing.T) {
+	pages := map[string]*ListResponse{
+		"":   {Emails: []*Email{{ID: "1"}, {ID: "2"}}, NextPageToken: "p2"},
+		"p2": {Emails: []*Email{{ID: "3"}, {ID: "4"}}, NextPageToken: "p3"},
+		"p3": {Emails: []*Email{{ID: "5"}, {ID: "6"}}},
+	}
+
+	collect := func(it *MessageIterator) ([]string, error) {
+		var ids []string
+		for {
+			email, err := it.Next(context.Background())
+			if err != nil {
+				return ids, err
+			}
+			ids = append(ids, email.ID)
+		}
+	}
+
+	t.Run("yields every message across pages", func(t *testing.T) {
+		var seen []*ListOptions
+		it := IterateMessages(context.Background(), pagedList(pages, &seen), &ListOptions{Query: "is:unread"})
+
+		ids, err := collect(it)
+
+		assert.ErrorIs(t, err, ErrIteratorDone)
+		assert.Equal(t, []string{"1", "2", "3", "4", "5", "6"}, ids)
+		require.Len(t, seen, 3)
+		assert.Equal(t, []string{"", "p2", "p3"}, []string{seen[0].PageToken, seen[1].PageToken, seen[2].PageToken})
+		assert.Equal(t, int64(DefaultEachPageSize), seen[0].MaxResults)
+		assert.Equal(t, "is:unread", seen[2].Query)
+
+		_, err = it.Next(context.Background())
+		assert.ErrorIs(t, err, ErrIteratorDone, "Next keeps reporting the end")
+		assert.Len(t, seen, 3)
+	})
+
+	t.Run("MaxResults caps the total", func(t *testing.T) {
+		var seen []*ListOptions
+		it := IterateMessages(context.Background(), pagedList(pages, &seen), &ListOptions{MaxResults: 3})
+
+		ids, err := collect(it)
+
+		assert.ErrorIs(t, err, ErrIteratorDone)
+		assert.Equal(t, []string{"1", "2", "3"}, ids)
+		assert.Len(t, seen, 2, "no page is fetched past the cap")
+		assert.Equal(t, int64(3), seen[0].MaxResults)
+	})
+
+	t.Run("starts at PageToken", func(t *testing.T) {
+		var seen []*ListOptions
+		it := IterateMessages(context.Background(), pagedList(pages, &seen), &ListOptions{PageToken: "p3"})
+
+		ids, err := collect(it)
+
+		assert.ErrorIs(t, err, ErrIteratorDone)
+		assert.Equal(t, []string{"5", "6"}, ids)
+	})
+
+	t.Run("list error is returned and the page retried", func(t *testing.T) {
+		listErr := errors.New("boom")
+		calls := 0
+		list := func(ctx context.Context, opts *ListOptions) (*ListResponse, error) {
+			calls++
+			if calls == 2 {
+				return nil, listErr
+			}
+			return pages[opts.PageToken], nil
+		}
+		it := IterateMessages(context.Background(), list, nil)
+
+		ids, err := collect(it)
+		assert.Same(t, listErr, err)
+		assert.Equal(t, []string{"1", "2"}, ids)
+
+		ids, err = collect(it)
+		assert.ErrorIs(t, err, ErrIteratorDone)
+		assert.Equal(t, []string{"3", "4", "5", "6"}, ids)
+	})
+}
+
+func TestIterateMessages_Cancel(t *testing.T) {
+	pages := map[string]*ListResponse{
+		"":   {Emails: []*Email{{ID: "1"}, {ID: "2"}}, NextPageToken: "p2"},
+		"p2": {Emails: []*Email{{ID: "3"}}},
+	}
+
+	t.Run("iterator context", func(t *testing.T) {
+		var seen []*ListOptions
+		ctx, cancel := context.WithCancel(context.Background())
+		it := IterateMessages(ctx, pagedList(pages, &seen), nil)
+
+		email, err := it.Next(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "1", email.ID)
+
+		cancel()
+		_, err = it.Next(context.Background())
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Len(t, seen, 1)
+	})
+
+	t.Run("Next context", func(t *testing.T) {
+		var seen []*ListOptions
+		it := IterateMessages(context.Background(), pagedList(pages, &seen), nil)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := it.Next(ctx)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Empty(t, seen)
+	})
+
+	t.Run("aborts a page fetch in progress", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		list := func(listCtx context.Context, opts *ListOptions) (*ListResponse, error) {
+			cancel()
+			<-listCtx.Done()
+			return nil, listCtx.Err()
+		}
+		it := IterateMessages(ctx, list, nil)
+
+		_, err := it.Next(context.Background())
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
@@ -3,6 +3,8 @@ package core
 import (
 	"errors"
 	"fmt"
+	"sort"
+	"strings"
 )
 
 // ConfigError represents a configuration validation error
@@ -28,3 +30,93 @@ func NewConfigFieldError(field, message string) error {
 
 // ErrNotConnected is returned when an operation is attempted on a disconnected client
 var ErrNotConnected = errors.New("client not connected")
+
+// ErrReauthRequired is returned when the refresh token has been revoked or has expired
+// (OAuth2 "invalid_grant"). Retrying will not help; the user must authorize the app again.
+var ErrReauthRequired = errors.New("re-authorization required")
+
+// ErrRateLimited is wrapped by provider errors when the API throttled the request
+// (HTTP 429 or a rate limit reason). Retrying after a pause, or with smaller
+// requests, usually succeeds
+var ErrRateLimited = errors.New("rate limited")
+
+// ErrNotSupported is returned by operations the provider cannot perform, such as
+// mailbox history on Outlook or the Focused Inbox on Gmail. Provider-agnostic code can
+// check for it with errors.Is and fall back to another approach
+var ErrNotSupported = errors.New("operation not supported by this provider")
+
+// BatchError is returned by batch operations that continue past individual failures.
+// Errors maps the index of each failed item to its error; items not in the map succeeded.
+// When the batch works on IDs, IDs holds them in input order so callers can retry
+// only FailedIDs without redoing SucceededIDs.
+type BatchError struct {
+	Total  int
+	Errors map[int]error
+	IDs    []string
+}
+
+func (e *BatchError) Error() string {
+	indexes := e.failedIndexes()
+	msg := fmt.Sprintf("%d of %d batch items failed", len(e.Errors), e.Total)
+	if len(indexes) == 0 {
+		return msg
+	}
+	if len(e.IDs) != e.Total {
+		return msg + fmt.Sprintf(" (first: item %d: %v)", indexes[0], e.Errors[indexes[0]])
+	}
+	failures := make([]string, 0, len(indexes))
+	for _, i := range indexes {
+		failures = append(failures, fmt.Sprintf("%s: %v", e.IDs[i], e.Errors[i]))
+	}
+	return msg + ": " + strings.Join(failures, "; ")
+}
+
+// SucceededIDs returns the IDs of the items that succeeded, in input order.
+// It returns nil when the batch did not record IDs
+func (e *BatchError) SucceededIDs() []string {
+	if len(e.IDs) != e.Total {
+		return nil
+	}
+	succeeded := make([]string, 0, e.Total-len(e.Errors))
+	for i, id := range e.IDs {
+		if _, failed := e.Errors[i]; !failed {
+			succeeded = append(succeeded, id)
+		}
+	}
+	return succeeded
+}
+
+// FailedIDs returns the IDs of the items that failed, in input order.
+// It returns nil when the batch did not record IDs
+func (e *BatchError) FailedIDs() []string {
+	if len(e.IDs) != e.Total {
+		return nil
+	}
+	indexes := e.failedIndexes()
+	failed := make([]string, 0, len(indexes))
+	for _, i := range indexes {
+		failed = append(failed, e.IDs[i])
+	}
+	return failed
+}
+
+// Unwrap returns the individual errors ordered by item index, so errors.Is and
+// errors.As match any of them.
+func (e *BatchError) Unwrap() []error {
+	indexes := e.failedIndexes()
+	errs := make([]error, 0, len(indexes))
+	for _, i := range indexes {
+		errs = append(errs, e.Errors[i])
+	}
+	return errs
+}
+
+// failedIndexes returns the indexes of the failed items in ascending order
+func (e *BatchError) failedIndexes() []int {
+	indexes := make([]int, 0, len(e.Errors))
+	for i := range e.Errors {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+	return indexes
+}
@@ -1,6 +1,7 @@
 package core
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -37,3 +38,32 @@ func TestNewConfigFieldError(t *testing.T) {
 	assert.ErrorAs(t, err, &configErr)
 	assert.Equal(t, "redirect_url", configErr.Field)
 }
+
+func TestBatchError(t *testing.T) {
+	errFirst := errors.New("first failure")
+	errSecond := errors.New("second failure")
+	err := &BatchError{Total: 5, Errors: map[int]error{3: errSecond, 1: errFirst}}
+
+	assert.Equal(t, "2 of 5 batch items failed (first: item 1: first failure)", err.Error())
+	assert.Equal(t, []error{errFirst, errSecond}, err.Unwrap())
+	assert.ErrorIs(t, err, errSecond)
+}
+
+func TestBatchError_IDs(t *testing.T) {
+	err := &BatchError{
+		Total:  4,
+		Errors: map[int]error{2: errors.New("quota"), 0: errors.New("not found")},
+		IDs:    []string{"msg1", "msg2", "msg3", "msg4"},
+	}
+
+	assert.Equal(t, "2 of 4 batch items failed: msg1: not found; msg3: quota", err.Error())
+	assert.Equal(t, []string{"msg2", "msg4"}, err.SucceededIDs())
+	assert.Equal(t, []string{"msg1", "msg3"}, err.FailedIDs())
+}
+
+func TestBatchError_WithoutIDs(t *testing.T) {
+	err := &BatchError{Total: 2, Errors: map[int]error{1: errors.New("boom")}}
+
+	assert.Nil(t, err.SucceededIDs())
+	assert.Nil(t, err.FailedIDs())
+}
@@ -0,0 +1,110 @@
+package core
+
+import (
+	"net/textproto"
+	"strings"
+)
+
+// Header returns the first value of the named message header (case-insensitive)
+// from RawHeaders. Returns an empty string if the header is not present.
+func (e *Email) Header(name string) string {
+	if values := e.RawHeaders[textproto.CanonicalMIMEHeaderKey(name)]; len(values) > 0 {
+		return values[0]
+	}
+	for key, values := range e.RawHeaders {
+		if strings.EqualFold(key, name) && len(values) > 0 {
+			return values[0]
+		}
+	}
+	return ""
+}
+
+// UnsubscribeLinks parses the List-Unsubscribe header (RFC 2369) and returns its
+// mailto: and http(s) targets in header order. The boolean reports whether the
+// sender supports one-click unsubscribe (RFC 8058) via List-Unsubscribe-Post,
+// in which case an HTTPS link can be POSTed to without user interaction.
+func (e *Email) UnsubscribeLinks() ([]string, bool) {
+	header := e.Header("List-Unsubscribe")
+	if header == "" {
+		return nil, false
+	}
+
+	var links []string
+	for _, part := range strings.Split(header, ",") {
+		part = strings.TrimSpace(part)
+		start := strings.Index(part, "<")
+		end := strings.LastIndex(part, ">")
+		if start == -1 || end <= start {
+			continue
+		}
+
+		link := strings.TrimSpace(part[start+1 : end])
+		lower := strings.ToLower(link)
+		if strings.HasPrefix(lower, "mailto:") || strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://") {
+			links = append(links, link)
+		}
+	}
+
+	oneClick := strings.EqualFold(strings.TrimSpace(e.Header("List-Unsubscribe-Post")), "List-Unsubscribe=One-Click")
+	hasHTTPS := false
+	for _, link := range links {
+		if strings.HasPrefix(strings.ToLower(link), "https://") {
+			hasHTTPS = true
+			break
+		}
+	}
+
+	return links, oneClick && hasHTTPS
+}
+
+// FilterByUnsubscribe returns the emails whose List-Unsubscribe header has (want true)
+// or lacks (want false) at least one link, as parsed by UnsubscribeLinks. A nil want
+// returns emails unchanged. Emails must have been fetched with their headers.
+func FilterByUnsubscribe(emails []*Email, want *bool) []*Email {
+	if want == nil {
+		return emails
+	}
+	filtered := emails[:0]
+	for _, email := range emails {
+		links, _ := email.UnsubscribeLinks()
+		if (len(links) > 0) == *want {
+			filtered = append(filtered, email)
+		}
+	}
+	return filtered
+}
+
+// IsAutoSubmitted reports whether the message was generated automatically (an auto-reply,
+// notification or bulk mailing), so reply automation should not answer it. It checks
+// Auto-Submitted (RFC 3834; any value but "no"), Precedence: bulk, list, junk or
+// auto_reply, and X-Auto-Response-Suppress asking for no automatic replies (All, OOF or
+// AutoReply).
+//
+// It relies on RawHeaders, which Outlook only returns for GetOptions.IncludeHeaders
+// (Gmail fetches them with every message). known is false when the email has no headers, in
+// which case the answer cannot be determined and auto is false.
+func (e *Email) IsAutoSubmitted() (auto, known bool) {
+	if len(e.RawHeaders) == 0 {
+		return false, false
+	}
+
+	if value := e.Header("Auto-Submitted"); value != "" {
+		keyword, _, _ := strings.Cut(value, ";")
+		if !strings.EqualFold(strings.TrimSpace(keyword), "no") {
+			return true, true
+		}
+	}
+
+	switch strings.ToLower(strings.TrimSpace(e.Header("Precedence"))) {
+	case "bulk", "list", "junk", "auto_reply":
+		return true, true
+	}
+
+	for _, token := range strings.Split(e.Header("X-Auto-Response-Suppress"), ",") {
+		switch strings.ToLower(strings.TrimSpace(token)) {
+		case "all", "oof", "autoreply":
+			return true, true
+		}
+	}
+	return false, true
+}
@@ -0,0 +1,145 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmail_Header(t *testing.T) {
+	email := &Email{
+		RawHeaders: map[string][]string{
+			"List-Unsubscribe": {"<mailto:unsub@example.com>"},
+		},
+	}
+
+	assert.Equal(t, "<mailto:unsub@example.com>", email.Header("List-Unsubscribe"))
+	assert.Equal(t, "<mailto:unsub@example.com>", email.Header("list-unsubscribe"))
+	assert.Empty(t, email.Header("X-Missing"))
+	assert.Empty(t, (&Email{}).Header("List-Unsubscribe"))
+}
+
+func TestEmail_UnsubscribeLinks(t *testing.T) {
+	tests := []struct {
+		name     string
+		headers  map[string][]string
+		links    []string
+		oneClick bool
+	}{
+		{
+			name:    "no header",
+			headers: nil,
+		},
+		{
+			name: "mailto only",
+			headers: map[string][]string{
+				"List-Unsubscribe": {"<mailto:unsubscribe@example.com?subject=unsubscribe>"},
+			},
+			links: []string{"mailto:unsubscribe@example.com?subject=unsubscribe"},
+		},
+		{
+			name: "mailto and https",
+			headers: map[string][]string{
+				"List-Unsubscribe": {"<mailto:unsub@example.com>, <https://example.com/unsub?id=123>"},
+			},
+			links: []string{"mailto:unsub@example.com", "https://example.com/unsub?id=123"},
+		},
+		{
+			name: "one-click post",
+			headers: map[string][]string{
+				"List-Unsubscribe":      {"<https://example.com/unsub/abc>, <mailto:unsub@example.com>"},
+				"List-Unsubscribe-Post": {"List-Unsubscribe=One-Click"},
+			},
+			links:    []string{"https://example.com/unsub/abc", "mailto:unsub@example.com"},
+			oneClick: true,
+		},
+		{
+			name: "one-click post without https link",
+			headers: map[string][]string{
+				"List-Unsubscribe":      {"<mailto:unsub@example.com>"},
+				"List-Unsubscribe-Post": {"List-Unsubscribe=One-Click"},
+			},
+			links: []string{"mailto:unsub@example.com"},
+		},
+		{
+			name: "lowercase header keys and unsupported schemes",
+			headers: map[string][]string{
+				"list-unsubscribe": {"<ftp://example.com/unsub>, <http://example.com/unsub>"},
+			},
+			links: []string{"http://example.com/unsub"},
+		},
+		{
+			name: "malformed entries are skipped",
+			headers: map[string][]string{
+				"List-Unsubscribe": {"https://example.com/no-brackets, <>"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			email := &Email{RawHeaders: tt.headers}
+
+			links, oneClick := email.UnsubscribeLinks()
+
+			assert.Equal(t, tt.links, links)
+			assert.Equal(t, tt.oneClick, oneClick)
+		})
+	}
+}
+
+func TestFilterByUnsubscribe(t *testing.T) {
+	newsletter := &Email{ID: "newsletter", RawHeaders: map[string][]string{"List-Unsubscribe": {"<https://example.com/unsub>"}}}
+	broken := &Email{ID: "broken", RawHeaders: map[string][]string{"List-Unsubscribe": {"not a link"}}}
+	personal := &Email{ID: "personal"}
+
+	ids := func(emails []*Email) []string {
+		var result []string
+		for _, email := range emails {
+			result = append(result, email.ID)
+		}
+		return result
+	}
+	yes, no := true, false
+
+	assert.Equal(t, []string{"newsletter"}, ids(FilterByUnsubscribe([]*Email{newsletter, broken, personal}, &yes)))
+	assert.Equal(t, []string{"broken", "personal"}, ids(FilterByUnsubscribe([]*Email{newsletter, broken, personal}, &no)))
+	assert.Equal(t, []string{"newsletter", "broken", "personal"}, ids(FilterByUnsubscribe([]*Email{newsletter, broken, personal}, nil)))
+}
+
+func TestEmail_IsAutoSubmitted(t *testing.T) {
+	tests := []struct {
+		name     string
+		headers  map[string][]string
+		expected bool
+	}{
+		{"personal message", map[string][]string{"Subject": {"Lunch?"}}, false},
+		{"auto-replied", map[string][]string{"Auto-Submitted": {"auto-replied"}}, true},
+		{"auto-generated with parameters", map[string][]string{"auto-submitted": {"auto-generated; owner-email=\"bot@example.com\""}}, true},
+		{"auto-submitted no", map[string][]string{"Auto-Submitted": {"No"}}, false},
+		{"precedence bulk", map[string][]string{"Precedence": {"bulk"}}, true},
+		{"precedence list", map[string][]string{"Precedence": {" List "}}, true},
+		{"precedence junk", map[string][]string{"Precedence": {"junk"}}, true},
+		{"precedence first-class", map[string][]string{"Precedence": {"first-class"}}, false},
+		{"suppress all", map[string][]string{"X-Auto-Response-Suppress": {"All"}}, true},
+		{"suppress out of office", map[string][]string{"X-Auto-Response-Suppress": {"DR, OOF, AutoReply"}}, true},
+		{"suppress receipts only", map[string][]string{"X-Auto-Response-Suppress": {"DR, RN, NRN"}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			email := &Email{RawHeaders: tt.headers}
+
+			auto, known := email.IsAutoSubmitted()
+			assert.True(t, known)
+			assert.Equal(t, tt.expected, auto)
+		})
+	}
+}
+
+func TestEmail_IsAutoSubmitted_NoHeaders(t *testing.T) {
+	auto, known := (&Email{Subject: "Out of office"}).IsAutoSubmitted()
+
+	assert.False(t, known, "without headers the answer is indeterminate")
+	assert.False(t, auto)
+}
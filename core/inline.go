@@ -0,0 +1,53 @@
+package core
+
+import (
+	"encoding/base64"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var cidReferencePattern = regexp.MustCompile(`(?i)cid:([^"'\s)>]+)`)
+
+// NormalizeContentID strips the angle brackets and surrounding space of a Content-ID
+// header value, giving the form referenced from HTML as cid:<id>
+func NormalizeContentID(contentID string) string {
+	return strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(contentID), "<"), ">"))
+}
+
+// HasCIDReferences reports whether an HTML body references inline attachments
+func HasCIDReferences(html string) bool {
+	return cidReferencePattern.MatchString(html)
+}
+
+// EmbedInlineAttachments rewrites cid: references in an HTML body (RFC 2392) to base64
+// data: URLs built from the attachment with the matching ContentID, so the HTML renders
+// on its own. Attachments without Data are skipped, and references without a matching
+// attachment are left unchanged.
+func EmbedInlineAttachments(html string, attachments []Attachment) string {
+	byContentID := make(map[string]Attachment, len(attachments))
+	for _, att := range attachments {
+		if att.ContentID != "" && att.Data != nil {
+			byContentID[strings.ToLower(att.ContentID)] = att
+		}
+	}
+	if len(byContentID) == 0 {
+		return html
+	}
+
+	return cidReferencePattern.ReplaceAllStringFunc(html, func(ref string) string {
+		contentID := ref[len("cid:"):]
+		if unescaped, err := url.PathUnescape(contentID); err == nil {
+			contentID = unescaped
+		}
+		att, ok := byContentID[strings.ToLower(contentID)]
+		if !ok {
+			return ref
+		}
+		mimeType := att.MimeType
+		if mimeType == "" {
+			mimeType = "application/octet-stream"
+		}
+		return "data:" + mimeType + ";base64," + base64.StdEncoding.EncodeToString(att.Data)
+	})
+}
@@ -0,0 +1,40 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmbedInlineAttachments(t *testing.T) {
+	html := `<img src="cid:logo@example.com"><img src='CID:chart%40example.com'><img src="cid:missing@example.com">`
+	attachments := []Attachment{
+		{ContentID: "logo@example.com", MimeType: "image/png", Data: []byte("png")},
+		{ContentID: "chart@example.com", Data: []byte("chart")},
+		{Filename: "report.pdf", MimeType: "application/pdf", Data: []byte("pdf")},
+	}
+
+	embedded := EmbedInlineAttachments(html, attachments)
+
+	assert.Equal(t, `<img src="data:image/png;base64,cG5n">`+
+		`<img src='data:application/octet-stream;base64,Y2hhcnQ='>`+
+		`<img src="cid:missing@example.com">`, embedded)
+}
+
+func TestEmbedInlineAttachments_NoData(t *testing.T) {
+	html := `<img src="cid:logo">`
+
+	assert.Equal(t, html, EmbedInlineAttachments(html, []Attachment{{ContentID: "logo"}}))
+	assert.Equal(t, html, EmbedInlineAttachments(html, nil))
+}
+
+func TestNormalizeContentID(t *testing.T) {
+	assert.Equal(t, "logo@example.com", NormalizeContentID(" <logo@example.com> "))
+	assert.Equal(t, "logo", NormalizeContentID("logo"))
+	assert.Equal(t, "", NormalizeContentID(""))
+}
+
+func TestHasCIDReferences(t *testing.T) {
+	assert.True(t, HasCIDReferences(`<img src="cid:logo">`))
+	assert.False(t, HasCIDReferences(`<img src="https://example.com/logo.png">`))
+}
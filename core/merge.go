@@ -0,0 +1,96 @@
+package core
+
+import "sort"
+
+// OrderBy specifies how merged emails are ordered
+type OrderBy string
+
+const (
+	// OrderByDateDesc orders emails newest first
+	OrderByDateDesc OrderBy = "date_desc"
+	// OrderByDateAsc orders emails oldest first
+	OrderByDateAsc OrderBy = "date_asc"
+	// OrderByRelevance orders search results by Email.Score, most relevant first
+	// (Outlook only; Gmail keeps its own order)
+	OrderByRelevance OrderBy = "relevance"
+)
+
+// MergeListResponses combines several list responses into one.
+// Emails are de-duplicated by StableKey (so the same message fetched from two
+// providers or folders appears once). The first occurrence wins. The merged response has no NextPageToken,
+// since page tokens cannot be combined, and TotalCount is the number of merged emails.
+func MergeListResponses(sortBy OrderBy, responses ...*ListResponse) *ListResponse {
+	seen := make(map[string]bool)
+	emails := make([]*Email, 0)
+
+	for _, resp := range responses {
+		if resp == nil {
+			continue
+		}
+		for _, email := range resp.Emails {
+			if email == nil {
+				continue
+			}
+			key := email.StableKey()
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			emails = append(emails, email)
+		}
+	}
+
+	if sortBy != OrderByDateAsc {
+		sortBy = OrderByDateDesc
+	}
+	SortEmails(emails, sortBy)
+
+	return &ListResponse{
+		Emails:     emails,
+		TotalCount: int64(len(emails)),
+	}
+}
+
+// Dedupe returns emails with duplicates removed, for unified inboxes that see the
+// same message through several connected accounts (e.g. both sender and recipient,
+// or a CC). Emails are keyed by StableKey: MessageID when present,
+// otherwise a hash of sender, date and subject. The first occurrence wins and order
+// is preserved. Nil emails are dropped; the input slice is not modified
+func Dedupe(emails []*Email) []*Email {
+	seen := make(map[string]bool, len(emails))
+	unique := make([]*Email, 0, len(emails))
+
+	for _, email := range emails {
+		if email == nil {
+			continue
+		}
+		key := email.StableKey()
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		unique = append(unique, email)
+	}
+
+	return unique
+}
+
+// SortEmails sorts emails in place by date in the given order, or by descending
+// Score for OrderByRelevance. Emails with equal dates or scores keep their relative
+// order. Any other OrderBy value, including "", leaves the slice unchanged.
+func SortEmails(emails []*Email, order OrderBy) {
+	switch order {
+	case OrderByDateDesc:
+		sort.SliceStable(emails, func(i, j int) bool {
+			return emails[i].Date.After(emails[j].Date)
+		})
+	case OrderByDateAsc:
+		sort.SliceStable(emails, func(i, j int) bool {
+			return emails[i].Date.Before(emails[j].Date)
+		})
+	case OrderByRelevance:
+		sort.SliceStable(emails, func(i, j int) bool {
+			return emails[i].Score > emails[j].Score
+		})
+	}
+}
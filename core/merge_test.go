@@ -0,0 +1,138 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeListResponses(t *testing.T) {
+	base := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	inbox := &ListResponse{
+		Emails: []*Email{
+			{ID: "a", Date: base},
+			{ID: "b", Date: base.Add(2 * time.Hour), MessageID: "<shared@example.com>"},
+		},
+		NextPageToken: "inbox-page-2",
+		TotalCount:    2,
+	}
+	archive := &ListResponse{
+		Emails: []*Email{
+			{ID: "other-id", Date: base.Add(2 * time.Hour), MessageID: "<shared@example.com>"},
+			{ID: "c", Date: base.Add(time.Hour)},
+			{ID: "a", Date: base},
+		},
+		TotalCount: 3,
+	}
+
+	tests := []struct {
+		name     string
+		sortBy   OrderBy
+		expected []string
+	}{
+		{name: "newest first", sortBy: OrderByDateDesc, expected: []string{"b", "c", "a"}},
+		{name: "oldest first", sortBy: OrderByDateAsc, expected: []string{"a", "c", "b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged := MergeListResponses(tt.sortBy, inbox, nil, archive)
+
+			require.Len(t, merged.Emails, 3)
+			ids := make([]string, 0, len(merged.Emails))
+			for _, email := range merged.Emails {
+				ids = append(ids, email.ID)
+			}
+			assert.Equal(t, tt.expected, ids)
+			assert.Equal(t, int64(3), merged.TotalCount)
+			assert.Empty(t, merged.NextPageToken)
+		})
+	}
+}
+
+func TestMergeListResponses_Empty(t *testing.T) {
+	merged := MergeListResponses(OrderByDateDesc)
+
+	assert.NotNil(t, merged.Emails)
+	assert.Empty(t, merged.Emails)
+	assert.Equal(t, int64(0), merged.TotalCount)
+}
+
+func TestSortEmails(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	newEmails := func() []*Email {
+		return []*Email{
+			{ID: "b", Date: base.Add(time.Hour)},
+			{ID: "c", Date: base.Add(2 * time.Hour)},
+			{ID: "a", Date: base},
+		}
+	}
+	ids := func(emails []*Email) []string {
+		var out []string
+		for _, e := range emails {
+			out = append(out, e.ID)
+		}
+		return out
+	}
+
+	emails := newEmails()
+	SortEmails(emails, OrderByDateDesc)
+	assert.Equal(t, []string{"c", "b", "a"}, ids(emails))
+
+	emails = newEmails()
+	SortEmails(emails, OrderByDateAsc)
+	assert.Equal(t, []string{"a", "b", "c"}, ids(emails))
+
+	emails = newEmails()
+	SortEmails(emails, "")
+	assert.Equal(t, []string{"b", "c", "a"}, ids(emails), "no order keeps the provider's order")
+}
+
+func TestSortEmails_Relevance(t *testing.T) {
+	emails := []*Email{
+		{ID: "low", Score: 1.5},
+		{ID: "high", Score: 9.25},
+		{ID: "unscored"},
+		{ID: "tie", Score: 1.5},
+	}
+
+	SortEmails(emails, OrderByRelevance)
+
+	var ids []string
+	for _, e := range emails {
+		ids = append(ids, e.ID)
+	}
+	assert.Equal(t, []string{"high", "low", "tie", "unscored"}, ids)
+}
+
+func TestDedupe(t *testing.T) {
+	sent := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+
+	gmailCopy := &Email{ID: "gmail-1", Subject: "Launch", MessageID: "<launch@example.com>"}
+	outlookCopy := &Email{ID: "AAMkAD-1", Subject: "Launch", MessageID: " launch@example.com "}
+	ccCopy := &Email{ID: "AAMkAD-2", Subject: "Launch", MessageID: "<launch@example.com>"}
+
+	// No Message-ID: matched by sender, date and subject
+	noHeaderGmail := &Email{ID: "gmail-2", Subject: "Lunch?", From: EmailAddress{Email: "Ana@example.com"}, Date: sent}
+	noHeaderOutlook := &Email{ID: "AAMkAD-3", Subject: "Lunch?", From: EmailAddress{Email: "ana@example.com"}, Date: sent.In(time.FixedZone("EST", -5*3600))}
+
+	distinct := &Email{ID: "gmail-3", Subject: "Lunch?", From: EmailAddress{Email: "ana@example.com"}, Date: sent.Add(time.Minute)}
+
+	input := []*Email{gmailCopy, noHeaderGmail, outlookCopy, nil, noHeaderOutlook, ccCopy, distinct}
+	result := Dedupe(input)
+
+	ids := make([]string, 0, len(result))
+	for _, email := range result {
+		ids = append(ids, email.ID)
+	}
+	assert.Equal(t, []string{"gmail-1", "gmail-2", "gmail-3"}, ids)
+	assert.Len(t, input, 7, "input must not be modified")
+}
+
+func TestDedupe_Empty(t *testing.T) {
+	assert.Empty(t, Dedupe(nil))
+	assert.NotNil(t, Dedupe(nil))
+}
@@ -0,0 +1,40 @@
+package core
+
+import (
+	"context"
+	"io"
+)
+
+// Provider is the set of mailbox operations every provider client implements,
+// so worker code can run against Gmail or Outlook without switching on the
+// concrete client type. Provider-specific features stay on the concrete clients
+type Provider interface {
+	// IsConnected reports whether the client has a usable connection
+	IsConnected() bool
+
+	// ListMessages lists one page of messages matching opts
+	ListMessages(ctx context.Context, opts *ListOptions) (*ListResponse, error)
+
+	// GetMessage retrieves a single message by ID
+	GetMessage(ctx context.Context, messageID string) (*Email, error)
+
+	// GetAttachment downloads an attachment of a message. Data holds the content,
+	// or Reader streams it for large attachments when the provider supports it
+	GetAttachment(ctx context.Context, messageID, attachmentID string) (*Attachment, error)
+
+	// GetAttachmentStream returns a reader over an attachment's content, which the
+	// caller must close, and its metadata with a nil Data
+	GetAttachmentStream(ctx context.Context, messageID, attachmentID string) (io.ReadCloser, *Attachment, error)
+
+	// MarkAsRead marks a message as read
+	MarkAsRead(ctx context.Context, messageID string) error
+
+	// MarkAsUnread marks a message as unread
+	MarkAsUnread(ctx context.Context, messageID string) error
+
+	// DeleteMessage deletes a message
+	DeleteMessage(ctx context.Context, messageID string) error
+
+	// Close disconnects the client and releases its resources
+	Close() error
+}
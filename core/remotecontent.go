@@ -0,0 +1,121 @@
+package core
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// RemoteContentPlaceholder replaces remote URLs removed by BlockRemoteContent. It is a
+// transparent 1x1 GIF, so the layout keeps its shape without a network fetch.
+const RemoteContentPlaceholder = "data:image/gif;base64,R0lGODlhAQABAIAAAAAAAP///yH5BAEAAAAALAAAAAABAAEAAAIBRAA7"
+
+var (
+	htmlTagPattern   = regexp.MustCompile(`<[a-zA-Z][^>]*>`)
+	imgTagPattern    = regexp.MustCompile(`(?i)^<img[\s/>]`)
+	urlAttrPattern   = regexp.MustCompile(`(?i)(\s)(src|background|srcset)\s*=\s*("[^"]*"|'[^']*'|[^\s>]+)`)
+	sizeAttrPattern  = regexp.MustCompile(`(?i)\s(width|height)\s*=\s*["']?\s*(\d+)`)
+	cssURLPattern    = regexp.MustCompile(`(?i)url\(\s*(['"]?)([^'")]*)(['"]?)\s*\)`)
+	remoteURLPattern = regexp.MustCompile(`(?i)^(https?:)?//`)
+)
+
+// BlockRemoteContent neutralizes remote resources in an HTML body so that rendering it
+// does not contact the sender. Remote src and background URLs, including CSS url()
+// references, are replaced with RemoteContentPlaceholder, remote srcset attributes are
+// dropped, and tracking pixels (remote images at most 1x1) are removed entirely.
+// Inline (cid:) and data: resources are kept. It returns the sanitized HTML and the
+// blocked URLs in document order, without duplicates.
+func BlockRemoteContent(html string) (string, []string) {
+	blocked := &blockedResources{seen: make(map[string]bool)}
+
+	html = htmlTagPattern.ReplaceAllStringFunc(html, func(tag string) string {
+		return blockTag(tag, blocked)
+	})
+	html = cssURLPattern.ReplaceAllStringFunc(html, func(ref string) string {
+		url := strings.TrimSpace(cssURLPattern.FindStringSubmatch(ref)[2])
+		if !remoteURLPattern.MatchString(url) {
+			return ref
+		}
+		blocked.add(url)
+		return "url(" + RemoteContentPlaceholder + ")"
+	})
+
+	return html, blocked.urls
+}
+
+// blockTag neutralizes the remote URL attributes of a single HTML start tag
+func blockTag(tag string, blocked *blockedResources) string {
+	if imgTagPattern.MatchString(tag) && isTrackingPixel(tag) {
+		if src := remoteSrc(tag); src != "" {
+			blocked.add(src)
+			return ""
+		}
+	}
+
+	return urlAttrPattern.ReplaceAllStringFunc(tag, func(attr string) string {
+		m := urlAttrPattern.FindStringSubmatch(attr)
+		value := strings.TrimSpace(strings.Trim(m[3], `"'`))
+
+		if strings.EqualFold(m[2], "srcset") {
+			var remote []string
+			for _, candidate := range strings.Split(value, ",") {
+				if fields := strings.Fields(candidate); len(fields) > 0 && remoteURLPattern.MatchString(fields[0]) {
+					remote = append(remote, fields[0])
+				}
+			}
+			if len(remote) == 0 {
+				return attr
+			}
+			for _, url := range remote {
+				blocked.add(url)
+			}
+			return ""
+		}
+
+		if !remoteURLPattern.MatchString(value) {
+			return attr
+		}
+		blocked.add(value)
+		return m[1] + m[2] + `="` + RemoteContentPlaceholder + `"`
+	})
+}
+
+// isTrackingPixel reports whether an img tag declares both a width and a height of at most 1
+func isTrackingPixel(tag string) bool {
+	sizes := make(map[string]int)
+	for _, m := range sizeAttrPattern.FindAllStringSubmatch(tag, -1) {
+		n, err := strconv.Atoi(m[2])
+		if err != nil {
+			return false
+		}
+		sizes[strings.ToLower(m[1])] = n
+	}
+	width, hasWidth := sizes["width"]
+	height, hasHeight := sizes["height"]
+	return hasWidth && hasHeight && width <= 1 && height <= 1
+}
+
+// remoteSrc returns the tag's src URL if it is remote, or ""
+func remoteSrc(tag string) string {
+	for _, m := range urlAttrPattern.FindAllStringSubmatch(tag, -1) {
+		value := strings.TrimSpace(strings.Trim(m[3], `"'`))
+		if strings.EqualFold(m[2], "src") && remoteURLPattern.MatchString(value) {
+			return value
+		}
+	}
+	return ""
+}
+
+// blockedResources collects blocked URLs in order, without duplicates
+type blockedResources struct {
+	seen map[string]bool
+	urls []string
+}
+
+func (b *blockedResources) add(url string) {
+	if b.seen[url] {
+		return
+	}
+	b.seen[url] = true
+	b.urls = append(b.urls, url)
+}
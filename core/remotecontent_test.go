@@ -0,0 +1,63 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlockRemoteContent(t *testing.T) {
+	html := `<html><body style="background: url('https://cdn.example.com/bg.png')">` +
+		`<table background="http://cdn.example.com/table.jpg"><tr><td>` +
+		`<img src="https://cdn.example.com/logo.png" alt="Logo">` +
+		`<img src='cid:inline-1'>` +
+		`<img src="data:image/png;base64,AAAA">` +
+		`<img srcset="https://cdn.example.com/a.png 1x, https://cdn.example.com/b.png 2x" src="cid:inline-2">` +
+		`<img width="1" height="1" src="https://track.example.com/open?id=42">` +
+		`</td></tr></table></body></html>`
+
+	sanitized, blocked := BlockRemoteContent(html)
+
+	assert.Equal(t, []string{
+		"http://cdn.example.com/table.jpg",
+		"https://cdn.example.com/logo.png",
+		"https://cdn.example.com/a.png",
+		"https://cdn.example.com/b.png",
+		"https://track.example.com/open?id=42",
+		"https://cdn.example.com/bg.png",
+	}, blocked)
+
+	assert.NotContains(t, sanitized, "example.com")
+	assert.Contains(t, sanitized, `<img src="`+RemoteContentPlaceholder+`" alt="Logo">`)
+	assert.Contains(t, sanitized, `background="`+RemoteContentPlaceholder+`"`)
+	assert.Contains(t, sanitized, "url("+RemoteContentPlaceholder+")")
+	assert.Contains(t, sanitized, `<img src='cid:inline-1'>`)
+	assert.Contains(t, sanitized, `<img src="data:image/png;base64,AAAA">`)
+	assert.Contains(t, sanitized, `<img src="cid:inline-2">`)
+	assert.NotContains(t, sanitized, "open?id=42")
+}
+
+func TestBlockRemoteContent_NoRemoteContent(t *testing.T) {
+	html := `<p>Hello <img src="cid:logo"> world, src=https://example.com stays in text</p>`
+
+	sanitized, blocked := BlockRemoteContent(html)
+
+	assert.Equal(t, html, sanitized)
+	assert.Nil(t, blocked)
+}
+
+func TestBlockRemoteContent_DuplicateURLs(t *testing.T) {
+	html := `<img src="//cdn.example.com/x.png"><img src="//cdn.example.com/x.png">`
+
+	sanitized, blocked := BlockRemoteContent(html)
+
+	assert.Equal(t, []string{"//cdn.example.com/x.png"}, blocked)
+	assert.Equal(t, `<img src="`+RemoteContentPlaceholder+`"><img src="`+RemoteContentPlaceholder+`">`, sanitized)
+}
+
+func TestBlockRemoteContent_LargeImageIsNotAPixel(t *testing.T) {
+	sanitized, blocked := BlockRemoteContent(`<img width="1" height="200" src="https://example.com/bar.png">`)
+
+	assert.Equal(t, []string{"https://example.com/bar.png"}, blocked)
+	assert.Equal(t, `<img width="1" height="200" src="`+RemoteContentPlaceholder+`">`, sanitized)
+}
@@ -0,0 +1,135 @@
+package core
+
+import (
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how requests throttled by the provider (HTTP 429 Too Many
+// Requests or 503 Service Unavailable) are retried. The wait before each retry grows
+// exponentially from BaseDelay by Multiplier, up to MaxDelay, with random jitter so
+// concurrent workers do not retry in lockstep. A Retry-After header takes precedence
+// over the computed delay. A nil policy or a MaxRetries of 0 disables retries
+type RetryPolicy struct {
+	MaxRetries int           // Retries after the first attempt
+	BaseDelay  time.Duration // Wait before the first retry
+	MaxDelay   time.Duration // Longest wait; a longer Retry-After is not waited for
+	Multiplier float64       // Growth of the wait per retry (values below 1 are treated as 1)
+}
+
+// DefaultRetryPolicy returns the policy used when a provider config sets none:
+// 3 retries starting at 1s, doubling up to 30s
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxRetries: 3,
+		BaseDelay:  time.Second,
+		MaxDelay:   30 * time.Second,
+		Multiplier: 2,
+	}
+}
+
+// Delay returns the jittered wait before retry number attempt (0 for the first retry):
+// a random duration between half and all of BaseDelay*Multiplier^attempt, capped at MaxDelay
+func (p *RetryPolicy) Delay(attempt int) time.Duration {
+	delay := float64(p.BaseDelay)
+	for range attempt {
+		delay *= max(p.Multiplier, 1)
+	}
+	if p.MaxDelay > 0 && delay > float64(p.MaxDelay) {
+		delay = float64(p.MaxDelay)
+	}
+	half := time.Duration(delay / 2)
+	return half + time.Duration(rand.Int64N(int64(half)+1))
+}
+
+// Transport returns an http.RoundTripper that retries throttled responses from base
+// according to the policy. Requests whose body cannot be replayed (no GetBody and not
+// an io.Seeker) are sent once. When the policy disables retries, base is returned unchanged
+func (p *RetryPolicy) Transport(base http.RoundTripper) http.RoundTripper {
+	if p == nil || p.MaxRetries <= 0 {
+		return base
+	}
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &retryTransport{policy: *p, base: base}
+}
+
+// retryTransport retries 429 and 503 responses, honoring their Retry-After header
+type retryTransport struct {
+	policy RetryPolicy
+	base   http.RoundTripper
+}
+
+// RoundTrip sends req, waiting and resending it while the response is throttled
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	seeker, seekable := req.Body.(io.Seeker)
+	replayable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil || seekable
+
+	for attempt := 0; ; attempt++ {
+		attemptReq := req
+		if attempt > 0 {
+			switch {
+			case req.GetBody != nil:
+				body, err := req.GetBody()
+				if err != nil {
+					return nil, err
+				}
+				attemptReq = req.Clone(req.Context())
+				attemptReq.Body = body
+			case seekable:
+				if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		resp, err := t.base.RoundTrip(attemptReq)
+		if err != nil || !isThrottled(resp.StatusCode) || !replayable || attempt >= t.policy.MaxRetries {
+			return resp, err
+		}
+
+		delay := t.policy.Delay(attempt)
+		if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+			// Waiting longer than MaxDelay would stall the caller; report the throttle instead
+			if t.policy.MaxDelay > 0 && retryAfter > t.policy.MaxDelay {
+				return resp, nil
+			}
+			delay = retryAfter
+		}
+
+		// Release the connection before waiting
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// isThrottled reports whether status asks the client to retry later
+func isThrottled(status int) bool {
+	return status == http.StatusTooManyRequests || status == http.StatusServiceUnavailable
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds or as an HTTP date
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0), true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(date.Sub(now), 0), true
+	}
+	return 0, false
+}
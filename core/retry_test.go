@@ -0,0 +1,179 @@
+package core
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sequenceTransport answers each request with the next status in statuses,
+// recording the request bodies it received
+type sequenceTransport struct {
+	statuses   []int
+	retryAfter string
+	bodies     []string
+}
+
+func (s *sequenceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body := ""
+	if req.Body != nil {
+		data, _ := io.ReadAll(req.Body)
+		body = string(data)
+	}
+	s.bodies = append(s.bodies, body)
+
+	status := s.statuses[min(len(s.bodies)-1, len(s.statuses)-1)]
+	header := http.Header{}
+	if status != http.StatusOK && s.retryAfter != "" {
+		header.Set("Retry-After", s.retryAfter)
+	}
+	return &http.Response{
+		StatusCode: status,
+		Header:     header,
+		Body:       io.NopCloser(strings.NewReader("")),
+		Request:    req,
+	}, nil
+}
+
+func fastRetryPolicy(maxRetries int) *RetryPolicy {
+	return &RetryPolicy{MaxRetries: maxRetries, BaseDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond, Multiplier: 2}
+}
+
+func TestRetryPolicy_Transport(t *testing.T) {
+	tests := []struct {
+		name             string
+		statuses         []int
+		maxRetries       int
+		expectedStatus   int
+		expectedAttempts int
+	}{
+		{"transient then success", []int{429, 503, 200}, 3, 200, 3},
+		{"success first", []int{200}, 3, 200, 1},
+		{"gives up after max retries", []int{429}, 2, 429, 3},
+		{"other errors are not retried", []int{500, 200}, 3, 500, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base := &sequenceTransport{statuses: tt.statuses}
+			client := &http.Client{Transport: fastRetryPolicy(tt.maxRetries).Transport(base)}
+
+			resp, err := client.Post("https://example.com/send", "text/plain", strings.NewReader("payload"))
+
+			require.NoError(t, err)
+			_ = resp.Body.Close()
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			require.Len(t, base.bodies, tt.expectedAttempts)
+			for _, body := range base.bodies {
+				assert.Equal(t, "payload", body, "the body is replayed on each attempt")
+			}
+		})
+	}
+}
+
+func TestRetryPolicy_Transport_SeekableBody(t *testing.T) {
+	base := &sequenceTransport{statuses: []int{503, 200}}
+	req, err := http.NewRequest(http.MethodPost, "https://example.com/send", nil)
+	require.NoError(t, err)
+	req.Body = struct {
+		io.ReadSeeker
+		io.Closer
+	}{strings.NewReader("payload"), io.NopCloser(nil)}
+
+	resp, err := fastRetryPolicy(3).Transport(base).RoundTrip(req)
+
+	require.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, []string{"payload", "payload"}, base.bodies)
+}
+
+func TestRetryPolicy_Transport_RetryAfter(t *testing.T) {
+	t.Run("honored", func(t *testing.T) {
+		base := &sequenceTransport{statuses: []int{429, 200}, retryAfter: "0"}
+		policy := &RetryPolicy{MaxRetries: 1, BaseDelay: time.Hour, MaxDelay: time.Hour, Multiplier: 2}
+
+		start := time.Now()
+		resp, err := (&http.Client{Transport: policy.Transport(base)}).Get("https://example.com")
+
+		require.NoError(t, err)
+		assert.Equal(t, 200, resp.StatusCode)
+		assert.Less(t, time.Since(start), time.Minute, "Retry-After replaces the computed delay")
+	})
+
+	t.Run("longer than MaxDelay is not waited for", func(t *testing.T) {
+		base := &sequenceTransport{statuses: []int{429, 200}, retryAfter: "120"}
+
+		resp, err := (&http.Client{Transport: fastRetryPolicy(3).Transport(base)}).Get("https://example.com")
+
+		require.NoError(t, err)
+		assert.Equal(t, 429, resp.StatusCode)
+		assert.Len(t, base.bodies, 1)
+	})
+}
+
+func TestRetryPolicy_Transport_ContextCancelled(t *testing.T) {
+	base := &sequenceTransport{statuses: []int{429}}
+	policy := &RetryPolicy{MaxRetries: 3, BaseDelay: time.Hour, MaxDelay: time.Hour, Multiplier: 2}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://example.com", nil)
+	require.NoError(t, err)
+	_, err = (&http.Client{Transport: policy.Transport(base)}).Do(req)
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Len(t, base.bodies, 1)
+}
+
+func TestRetryPolicy_Transport_Disabled(t *testing.T) {
+	base := &sequenceTransport{statuses: []int{429}}
+
+	assert.Same(t, http.RoundTripper(base), (&RetryPolicy{}).Transport(base))
+	assert.Same(t, http.RoundTripper(base), (*RetryPolicy)(nil).Transport(base))
+}
+
+func TestRetryPolicy_Delay(t *testing.T) {
+	policy := &RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second, Multiplier: 2}
+
+	for range 20 {
+		first := policy.Delay(0)
+		assert.GreaterOrEqual(t, first, 50*time.Millisecond)
+		assert.LessOrEqual(t, first, 100*time.Millisecond)
+
+		third := policy.Delay(2)
+		assert.GreaterOrEqual(t, third, 200*time.Millisecond)
+		assert.LessOrEqual(t, third, 400*time.Millisecond)
+
+		capped := policy.Delay(10)
+		assert.GreaterOrEqual(t, capped, 500*time.Millisecond)
+		assert.LessOrEqual(t, capped, time.Second)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value    string
+		expected time.Duration
+		ok       bool
+	}{
+		{"", 0, false},
+		{"5", 5 * time.Second, true},
+		{"Wed, 01 Jan 2025 12:00:30 GMT", 30 * time.Second, true},
+		{"Wed, 01 Jan 2025 11:00:00 GMT", 0, true},
+		{"soon", 0, false},
+	}
+
+	for _, tt := range tests {
+		delay, ok := parseRetryAfter(tt.value, now)
+		assert.Equal(t, tt.ok, ok, tt.value)
+		assert.Equal(t, tt.expected, delay, tt.value)
+	}
+}
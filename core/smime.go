@@ -0,0 +1,30 @@
+package core
+
+import (
+	"mime"
+	"strings"
+)
+
+// SecureMIMEType reports whether a Content-Type denotes a signed or encrypted message
+// part (S/MIME, RFC 8551, or PGP/MIME, RFC 3156). An application/pkcs7-mime part is
+// encrypted unless its smime-type parameter says signed-data, in which case the content
+// is wrapped in the signature and is still not readable as a plain body.
+func SecureMIMEType(contentType string) (signed, encrypted bool) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	}
+
+	switch mediaType {
+	case "multipart/signed":
+		return true, false
+	case "multipart/encrypted":
+		return false, true
+	case "application/pkcs7-mime", "application/x-pkcs7-mime":
+		if strings.EqualFold(params["smime-type"], "signed-data") {
+			return true, false
+		}
+		return false, true
+	}
+	return false, false
+}
@@ -0,0 +1,32 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecureMIMEType(t *testing.T) {
+	tests := []struct {
+		contentType   string
+		wantSigned    bool
+		wantEncrypted bool
+	}{
+		{`multipart/signed; protocol="application/pkcs7-signature"; micalg=sha-256; boundary="b1"`, true, false},
+		{`multipart/encrypted; protocol="application/pgp-encrypted"; boundary="b1"`, false, true},
+		{`application/pkcs7-mime; smime-type=enveloped-data; name=smime.p7m`, false, true},
+		{`application/x-pkcs7-mime; smime-type=signed-data; name=smime.p7m`, true, false},
+		{`application/pkcs7-mime`, false, true},
+		{`text/plain; charset=utf-8`, false, false},
+		{`multipart/mixed; boundary="b1"`, false, false},
+		{"", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.contentType, func(t *testing.T) {
+			signed, encrypted := SecureMIMEType(tt.contentType)
+			assert.Equal(t, tt.wantSigned, signed, "signed")
+			assert.Equal(t, tt.wantEncrypted, encrypted, "encrypted")
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+)
+
+// StableKey returns an identifier for the message that is the same across providers
+// and folders, for tools that migrate or sync mail between systems where provider IDs
+// differ. It is "mid:" followed by MessageID (without angle brackets) when it is set.
+//
+// Otherwise it falls back to "hash:" followed by a SHA-256 of the sender address,
+// the sent time (to the second, in UTC; Date when SentDate is unset) and the subject.
+// The fallback can collide: two distinct messages from the same sender with the same
+// subject sent in the same second get the same key.
+func (e *Email) StableKey() string {
+	if messageID := normalizeMessageID(e.MessageID); messageID != "" {
+		return "mid:" + messageID
+	}
+
+	h := sha256.New()
+	h.Write([]byte(strings.ToLower(strings.TrimSpace(e.From.Email))))
+	h.Write([]byte{0})
+	sent := e.SentDate
+	if sent.IsZero() {
+		sent = e.Date
+	}
+	h.Write([]byte(strconv.FormatInt(sent.UTC().Unix(), 10)))
+	h.Write([]byte{0})
+	h.Write([]byte(strings.TrimSpace(e.Subject)))
+	return "hash:" + hex.EncodeToString(h.Sum(nil))
+}
+
+// normalizeMessageID trims whitespace and the enclosing angle brackets of a Message-ID
+func normalizeMessageID(messageID string) string {
+	messageID = strings.TrimSpace(messageID)
+	messageID = strings.TrimPrefix(messageID, "<")
+	messageID = strings.TrimSuffix(messageID, ">")
+	return strings.TrimSpace(messageID)
+}
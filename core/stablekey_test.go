@@ -0,0 +1,75 @@
+package core
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmail_StableKey_MessageID(t *testing.T) {
+	gmailCopy := &Email{
+		ID:        "18c1f2a3b4c5d6e7",
+		MessageID: "<CAB123@mail.gmail.com>",
+	}
+	outlookCopy := &Email{
+		ID:        "AAMkAGI2TG93AAA=",
+		MessageID: " <CAB123@mail.gmail.com> ",
+	}
+	other := &Email{
+		ID:        "18c1f2a3b4c5d6e7",
+		MessageID: "<CAB456@mail.gmail.com>",
+	}
+
+	assert.Equal(t, "mid:CAB123@mail.gmail.com", gmailCopy.StableKey())
+	assert.Equal(t, gmailCopy.StableKey(), outlookCopy.StableKey(), "same message in two providers")
+	assert.NotEqual(t, gmailCopy.StableKey(), other.StableKey(), "same provider ID, different message")
+}
+
+func TestEmail_StableKey_Fallback(t *testing.T) {
+	date := time.Date(2024, 3, 1, 10, 30, 0, 0, time.UTC)
+	base := Email{
+		ID:      "provider-1",
+		From:    EmailAddress{Email: "Sender@Example.com", Name: "Sender"},
+		Date:    date,
+		Subject: "Quarterly report",
+	}
+
+	same := base
+	same.ID = "provider-2"
+	same.From = EmailAddress{Email: "sender@example.com"}
+	same.Date = date.In(time.FixedZone("CET", 3600)).Add(250 * time.Millisecond)
+
+	differentSubject := base
+	differentSubject.Subject = "Quarterly report (v2)"
+
+	differentDate := base
+	differentDate.Date = date.Add(time.Minute)
+
+	differentSender := base
+	differentSender.From = EmailAddress{Email: "other@example.com"}
+
+	key := base.StableKey()
+	assert.True(t, strings.HasPrefix(key, "hash:"))
+	assert.Equal(t, key, same.StableKey(), "provider ID, address case, time zone and sub-second time are ignored")
+	assert.NotEqual(t, key, differentSubject.StableKey())
+	assert.NotEqual(t, key, differentDate.StableKey())
+	assert.NotEqual(t, key, differentSender.StableKey())
+}
+
+func TestEmail_StableKey_FieldBoundaries(t *testing.T) {
+	date := time.Date(2024, 3, 1, 10, 30, 0, 0, time.UTC)
+	a := &Email{From: EmailAddress{Email: "a@example.com"}, Date: date, Subject: "1 hello"}
+	b := &Email{From: EmailAddress{Email: "a@example.com1"}, Date: date, Subject: " hello"}
+
+	assert.NotEqual(t, a.StableKey(), b.StableKey())
+}
+
+func TestEmail_StableKey_UsesSentDate(t *testing.T) {
+	sent := time.Date(2024, 3, 1, 10, 30, 0, 0, time.UTC)
+	sender := &Email{From: EmailAddress{Email: "a@example.com"}, Subject: "Hi", Date: sent, SentDate: sent}
+	recipient := &Email{From: EmailAddress{Email: "a@example.com"}, Subject: "Hi", Date: sent.Add(3 * time.Minute), SentDate: sent}
+
+	assert.Equal(t, sender.StableKey(), recipient.StableKey(), "delivery delay must not change the key")
+}
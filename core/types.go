@@ -1,25 +1,74 @@
 package core
 
-import "time"
+import (
+	"io"
+	"time"
+)
 
 // Email represents a normalized email message across providers
 type Email struct {
-	ID          string         `json:"id"`
-	ThreadID    string         `json:"thread_id"`
-	Subject     string         `json:"subject"`
-	From        EmailAddress   `json:"from"`
-	To          []EmailAddress `json:"to"`
-	Cc          []EmailAddress `json:"cc,omitempty"`
-	Bcc         []EmailAddress `json:"bcc,omitempty"`
-	ReplyTo     []EmailAddress `json:"reply_to,omitempty"`
-	Date        time.Time      `json:"date"`
-	Body        EmailBody      `json:"body"`
-	Snippet     string         `json:"snippet"`
-	Labels      []string       `json:"labels,omitempty"`
-	Attachments []Attachment   `json:"attachments,omitempty"`
-	IsRead      bool           `json:"is_read"`
-	IsStarred   bool           `json:"is_starred"`
-	IsDraft     bool           `json:"is_draft"`
+	ID           string         `json:"id"`
+	ThreadID     string         `json:"thread_id"`
+	MessageID    string         `json:"message_id,omitempty"` // RFC 5322 Message-ID with angle brackets, e.g. "<abc@example.com>"; the same in every mailbox holding the message
+	Subject      string         `json:"subject"`
+	From         EmailAddress   `json:"from"`
+	To           []EmailAddress `json:"to"`
+	Cc           []EmailAddress `json:"cc,omitempty"`
+	Bcc          []EmailAddress `json:"bcc,omitempty"`
+	ReplyTo      []EmailAddress `json:"reply_to,omitempty"`
+	Date         time.Time      `json:"date"`                    // When the message was received; the sent time if the provider reports none
+	SentDate     time.Time      `json:"sent_date,omitempty"`     // When the sender sent the message (Date header / sentDateTime)
+	ReceivedDate time.Time      `json:"received_date,omitempty"` // When the mailbox received the message (internalDate / receivedDateTime)
+	Body         EmailBody      `json:"body"`
+	Snippet      string         `json:"snippet"`
+	Labels       []string       `json:"labels,omitempty"`
+	Attachments  []Attachment   `json:"attachments,omitempty"`
+	IsRead       bool           `json:"is_read"`
+	IsStarred    bool           `json:"is_starred"`
+	IsDraft      bool           `json:"is_draft"`
+	Focused      bool           `json:"focused,omitempty"`   // In the Focused Inbox (Outlook only; always false for Gmail)
+	Signed       bool           `json:"signed,omitempty"`    // S/MIME or PGP signed; the signature is kept as an attachment
+	Encrypted    bool           `json:"encrypted,omitempty"` // S/MIME or PGP encrypted; the body is empty and the encrypted part is kept as an attachment
+
+	// ExtendedProperties holds the MAPI properties requested with GetOptions.ExtendedProperties,
+	// keyed by the requested property ID (Outlook only)
+	ExtendedProperties map[string]string `json:"extended_properties,omitempty"`
+
+	// RawHeaders holds every header of the message, keyed by canonical name with
+	// repeated headers (e.g. Received) in message order. Gmail returns them with every
+	// fetched message; Outlook only with GetOptions.IncludeHeaders (or, in listings,
+	// ListOptions.HasUnsubscribe). Use Header to read a single value
+	RawHeaders map[string][]string `json:"raw_headers,omitempty"`
+
+	// ProviderMeta holds provider-specific identifiers, e.g. Gmail's X-GM-MSGID and
+	// X-GM-THRID (decimal, as used by IMAP) for tools bridging the API and IMAP
+	ProviderMeta map[string]string `json:"provider_meta,omitempty"`
+
+	// Score is the search relevance reported by the provider for ListOptions.Query,
+	// higher meaning more relevant; 0 when none is reported (Outlook only)
+	Score float64 `json:"score,omitempty"`
+
+	// BlockedResources lists the remote URLs removed from Body.HTML when requested
+	// with GetOptions.BlockRemoteContent
+	BlockedResources []string `json:"blocked_resources,omitempty"`
+
+	// Event is the calendar event of a meeting message when the provider reports it
+	// as structured data rather than an iCalendar part (Outlook event messages fetched
+	// with GetMessage). Use CalendarEvent, which falls back to the attachments
+	Event *Event `json:"event,omitempty"`
+}
+
+// FormatDate renders Date with layout (see time.Layout) as wall-clock time in loc,
+// e.g. e.FormatDate("Mon Jan 2 15:04", userLocation). A nil loc keeps the location
+// the provider reported. Returns an empty string when the date is unknown
+func (e *Email) FormatDate(layout string, loc *time.Location) string {
+	if e.Date.IsZero() {
+		return ""
+	}
+	if loc == nil {
+		return e.Date.Format(layout)
+	}
+	return e.Date.In(loc).Format(layout)
 }
 
 // EmailAddress represents an email address with optional name
@@ -41,14 +90,58 @@ type Attachment struct {
 	MimeType string `json:"mime_type"`
 	Size     int64  `json:"size"`
 	Data     []byte `json:"data,omitempty"`
+
+	// ContentID identifies inline attachments (without angle brackets); HTML bodies
+	// reference them as cid:<ContentID>
+	ContentID string `json:"content_id,omitempty"`
+
+	// Reader streams the content of large attachments instead of Data, when the
+	// provider supports it. The caller must close it.
+	Reader io.ReadCloser `json:"-"`
 }
 
 // ListOptions contains options for listing emails
 type ListOptions struct {
-	MaxResults int64    `json:"max_results,omitempty"`
-	PageToken  string   `json:"page_token,omitempty"`
-	Query      string   `json:"query,omitempty"`
-	Labels     []string `json:"labels,omitempty"`
+	MaxResults      int64    `json:"max_results,omitempty"`
+	PageToken       string   `json:"page_token,omitempty"`
+	Query           string   `json:"query,omitempty"`
+	Labels          []string `json:"labels,omitempty"`
+	HasAttachments  *bool    `json:"has_attachments,omitempty"`   // nil = no filter, true/false = only with/without attachments
+	ExcludeFromSelf bool     `json:"exclude_from_self,omitempty"` // Exclude messages sent by the authenticated user
+	Focused         *bool    `json:"focused,omitempty"`           // nil = no filter, true/false = Focused/Other tab (Outlook only; ignored by Gmail)
+
+	// HasCalendarInvite filters on meeting invitations: nil = no filter, true/false = only
+	// with/without an invite. Outlook matches meeting requests exactly; Gmail approximates
+	// it by looking for an attached .ics file, so it also matches forwarded calendar files
+	// and misses invites sent without one.
+	HasCalendarInvite *bool `json:"has_calendar_invite,omitempty"`
+
+	// HasUnsubscribe keeps only messages with (true) or without (false) a parsable
+	// List-Unsubscribe header; nil = no filter. Neither provider can filter on headers,
+	// so it is applied client-side to each fetched page, which may then hold fewer than
+	// MaxResults messages. Ignored with IDsOnly, which fetches no headers.
+	HasUnsubscribe *bool `json:"has_unsubscribe,omitempty"`
+
+	// Filter is a raw OData $filter expression, e.g. from outlook.QueryBuilder.BuildFilter,
+	// combined with the structured filters above (Outlook only; ignored by Gmail)
+	Filter string `json:"filter,omitempty"`
+
+	// OrderBy sorts the returned page by date, or by Score for OrderByRelevance. "" keeps
+	// the provider's order, which for Gmail is only roughly newest first. Sorting applies
+	// within a page; pages themselves still follow the provider's order.
+	OrderBy OrderBy `json:"order_by,omitempty"`
+
+	// ExpandSmallAttachments, when positive, returns attachments smaller than this many
+	// bytes, content included, in Email.Attachments of the listed messages, saving a
+	// GetAttachment call each. Larger attachments are left out of the list. Every listed
+	// message carries its small attachments, so keep the threshold low (Outlook only;
+	// ignored by Gmail)
+	ExpandSmallAttachments int64 `json:"expand_small_attachments,omitempty"`
+
+	// IDsOnly returns emails with only ID and ThreadID set, skipping message contents.
+	// This is the cheapest listing, meant for sync and diff jobs. Pages are not sorted
+	// locally, since no dates are fetched
+	IDsOnly bool `json:"ids_only,omitempty"`
 }
 
 // ListResponse contains the result of listing emails
@@ -58,6 +151,66 @@ type ListResponse struct {
 	TotalCount    int64    `json:"total_count"`
 }
 
+// Thread is a conversation: a message and its replies
+type Thread struct {
+	ID        string   `json:"id"`
+	Snippet   string   `json:"snippet,omitempty"`
+	Messages  []*Email `json:"messages,omitempty"` // Ordered by date, oldest first
+	HistoryID string   `json:"history_id,omitempty"`
+}
+
+// ThreadListResponse contains the result of listing threads
+type ThreadListResponse struct {
+	Threads       []*Thread `json:"threads"`
+	NextPageToken string    `json:"next_page_token,omitempty"`
+	TotalCount    int64     `json:"total_count"`
+}
+
+// GetOptions contains options for retrieving a single email
+type GetOptions struct {
+	MarkReadOnFetch bool     `json:"mark_read_on_fetch,omitempty"` // Mark the message as read after a successful fetch
+	RetryNotFound   bool     `json:"retry_not_found,omitempty"`    // Retry briefly on not-found, for messages just moved or imported (Outlook only)
+	PreferBodyType  BodyType `json:"prefer_body_type,omitempty"`   // Ask the provider to return the body in this format (Outlook only)
+
+	// DownloadAttachments lists attachments, by filename or ID, whose content is
+	// downloaded along with the message into Attachment.Data
+	DownloadAttachments []string `json:"download_attachments,omitempty"`
+
+	// ExtendedProperties lists single-value MAPI property IDs to fetch, e.g.
+	// "Integer 0x0E07" for PR_MESSAGE_FLAGS (Outlook only)
+	ExtendedProperties []string `json:"extended_properties,omitempty"`
+
+	// IncludeHeaders fills Email.RawHeaders with all message headers in the same request
+	// (Outlook; Gmail returns them with every message)
+	IncludeHeaders bool `json:"include_headers,omitempty"`
+
+	// BlockRemoteContent neutralizes remote images and tracking pixels in Body.HTML
+	// (see BlockRemoteContent) and reports the blocked URLs in Email.BlockedResources
+	BlockRemoteContent bool `json:"block_remote_content,omitempty"`
+}
+
+// WantsAttachment reports whether DownloadAttachments names the attachment,
+// by filename or by ID
+func (o *GetOptions) WantsAttachment(att Attachment) bool {
+	if o == nil {
+		return false
+	}
+	for _, want := range o.DownloadAttachments {
+		if want != "" && (want == att.Filename || want == att.ID) {
+			return true
+		}
+	}
+	return false
+}
+
+// BodyType selects the format of a message body
+type BodyType string
+
+const (
+	BodyTypeHTML BodyType = "html"
+	BodyTypeText BodyType = "text"
+)
+
 // Draft represents a message being composed for sending
 type Draft struct {
 	To          []EmailAddress    `json:"to,omitempty"`
@@ -68,11 +221,23 @@ type Draft struct {
 	Attachments []Attachment      `json:"attachments,omitempty"`
 	ReplyTo     []EmailAddress    `json:"reply_to,omitempty"`
 	Headers     map[string]string `json:"headers,omitempty"`
+
+	// AttachedMessages lists IDs of existing messages to forward as attachments: an .eml
+	// (message/rfc822) part in Gmail, an item attachment in Outlook
+	AttachedMessages []string `json:"attached_messages,omitempty"`
 }
 
 // SendOptions contains options for sending emails
 type SendOptions struct {
-	CustomHeaders map[string]string `json:"custom_headers,omitempty"`
+	CustomHeaders  map[string]string `json:"custom_headers,omitempty"`
+	IdempotencyKey string            `json:"idempotency_key,omitempty"` // Same key yields the same Message-ID so retried sends are detectable
+	ReturnSentID   bool              `json:"return_sent_id,omitempty"`  // Resolve the stored Sent Items message ID (Outlook; costs extra requests)
+	ReturnPath     string            `json:"return_path,omitempty"`     // Bounce address written as Return-Path (Gmail only; see docs for limits)
+}
+
+// ForwardOptions contains options for forwarding emails
+type ForwardOptions struct {
+	IncludeAttachments bool `json:"include_attachments,omitempty"` // Re-attach the original attachments (Gmail; Outlook always forwards them)
 }
 
 // SendResponse contains the result of sending an email
@@ -81,6 +246,19 @@ type SendResponse struct {
 	ThreadID string `json:"thread_id,omitempty"`
 }
 
+// DraftResponse is a draft saved in the mailbox
+type DraftResponse struct {
+	ID      string `json:"id"`
+	Message *Email `json:"message"` // The draft's content; its ID is the message ID, not the draft ID
+}
+
+// DraftListResponse contains the result of listing drafts
+type DraftListResponse struct {
+	Drafts        []*DraftResponse `json:"drafts"`
+	NextPageToken string           `json:"next_page_token,omitempty"`
+	TotalCount    int64            `json:"total_count"`
+}
+
 // BatchModifyRequest contains options for batch modifying messages
 type BatchModifyRequest struct {
 	MessageIDs     []string `json:"message_ids"`
@@ -147,7 +325,19 @@ type HistoryLabelChange struct {
 type Label struct {
 	ID             string `json:"id"`
 	Name           string `json:"name"`
-	Type           string `json:"type"`            // "system" or "user"
-	TotalMessages  int    `json:"total_messages"`  // Total number of messages
-	UnreadMessages int    `json:"unread_messages"` // Number of unread messages
+	Type           string `json:"type"`                // "system" or "user"
+	TotalMessages  int    `json:"total_messages"`      // Total number of messages
+	UnreadMessages int    `json:"unread_messages"`     // Number of unread messages
+	ParentID       string `json:"parent_id,omitempty"` // ID of the parent label or folder; empty at the top level
+	Depth          int    `json:"depth"`               // Nesting level: 0 at the top level, 1 for a direct child, and so on
+}
+
+// LabelOptions describes a label to create with its color and visibility (Gmail only).
+// Empty visibilities show the label in both the label list and the message list
+type LabelOptions struct {
+	Name                  string `json:"name"`
+	TextColor             string `json:"text_color,omitempty"`              // Hex color from Gmail's label palette, e.g. "#ffffff"; set together with BackgroundColor
+	BackgroundColor       string `json:"background_color,omitempty"`        // Hex color from Gmail's label palette, e.g. "#16a766"; set together with TextColor
+	LabelListVisibility   string `json:"label_list_visibility,omitempty"`   // "labelShow" (default), "labelShowIfUnread" or "labelHide"
+	MessageListVisibility string `json:"message_list_visibility,omitempty"` // "show" (default) or "hide"
 }
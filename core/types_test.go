@@ -2,6 +2,7 @@ package core
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -36,6 +37,17 @@ func TestEmail_Fields(t *testing.T) {
 	assert.False(t, email.IsStarred)
 }
 
+func TestEmail_FormatDate(t *testing.T) {
+	email := &Email{Date: time.Date(2025, 3, 14, 22, 30, 0, 0, time.UTC)}
+	tokyo := time.FixedZone("JST", 9*60*60)
+	newYork := time.FixedZone("EST", -5*60*60)
+
+	assert.Equal(t, "2025-03-15 07:30 JST", email.FormatDate("2006-01-02 15:04 MST", tokyo), "the date rolls over in Tokyo")
+	assert.Equal(t, "Fri 17:30", email.FormatDate("Mon 15:04", newYork))
+	assert.Equal(t, "22:30 UTC", email.FormatDate("15:04 MST", nil), "a nil location keeps the reported one")
+	assert.Empty(t, (&Email{}).FormatDate(time.RFC3339, tokyo), "an unknown date renders empty")
+}
+
 func TestEmailAddress(t *testing.T) {
 	t.Run("with name", func(t *testing.T) {
 		addr := EmailAddress{
@@ -171,3 +183,13 @@ func TestEmail_ComplexFields(t *testing.T) {
 	assert.False(t, email.IsRead)
 	assert.True(t, email.IsStarred)
 }
+
+func TestGetOptions_WantsAttachment(t *testing.T) {
+	att := Attachment{ID: "att-1", Filename: "invite.ics"}
+
+	assert.True(t, (&GetOptions{DownloadAttachments: []string{"invite.ics"}}).WantsAttachment(att))
+	assert.True(t, (&GetOptions{DownloadAttachments: []string{"att-1"}}).WantsAttachment(att))
+	assert.False(t, (&GetOptions{DownloadAttachments: []string{"report.pdf"}}).WantsAttachment(att))
+	assert.False(t, (&GetOptions{DownloadAttachments: []string{""}}).WantsAttachment(Attachment{}))
+	assert.False(t, (*GetOptions)(nil).WantsAttachment(att))
+}
@@ -0,0 +1,6 @@
+package core
+
+import mailbridge "github.com/danielrivera/mailbridge-go"
+
+// DefaultUserAgent is the User-Agent sent to provider APIs when none is configured
+const DefaultUserAgent = "mailbridge-go/" + mailbridge.Version
@@ -0,0 +1,12 @@
+package core
+
+import (
+	"testing"
+
+	mailbridge "github.com/danielrivera/mailbridge-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultUserAgent_ContainsVersion(t *testing.T) {
+	assert.Equal(t, "mailbridge-go/"+mailbridge.Version, DefaultUserAgent)
+}
@@ -0,0 +1,121 @@
+package core
+
+import (
+	"encoding/base64"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var emailRegex = regexp.MustCompile(`^[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}$`)
+
+// SendLimits holds a provider's size limits for outgoing messages, in bytes.
+// A zero value disables the corresponding check.
+type SendLimits struct {
+	MaxAttachmentSize int64 // Largest single attachment
+	MaxTotalSize      int64 // Largest message: body plus all attachments
+
+	// Base64Encoded counts attachments at their base64-encoded size, for providers
+	// whose limits apply to a request carrying the attachments base64-encoded
+	Base64Encoded bool
+}
+
+// ValidateDraft checks a draft before it is sent: at least one recipient, valid
+// addresses, a non-blank subject, a text or HTML body, complete attachments, and the
+// provider's size limits. Providers call it before hitting the API; UIs can call it
+// with the provider's limits for pre-flight validation.
+func ValidateDraft(draft *Draft, limits SendLimits) error {
+	if draft == nil {
+		return fmt.Errorf("draft is nil")
+	}
+
+	// At least one recipient required
+	if len(draft.To) == 0 && len(draft.Cc) == 0 && len(draft.Bcc) == 0 {
+		return fmt.Errorf("at least one recipient required (To, Cc, or Bcc)")
+	}
+
+	// Validate all email addresses
+	if err := ValidateAddresses(draft.To, draft.Cc, draft.Bcc, draft.ReplyTo); err != nil {
+		return err
+	}
+
+	// Subject required
+	if strings.TrimSpace(draft.Subject) == "" {
+		return fmt.Errorf("subject is required")
+	}
+
+	// Body required (text or HTML)
+	if draft.Body.Text == "" && draft.Body.HTML == "" {
+		return fmt.Errorf("email body required (text or html)")
+	}
+
+	// Validate attachments
+	totalSize := int64(len(draft.Body.Text) + len(draft.Body.HTML))
+	for _, att := range draft.Attachments {
+		if att.Filename == "" {
+			return fmt.Errorf("attachment filename required")
+		}
+		if att.MimeType == "" {
+			return fmt.Errorf("attachment MIME type required for %s", att.Filename)
+		}
+		if len(att.Data) == 0 {
+			return fmt.Errorf("attachment %s has no data", att.Filename)
+		}
+		size := int64(len(att.Data))
+		if limits.Base64Encoded {
+			size = int64(base64.StdEncoding.EncodedLen(len(att.Data)))
+		}
+		if limits.MaxAttachmentSize > 0 && size > limits.MaxAttachmentSize {
+			return fmt.Errorf("attachment %s exceeds %s limit (size: %d bytes)", att.Filename, formatSize(limits.MaxAttachmentSize), size)
+		}
+		totalSize += size
+	}
+
+	if limits.MaxTotalSize > 0 && totalSize > limits.MaxTotalSize {
+		return fmt.Errorf("message exceeds %s total size limit (size: %d bytes)", formatSize(limits.MaxTotalSize), totalSize)
+	}
+
+	return nil
+}
+
+// ValidateAddresses checks that every address in the given lists is a valid email
+// address, as ValidateDraft does for a draft's recipients. Providers use it for
+// operations that take recipients without a full draft, such as replies.
+func ValidateAddresses(lists ...[]EmailAddress) error {
+	for _, addresses := range lists {
+		for _, addr := range addresses {
+			if !isValidEmail(addr.Email) {
+				return fmt.Errorf("invalid email address: %s", addr.Email)
+			}
+		}
+	}
+	return nil
+}
+
+// ValidateSendOptions checks send options before a message is built. A nil opts is valid.
+func ValidateSendOptions(opts *SendOptions) error {
+	if opts == nil {
+		return nil
+	}
+	if opts.ReturnPath != "" && !isValidEmail(opts.ReturnPath) {
+		return fmt.Errorf("invalid return path: %s", opts.ReturnPath)
+	}
+	return nil
+}
+
+// isValidEmail validates an email address format
+func isValidEmail(email string) bool {
+	if email == "" {
+		return false
+	}
+	return emailRegex.MatchString(email)
+}
+
+// formatSize formats a byte count as whole megabytes when possible
+func formatSize(bytes int64) string {
+	const mb = 1024 * 1024
+	if bytes%mb == 0 {
+		return fmt.Sprintf("%dMB", bytes/mb)
+	}
+	return fmt.Sprintf("%d bytes", bytes)
+}
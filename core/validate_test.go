@@ -0,0 +1,284 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateDraft(t *testing.T) {
+	tests := []struct {
+		name    string
+		draft   *Draft
+		wantErr bool
+		errMsg  string
+	}{
+		{
+			name: "valid draft - To only",
+			draft: &Draft{
+				To:      []EmailAddress{{Email: "test@example.com"}},
+				Subject: "Test",
+				Body:    EmailBody{Text: "Hello"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "valid draft - Cc only",
+			draft: &Draft{
+				Cc:      []EmailAddress{{Email: "test@example.com"}},
+				Subject: "Test",
+				Body:    EmailBody{Text: "Hello"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "valid draft - Bcc only",
+			draft: &Draft{
+				Bcc:     []EmailAddress{{Email: "test@example.com"}},
+				Subject: "Test",
+				Body:    EmailBody{Text: "Hello"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "valid draft - HTML body",
+			draft: &Draft{
+				To:      []EmailAddress{{Email: "test@example.com"}},
+				Subject: "Test",
+				Body:    EmailBody{HTML: "<p>Hello</p>"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "valid draft - with attachment",
+			draft: &Draft{
+				To:      []EmailAddress{{Email: "test@example.com"}},
+				Subject: "Test",
+				Body:    EmailBody{Text: "Hello"},
+				Attachments: []Attachment{
+					{Filename: "test.txt", MimeType: "text/plain", Data: []byte("data")},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid reply-to address",
+			draft: &Draft{
+				To:      []EmailAddress{{Email: "test@example.com"}},
+				ReplyTo: []EmailAddress{{Email: "not-an-address"}},
+				Subject: "Test",
+				Body:    EmailBody{Text: "Hello"},
+			},
+			wantErr: true,
+			errMsg:  "invalid email address: not-an-address",
+		},
+		{
+			name:    "nil draft",
+			draft:   nil,
+			wantErr: true,
+			errMsg:  "draft is nil",
+		},
+		{
+			name: "no recipients",
+			draft: &Draft{
+				Subject: "Test",
+				Body:    EmailBody{Text: "Hello"},
+			},
+			wantErr: true,
+			errMsg:  "at least one recipient required",
+		},
+		{
+			name: "invalid email address",
+			draft: &Draft{
+				To:      []EmailAddress{{Email: "invalid-email"}},
+				Subject: "Test",
+				Body:    EmailBody{Text: "Hello"},
+			},
+			wantErr: true,
+			errMsg:  "invalid email address",
+		},
+		{
+			name: "empty subject",
+			draft: &Draft{
+				To:      []EmailAddress{{Email: "test@example.com"}},
+				Subject: "",
+				Body:    EmailBody{Text: "Hello"},
+			},
+			wantErr: true,
+			errMsg:  "subject is required",
+		},
+		{
+			name: "whitespace-only subject",
+			draft: &Draft{
+				To:      []EmailAddress{{Email: "test@example.com"}},
+				Subject: "   ",
+				Body:    EmailBody{Text: "Hello"},
+			},
+			wantErr: true,
+			errMsg:  "subject is required",
+		},
+		{
+			name: "empty body",
+			draft: &Draft{
+				To:      []EmailAddress{{Email: "test@example.com"}},
+				Subject: "Test",
+				Body:    EmailBody{},
+			},
+			wantErr: true,
+			errMsg:  "email body required",
+		},
+		{
+			name: "attachment without filename",
+			draft: &Draft{
+				To:      []EmailAddress{{Email: "test@example.com"}},
+				Subject: "Test",
+				Body:    EmailBody{Text: "Hello"},
+				Attachments: []Attachment{
+					{Filename: "", MimeType: "text/plain", Data: []byte("data")},
+				},
+			},
+			wantErr: true,
+			errMsg:  "attachment filename required",
+		},
+		{
+			name: "attachment without mime type",
+			draft: &Draft{
+				To:      []EmailAddress{{Email: "test@example.com"}},
+				Subject: "Test",
+				Body:    EmailBody{Text: "Hello"},
+				Attachments: []Attachment{
+					{Filename: "test.txt", MimeType: "", Data: []byte("data")},
+				},
+			},
+			wantErr: true,
+			errMsg:  "attachment MIME type required",
+		},
+		{
+			name: "attachment without data",
+			draft: &Draft{
+				To:      []EmailAddress{{Email: "test@example.com"}},
+				Subject: "Test",
+				Body:    EmailBody{Text: "Hello"},
+				Attachments: []Attachment{
+					{Filename: "test.txt", MimeType: "text/plain", Data: []byte{}},
+				},
+			},
+			wantErr: true,
+			errMsg:  "has no data",
+		},
+		{
+			name: "attachment too large",
+			draft: &Draft{
+				To:      []EmailAddress{{Email: "test@example.com"}},
+				Subject: "Test",
+				Body:    EmailBody{Text: "Hello"},
+				Attachments: []Attachment{
+					{Filename: "huge.bin", MimeType: "application/octet-stream", Data: make([]byte, 26*1024*1024)},
+				},
+			},
+			wantErr: true,
+			errMsg:  "exceeds 25MB limit",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateDraft(tt.draft, SendLimits{MaxAttachmentSize: 25 * 1024 * 1024})
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errMsg)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateDraft_SizeLimits(t *testing.T) {
+	newDraft := func(sizes ...int) *Draft {
+		draft := &Draft{
+			To:      []EmailAddress{{Email: "test@example.com"}},
+			Subject: "Test",
+			Body:    EmailBody{Text: "0123456789"}, // 10 bytes
+		}
+		for _, size := range sizes {
+			draft.Attachments = append(draft.Attachments, Attachment{
+				Filename: "file.bin", MimeType: "application/octet-stream", Data: make([]byte, size),
+			})
+		}
+		return draft
+	}
+
+	tests := []struct {
+		name    string
+		draft   *Draft
+		limits  SendLimits
+		wantErr string
+	}{
+		{"attachment over limit", newDraft(1500), SendLimits{MaxAttachmentSize: 1000}, "exceeds 1000 bytes limit"},
+		{"attachment within limit", newDraft(1500), SendLimits{MaxAttachmentSize: 2000}, ""},
+		{"total over limit", newDraft(600, 600), SendLimits{MaxAttachmentSize: 1000, MaxTotalSize: 1200}, "exceeds 1200 bytes total size limit"},
+		{"body counts toward total", newDraft(1195), SendLimits{MaxTotalSize: 1200}, "total size limit"},
+		{"total within limit", newDraft(600, 590), SendLimits{MaxAttachmentSize: 1000, MaxTotalSize: 1200}, ""},
+		{"zero limits disable checks", newDraft(5000, 5000), SendLimits{}, ""},
+		{"encoded attachment over limit", newDraft(900), SendLimits{MaxAttachmentSize: 1000, Base64Encoded: true}, "exceeds 1000 bytes limit (size: 1200 bytes)"},
+		{"encoded attachment within limit", newDraft(750), SendLimits{MaxAttachmentSize: 1000, Base64Encoded: true}, ""},
+		{"encoded total over limit", newDraft(450, 450), SendLimits{MaxTotalSize: 1200, Base64Encoded: true}, "exceeds 1200 bytes total size limit (size: 1210 bytes)"},
+		{"megabyte limits are formatted", newDraft(2 * 1024 * 1024), SendLimits{MaxAttachmentSize: 1024 * 1024}, "exceeds 1MB limit"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateDraft(tt.draft, tt.limits)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateSendOptions(t *testing.T) {
+	assert.NoError(t, ValidateSendOptions(nil))
+	assert.NoError(t, ValidateSendOptions(&SendOptions{}))
+	assert.NoError(t, ValidateSendOptions(&SendOptions{ReturnPath: "bounces@example.com"}))
+	assert.ErrorContains(t, ValidateSendOptions(&SendOptions{ReturnPath: "bounces"}), "invalid return path")
+}
+
+func TestValidateAddresses(t *testing.T) {
+	assert.NoError(t, ValidateAddresses())
+	assert.NoError(t, ValidateAddresses(nil, []EmailAddress{{Email: "a@example.com"}}))
+	assert.EqualError(t,
+		ValidateAddresses([]EmailAddress{{Email: "a@example.com"}}, []EmailAddress{{Email: "bad"}}),
+		"invalid email address: bad")
+}
+
+func TestIsValidEmail(t *testing.T) {
+	tests := []struct {
+		name  string
+		email string
+		valid bool
+	}{
+		{"valid simple", "test@example.com", true},
+		{"valid with plus", "user+tag@example.com", true},
+		{"valid with dash", "first-last@example.com", true},
+		{"valid with dot", "first.last@example.com", true},
+		{"valid subdomain", "user@mail.example.com", true},
+		{"invalid no at", "userexample.com", false},
+		{"invalid no domain", "user@", false},
+		{"invalid no user", "@example.com", false},
+		{"invalid no tld", "user@example", false},
+		{"invalid spaces", "user @example.com", false},
+		{"empty string", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := isValidEmail(tt.email)
+			assert.Equal(t, tt.valid, result, "Email: %s", tt.email)
+		})
+	}
+}
@@ -213,7 +213,7 @@ func listRecentMessages(ctx context.Context) {
 	for i, email := range response.Emails {
 		fmt.Printf("[%d] From: %s <%s>\n", i+1, email.From.Name, email.From.Email)
 		fmt.Printf("    Subject: %s\n", email.Subject)
-		fmt.Printf("    Date: %s\n", email.Date.Format("2006-01-02 15:04"))
+		fmt.Printf("    Date: %s\n", email.FormatDate("2006-01-02 15:04", time.Local))
 		fmt.Printf("    Read: %v | Starred: %v\n", email.IsRead, email.IsStarred)
 		if len(email.Snippet) > 80 {
 			fmt.Printf("    Preview: %s...\n", email.Snippet[:80])
@@ -247,7 +247,7 @@ func listUnreadMessages(ctx context.Context) {
 	for i, email := range response.Emails {
 		fmt.Printf("[%d] %s\n", i+1, email.Subject)
 		fmt.Printf("    From: %s\n", email.From.Email)
-		fmt.Printf("    Date: %s\n", email.Date.Format("2006-01-02 15:04"))
+		fmt.Printf("    Date: %s\n", email.FormatDate("2006-01-02 15:04", time.Local))
 		fmt.Println()
 	}
 }
@@ -275,7 +275,7 @@ func searchMessages(ctx context.Context) {
 	for i, email := range response.Emails {
 		fmt.Printf("[%d] %s\n", i+1, email.Subject)
 		fmt.Printf("    From: %s\n", email.From.Email)
-		fmt.Printf("    Date: %s\n", email.Date.Format("2006-01-02"))
+		fmt.Printf("    Date: %s\n", email.FormatDate("2006-01-02", time.Local))
 		fmt.Printf("    Attachments: %d\n", len(email.Attachments))
 		for j, att := range email.Attachments {
 			fmt.Printf("      [%d] %s (%s, %s)\n", j+1, att.Filename, att.MimeType, formatBytes(att.Size))
@@ -320,7 +320,7 @@ func getMessageDetails(ctx context.Context) {
 		fmt.Println()
 	}
 
-	fmt.Printf("Date: %s\n", email.Date.Format("2006-01-02 15:04:05"))
+	fmt.Printf("Date: %s\n", email.FormatDate("2006-01-02 15:04:05", time.Local))
 	fmt.Printf("Labels: %v\n", email.Labels)
 	fmt.Printf("\nBody (Text):\n%s\n", truncate(email.Body.Text, 200))
 
@@ -427,21 +427,21 @@ func downloadAttachments(ctx context.Context) {
 		for j, att := range email.Attachments {
 			fmt.Printf("  [%d/%d] %s (%s)\n", j+1, len(email.Attachments), att.Filename, formatBytes(att.Size))
 
-			data, err := client.GetAttachment(ctx, email.ID, att.ID)
+			attachment, err := client.GetAttachment(ctx, email.ID, att.ID)
 			if err != nil {
 				fmt.Printf("        ❌ Failed: %v\n", err)
 				continue
 			}
 
 			filename := filepath.Join(messageDir, sanitizeFilename(att.Filename))
-			if err := os.WriteFile(filename, data, 0644); err != nil {
+			if err := os.WriteFile(filename, attachment.Data, 0644); err != nil {
 				fmt.Printf("        ❌ Failed to save: %v\n", err)
 				continue
 			}
 
 			fmt.Printf("        ✓ Downloaded: %s\n", filename)
 			totalDownloaded++
-			totalBytes += int64(len(data))
+			totalBytes += attachment.Size
 		}
 		fmt.Println()
 	}
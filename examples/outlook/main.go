@@ -35,28 +35,22 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"log"
-	"net/http"
 	"os"
 	"strings"
 	"time"
 
 	"github.com/danielrivera/mailbridge-go/core"
 	"github.com/danielrivera/mailbridge-go/outlook"
-	"golang.org/x/oauth2"
 )
 
 const (
-	tokenFile = "token.json"
-	port      = ":8080"
+	tokenKey = "token" // saved as token.json
+	port     = ":8080"
 )
 
-var (
-	client        *outlook.Client
-	expectedState = "random-state-string" // In production, generate random state
-)
+var client *outlook.Client
 
 func main() {
 	ctx := context.Background()
@@ -97,129 +91,23 @@ func main() {
 	if err != nil {
 		log.Fatal("Failed to create client:", err)
 	}
-
-	// Try to load existing token
-	token, err := loadToken()
-	if err == nil {
-		log.Println("Loading saved token...")
-		err = client.ConnectWithToken(ctx, token)
-		if err != nil {
-			log.Println("Failed to connect with saved token:", err)
-			token = nil
-		} else {
-			log.Println("Successfully connected with saved token")
-
-			// Check if token is expired
-			if token.Expiry.Before(time.Now()) {
-				log.Println("Token expired, refreshing...")
-				newToken, err := client.RefreshToken(ctx)
-				if err != nil {
-					log.Println("Failed to refresh token:", err)
-					token = nil
-				} else {
-					token = newToken
-					if err := saveToken(token); err != nil {
-						log.Printf("Warning: failed to save refreshed token: %v", err)
-					}
-					log.Println("Token refreshed successfully")
-				}
-			}
-		}
-	}
-
-	// If no valid token, start OAuth flow
-	if token == nil {
-		log.Println("No valid token found, starting OAuth flow...")
-		authURL := client.GetAuthURL(expectedState)
-
-		fmt.Println("\n" + strings.Repeat("=", 80))
-		fmt.Println("AUTHORIZATION REQUIRED")
-		fmt.Println(strings.Repeat("=", 80))
-		fmt.Printf("\nPlease visit this URL to authorize:\n\n%s\n\n", authURL)
-		fmt.Println(strings.Repeat("=", 80))
-
-		// Start HTTP server for callback
-		http.HandleFunc("/callback", handleCallback)
-		http.HandleFunc("/", handleRoot)
-
-		fmt.Printf("\nStarting callback server on http://localhost%s\n", port)
-		fmt.Println("Waiting for authorization...")
-
-		if err := http.ListenAndServe(port, nil); err != nil {
-			log.Fatal("Failed to start server:", err)
+	defer func() {
+		if err := client.Close(); err != nil {
+			log.Printf("Warning: failed to close client: %v", err)
 		}
-	} else {
-		// Token is valid, run examples
-		runExamples(ctx)
-	}
-}
-
-func handleRoot(w http.ResponseWriter, r *http.Request) {
-	_, _ = fmt.Fprintf(w, `
-		<html>
-		<head><title>Outlook Example</title></head>
-		<body>
-			<h1>Outlook Integration Example</h1>
-			<p>Waiting for authorization callback...</p>
-			<p>If you've already authorized, this page will close automatically.</p>
-		</body>
-		</html>
-	`)
-}
-
-func handleCallback(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
+	}()
 
-	// Verify state
-	state := r.URL.Query().Get("state")
-	if state != expectedState {
-		http.Error(w, "Invalid state parameter", http.StatusBadRequest)
-		return
+	// Connect with the token saved in token.json, or authorize in the browser
+	// through a callback server on RedirectURL and save the new token
+	store := &core.FileTokenStore{Dir: "."}
+	prompt := func(authURL string) {
+		fmt.Printf("Visit this URL to authorize the application:\n\n%s\n\n", authURL)
 	}
-
-	// Get authorization code
-	code := r.URL.Query().Get("code")
-	if code == "" {
-		errorMsg := r.URL.Query().Get("error")
-		errorDesc := r.URL.Query().Get("error_description")
-		http.Error(w, fmt.Sprintf("Authorization failed: %s - %s", errorMsg, errorDesc), http.StatusBadRequest)
-		return
+	if err := core.RunInteractiveAuth(ctx, client, store, tokenKey, prompt); err != nil {
+		log.Fatal("Failed to authenticate:", err)
 	}
 
-	// Exchange code for token
-	log.Println("Received authorization code, exchanging for token...")
-	err := client.ConnectWithAuthCode(ctx, code)
-	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to exchange code for token: %v", err), http.StatusInternalServerError)
-		return
-	}
-
-	// Save token
-	token := client.GetToken()
-	if err := saveToken(token); err != nil {
-		log.Println("Failed to save token:", err)
-	} else {
-		log.Println("Token saved successfully")
-	}
-
-	// Success page
-	_, _ = fmt.Fprintf(w, `
-		<html>
-		<head><title>Authorization Successful</title></head>
-		<body>
-			<h1>Authorization Successful!</h1>
-			<p>You can close this window and return to the terminal.</p>
-			<script>setTimeout(() => window.close(), 3000);</script>
-		</body>
-		</html>
-	`)
-
-	// Run examples in background
-	go func() {
-		time.Sleep(2 * time.Second)
-		runExamples(context.Background())
-		os.Exit(0)
-	}()
+	runExamples(ctx)
 }
 
 func runExamples(ctx context.Context) {
@@ -264,7 +152,7 @@ func listMessages(ctx context.Context) {
 	for i, email := range response.Emails {
 		fmt.Printf("[%d] From: %s <%s>\n", i+1, email.From.Name, email.From.Email)
 		fmt.Printf("    Subject: %s\n", email.Subject)
-		fmt.Printf("    Date: %s\n", email.Date.Format("2006-01-02 15:04"))
+		fmt.Printf("    Date: %s\n", email.FormatDate("2006-01-02 15:04", time.Local))
 		fmt.Printf("    Read: %v\n", email.IsRead)
 		if len(email.Snippet) > 100 {
 			fmt.Printf("    Preview: %s...\n", email.Snippet[:100])
@@ -276,7 +164,7 @@ func listMessages(ctx context.Context) {
 }
 
 func listFolders(ctx context.Context) {
-	folders, err := client.ListFolders(ctx)
+	folders, err := client.ListFolders(ctx, nil)
 	if err != nil {
 		log.Printf("Failed to list folders: %v\n", err)
 		return
@@ -308,38 +196,7 @@ func searchMessages(ctx context.Context) {
 	for i, email := range response.Emails {
 		fmt.Printf("[%d] %s\n", i+1, email.Subject)
 		fmt.Printf("    From: %s\n", email.From.Email)
-		fmt.Printf("    Date: %s\n", email.Date.Format("2006-01-02 15:04"))
+		fmt.Printf("    Date: %s\n", email.FormatDate("2006-01-02 15:04", time.Local))
 		fmt.Println()
 	}
 }
-
-// Token persistence functions
-
-func saveToken(token *oauth2.Token) error {
-	data, err := json.MarshalIndent(token, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal token: %w", err)
-	}
-
-	err = os.WriteFile(tokenFile, data, 0600)
-	if err != nil {
-		return fmt.Errorf("failed to write token file: %w", err)
-	}
-
-	return nil
-}
-
-func loadToken() (*oauth2.Token, error) {
-	data, err := os.ReadFile(tokenFile)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read token file: %w", err)
-	}
-
-	var token oauth2.Token
-	err = json.Unmarshal(data, &token)
-	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal token: %w", err)
-	}
-
-	return &token, nil
-}
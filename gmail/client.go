@@ -2,12 +2,17 @@ package gmail
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"sync"
 
 	"github.com/danielrivera/mailbridge-go/core"
 	"github.com/danielrivera/mailbridge-go/gmail/internal"
+	"github.com/danielrivera/mailbridge-go/gmail/operations/drafts"
 	"github.com/danielrivera/mailbridge-go/gmail/operations/labels"
 	"github.com/danielrivera/mailbridge-go/gmail/operations/messages"
+	"github.com/danielrivera/mailbridge-go/gmail/operations/threads"
 	"github.com/danielrivera/mailbridge-go/gmail/operations/watch"
 	"golang.org/x/oauth2"
 	"google.golang.org/api/gmail/v1"
@@ -18,8 +23,10 @@ import (
 type Client struct {
 	config       *Config
 	oauth2Config *oauth2.Config
-	service      internal.GmailService
-	token        *oauth2.Token
+
+	mu      sync.RWMutex // guards service and token
+	service internal.GmailService
+	token   *oauth2.Token
 }
 
 // New creates a new Gmail client
@@ -46,34 +53,63 @@ func (c *Client) ExchangeCode(ctx context.Context, code string) (*oauth2.Token,
 		return nil, fmt.Errorf("failed to exchange code: %w", err)
 	}
 
-	c.token = token
+	c.SetToken(token)
 	return token, nil
 }
 
 // SetToken sets the OAuth2 token for the client
 func (c *Client) SetToken(token *oauth2.Token) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.token = token
 }
 
 // Connect establishes connection to Gmail API using the stored token
 func (c *Client) Connect(ctx context.Context) error {
-	if c.token == nil {
+	token := c.GetToken()
+	if token == nil {
 		return fmt.Errorf("no token available, please authenticate first")
 	}
 
-	httpClient := c.oauth2Config.Client(ctx, c.token)
+	// The token source refreshes the token when it expires
+	httpClient := oauth2.NewClient(ctx, c.tokenSource(ctx, token))
+	// Retry throttled requests below the API calls, so every operation is covered
+	httpClient.Transport = c.config.retryPolicy().Transport(httpClient.Transport)
 
 	service, err := gmail.NewService(ctx, option.WithHTTPClient(httpClient))
 	if err != nil {
 		return fmt.Errorf("failed to create gmail service: %w", err)
 	}
+	// option.WithUserAgent is ignored when a custom HTTP client is supplied,
+	// so the fragment is set on the service directly
+	service.UserAgent = c.config.userAgent()
 
-	c.service = internal.NewRealGmailService(service)
+	c.SetService(internal.NewRealGmailService(service, httpClient))
 	return nil
 }
 
-// SetService sets the Gmail service (used for testing)
+// tokenSource returns a source that refreshes token on expiry, storing each new token
+// on the client and passing it to Config.OnTokenRefresh. Expiry is checked against
+// Config.Clock, not the wall clock. Refreshes outlive ctx's cancellation, as they
+// happen long after Connect returns
+func (c *Client) tokenSource(ctx context.Context, token *oauth2.Token) oauth2.TokenSource {
+	refreshCtx := context.WithoutCancel(ctx)
+	source := core.ClockTokenSource(c.config.clock(), token, func(current *oauth2.Token) (*oauth2.Token, error) {
+		return c.oauth2Config.TokenSource(refreshCtx, &oauth2.Token{RefreshToken: current.RefreshToken}).Token()
+	})
+	return core.NotifyingTokenSource(source, token, func(newToken *oauth2.Token) {
+		c.SetToken(newToken)
+		if c.config.OnTokenRefresh != nil {
+			c.config.OnTokenRefresh(newToken)
+		}
+	})
+}
+
+// SetService sets the Gmail service (used for testing).
+// It is safe to call concurrently with other client methods.
 func (c *Client) SetService(service internal.GmailService) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.service = service
 }
 
@@ -83,38 +119,66 @@ func (c *Client) ConnectWithToken(ctx context.Context, token *oauth2.Token) erro
 	return c.Connect(ctx)
 }
 
+// ConnectWithAuthCode exchanges an authorization code for a token and establishes connection
+func (c *Client) ConnectWithAuthCode(ctx context.Context, code string) error {
+	token, err := c.ExchangeCode(ctx, code)
+	if err != nil {
+		return err
+	}
+	return c.ConnectWithToken(ctx, token)
+}
+
 // IsConnected returns true if the client is connected to Gmail API
 func (c *Client) IsConnected() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	return c.service != nil
 }
 
-// ensureConnected checks if the client is connected and returns an error if not
-func (c *Client) ensureConnected() error {
-	if !c.IsConnected() {
-		return core.ErrNotConnected
+// getService returns the current Gmail service, or core.ErrNotConnected if the client is not connected
+func (c *Client) getService() (internal.GmailService, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.service == nil {
+		return nil, core.ErrNotConnected
 	}
-	return nil
+	return c.service, nil
 }
 
-// GetToken returns the current OAuth2 token
+// GetToken returns the current OAuth2 token, including any refreshed since Connect
 func (c *Client) GetToken() *oauth2.Token {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	return c.token
 }
 
+// GrantedScopes returns the scopes granted to the current token, read from
+// the token's "scope" field as returned by Google's token endpoint.
+// It returns nil when there is no token or the scopes cannot be determined.
+func (c *Client) GrantedScopes() []string {
+	token := c.GetToken()
+	if token == nil {
+		return nil
+	}
+	scopeField, _ := token.Extra("scope").(string)
+	return core.GrantedScopes(scopeField, token.AccessToken)
+}
+
 // RefreshToken refreshes the OAuth2 token if needed
 func (c *Client) RefreshToken(ctx context.Context) (*oauth2.Token, error) {
-	if c.token == nil {
+	token := c.GetToken()
+	if token == nil {
 		return nil, fmt.Errorf("no token to refresh")
 	}
 
-	tokenSource := c.oauth2Config.TokenSource(ctx, c.token)
-	newToken, err := tokenSource.Token()
+	newToken, err := c.tokenSource(ctx, token).Token()
 	if err != nil {
+		if isInvalidGrant(err) {
+			return nil, fmt.Errorf("failed to refresh token: %w: %w", core.ErrReauthRequired, err)
+		}
 		return nil, fmt.Errorf("failed to refresh token: %w", err)
 	}
 
-	c.token = newToken
-
 	// Reconnect with new token
 	if err := c.Connect(ctx); err != nil {
 		return nil, fmt.Errorf("failed to reconnect after token refresh: %w", err)
@@ -123,8 +187,17 @@ func (c *Client) RefreshToken(ctx context.Context) (*oauth2.Token, error) {
 	return newToken, nil
 }
 
+// isInvalidGrant reports whether err is an OAuth2 "invalid_grant" error,
+// which means the refresh token was revoked or has expired
+func isInvalidGrant(err error) bool {
+	var retrieveErr *oauth2.RetrieveError
+	return errors.As(err, &retrieveErr) && retrieveErr.ErrorCode == "invalid_grant"
+}
+
 // Close closes the Gmail client and cleans up resources
 func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.service = nil
 	c.token = nil
 	return nil
@@ -134,210 +207,565 @@ func (c *Client) Close() error {
 
 // ListMessages lists messages from Gmail
 func (c *Client) ListMessages(ctx context.Context, opts *core.ListOptions) (*core.ListResponse, error) {
-	if err := c.ensureConnected(); err != nil {
+	service, err := c.getService()
+	if err != nil {
 		return nil, err
 	}
-	return messages.ListMessages(ctx, c.service, opts)
+	opts = c.config.listOptions(opts)
+	resp, err := messages.ListMessages(ctx, service, opts)
+	if err != nil {
+		return nil, err
+	}
+	// ID-only stubs are not converted messages, so the hook is not applied to them
+	if opts == nil || !opts.IDsOnly {
+		c.config.applyConversionHook(resp.Emails...)
+	}
+	return resp, nil
 }
 
 // GetMessage retrieves a specific message by ID
 func (c *Client) GetMessage(ctx context.Context, messageID string) (*core.Email, error) {
-	if err := c.ensureConnected(); err != nil {
+	service, err := c.getService()
+	if err != nil {
+		return nil, err
+	}
+	email, err := messages.GetMessage(ctx, service, messageID)
+	if err != nil {
+		return nil, err
+	}
+	c.config.applyConversionHook(email)
+	return email, nil
+}
+
+// MessagesIterator returns an iterator over the messages matching opts: call Next until
+// it returns core.ErrIteratorDone. Page tokens are handled internally, and opts.MaxResults
+// caps the total number of messages yielded rather than the page size
+func (c *Client) MessagesIterator(ctx context.Context, opts *core.ListOptions) *core.MessageIterator {
+	return core.IterateMessages(ctx, c.ListMessages, opts)
+}
+
+// EachMessage pages through the messages matching opts and calls fn once per message,
+// stopping at the first error from fn or when ctx is cancelled
+func (c *Client) EachMessage(ctx context.Context, opts *core.ListOptions, fn func(*core.Email) error) error {
+	return core.EachMessage(ctx, c.ListMessages, c.config.listOptions(opts), fn)
+}
+
+// GetMessageWithOptions retrieves a specific message by ID, applying the given options
+func (c *Client) GetMessageWithOptions(ctx context.Context, messageID string, opts *core.GetOptions) (*core.Email, error) {
+	service, err := c.getService()
+	if err != nil {
+		return nil, err
+	}
+	email, err := messages.GetMessageWithOptions(ctx, service, messageID, opts)
+	if err != nil {
+		return nil, err
+	}
+	c.config.applyConversionHook(email)
+	return email, nil
+}
+
+// GetMessageInlineHTML returns the message's HTML body as standalone HTML, with inline
+// (cid:) images embedded as base64 data: URLs
+func (c *Client) GetMessageInlineHTML(ctx context.Context, messageID string) (string, error) {
+	service, err := c.getService()
+	if err != nil {
+		return "", err
+	}
+	return messages.GetMessageInlineHTML(ctx, service, messageID)
+}
+
+// GetMessageSize returns the estimated size of a message in bytes without downloading its body
+func (c *Client) GetMessageSize(ctx context.Context, messageID string) (int64, error) {
+	service, err := c.getService()
+	if err != nil {
+		return 0, err
+	}
+	return messages.GetMessageSize(ctx, service, messageID)
+}
+
+// GetThreadMessages retrieves all messages in a thread, ordered by date
+func (c *Client) GetThreadMessages(ctx context.Context, threadID string) ([]*core.Email, error) {
+	service, err := c.getService()
+	if err != nil {
+		return nil, err
+	}
+	emails, err := messages.GetThreadMessages(ctx, service, threadID)
+	if err != nil {
 		return nil, err
 	}
-	return messages.GetMessage(ctx, c.service, messageID)
+	c.config.applyConversionHook(emails...)
+	return emails, nil
 }
 
-// GetAttachment downloads an attachment by its ID from a specific message
-func (c *Client) GetAttachment(ctx context.Context, messageID, attachmentID string) ([]byte, error) {
-	if err := c.ensureConnected(); err != nil {
+// ListThreads lists conversations matching the options. Each thread carries its ID,
+// snippet and history ID only; use GetThread to fetch its messages
+func (c *Client) ListThreads(ctx context.Context, opts *core.ListOptions) (*core.ThreadListResponse, error) {
+	service, err := c.getService()
+	if err != nil {
+		return nil, err
+	}
+	return threads.ListThreads(ctx, service, c.config.listOptions(opts))
+}
+
+// GetThread retrieves a conversation with all its messages, ordered by date (oldest first)
+func (c *Client) GetThread(ctx context.Context, threadID string) (*core.Thread, error) {
+	service, err := c.getService()
+	if err != nil {
+		return nil, err
+	}
+	thread, err := threads.GetThread(ctx, service, threadID)
+	if err != nil {
+		return nil, err
+	}
+	c.config.applyConversionHook(thread.Messages...)
+	return thread, nil
+}
+
+// GetAttachment downloads an attachment by its ID from a specific message. Gmail's
+// attachment endpoint returns only the content, so Filename and MimeType are not set;
+// they are in the message's Attachments, or use GetAttachmentMetadata
+func (c *Client) GetAttachment(ctx context.Context, messageID, attachmentID string) (*core.Attachment, error) {
+	service, err := c.getService()
+	if err != nil {
+		return nil, err
+	}
+	data, err := messages.GetAttachment(ctx, service, messageID, attachmentID)
+	if err != nil {
+		return nil, err
+	}
+	return &core.Attachment{ID: attachmentID, Size: int64(len(data)), Data: data}, nil
+}
+
+// GetAttachmentStream returns a reader over an attachment's content and its metadata
+// (filename, MIME type and size; Data is nil), e.g. to io.Copy a large file to disk.
+// The caller must close the reader
+func (c *Client) GetAttachmentStream(ctx context.Context, messageID, attachmentID string) (io.ReadCloser, *core.Attachment, error) {
+	service, err := c.getService()
+	if err != nil {
+		return nil, nil, err
+	}
+	return messages.GetAttachmentStream(ctx, service, messageID, attachmentID)
+}
+
+// GetAttachmentMetadata returns an attachment's filename, MIME type and size without its content
+func (c *Client) GetAttachmentMetadata(ctx context.Context, messageID, attachmentID string) (*core.Attachment, error) {
+	service, err := c.getService()
+	if err != nil {
 		return nil, err
 	}
-	return messages.GetAttachment(ctx, c.service, messageID, attachmentID)
+	return messages.GetAttachmentMetadata(ctx, service, messageID, attachmentID)
+}
+
+// GetAttachmentByIndex downloads the Nth (zero-based) attachment of a message
+func (c *Client) GetAttachmentByIndex(ctx context.Context, messageID string, index int) (*core.Attachment, error) {
+	service, err := c.getService()
+	if err != nil {
+		return nil, err
+	}
+	return messages.GetAttachmentByIndex(ctx, service, messageID, index)
+}
+
+// GetMessagePart downloads the body of a single MIME part of a message
+func (c *Client) GetMessagePart(ctx context.Context, messageID, partID string) ([]byte, error) {
+	service, err := c.getService()
+	if err != nil {
+		return nil, err
+	}
+	return messages.GetMessagePart(ctx, service, messageID, partID)
+}
+
+// SendLimits returns Gmail's size limits for outgoing messages, for use with core.ValidateDraft
+func SendLimits() core.SendLimits {
+	return messages.SendLimits()
 }
 
 // SendMessage sends an email message
 func (c *Client) SendMessage(ctx context.Context, draft *core.Draft, opts *core.SendOptions) (*core.SendResponse, error) {
-	if err := c.ensureConnected(); err != nil {
+	service, err := c.getService()
+	if err != nil {
+		return nil, err
+	}
+	return messages.SendMessage(ctx, service, draft, opts, c.config.clock())
+}
+
+// BatchSend sends each draft, up to Config.BatchSendConcurrency at a time. Throttled
+// sends are retried per Config.RetryPolicy. It continues past failed drafts and
+// returns one response per draft; failures are reported together as a *core.BatchError
+func (c *Client) BatchSend(ctx context.Context, drafts []*core.Draft, opts *core.SendOptions) ([]core.SendResponse, error) {
+	service, err := c.getService()
+	if err != nil {
+		return nil, err
+	}
+	return messages.BatchSend(ctx, service, drafts, opts, c.config.clock(), c.config.BatchSendConcurrency)
+}
+
+// Draft operations - delegate to operations/drafts package
+
+// CreateDraft saves a draft in the mailbox without sending it, built like SendMessage
+func (c *Client) CreateDraft(ctx context.Context, draft *core.Draft, opts *core.SendOptions) (*core.DraftResponse, error) {
+	service, err := c.getService()
+	if err != nil {
+		return nil, err
+	}
+	return drafts.CreateDraft(ctx, service, draft, opts, c.config.clock())
+}
+
+// ListDrafts lists saved drafts with their content
+func (c *Client) ListDrafts(ctx context.Context, opts *core.ListOptions) (*core.DraftListResponse, error) {
+	service, err := c.getService()
+	if err != nil {
+		return nil, err
+	}
+	resp, err := drafts.ListDrafts(ctx, service, c.config.listOptions(opts))
+	if err != nil {
+		return nil, err
+	}
+	if opts == nil || !opts.IDsOnly {
+		for _, draft := range resp.Drafts {
+			c.config.applyConversionHook(draft.Message)
+		}
+	}
+	return resp, nil
+}
+
+// GetDraft retrieves a saved draft with its content
+func (c *Client) GetDraft(ctx context.Context, draftID string) (*core.DraftResponse, error) {
+	service, err := c.getService()
+	if err != nil {
+		return nil, err
+	}
+	draft, err := drafts.GetDraft(ctx, service, draftID)
+	if err != nil {
+		return nil, err
+	}
+	c.config.applyConversionHook(draft.Message)
+	return draft, nil
+}
+
+// UpdateDraft replaces the content of a saved draft
+func (c *Client) UpdateDraft(ctx context.Context, draftID string, draft *core.Draft, opts *core.SendOptions) (*core.DraftResponse, error) {
+	service, err := c.getService()
+	if err != nil {
+		return nil, err
+	}
+	return drafts.UpdateDraft(ctx, service, draftID, draft, opts, c.config.clock())
+}
+
+// SendDraft sends a saved draft as it is
+func (c *Client) SendDraft(ctx context.Context, draftID string) (*core.SendResponse, error) {
+	service, err := c.getService()
+	if err != nil {
+		return nil, err
+	}
+	return drafts.SendDraft(ctx, service, draftID)
+}
+
+// DeleteDraft permanently deletes a saved draft
+func (c *Client) DeleteDraft(ctx context.Context, draftID string) error {
+	service, err := c.getService()
+	if err != nil {
+		return err
+	}
+	return drafts.DeleteDraft(ctx, service, draftID)
+}
+
+// SendDraftInThread sends an existing draft as part of the given thread
+func (c *Client) SendDraftInThread(ctx context.Context, draftID, threadID string) (*core.SendResponse, error) {
+	service, err := c.getService()
+	if err != nil {
+		return nil, err
+	}
+	return messages.SendDraftInThread(ctx, service, draftID, threadID)
+}
+
+// ReplyToMessage replies to the sender of a message, in the message's thread
+func (c *Client) ReplyToMessage(ctx context.Context, messageID string, draft *core.Draft, opts *core.SendOptions) (*core.SendResponse, error) {
+	service, err := c.getService()
+	if err != nil {
 		return nil, err
 	}
-	return messages.SendMessage(ctx, c.service, draft, opts)
+	return messages.ReplyToMessage(ctx, service, messageID, draft, opts, c.config.clock())
+}
+
+// ReplyAllToMessage replies to the sender and all recipients of a message except
+// the authenticated user, in the message's thread
+func (c *Client) ReplyAllToMessage(ctx context.Context, messageID string, draft *core.Draft, opts *core.SendOptions) (*core.SendResponse, error) {
+	service, err := c.getService()
+	if err != nil {
+		return nil, err
+	}
+	return messages.ReplyAllToMessage(ctx, service, messageID, draft, opts, c.config.clock())
+}
+
+// ForwardMessage forwards a message to the given recipients, quoting the original below
+// the draft's body. Set opts.IncludeAttachments to re-attach the original attachments
+func (c *Client) ForwardMessage(ctx context.Context, messageID string, to []core.EmailAddress, draft *core.Draft, opts *core.ForwardOptions) (*core.SendResponse, error) {
+	service, err := c.getService()
+	if err != nil {
+		return nil, err
+	}
+	return messages.ForwardMessage(ctx, service, messageID, to, draft, opts, c.config.clock())
 }
 
 // Label operations - delegate to operations/labels package
 
 // ListLabels lists all labels in the user's mailbox
 func (c *Client) ListLabels(ctx context.Context) ([]*labels.Label, error) {
-	if err := c.ensureConnected(); err != nil {
+	service, err := c.getService()
+	if err != nil {
 		return nil, err
 	}
-	return labels.ListLabels(ctx, c.service)
+	return labels.ListLabels(ctx, service)
 }
 
 // GetLabel gets a specific label by ID
 func (c *Client) GetLabel(ctx context.Context, labelID string) (*labels.Label, error) {
-	if err := c.ensureConnected(); err != nil {
+	service, err := c.getService()
+	if err != nil {
 		return nil, err
 	}
-	return labels.GetLabel(ctx, c.service, labelID)
+	return labels.GetLabel(ctx, service, labelID)
+}
+
+// GetMessageLabels returns the labels applied to a message, resolved from their IDs
+// to full labels with type, hierarchy and message counts
+func (c *Client) GetMessageLabels(ctx context.Context, messageID string) ([]core.Label, error) {
+	service, err := c.getService()
+	if err != nil {
+		return nil, err
+	}
+	return labels.GetMessageLabels(ctx, service, messageID)
 }
 
 // FindLabelByName finds a label by its name
 func (c *Client) FindLabelByName(ctx context.Context, name string) (*labels.Label, error) {
-	if err := c.ensureConnected(); err != nil {
+	service, err := c.getService()
+	if err != nil {
 		return nil, err
 	}
-	return labels.FindLabelByName(ctx, c.service, name)
+	return labels.FindLabelByName(ctx, service, name)
 }
 
 // CreateLabel creates a new label (folder)
 func (c *Client) CreateLabel(ctx context.Context, name string) (*labels.Label, error) {
-	if err := c.ensureConnected(); err != nil {
+	service, err := c.getService()
+	if err != nil {
+		return nil, err
+	}
+	return labels.CreateLabel(ctx, service, name)
+}
+
+// CreateLabelWithOptions creates a new label with a color from Gmail's palette and the given visibility
+func (c *Client) CreateLabelWithOptions(ctx context.Context, opts core.LabelOptions) (*labels.Label, error) {
+	service, err := c.getService()
+	if err != nil {
+		return nil, err
+	}
+	return labels.CreateLabelWithOptions(ctx, service, opts)
+}
+
+// UpdateLabel renames a label, keeping its color, visibility and messages
+func (c *Client) UpdateLabel(ctx context.Context, labelID, newName string) (*labels.Label, error) {
+	service, err := c.getService()
+	if err != nil {
 		return nil, err
 	}
-	return labels.CreateLabel(ctx, c.service, name)
+	return labels.UpdateLabel(ctx, service, labelID, newName)
 }
 
 // DeleteLabel deletes a label
 func (c *Client) DeleteLabel(ctx context.Context, labelID string) error {
-	if err := c.ensureConnected(); err != nil {
+	service, err := c.getService()
+	if err != nil {
 		return err
 	}
-	return labels.DeleteLabel(ctx, c.service, labelID)
+	return labels.DeleteLabel(ctx, service, labelID)
 }
 
 // AddLabelToMessage adds a label to a message
 func (c *Client) AddLabelToMessage(ctx context.Context, messageID string, labelID string) error {
-	if err := c.ensureConnected(); err != nil {
+	service, err := c.getService()
+	if err != nil {
 		return err
 	}
-	return labels.AddLabelToMessage(ctx, c.service, messageID, labelID)
+	return labels.AddLabelToMessage(ctx, service, messageID, labelID)
 }
 
 // RemoveLabelFromMessage removes a label from a message
 func (c *Client) RemoveLabelFromMessage(ctx context.Context, messageID string, labelID string) error {
-	if err := c.ensureConnected(); err != nil {
+	service, err := c.getService()
+	if err != nil {
 		return err
 	}
-	return labels.RemoveLabelFromMessage(ctx, c.service, messageID, labelID)
+	return labels.RemoveLabelFromMessage(ctx, service, messageID, labelID)
+}
+
+// SetMessageLabels sets the labels on a message to exactly the given label IDs,
+// adding and removing labels in a single modify. The immutable SENT, DRAFT and CHAT
+// labels are neither added nor removed
+func (c *Client) SetMessageLabels(ctx context.Context, messageID string, labelIDs []string) error {
+	service, err := c.getService()
+	if err != nil {
+		return err
+	}
+	return labels.SetMessageLabels(ctx, service, messageID, labelIDs)
 }
 
 // MarkAsRead marks a message as read
 func (c *Client) MarkAsRead(ctx context.Context, messageID string) error {
-	if err := c.ensureConnected(); err != nil {
+	service, err := c.getService()
+	if err != nil {
 		return err
 	}
-	return labels.MarkAsRead(ctx, c.service, messageID)
+	return labels.MarkAsRead(ctx, service, messageID)
 }
 
 // MarkAsUnread marks a message as unread
 func (c *Client) MarkAsUnread(ctx context.Context, messageID string) error {
-	if err := c.ensureConnected(); err != nil {
+	service, err := c.getService()
+	if err != nil {
+		return err
+	}
+	return labels.MarkAsUnread(ctx, service, messageID)
+}
+
+// SetFocused is not supported: Gmail has no Focused Inbox. It always returns
+// core.ErrNotSupported; use labels or categories (e.g. CATEGORY_PRIMARY) instead
+func (c *Client) SetFocused(ctx context.Context, messageID string, focused bool) error {
+	return fmt.Errorf("gmail has no focused inbox: %w", core.ErrNotSupported)
+}
+
+// ReportSpam marks a message as spam and removes it from the inbox
+func (c *Client) ReportSpam(ctx context.Context, messageID string) error {
+	service, err := c.getService()
+	if err != nil {
+		return err
+	}
+	return labels.ReportSpam(ctx, service, messageID)
+}
+
+// NotSpam moves a message out of spam back to the inbox
+func (c *Client) NotSpam(ctx context.Context, messageID string) error {
+	service, err := c.getService()
+	if err != nil {
 		return err
 	}
-	return labels.MarkAsUnread(ctx, c.service, messageID)
+	return labels.NotSpam(ctx, service, messageID)
 }
 
 // MoveMessageToFolder moves a message to a specific folder/label
 func (c *Client) MoveMessageToFolder(ctx context.Context, messageID string, folderName string) error {
-	if err := c.ensureConnected(); err != nil {
+	service, err := c.getService()
+	if err != nil {
 		return err
 	}
-	return labels.MoveMessageToFolder(ctx, c.service, messageID, folderName)
+	return labels.MoveMessageToFolder(ctx, service, messageID, folderName)
 }
 
 // TrashMessage moves a message to trash (reversible)
 func (c *Client) TrashMessage(ctx context.Context, messageID string) error {
-	if err := c.ensureConnected(); err != nil {
+	service, err := c.getService()
+	if err != nil {
 		return err
 	}
-	return labels.TrashMessage(ctx, c.service, messageID)
+	return labels.TrashMessage(ctx, service, messageID)
 }
 
 // UntrashMessage removes a message from trash
 func (c *Client) UntrashMessage(ctx context.Context, messageID string) error {
-	if err := c.ensureConnected(); err != nil {
+	service, err := c.getService()
+	if err != nil {
 		return err
 	}
-	return labels.UntrashMessage(ctx, c.service, messageID)
+	return labels.UntrashMessage(ctx, service, messageID)
 }
 
 // BatchTrashMessages moves multiple messages to trash
 func (c *Client) BatchTrashMessages(ctx context.Context, messageIDs []string) error {
-	if err := c.ensureConnected(); err != nil {
+	service, err := c.getService()
+	if err != nil {
 		return err
 	}
-	return labels.BatchTrashMessages(ctx, c.service, messageIDs)
+	return labels.BatchTrashMessages(ctx, service, messageIDs)
 }
 
 // DeleteMessage permanently deletes a message (not reversible)
 func (c *Client) DeleteMessage(ctx context.Context, messageID string) error {
-	if err := c.ensureConnected(); err != nil {
+	service, err := c.getService()
+	if err != nil {
 		return err
 	}
-	return messages.DeleteMessage(ctx, c.service, messageID)
+	return messages.DeleteMessage(ctx, service, messageID)
 }
 
 // BatchDeleteMessages permanently deletes multiple messages
 func (c *Client) BatchDeleteMessages(ctx context.Context, messageIDs []string) error {
-	if err := c.ensureConnected(); err != nil {
+	service, err := c.getService()
+	if err != nil {
 		return err
 	}
-	return messages.BatchDeleteMessages(ctx, c.service, messageIDs)
+	return messages.BatchDeleteMessages(ctx, service, messageIDs)
 }
 
 // BatchModifyMessages modifies labels on multiple messages
 func (c *Client) BatchModifyMessages(ctx context.Context, req *core.BatchModifyRequest) error {
-	if err := c.ensureConnected(); err != nil {
+	service, err := c.getService()
+	if err != nil {
 		return err
 	}
-	return labels.BatchModifyMessages(ctx, c.service, req.MessageIDs, req.AddLabelIDs, req.RemoveLabelIDs)
+	return labels.BatchModifyMessages(ctx, service, req.MessageIDs, req.AddLabelIDs, req.RemoveLabelIDs)
 }
 
 // BatchMarkAsRead marks multiple messages as read
 func (c *Client) BatchMarkAsRead(ctx context.Context, messageIDs []string) error {
-	if err := c.ensureConnected(); err != nil {
+	service, err := c.getService()
+	if err != nil {
 		return err
 	}
-	return labels.BatchMarkAsRead(ctx, c.service, messageIDs)
+	return labels.BatchMarkAsRead(ctx, service, messageIDs)
 }
 
 // BatchMarkAsUnread marks multiple messages as unread
 func (c *Client) BatchMarkAsUnread(ctx context.Context, messageIDs []string) error {
-	if err := c.ensureConnected(); err != nil {
+	service, err := c.getService()
+	if err != nil {
 		return err
 	}
-	return labels.BatchMarkAsUnread(ctx, c.service, messageIDs)
+	return labels.BatchMarkAsUnread(ctx, service, messageIDs)
 }
 
 // BatchMoveToFolder moves multiple messages to a specific folder
 func (c *Client) BatchMoveToFolder(ctx context.Context, messageIDs []string, folderName string) error {
-	if err := c.ensureConnected(); err != nil {
+	service, err := c.getService()
+	if err != nil {
 		return err
 	}
-	return labels.BatchMoveToFolder(ctx, c.service, messageIDs, folderName)
+	return labels.BatchMoveToFolder(ctx, service, messageIDs, folderName)
 }
 
 // WatchMailbox sets up push notifications for the mailbox
 func (c *Client) WatchMailbox(ctx context.Context, req *core.WatchRequest) (*core.WatchResponse, error) {
-	if err := c.ensureConnected(); err != nil {
+	service, err := c.getService()
+	if err != nil {
 		return nil, err
 	}
-	return watch.WatchMailbox(ctx, c.service, req)
+	return watch.WatchMailbox(ctx, service, req)
 }
 
 // StopWatch stops push notifications for the mailbox
 func (c *Client) StopWatch(ctx context.Context) error {
-	if err := c.ensureConnected(); err != nil {
+	service, err := c.getService()
+	if err != nil {
 		return err
 	}
-	return watch.StopWatch(ctx, c.service)
+	return watch.StopWatch(ctx, service)
 }
 
 // GetHistory retrieves mailbox history starting from a history ID
 func (c *Client) GetHistory(ctx context.Context, req *core.HistoryRequest) (*core.HistoryResponse, error) {
-	if err := c.ensureConnected(); err != nil {
+	service, err := c.getService()
+	if err != nil {
 		return nil, err
 	}
-	return watch.GetHistory(ctx, c.service, req)
+	return watch.GetHistory(ctx, service, req)
 }
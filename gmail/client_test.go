@@ -2,12 +2,48 @@ package gmail
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	mailbridge "github.com/danielrivera/mailbridge-go"
+	"github.com/danielrivera/mailbridge-go/core"
+	gmailtest "github.com/danielrivera/mailbridge-go/gmail/testing"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"golang.org/x/oauth2"
+	gmailapi "google.golang.org/api/gmail/v1"
 )
 
+// Client must be usable with core.RunInteractiveAuth
+var _ core.AuthClient = (*Client)(nil)
+
+// Client must be usable as a core.Provider
+var _ core.Provider = (*Client)(nil)
+
+// recordingTransport records outgoing requests and returns a canned JSON response
+type recordingTransport struct {
+	requests []*http.Request
+	body     string
+}
+
+func (r *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	r.requests = append(r.requests, req)
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(r.body)),
+		Request:    req,
+	}, nil
+}
+
 func TestNew(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -125,3 +161,398 @@ func TestClient_ConnectWithoutToken(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "no token available")
 }
+
+func TestClient_UserAgent(t *testing.T) {
+	tests := []struct {
+		name      string
+		userAgent string
+		expected  string
+	}{
+		{
+			name:      "custom user agent",
+			userAgent: "my-app/2.1",
+			expected:  "my-app/2.1",
+		},
+		{
+			name:     "default user agent includes version",
+			expected: "mailbridge-go/" + mailbridge.Version,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := newTestConfig()
+			config.UserAgent = tt.userAgent
+			client, err := New(config)
+			require.NoError(t, err)
+
+			transport := &recordingTransport{body: `{"labels":[]}`}
+			ctx := context.WithValue(context.Background(), oauth2.HTTPClient, &http.Client{Transport: transport})
+
+			err = client.ConnectWithToken(ctx, &oauth2.Token{
+				AccessToken: "test-token",
+				Expiry:      time.Now().Add(time.Hour),
+			})
+			require.NoError(t, err)
+
+			_, err = client.ListLabels(ctx)
+			require.NoError(t, err)
+
+			require.Len(t, transport.requests, 1)
+			assert.Contains(t, transport.requests[0].Header.Get("User-Agent"), tt.expected)
+		})
+	}
+}
+
+// throttlingTransport answers the first throttled requests with 429 and a Retry-After
+// header, then with a canned JSON response
+type throttlingTransport struct {
+	throttled int
+	body      string
+	attempts  int
+}
+
+func (r *throttlingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	r.attempts++
+	if r.attempts <= r.throttled {
+		return &http.Response{
+			StatusCode: http.StatusTooManyRequests,
+			Header:     http.Header{"Content-Type": []string{"application/json"}, "Retry-After": []string{"0"}},
+			Body:       io.NopCloser(strings.NewReader(`{"error":{"code":429,"message":"Too many requests"}}`)),
+			Request:    req,
+		}, nil
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(r.body)),
+		Request:    req,
+	}, nil
+}
+
+func TestClient_RetryPolicy(t *testing.T) {
+	tests := []struct {
+		name             string
+		policy           *core.RetryPolicy
+		expectedAttempts int
+		wantErr          bool
+	}{
+		{"default policy retries", nil, 3, false},
+		{"custom policy gives up", &core.RetryPolicy{MaxRetries: 1, BaseDelay: time.Millisecond, MaxDelay: time.Second}, 2, true},
+		{"disabled", &core.RetryPolicy{}, 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := newTestConfig()
+			config.RetryPolicy = tt.policy
+			client, err := New(config)
+			require.NoError(t, err)
+
+			transport := &throttlingTransport{throttled: 2, body: `{"labels":[]}`}
+			ctx := context.WithValue(context.Background(), oauth2.HTTPClient, &http.Client{Transport: transport})
+			require.NoError(t, client.ConnectWithToken(ctx, &oauth2.Token{
+				AccessToken: "test-token",
+				Expiry:      time.Now().Add(time.Hour),
+			}))
+
+			_, err = client.ListLabels(ctx)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expectedAttempts, transport.attempts)
+		})
+	}
+}
+
+func TestClient_SetService_Concurrent(t *testing.T) {
+	client := newTestClient(t)
+
+	mockService := &gmailtest.MockGmailService{}
+	mockUsers := &gmailtest.MockUsersService{}
+	mockLabels := &gmailtest.MockLabelsService{}
+	mockListCall := &gmailtest.MockLabelsListCall{}
+
+	mockService.On("GetUsersService").Return(mockUsers)
+	mockUsers.On("GetLabelsService").Return(mockLabels)
+	mockLabels.On("List", "me").Return(mockListCall)
+	mockListCall.On("Context", mock.Anything).Return(mockListCall)
+	mockListCall.On("Do").Return(&gmailapi.ListLabelsResponse{}, nil)
+
+	client.SetService(mockService)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			client.SetService(mockService)
+		}()
+		go func() {
+			defer wg.Done()
+			assert.True(t, client.IsConnected())
+			_, err := client.ListLabels(context.Background())
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+}
+
+func TestClient_GrantedScopes(t *testing.T) {
+	client := newTestClient(t)
+
+	// No token
+	assert.Nil(t, client.GrantedScopes())
+
+	token := (&oauth2.Token{AccessToken: "ya29.opaque"}).WithExtra(map[string]interface{}{
+		"scope": "https://www.googleapis.com/auth/gmail.readonly https://www.googleapis.com/auth/gmail.send",
+	})
+	client.SetToken(token)
+
+	assert.Equal(t, []string{
+		"https://www.googleapis.com/auth/gmail.readonly",
+		"https://www.googleapis.com/auth/gmail.send",
+	}, client.GrantedScopes())
+}
+
+func TestClient_ListMessages_DefaultPageSize(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     *core.ListOptions
+		expected int64
+	}{
+		{name: "nil options", opts: nil, expected: 25},
+		{name: "unset max results", opts: &core.ListOptions{}, expected: 25},
+		{name: "explicit max results", opts: &core.ListOptions{MaxResults: 5}, expected: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := newTestConfig()
+			config.DefaultPageSize = 25
+			client, err := New(config)
+			require.NoError(t, err)
+
+			mockService := &gmailtest.MockGmailService{}
+			mockUsers := &gmailtest.MockUsersService{}
+			mockMessages := &gmailtest.MockMessagesService{}
+			mockListCall := &gmailtest.MockMessagesListCall{}
+			mockService.On("GetUsersService").Return(mockUsers)
+			mockUsers.On("GetMessagesService").Return(mockMessages)
+			mockMessages.On("List", "me").Return(mockListCall)
+			mockListCall.On("MaxResults", tt.expected).Return(mockListCall)
+			mockListCall.On("Context", mock.Anything).Return(mockListCall)
+			mockListCall.On("Do").Return(&gmailapi.ListMessagesResponse{}, nil)
+			client.SetService(mockService)
+
+			_, err = client.ListMessages(context.Background(), tt.opts)
+
+			require.NoError(t, err)
+			mockListCall.AssertExpectations(t)
+		})
+	}
+}
+
+func TestClient_ConversionHook(t *testing.T) {
+	config := newTestConfig()
+	config.ConversionHook = func(email *core.Email) {
+		email.Subject = "[redacted] " + email.Subject
+	}
+	client, err := New(config)
+	require.NoError(t, err)
+
+	mockService := &gmailtest.MockGmailService{}
+	mockUsers := &gmailtest.MockUsersService{}
+	mockMessages := &gmailtest.MockMessagesService{}
+	mockListCall := &gmailtest.MockMessagesListCall{}
+	mockGetCall := &gmailtest.MockMessagesGetCall{}
+	mockService.On("GetUsersService").Return(mockUsers)
+	mockUsers.On("GetMessagesService").Return(mockMessages)
+	mockMessages.On("List", "me").Return(mockListCall)
+	mockListCall.On("MaxResults", mock.Anything).Return(mockListCall)
+	mockListCall.On("Fields", mock.Anything).Return(mockListCall)
+	mockListCall.On("Context", mock.Anything).Return(mockListCall)
+	mockListCall.On("Do").Return(&gmailapi.ListMessagesResponse{
+		Messages: []*gmailapi.Message{{Id: "msg-1", ThreadId: "thread-1"}},
+	}, nil)
+	mockMessages.On("Get", "me", "msg-1").Return(mockGetCall)
+	mockGetCall.On("Format", "full").Return(mockGetCall)
+	mockGetCall.On("Context", mock.Anything).Return(mockGetCall)
+	mockGetCall.On("Do").Return(&gmailapi.Message{
+		Id: "msg-1",
+		Payload: &gmailapi.MessagePart{
+			Headers: []*gmailapi.MessagePartHeader{{Name: "Subject", Value: "Payroll"}},
+		},
+	}, nil)
+	client.SetService(mockService)
+
+	resp, err := client.ListMessages(context.Background(), &core.ListOptions{MaxResults: 1})
+	require.NoError(t, err)
+	require.Len(t, resp.Emails, 1)
+	assert.Equal(t, "[redacted] Payroll", resp.Emails[0].Subject)
+
+	email, err := client.GetMessage(context.Background(), "msg-1")
+	require.NoError(t, err)
+	assert.Equal(t, "[redacted] Payroll", email.Subject, "hook runs once per returned email")
+
+	ids, err := client.ListMessages(context.Background(), &core.ListOptions{MaxResults: 1, IDsOnly: true})
+	require.NoError(t, err)
+	assert.Empty(t, ids.Emails[0].Subject, "ID-only stubs are not passed to the hook")
+}
+
+func TestClient_RefreshToken_ReauthRequired(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		body       string
+		wantReauth bool
+	}{
+		{
+			name:       "invalid grant",
+			status:     http.StatusBadRequest,
+			body:       `{"error":"invalid_grant","error_description":"Token has been expired or revoked."}`,
+			wantReauth: true,
+		},
+		{
+			name:       "server error",
+			status:     http.StatusInternalServerError,
+			body:       `{"error":"server_error"}`,
+			wantReauth: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client := newTestClient(t)
+			client.oauth2Config.Endpoint.TokenURL = server.URL
+			client.SetToken(&oauth2.Token{
+				AccessToken:  "expired-access",
+				RefreshToken: "revoked-refresh",
+				Expiry:       time.Now().Add(-time.Hour),
+			})
+
+			token, err := client.RefreshToken(context.Background())
+
+			assert.Nil(t, token)
+			require.Error(t, err)
+			assert.Equal(t, tt.wantReauth, errors.Is(err, core.ErrReauthRequired))
+		})
+	}
+}
+
+// tokenEndpointTransport serves the OAuth2 token endpoint, minting a new access token on
+// every refresh that expires at once, and answers API requests with a canned JSON body
+type tokenEndpointTransport struct {
+	minted      int
+	apiRequests []*http.Request
+}
+
+func (r *tokenEndpointTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body := `{"labels":[]}`
+	if req.URL.Host == "oauth.test" {
+		r.minted++
+		// Tokens expiring within oauth2's 10s expiry margin are already considered expired
+		body = fmt.Sprintf(`{"access_token":"access-%d","token_type":"Bearer","refresh_token":"refresh","expires_in":1}`, r.minted)
+	} else {
+		r.apiRequests = append(r.apiRequests, req)
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+func TestClient_AutoRefresh(t *testing.T) {
+	var persisted []*oauth2.Token
+	config := newTestConfig()
+	config.OnTokenRefresh = func(token *oauth2.Token) { persisted = append(persisted, token) }
+	client, err := New(config)
+	require.NoError(t, err)
+	client.oauth2Config.Endpoint.TokenURL = "https://oauth.test/token"
+
+	transport := &tokenEndpointTransport{}
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), oauth2.HTTPClient, &http.Client{Transport: transport}))
+	require.NoError(t, client.ConnectWithToken(ctx, &oauth2.Token{
+		AccessToken:  "expired-access",
+		RefreshToken: "refresh",
+		Expiry:       time.Now().Add(-time.Minute),
+	}))
+	cancel() // refreshes must not depend on the context passed to Connect
+
+	for range 2 {
+		_, err = client.ListLabels(context.Background())
+		require.NoError(t, err)
+	}
+
+	require.Len(t, persisted, 2, "every refresh reaches OnTokenRefresh")
+	assert.Equal(t, "access-1", persisted[0].AccessToken)
+	assert.Equal(t, "access-2", persisted[1].AccessToken)
+	assert.Same(t, persisted[1], client.GetToken())
+	require.Len(t, transport.apiRequests, 2)
+	assert.Equal(t, "Bearer access-1", transport.apiRequests[0].Header.Get("Authorization"))
+	assert.Equal(t, "Bearer access-2", transport.apiRequests[1].Header.Get("Authorization"))
+}
+
+func TestClient_RefreshToken_NotifiesCallback(t *testing.T) {
+	var persisted *oauth2.Token
+	config := newTestConfig()
+	config.OnTokenRefresh = func(token *oauth2.Token) { persisted = token }
+	client, err := New(config)
+	require.NoError(t, err)
+	client.oauth2Config.Endpoint.TokenURL = "https://oauth.test/token"
+	client.SetToken(&oauth2.Token{
+		AccessToken:  "expired-access",
+		RefreshToken: "refresh",
+		Expiry:       time.Now().Add(-time.Minute),
+	})
+
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, &http.Client{Transport: &tokenEndpointTransport{}})
+	token, err := client.RefreshToken(ctx)
+
+	require.NoError(t, err)
+	assert.Equal(t, "access-1", token.AccessToken)
+	assert.Same(t, token, persisted)
+	assert.Same(t, token, client.GetToken())
+	assert.True(t, client.IsConnected())
+}
+
+func TestClient_SetFocused_NotSupported(t *testing.T) {
+	client := newTestClient(t)
+
+	err := client.SetFocused(context.Background(), "msg-123", true)
+
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, core.ErrNotSupported))
+}
+
+func TestClient_GetAttachment(t *testing.T) {
+	client := newTestClient(t)
+
+	mockService := &gmailtest.MockGmailService{}
+	mockUsers := &gmailtest.MockUsersService{}
+	mockMessages := &gmailtest.MockMessagesService{}
+	mockAttachmentCall := &gmailtest.MockMessagesAttachmentGetCall{}
+	mockService.On("GetUsersService").Return(mockUsers)
+	mockUsers.On("GetMessagesService").Return(mockMessages)
+	mockMessages.On("GetAttachment", "me", "msg-123", "att-1").Return(mockAttachmentCall)
+	mockAttachmentCall.On("Context", mock.Anything).Return(mockAttachmentCall)
+	mockAttachmentCall.On("Do").Return(&gmailapi.MessagePartBody{Data: "SGVsbG8"}, nil)
+	client.SetService(mockService)
+
+	attachment, err := client.GetAttachment(context.Background(), "msg-123", "att-1")
+
+	require.NoError(t, err)
+	assert.Equal(t, &core.Attachment{ID: "att-1", Size: 5, Data: []byte("Hello")}, attachment)
+}
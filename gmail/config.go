@@ -12,6 +12,26 @@ type Config struct {
 	ClientSecret string   `json:"client_secret"`
 	RedirectURL  string   `json:"redirect_url"`
 	Scopes       []string `json:"scopes,omitempty"`
+	UserAgent    string   `json:"user_agent,omitempty"` // Application identifier sent with API requests (default: core.DefaultUserAgent)
+
+	DefaultPageSize int `json:"default_page_size,omitempty"` // MaxResults used when ListOptions.MaxResults is 0 (default: provider default)
+
+	BatchSendConcurrency int `json:"batch_send_concurrency,omitempty"` // Drafts sent in parallel by BatchSend (default: 4)
+
+	Clock core.Clock `json:"-"` // Source of the current time (default: core.SystemClock)
+
+	// RetryPolicy controls retries of requests Gmail throttles with 429 or 503, honoring
+	// Retry-After (default: core.DefaultRetryPolicy). Set MaxRetries to 0 to disable retries
+	RetryPolicy *core.RetryPolicy `json:"retry_policy,omitempty"`
+
+	// ConversionHook, when set, is called with every email the client returns from
+	// ListMessages, GetMessage, GetMessageWithOptions and GetThreadMessages, and may
+	// modify it (e.g. redact PII or tag internal senders)
+	ConversionHook func(*core.Email) `json:"-"`
+
+	// OnTokenRefresh, when set, is called with every new token the client obtains,
+	// whether refreshed automatically on expiry or by RefreshToken, so it can be persisted
+	OnTokenRefresh func(*oauth2.Token) `json:"-"`
 }
 
 // DefaultScopes returns the default Gmail API scopes
@@ -35,12 +55,74 @@ func (c *Config) Validate() error {
 	if c.RedirectURL == "" {
 		return core.NewConfigFieldError("redirect_url", "is required")
 	}
+	if c.DefaultPageSize < 0 {
+		return core.NewConfigFieldError("default_page_size", "cannot be negative")
+	}
+	if c.BatchSendConcurrency < 0 {
+		return core.NewConfigFieldError("batch_send_concurrency", "cannot be negative")
+	}
+	if c.RetryPolicy != nil && c.RetryPolicy.MaxRetries < 0 {
+		return core.NewConfigFieldError("retry_policy", "max retries cannot be negative")
+	}
 	if len(c.Scopes) == 0 {
 		c.Scopes = DefaultScopes()
 	}
 	return nil
 }
 
+// userAgent returns the configured User-Agent or the library default
+func (c *Config) userAgent() string {
+	if c.UserAgent == "" {
+		return core.DefaultUserAgent
+	}
+	return c.UserAgent
+}
+
+// clock returns the configured Clock or core.SystemClock
+func (c *Config) clock() core.Clock {
+	if c == nil || c.Clock == nil {
+		return core.SystemClock
+	}
+	return c.Clock
+}
+
+// retryPolicy returns the configured RetryPolicy or core.DefaultRetryPolicy
+func (c *Config) retryPolicy() *core.RetryPolicy {
+	if c == nil || c.RetryPolicy == nil {
+		return core.DefaultRetryPolicy()
+	}
+	return c.RetryPolicy
+}
+
+// applyConversionHook calls ConversionHook on each non-nil email, if a hook is set
+func (c *Config) applyConversionHook(emails ...*core.Email) {
+	if c == nil || c.ConversionHook == nil {
+		return
+	}
+	for _, email := range emails {
+		if email != nil {
+			c.ConversionHook(email)
+		}
+	}
+}
+
+// listOptions returns opts with MaxResults set to DefaultPageSize when the caller left it unset.
+// The caller's options are never modified.
+func (c *Config) listOptions(opts *core.ListOptions) *core.ListOptions {
+	if c == nil || c.DefaultPageSize <= 0 {
+		return opts
+	}
+	if opts == nil {
+		return &core.ListOptions{MaxResults: int64(c.DefaultPageSize)}
+	}
+	if opts.MaxResults > 0 {
+		return opts
+	}
+	withDefault := *opts
+	withDefault.MaxResults = int64(c.DefaultPageSize)
+	return &withDefault
+}
+
 // ToOAuth2Config converts Gmail config to oauth2.Config
 func (c *Config) ToOAuth2Config() *oauth2.Config {
 	return &oauth2.Config{
@@ -3,6 +3,7 @@ package gmail
 import (
 	"testing"
 
+	"github.com/danielrivera/mailbridge-go/core"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -74,6 +75,39 @@ func TestConfig_Validate(t *testing.T) {
 			wantErr: true,
 			errMsg:  "redirect_url",
 		},
+		{
+			name: "negative default page size",
+			config: &Config{
+				ClientID:        "test-id",
+				ClientSecret:    "test-secret",
+				RedirectURL:     "http://localhost",
+				DefaultPageSize: -1,
+			},
+			wantErr: true,
+			errMsg:  "default_page_size",
+		},
+		{
+			name: "negative batch send concurrency",
+			config: &Config{
+				ClientID:             "test-id",
+				ClientSecret:         "test-secret",
+				RedirectURL:          "http://localhost",
+				BatchSendConcurrency: -1,
+			},
+			wantErr: true,
+			errMsg:  "batch_send_concurrency",
+		},
+		{
+			name: "negative retry count",
+			config: &Config{
+				ClientID:     "test-id",
+				ClientSecret: "test-secret",
+				RedirectURL:  "http://localhost",
+				RetryPolicy:  &core.RetryPolicy{MaxRetries: -1},
+			},
+			wantErr: true,
+			errMsg:  "retry_policy",
+		},
 		{
 			name: "missing scopes auto-filled",
 			config: &Config{
@@ -128,3 +162,25 @@ func TestConfig_ToOAuth2Config(t *testing.T) {
 	assert.Equal(t, []string{"scope1", "scope2"}, oauth2Config.Scopes)
 	assert.NotNil(t, oauth2Config.Endpoint)
 }
+
+func TestConfig_ListOptions(t *testing.T) {
+	config := newTestConfig()
+	config.DefaultPageSize = 25
+
+	// Unset MaxResults gets the configured default
+	assert.Equal(t, int64(25), config.listOptions(nil).MaxResults)
+
+	opts := &core.ListOptions{Query: "is:unread"}
+	withDefault := config.listOptions(opts)
+	assert.Equal(t, int64(25), withDefault.MaxResults)
+	assert.Equal(t, "is:unread", withDefault.Query)
+	assert.Equal(t, int64(0), opts.MaxResults, "caller options must not be modified")
+
+	// Explicit MaxResults wins
+	explicit := &core.ListOptions{MaxResults: 5}
+	assert.Same(t, explicit, config.listOptions(explicit))
+
+	// No default configured
+	config.DefaultPageSize = 0
+	assert.Nil(t, config.listOptions(nil))
+}
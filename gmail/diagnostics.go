@@ -0,0 +1,39 @@
+package gmail
+
+import (
+	"context"
+
+	"github.com/danielrivera/mailbridge-go/core"
+	"github.com/danielrivera/mailbridge-go/gmail/operations/labels"
+)
+
+// Diagnostics returns a health report for the client: connection status, token expiry,
+// granted scopes and the latency of a sample API call (listing labels).
+// Token values are never included in the report.
+func (c *Client) Diagnostics(ctx context.Context) (*core.Diagnostics, error) {
+	report := &core.Diagnostics{Provider: "gmail"}
+
+	if token := c.GetToken(); token != nil {
+		report.HasToken = true
+		report.HasRefreshToken = token.RefreshToken != ""
+		report.TokenExpiry = token.Expiry
+		report.TokenExpired = !token.Expiry.IsZero() && c.config.clock().Now().After(token.Expiry)
+		report.Scopes = c.GrantedScopes()
+	}
+
+	service, err := c.getService()
+	if err != nil {
+		return report, nil
+	}
+	report.Connected = true
+
+	clock := c.config.clock()
+	start := clock.Now()
+	_, err = labels.ListLabels(ctx, service)
+	report.Latency = clock.Now().Sub(start)
+	if err != nil {
+		report.Error = err.Error()
+	}
+
+	return report, nil
+}
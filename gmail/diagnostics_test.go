@@ -0,0 +1,90 @@
+package gmail
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/danielrivera/mailbridge-go/core"
+	gmailtest "github.com/danielrivera/mailbridge-go/gmail/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+	gmailapi "google.golang.org/api/gmail/v1"
+)
+
+func TestClient_Diagnostics(t *testing.T) {
+	client := newTestClient(t)
+	expiry := time.Now().Add(time.Hour).Truncate(time.Second)
+
+	token := (&oauth2.Token{
+		AccessToken:  "ya29.super-secret-access",
+		RefreshToken: "1//super-secret-refresh",
+		Expiry:       expiry,
+	}).WithExtra(map[string]interface{}{
+		"scope": "https://www.googleapis.com/auth/gmail.readonly",
+	})
+	client.SetToken(token)
+
+	mockService := &gmailtest.MockGmailService{}
+	mockUsers := &gmailtest.MockUsersService{}
+	mockLabels := &gmailtest.MockLabelsService{}
+	mockListCall := &gmailtest.MockLabelsListCall{}
+	mockService.On("GetUsersService").Return(mockUsers)
+	mockUsers.On("GetLabelsService").Return(mockLabels)
+	mockLabels.On("List", "me").Return(mockListCall)
+	mockListCall.On("Context", mock.Anything).Return(mockListCall)
+	mockListCall.On("Do").Return(&gmailapi.ListLabelsResponse{}, nil)
+	client.SetService(mockService)
+
+	report, err := client.Diagnostics(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, "gmail", report.Provider)
+	assert.True(t, report.Connected)
+	assert.True(t, report.HasToken)
+	assert.True(t, report.HasRefreshToken)
+	assert.False(t, report.TokenExpired)
+	assert.Equal(t, expiry, report.TokenExpiry)
+	assert.Equal(t, []string{"https://www.googleapis.com/auth/gmail.readonly"}, report.Scopes)
+	assert.Empty(t, report.Error)
+
+	encoded, err := json.Marshal(report)
+	require.NoError(t, err)
+	assert.NotContains(t, string(encoded), "super-secret-access")
+	assert.NotContains(t, string(encoded), "super-secret-refresh")
+}
+
+func TestClient_Diagnostics_NotConnected(t *testing.T) {
+	client := newTestClient(t)
+
+	report, err := client.Diagnostics(context.Background())
+
+	require.NoError(t, err)
+	assert.False(t, report.Connected)
+	assert.False(t, report.HasToken)
+	assert.Zero(t, report.Latency)
+}
+
+func TestClient_Diagnostics_TokenExpiryWithFakeClock(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	clock := core.NewFakeClock(now)
+
+	config := newTestConfig()
+	config.Clock = clock
+	client, err := New(config)
+	require.NoError(t, err)
+	client.SetToken(&oauth2.Token{AccessToken: "opaque", Expiry: now.Add(time.Minute)})
+
+	report, err := client.Diagnostics(context.Background())
+	require.NoError(t, err)
+	assert.False(t, report.TokenExpired)
+
+	clock.Advance(2 * time.Minute)
+
+	report, err = client.Diagnostics(context.Background())
+	require.NoError(t, err)
+	assert.True(t, report.TokenExpired)
+}
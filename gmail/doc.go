@@ -152,9 +152,9 @@
 //	})
 //
 //	// Reply to a message
-//	err := client.ReplyToMessage(ctx, messageID, &core.Message{
+//	resp, err := client.ReplyToMessage(ctx, messageID, &core.Draft{
 //	    Body: core.EmailBody{Text: "Thanks for your message!"},
-//	})
+//	}, nil)
 //
 // Attachments:
 //
@@ -163,7 +163,7 @@
 //	if err != nil {
 //	    log.Fatal(err)
 //	}
-//	os.WriteFile(attachment.Filename, attachment.Data, 0644)
+//	os.WriteFile(filename, attachment.Data, 0644) // filename from email.Attachments
 //
 //	// Send message with attachment
 //	err := client.SendMessage(ctx, &core.Message{
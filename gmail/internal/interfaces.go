@@ -2,8 +2,10 @@ package internal
 
 import (
 	"context"
+	"io"
 
 	"google.golang.org/api/gmail/v1"
+	"google.golang.org/api/googleapi"
 )
 
 // GmailService is an interface for gmail.Service operations
@@ -15,9 +17,12 @@ type GmailService interface {
 type UsersService interface {
 	GetMessagesService() MessagesService
 	GetLabelsService() LabelsService
+	GetThreadsService() ThreadsService
+	GetDraftsService() DraftsService
 	Watch(userID string, req *gmail.WatchRequest) UsersWatchCall
 	Stop(userID string) UsersStopCall
 	GetHistory(userID string) UsersHistoryListCall
+	GetProfile(userID string) UsersGetProfileCall
 }
 
 // MessagesService is an interface for gmail messages operations
@@ -26,6 +31,7 @@ type MessagesService interface {
 	Get(userID, messageID string) MessagesGetCall
 	Modify(userID, messageID string, req *gmail.ModifyMessageRequest) MessagesModifyCall
 	GetAttachment(userID, messageID, attachmentID string) MessagesAttachmentGetCall
+	GetAttachmentContent(ctx context.Context, userID, messageID, attachmentID string) (io.ReadCloser, error)
 	Send(userID string, message *gmail.Message) MessagesSendCall
 	Trash(userID, messageID string) MessagesTrashCall
 	Untrash(userID, messageID string) MessagesUntrashCall
@@ -37,15 +43,33 @@ type LabelsService interface {
 	List(userID string) LabelsListCall
 	Get(userID, labelID string) LabelsGetCall
 	Create(userID string, label *gmail.Label) LabelsCreateCall
+	Patch(userID, labelID string, label *gmail.Label) LabelsPatchCall
 	Delete(userID, labelID string) LabelsDeleteCall
 }
 
+// ThreadsService is an interface for gmail threads operations
+type ThreadsService interface {
+	List(userID string) ThreadsListCall
+	Get(userID, threadID string) ThreadsGetCall
+}
+
+// DraftsService is an interface for gmail drafts operations
+type DraftsService interface {
+	List(userID string) DraftsListCall
+	Get(userID, draftID string) DraftsGetCall
+	Create(userID string, draft *gmail.Draft) DraftsCreateCall
+	Update(userID, draftID string, draft *gmail.Draft) DraftsUpdateCall
+	Send(userID string, draft *gmail.Draft) DraftsSendCall
+	Delete(userID, draftID string) DraftsDeleteCall
+}
+
 // MessagesListCall is an interface for messages list API calls
 type MessagesListCall interface {
 	MaxResults(maxResults int64) MessagesListCall
 	PageToken(token string) MessagesListCall
 	Q(query string) MessagesListCall
 	LabelIds(labelIds ...string) MessagesListCall
+	Fields(fields ...googleapi.Field) MessagesListCall
 	Context(ctx context.Context) MessagesListCall
 	Do() (*gmail.ListMessagesResponse, error)
 }
@@ -53,6 +77,7 @@ type MessagesListCall interface {
 // MessagesGetCall is an interface for messages get API calls
 type MessagesGetCall interface {
 	Format(format string) MessagesGetCall
+	Fields(fields ...googleapi.Field) MessagesGetCall
 	Context(ctx context.Context) MessagesGetCall
 	Do() (*gmail.Message, error)
 }
@@ -87,6 +112,12 @@ type LabelsCreateCall interface {
 	Do() (*gmail.Label, error)
 }
 
+// LabelsPatchCall is an interface for labels patch API calls
+type LabelsPatchCall interface {
+	Context(ctx context.Context) LabelsPatchCall
+	Do() (*gmail.Label, error)
+}
+
 // LabelsDeleteCall is an interface for labels delete API calls
 type LabelsDeleteCall interface {
 	Context(ctx context.Context) LabelsDeleteCall
@@ -117,6 +148,63 @@ type MessagesDeleteCall interface {
 	Do() error
 }
 
+// ThreadsListCall is an interface for threads list API calls
+type ThreadsListCall interface {
+	MaxResults(maxResults int64) ThreadsListCall
+	PageToken(token string) ThreadsListCall
+	Q(query string) ThreadsListCall
+	LabelIds(labelIds ...string) ThreadsListCall
+	Context(ctx context.Context) ThreadsListCall
+	Do() (*gmail.ListThreadsResponse, error)
+}
+
+// ThreadsGetCall is an interface for threads get API calls
+type ThreadsGetCall interface {
+	Format(format string) ThreadsGetCall
+	Context(ctx context.Context) ThreadsGetCall
+	Do() (*gmail.Thread, error)
+}
+
+// DraftsListCall is an interface for drafts list API calls
+type DraftsListCall interface {
+	MaxResults(maxResults int64) DraftsListCall
+	PageToken(token string) DraftsListCall
+	Q(query string) DraftsListCall
+	Context(ctx context.Context) DraftsListCall
+	Do() (*gmail.ListDraftsResponse, error)
+}
+
+// DraftsGetCall is an interface for drafts get API calls
+type DraftsGetCall interface {
+	Format(format string) DraftsGetCall
+	Context(ctx context.Context) DraftsGetCall
+	Do() (*gmail.Draft, error)
+}
+
+// DraftsCreateCall is an interface for drafts create API calls
+type DraftsCreateCall interface {
+	Context(ctx context.Context) DraftsCreateCall
+	Do() (*gmail.Draft, error)
+}
+
+// DraftsUpdateCall is an interface for drafts update API calls
+type DraftsUpdateCall interface {
+	Context(ctx context.Context) DraftsUpdateCall
+	Do() (*gmail.Draft, error)
+}
+
+// DraftsSendCall is an interface for drafts send API calls
+type DraftsSendCall interface {
+	Context(ctx context.Context) DraftsSendCall
+	Do() (*gmail.Message, error)
+}
+
+// DraftsDeleteCall is an interface for drafts delete API calls
+type DraftsDeleteCall interface {
+	Context(ctx context.Context) DraftsDeleteCall
+	Do() error
+}
+
 // UsersWatchCall is an interface for users watch API calls
 type UsersWatchCall interface {
 	Context(ctx context.Context) UsersWatchCall
@@ -129,6 +217,12 @@ type UsersStopCall interface {
 	Do() error
 }
 
+// UsersGetProfileCall is an interface for users getProfile API calls
+type UsersGetProfileCall interface {
+	Context(ctx context.Context) UsersGetProfileCall
+	Do() (*gmail.Profile, error)
+}
+
 // UsersHistoryListCall is an interface for users history list API calls
 type UsersHistoryListCall interface {
 	MaxResults(maxResults int64) UsersHistoryListCall
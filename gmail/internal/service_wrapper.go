@@ -2,37 +2,53 @@ package internal
 
 import (
 	"context"
+	"io"
+	"net/http"
+	"net/url"
 
 	"google.golang.org/api/gmail/v1"
+	"google.golang.org/api/googleapi"
 )
 
 // RealGmailService wraps gmail.Service to implement GmailService interface
 type RealGmailService struct {
-	service *gmail.Service
+	service    *gmail.Service
+	httpClient *http.Client // the client service was built with, for requests it cannot stream
 }
 
-// NewRealGmailService creates a new RealGmailService
-func NewRealGmailService(service *gmail.Service) *RealGmailService {
-	return &RealGmailService{service: service}
+// NewRealGmailService creates a new RealGmailService. httpClient must be the client
+// service was created with
+func NewRealGmailService(service *gmail.Service, httpClient *http.Client) *RealGmailService {
+	return &RealGmailService{service: service, httpClient: httpClient}
 }
 
 func (r *RealGmailService) GetUsersService() UsersService {
-	return &realUsersService{users: r.service.Users}
+	return &realUsersService{users: r.service.Users, service: r.service, httpClient: r.httpClient}
 }
 
 // realUsersService wraps gmail.UsersService
 type realUsersService struct {
-	users *gmail.UsersService
+	users      *gmail.UsersService
+	service    *gmail.Service
+	httpClient *http.Client
 }
 
 func (r *realUsersService) GetMessagesService() MessagesService {
-	return &realMessagesService{messages: r.users.Messages}
+	return &realMessagesService{messages: r.users.Messages, service: r.service, httpClient: r.httpClient}
 }
 
 func (r *realUsersService) GetLabelsService() LabelsService {
 	return &realLabelsService{labels: r.users.Labels}
 }
 
+func (r *realUsersService) GetThreadsService() ThreadsService {
+	return &realThreadsService{threads: r.users.Threads}
+}
+
+func (r *realUsersService) GetDraftsService() DraftsService {
+	return &realDraftsService{drafts: r.users.Drafts}
+}
+
 func (r *realUsersService) Watch(userID string, req *gmail.WatchRequest) UsersWatchCall {
 	return &realUsersWatchCall{call: r.users.Watch(userID, req)}
 }
@@ -45,9 +61,15 @@ func (r *realUsersService) GetHistory(userID string) UsersHistoryListCall {
 	return &realUsersHistoryListCall{call: r.users.History.List(userID)}
 }
 
+func (r *realUsersService) GetProfile(userID string) UsersGetProfileCall {
+	return &realUsersGetProfileCall{call: r.users.GetProfile(userID)}
+}
+
 // realMessagesService wraps gmail.MessagesService
 type realMessagesService struct {
-	messages *gmail.UsersMessagesService
+	messages   *gmail.UsersMessagesService
+	service    *gmail.Service
+	httpClient *http.Client
 }
 
 func (r *realMessagesService) List(userID string) MessagesListCall {
@@ -66,6 +88,39 @@ func (r *realMessagesService) GetAttachment(userID, messageID, attachmentID stri
 	return &realMessagesAttachmentGetCall{call: r.messages.Attachments.Get(userID, messageID, attachmentID)}
 }
 
+// GetAttachmentContent sends the attachments.get request itself and returns the raw
+// JSON response body, limited to the data field, so the caller can decode the content
+// as it arrives instead of the generated client reading the whole response into
+// memory. The caller must close the returned reader
+func (r *realMessagesService) GetAttachmentContent(ctx context.Context, userID, messageID, attachmentID string) (io.ReadCloser, error) {
+	params := url.Values{"alt": {"json"}, "prettyPrint": {"false"}, "fields": {"data"}}
+	urls := googleapi.ResolveRelative(r.service.BasePath, "gmail/v1/users/{userId}/messages/{messageId}/attachments/{id}") + "?" + params.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, urls, nil)
+	if err != nil {
+		return nil, err
+	}
+	googleapi.Expand(req.URL, map[string]string{
+		"userId":    userID,
+		"messageId": messageID,
+		"id":        attachmentID,
+	})
+	userAgent := googleapi.UserAgent
+	if r.service.UserAgent != "" {
+		userAgent += " " + r.service.UserAgent
+	}
+	req.Header.Set("User-Agent", userAgent)
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := googleapi.CheckResponse(resp); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
 func (r *realMessagesService) Send(userID string, message *gmail.Message) MessagesSendCall {
 	return &realMessagesSendCall{call: r.messages.Send(userID, message)}
 }
@@ -99,10 +154,56 @@ func (r *realLabelsService) Create(userID string, label *gmail.Label) LabelsCrea
 	return &realLabelsCreateCall{call: r.labels.Create(userID, label)}
 }
 
+func (r *realLabelsService) Patch(userID, labelID string, label *gmail.Label) LabelsPatchCall {
+	return &realLabelsPatchCall{call: r.labels.Patch(userID, labelID, label)}
+}
+
 func (r *realLabelsService) Delete(userID, labelID string) LabelsDeleteCall {
 	return &realLabelsDeleteCall{call: r.labels.Delete(userID, labelID)}
 }
 
+// realThreadsService wraps gmail.UsersThreadsService
+type realThreadsService struct {
+	threads *gmail.UsersThreadsService
+}
+
+func (r *realThreadsService) List(userID string) ThreadsListCall {
+	return &realThreadsListCall{call: r.threads.List(userID)}
+}
+
+func (r *realThreadsService) Get(userID, threadID string) ThreadsGetCall {
+	return &realThreadsGetCall{call: r.threads.Get(userID, threadID)}
+}
+
+// realDraftsService wraps gmail.UsersDraftsService
+type realDraftsService struct {
+	drafts *gmail.UsersDraftsService
+}
+
+func (r *realDraftsService) List(userID string) DraftsListCall {
+	return &realDraftsListCall{call: r.drafts.List(userID)}
+}
+
+func (r *realDraftsService) Get(userID, draftID string) DraftsGetCall {
+	return &realDraftsGetCall{call: r.drafts.Get(userID, draftID)}
+}
+
+func (r *realDraftsService) Create(userID string, draft *gmail.Draft) DraftsCreateCall {
+	return &realDraftsCreateCall{call: r.drafts.Create(userID, draft)}
+}
+
+func (r *realDraftsService) Update(userID, draftID string, draft *gmail.Draft) DraftsUpdateCall {
+	return &realDraftsUpdateCall{call: r.drafts.Update(userID, draftID, draft)}
+}
+
+func (r *realDraftsService) Send(userID string, draft *gmail.Draft) DraftsSendCall {
+	return &realDraftsSendCall{call: r.drafts.Send(userID, draft)}
+}
+
+func (r *realDraftsService) Delete(userID, draftID string) DraftsDeleteCall {
+	return &realDraftsDeleteCall{call: r.drafts.Delete(userID, draftID)}
+}
+
 // Call wrappers
 type realMessagesListCall struct {
 	call *gmail.UsersMessagesListCall
@@ -128,6 +229,11 @@ func (r *realMessagesListCall) LabelIds(labelIds ...string) MessagesListCall {
 	return r
 }
 
+func (r *realMessagesListCall) Fields(fields ...googleapi.Field) MessagesListCall {
+	r.call = r.call.Fields(fields...)
+	return r
+}
+
 func (r *realMessagesListCall) Context(ctx context.Context) MessagesListCall {
 	r.call = r.call.Context(ctx)
 	return r
@@ -146,6 +252,11 @@ func (r *realMessagesGetCall) Format(format string) MessagesGetCall {
 	return r
 }
 
+func (r *realMessagesGetCall) Fields(fields ...googleapi.Field) MessagesGetCall {
+	r.call = r.call.Fields(fields...)
+	return r
+}
+
 func (r *realMessagesGetCall) Context(ctx context.Context) MessagesGetCall {
 	r.call = r.call.Context(ctx)
 	return r
@@ -220,6 +331,19 @@ func (r *realLabelsCreateCall) Do() (*gmail.Label, error) {
 	return r.call.Do()
 }
 
+type realLabelsPatchCall struct {
+	call *gmail.UsersLabelsPatchCall
+}
+
+func (r *realLabelsPatchCall) Context(ctx context.Context) LabelsPatchCall {
+	r.call = r.call.Context(ctx)
+	return r
+}
+
+func (r *realLabelsPatchCall) Do() (*gmail.Label, error) {
+	return r.call.Do()
+}
+
 type realLabelsDeleteCall struct {
 	call *gmail.UsersLabelsDeleteCall
 }
@@ -285,6 +409,155 @@ func (r *realMessagesDeleteCall) Do() error {
 	return r.call.Do()
 }
 
+type realThreadsListCall struct {
+	call *gmail.UsersThreadsListCall
+}
+
+func (r *realThreadsListCall) MaxResults(maxResults int64) ThreadsListCall {
+	r.call = r.call.MaxResults(maxResults)
+	return r
+}
+
+func (r *realThreadsListCall) PageToken(token string) ThreadsListCall {
+	r.call = r.call.PageToken(token)
+	return r
+}
+
+func (r *realThreadsListCall) Q(query string) ThreadsListCall {
+	r.call = r.call.Q(query)
+	return r
+}
+
+func (r *realThreadsListCall) LabelIds(labelIds ...string) ThreadsListCall {
+	r.call = r.call.LabelIds(labelIds...)
+	return r
+}
+
+func (r *realThreadsListCall) Context(ctx context.Context) ThreadsListCall {
+	r.call = r.call.Context(ctx)
+	return r
+}
+
+func (r *realThreadsListCall) Do() (*gmail.ListThreadsResponse, error) {
+	return r.call.Do()
+}
+
+type realThreadsGetCall struct {
+	call *gmail.UsersThreadsGetCall
+}
+
+func (r *realThreadsGetCall) Format(format string) ThreadsGetCall {
+	r.call = r.call.Format(format)
+	return r
+}
+
+func (r *realThreadsGetCall) Context(ctx context.Context) ThreadsGetCall {
+	r.call = r.call.Context(ctx)
+	return r
+}
+
+func (r *realThreadsGetCall) Do() (*gmail.Thread, error) {
+	return r.call.Do()
+}
+
+type realDraftsListCall struct {
+	call *gmail.UsersDraftsListCall
+}
+
+func (r *realDraftsListCall) MaxResults(maxResults int64) DraftsListCall {
+	r.call = r.call.MaxResults(maxResults)
+	return r
+}
+
+func (r *realDraftsListCall) PageToken(token string) DraftsListCall {
+	r.call = r.call.PageToken(token)
+	return r
+}
+
+func (r *realDraftsListCall) Q(query string) DraftsListCall {
+	r.call = r.call.Q(query)
+	return r
+}
+
+func (r *realDraftsListCall) Context(ctx context.Context) DraftsListCall {
+	r.call = r.call.Context(ctx)
+	return r
+}
+
+func (r *realDraftsListCall) Do() (*gmail.ListDraftsResponse, error) {
+	return r.call.Do()
+}
+
+type realDraftsGetCall struct {
+	call *gmail.UsersDraftsGetCall
+}
+
+func (r *realDraftsGetCall) Format(format string) DraftsGetCall {
+	r.call = r.call.Format(format)
+	return r
+}
+
+func (r *realDraftsGetCall) Context(ctx context.Context) DraftsGetCall {
+	r.call = r.call.Context(ctx)
+	return r
+}
+
+func (r *realDraftsGetCall) Do() (*gmail.Draft, error) {
+	return r.call.Do()
+}
+
+type realDraftsCreateCall struct {
+	call *gmail.UsersDraftsCreateCall
+}
+
+func (r *realDraftsCreateCall) Context(ctx context.Context) DraftsCreateCall {
+	r.call = r.call.Context(ctx)
+	return r
+}
+
+func (r *realDraftsCreateCall) Do() (*gmail.Draft, error) {
+	return r.call.Do()
+}
+
+type realDraftsUpdateCall struct {
+	call *gmail.UsersDraftsUpdateCall
+}
+
+func (r *realDraftsUpdateCall) Context(ctx context.Context) DraftsUpdateCall {
+	r.call = r.call.Context(ctx)
+	return r
+}
+
+func (r *realDraftsUpdateCall) Do() (*gmail.Draft, error) {
+	return r.call.Do()
+}
+
+type realDraftsSendCall struct {
+	call *gmail.UsersDraftsSendCall
+}
+
+func (r *realDraftsSendCall) Context(ctx context.Context) DraftsSendCall {
+	r.call = r.call.Context(ctx)
+	return r
+}
+
+func (r *realDraftsSendCall) Do() (*gmail.Message, error) {
+	return r.call.Do()
+}
+
+type realDraftsDeleteCall struct {
+	call *gmail.UsersDraftsDeleteCall
+}
+
+func (r *realDraftsDeleteCall) Context(ctx context.Context) DraftsDeleteCall {
+	r.call = r.call.Context(ctx)
+	return r
+}
+
+func (r *realDraftsDeleteCall) Do() error {
+	return r.call.Do()
+}
+
 type realUsersWatchCall struct {
 	call *gmail.UsersWatchCall
 }
@@ -311,6 +584,19 @@ func (r *realUsersStopCall) Do() error {
 	return r.call.Do()
 }
 
+type realUsersGetProfileCall struct {
+	call *gmail.UsersGetProfileCall
+}
+
+func (r *realUsersGetProfileCall) Context(ctx context.Context) UsersGetProfileCall {
+	r.call = r.call.Context(ctx)
+	return r
+}
+
+func (r *realUsersGetProfileCall) Do() (*gmail.Profile, error) {
+	return r.call.Do()
+}
+
 type realUsersHistoryListCall struct {
 	call *gmail.UsersHistoryListCall
 }
@@ -0,0 +1,155 @@
+package drafts
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/danielrivera/mailbridge-go/core"
+	"github.com/danielrivera/mailbridge-go/gmail/internal"
+	"github.com/danielrivera/mailbridge-go/gmail/operations"
+	"github.com/danielrivera/mailbridge-go/gmail/operations/messages"
+	"google.golang.org/api/gmail/v1"
+)
+
+// CreateDraft saves a draft in the mailbox without sending it. The message is built
+// and validated exactly as SendMessage would send it. Gmail returns only the new
+// message's IDs and labels, so Message carries just those; use GetDraft for the content
+func CreateDraft(ctx context.Context, service internal.GmailService, draft *core.Draft, opts *core.SendOptions, clock core.Clock) (*core.DraftResponse, error) {
+	raw, err := messages.BuildRawMessage(ctx, service, draft, opts, clock)
+	if err != nil {
+		return nil, err
+	}
+
+	draftsService := service.GetUsersService().GetDraftsService()
+	created, err := draftsService.Create(operations.UserIDMe, &gmail.Draft{
+		Message: &gmail.Message{Raw: raw},
+	}).Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create draft: %w", err)
+	}
+
+	return convertDraft(created), nil
+}
+
+// ListDrafts lists drafts, fetching each one's content like ListMessages does.
+// MaxResults, PageToken and the search filters apply; with IDsOnly, only the draft,
+// message and thread IDs are returned and no draft is fetched
+func ListDrafts(ctx context.Context, service internal.GmailService, opts *core.ListOptions) (*core.DraftListResponse, error) {
+	draftsService := service.GetUsersService().GetDraftsService()
+	call := draftsService.List(operations.UserIDMe)
+
+	if opts != nil {
+		if opts.MaxResults > 0 {
+			call = call.MaxResults(opts.MaxResults)
+		}
+		if opts.PageToken != "" {
+			call = call.PageToken(opts.PageToken)
+		}
+		if query := messages.BuildQuery(opts); query != "" {
+			call = call.Q(query)
+		}
+	}
+
+	resp, err := call.Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list drafts: %w", err)
+	}
+
+	drafts := make([]*core.DraftResponse, 0, len(resp.Drafts))
+	for _, d := range resp.Drafts {
+		if opts != nil && opts.IDsOnly {
+			drafts = append(drafts, convertDraft(d))
+			continue
+		}
+		draft, err := GetDraft(ctx, service, d.Id)
+		if err != nil {
+			// Skip drafts deleted or sent since the list was taken
+			continue
+		}
+		drafts = append(drafts, draft)
+	}
+
+	return &core.DraftListResponse{
+		Drafts:        drafts,
+		NextPageToken: resp.NextPageToken,
+		TotalCount:    resp.ResultSizeEstimate,
+	}, nil
+}
+
+// GetDraft retrieves a draft with its full content
+func GetDraft(ctx context.Context, service internal.GmailService, draftID string) (*core.DraftResponse, error) {
+	if draftID == "" {
+		return nil, fmt.Errorf("draft ID is required")
+	}
+
+	draftsService := service.GetUsersService().GetDraftsService()
+	d, err := draftsService.Get(operations.UserIDMe, draftID).Format("full").Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get draft: %w", err)
+	}
+
+	return convertDraft(d), nil
+}
+
+// UpdateDraft replaces the content of a draft. Gmail drafts are immutable messages,
+// so the whole draft is rebuilt and the response carries the new message's IDs
+func UpdateDraft(ctx context.Context, service internal.GmailService, draftID string, draft *core.Draft, opts *core.SendOptions, clock core.Clock) (*core.DraftResponse, error) {
+	if draftID == "" {
+		return nil, fmt.Errorf("draft ID is required")
+	}
+	raw, err := messages.BuildRawMessage(ctx, service, draft, opts, clock)
+	if err != nil {
+		return nil, err
+	}
+
+	draftsService := service.GetUsersService().GetDraftsService()
+	updated, err := draftsService.Update(operations.UserIDMe, draftID, &gmail.Draft{
+		Id:      draftID,
+		Message: &gmail.Message{Raw: raw},
+	}).Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to update draft: %w", err)
+	}
+
+	return convertDraft(updated), nil
+}
+
+// SendDraft sends a saved draft as it is; the draft is removed from the mailbox
+func SendDraft(ctx context.Context, service internal.GmailService, draftID string) (*core.SendResponse, error) {
+	if draftID == "" {
+		return nil, fmt.Errorf("draft ID is required")
+	}
+
+	draftsService := service.GetUsersService().GetDraftsService()
+	sent, err := draftsService.Send(operations.UserIDMe, &gmail.Draft{Id: draftID}).Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to send draft: %w", err)
+	}
+
+	return &core.SendResponse{
+		ID:       sent.Id,
+		ThreadID: sent.ThreadId,
+	}, nil
+}
+
+// DeleteDraft permanently deletes a draft (it is not moved to Trash)
+func DeleteDraft(ctx context.Context, service internal.GmailService, draftID string) error {
+	if draftID == "" {
+		return fmt.Errorf("draft ID is required")
+	}
+
+	draftsService := service.GetUsersService().GetDraftsService()
+	if err := draftsService.Delete(operations.UserIDMe, draftID).Context(ctx).Do(); err != nil {
+		return fmt.Errorf("failed to delete draft: %w", err)
+	}
+	return nil
+}
+
+// convertDraft converts a Gmail draft to the normalized DraftResponse
+func convertDraft(d *gmail.Draft) *core.DraftResponse {
+	response := &core.DraftResponse{ID: d.Id}
+	if d.Message != nil {
+		response.Message = messages.ConvertMessage(d.Message)
+	}
+	return response
+}
@@ -0,0 +1,253 @@
+package drafts
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/danielrivera/mailbridge-go/core"
+	gmailtest "github.com/danielrivera/mailbridge-go/gmail/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/gmail/v1"
+)
+
+func newTestDraft(subject string) *core.Draft {
+	return &core.Draft{
+		To:      []core.EmailAddress{{Email: "bob@example.com"}},
+		Subject: subject,
+		Body:    core.EmailBody{Text: "Draft body"},
+	}
+}
+
+func decodeRaw(t *testing.T, raw string) string {
+	t.Helper()
+	decoded, err := base64.RawURLEncoding.DecodeString(raw)
+	require.NoError(t, err)
+	return string(decoded)
+}
+
+func TestCreateDraft_ThenSend(t *testing.T) {
+	ctx := context.Background()
+	mockGmailService, mockDraftsService := setupMockDraftsService()
+	mockCreateCall := &gmailtest.MockDraftsCreateCall{}
+	mockSendCall := &gmailtest.MockDraftsSendCall{}
+
+	var created *gmail.Draft
+	mockDraftsService.On("Create", "me", mock.AnythingOfType("*gmail.Draft")).
+		Run(func(args mock.Arguments) { created = args.Get(1).(*gmail.Draft) }).
+		Return(mockCreateCall)
+	mockCreateCall.On("Context", ctx).Return(mockCreateCall)
+	mockCreateCall.On("Do").Return(&gmail.Draft{
+		Id:      "r-123",
+		Message: &gmail.Message{Id: "msg-1", ThreadId: "thread-1", LabelIds: []string{"DRAFT"}},
+	}, nil)
+
+	var sent *gmail.Draft
+	mockDraftsService.On("Send", "me", mock.AnythingOfType("*gmail.Draft")).
+		Run(func(args mock.Arguments) { sent = args.Get(1).(*gmail.Draft) }).
+		Return(mockSendCall)
+	mockSendCall.On("Context", ctx).Return(mockSendCall)
+	mockSendCall.On("Do").Return(&gmail.Message{Id: "msg-sent", ThreadId: "thread-1"}, nil)
+
+	draft, err := CreateDraft(ctx, mockGmailService, newTestDraft("Saved for later"), nil, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, "r-123", draft.ID)
+	require.NotNil(t, draft.Message)
+	assert.Equal(t, "msg-1", draft.Message.ID)
+	assert.True(t, draft.Message.IsDraft)
+	raw := decodeRaw(t, created.Message.Raw)
+	assert.Contains(t, raw, "To: bob@example.com\r\n")
+	assert.Contains(t, raw, "Subject: Saved for later\r\n")
+	assert.Contains(t, raw, "Draft body")
+
+	resp, err := SendDraft(ctx, mockGmailService, draft.ID)
+
+	require.NoError(t, err)
+	assert.Equal(t, &core.SendResponse{ID: "msg-sent", ThreadID: "thread-1"}, resp)
+	assert.Equal(t, &gmail.Draft{Id: "r-123"}, sent)
+}
+
+func TestCreateDraft_InvalidDraft(t *testing.T) {
+	mockGmailService, mockDraftsService := setupMockDraftsService()
+
+	_, err := CreateDraft(context.Background(), mockGmailService, &core.Draft{Subject: "No recipients", Body: core.EmailBody{Text: "x"}}, nil, nil)
+
+	assert.ErrorContains(t, err, "invalid draft")
+	mockDraftsService.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestUpdateDraft(t *testing.T) {
+	ctx := context.Background()
+	mockGmailService, mockDraftsService := setupMockDraftsService()
+	mockUpdateCall := &gmailtest.MockDraftsUpdateCall{}
+
+	var updated *gmail.Draft
+	mockDraftsService.On("Update", "me", "r-123", mock.AnythingOfType("*gmail.Draft")).
+		Run(func(args mock.Arguments) { updated = args.Get(2).(*gmail.Draft) }).
+		Return(mockUpdateCall)
+	mockUpdateCall.On("Context", ctx).Return(mockUpdateCall)
+	mockUpdateCall.On("Do").Return(&gmail.Draft{
+		Id:      "r-123",
+		Message: &gmail.Message{Id: "msg-2", ThreadId: "thread-1"},
+	}, nil)
+
+	draft, err := UpdateDraft(ctx, mockGmailService, "r-123", newTestDraft("Second version"), nil, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, "r-123", draft.ID)
+	assert.Equal(t, "msg-2", draft.Message.ID, "the update replaces the draft's message")
+	assert.Equal(t, "r-123", updated.Id)
+	assert.Contains(t, decodeRaw(t, updated.Message.Raw), "Subject: Second version\r\n")
+
+	_, err = UpdateDraft(ctx, mockGmailService, "", newTestDraft("x"), nil, nil)
+	assert.EqualError(t, err, "draft ID is required")
+}
+
+func TestListDrafts(t *testing.T) {
+	ctx := context.Background()
+	mockGmailService, mockDraftsService := setupMockDraftsService()
+	mockListCall := &gmailtest.MockDraftsListCall{}
+
+	mockDraftsService.On("List", "me").Return(mockListCall)
+	mockListCall.On("MaxResults", int64(10)).Return(mockListCall)
+	mockListCall.On("Q", "subject:invoice").Return(mockListCall)
+	mockListCall.On("Context", ctx).Return(mockListCall)
+	mockListCall.On("Do").Return(&gmail.ListDraftsResponse{
+		Drafts: []*gmail.Draft{
+			{Id: "r-1", Message: &gmail.Message{Id: "msg-1", ThreadId: "thread-1"}},
+			{Id: "r-gone", Message: &gmail.Message{Id: "msg-gone"}},
+		},
+		NextPageToken:      "next",
+		ResultSizeEstimate: 2,
+	}, nil)
+
+	mockGetCall := &gmailtest.MockDraftsGetCall{}
+	mockDraftsService.On("Get", "me", "r-1").Return(mockGetCall)
+	mockGetCall.On("Format", "full").Return(mockGetCall)
+	mockGetCall.On("Context", ctx).Return(mockGetCall)
+	mockGetCall.On("Do").Return(&gmail.Draft{
+		Id: "r-1",
+		Message: &gmail.Message{
+			Id:       "msg-1",
+			ThreadId: "thread-1",
+			Payload: &gmail.MessagePart{
+				MimeType: "text/plain",
+				Headers:  []*gmail.MessagePartHeader{{Name: "Subject", Value: "Invoice 42"}},
+				Body:     &gmail.MessagePartBody{Data: base64.URLEncoding.EncodeToString([]byte("Please pay"))},
+			},
+		},
+	}, nil)
+
+	mockGoneCall := &gmailtest.MockDraftsGetCall{}
+	mockDraftsService.On("Get", "me", "r-gone").Return(mockGoneCall)
+	mockGoneCall.On("Format", "full").Return(mockGoneCall)
+	mockGoneCall.On("Context", ctx).Return(mockGoneCall)
+	mockGoneCall.On("Do").Return(nil, errors.New("not found"))
+
+	resp, err := ListDrafts(ctx, mockGmailService, &core.ListOptions{MaxResults: 10, Query: "subject:invoice"})
+
+	require.NoError(t, err)
+	require.Len(t, resp.Drafts, 1, "drafts that cannot be fetched are skipped")
+	assert.Equal(t, "r-1", resp.Drafts[0].ID)
+	assert.Equal(t, "Invoice 42", resp.Drafts[0].Message.Subject)
+	assert.Equal(t, "Please pay", resp.Drafts[0].Message.Body.Text)
+	assert.Equal(t, "next", resp.NextPageToken)
+	assert.Equal(t, int64(2), resp.TotalCount)
+}
+
+func TestListDrafts_IDsOnly(t *testing.T) {
+	ctx := context.Background()
+	mockGmailService, mockDraftsService := setupMockDraftsService()
+	mockListCall := &gmailtest.MockDraftsListCall{}
+
+	mockDraftsService.On("List", "me").Return(mockListCall)
+	mockListCall.On("Context", ctx).Return(mockListCall)
+	mockListCall.On("Do").Return(&gmail.ListDraftsResponse{
+		Drafts: []*gmail.Draft{{Id: "r-1", Message: &gmail.Message{Id: "msg-1", ThreadId: "thread-1"}}},
+	}, nil)
+
+	resp, err := ListDrafts(ctx, mockGmailService, &core.ListOptions{IDsOnly: true})
+
+	require.NoError(t, err)
+	require.Len(t, resp.Drafts, 1)
+	assert.Equal(t, "r-1", resp.Drafts[0].ID)
+	assert.Equal(t, "msg-1", resp.Drafts[0].Message.ID)
+	assert.Equal(t, "thread-1", resp.Drafts[0].Message.ThreadID)
+	mockDraftsService.AssertNotCalled(t, "Get", mock.Anything, mock.Anything)
+}
+
+func TestDeleteDraft(t *testing.T) {
+	ctx := context.Background()
+	mockGmailService, mockDraftsService := setupMockDraftsService()
+	mockDeleteCall := &gmailtest.MockDraftsDeleteCall{}
+
+	mockDraftsService.On("Delete", "me", "r-123").Return(mockDeleteCall)
+	mockDeleteCall.On("Context", ctx).Return(mockDeleteCall)
+	mockDeleteCall.On("Do").Return(nil)
+
+	require.NoError(t, DeleteDraft(ctx, mockGmailService, "r-123"))
+	mockDeleteCall.AssertExpectations(t)
+}
+
+func TestDraftsAPIErrors(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("get", func(t *testing.T) {
+		mockGmailService, mockDraftsService := setupMockDraftsService()
+		mockGetCall := &gmailtest.MockDraftsGetCall{}
+		mockDraftsService.On("Get", "me", "r-1").Return(mockGetCall)
+		mockGetCall.On("Format", "full").Return(mockGetCall)
+		mockGetCall.On("Context", ctx).Return(mockGetCall)
+		mockGetCall.On("Do").Return(nil, errors.New("API error"))
+
+		_, err := GetDraft(ctx, mockGmailService, "r-1")
+		assert.ErrorContains(t, err, "failed to get draft")
+	})
+
+	t.Run("send", func(t *testing.T) {
+		mockGmailService, mockDraftsService := setupMockDraftsService()
+		mockSendCall := &gmailtest.MockDraftsSendCall{}
+		mockDraftsService.On("Send", "me", mock.Anything).Return(mockSendCall)
+		mockSendCall.On("Context", ctx).Return(mockSendCall)
+		mockSendCall.On("Do").Return(nil, errors.New("API error"))
+
+		_, err := SendDraft(ctx, mockGmailService, "r-1")
+		assert.ErrorContains(t, err, "failed to send draft")
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		mockGmailService, mockDraftsService := setupMockDraftsService()
+		mockDeleteCall := &gmailtest.MockDraftsDeleteCall{}
+		mockDraftsService.On("Delete", "me", "r-1").Return(mockDeleteCall)
+		mockDeleteCall.On("Context", ctx).Return(mockDeleteCall)
+		mockDeleteCall.On("Do").Return(errors.New("API error"))
+
+		assert.ErrorContains(t, DeleteDraft(ctx, mockGmailService, "r-1"), "failed to delete draft")
+	})
+
+	t.Run("empty draft ID", func(t *testing.T) {
+		mockGmailService, _ := setupMockDraftsService()
+
+		_, err := GetDraft(ctx, mockGmailService, "")
+		assert.EqualError(t, err, "draft ID is required")
+		_, err = SendDraft(ctx, mockGmailService, "")
+		assert.EqualError(t, err, "draft ID is required")
+		assert.EqualError(t, DeleteDraft(ctx, mockGmailService, ""), "draft ID is required")
+	})
+}
+
+// Helper function to setup mock drafts service
+func setupMockDraftsService() (*gmailtest.MockGmailService, *gmailtest.MockDraftsService) {
+	mockGmailService := &gmailtest.MockGmailService{}
+	mockUsersService := &gmailtest.MockUsersService{}
+	mockDraftsService := &gmailtest.MockDraftsService{}
+
+	mockGmailService.On("GetUsersService").Return(mockUsersService)
+	mockUsersService.On("GetDraftsService").Return(mockDraftsService)
+
+	return mockGmailService, mockDraftsService
+}
@@ -3,8 +3,8 @@ package operations
 import (
 	"context"
 	"fmt"
-	"strings"
 
+	"github.com/danielrivera/mailbridge-go/core"
 	"github.com/danielrivera/mailbridge-go/gmail/internal"
 )
 
@@ -12,7 +12,8 @@ import (
 const UserIDMe = "me"
 
 // BatchOperation executes a batch operation on multiple messages with error aggregation.
-// It continues processing all messages even if some fail, and returns an aggregated error.
+// It continues processing all messages even if some fail, and returns an aggregated error
+// wrapping a *core.BatchError that reports which message IDs succeeded and which failed.
 func BatchOperation(
 	ctx context.Context,
 	messageIDs []string,
@@ -23,15 +24,16 @@ func BatchOperation(
 		return nil
 	}
 
-	var errors []string
-	for _, messageID := range messageIDs {
+	failures := make(map[int]error)
+	for i, messageID := range messageIDs {
 		if err := operation(ctx, messageID); err != nil {
-			errors = append(errors, fmt.Sprintf("%s: %v", messageID, err))
+			failures[i] = err
 		}
 	}
 
-	if len(errors) > 0 {
-		return fmt.Errorf("failed to %s %d messages: %s", operationName, len(errors), strings.Join(errors, "; "))
+	if len(failures) > 0 {
+		batchErr := &core.BatchError{Total: len(messageIDs), Errors: failures, IDs: messageIDs}
+		return fmt.Errorf("failed to %s %d messages: %w", operationName, len(failures), batchErr)
 	}
 
 	return nil
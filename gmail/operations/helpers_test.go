@@ -5,8 +5,10 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/danielrivera/mailbridge-go/core"
 	gmailtest "github.com/danielrivera/mailbridge-go/gmail/testing"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestUserIDMe(t *testing.T) {
@@ -60,6 +62,25 @@ func TestBatchOperation_PartialFailure(t *testing.T) {
 	assert.Contains(t, err.Error(), "msg2: test error")
 }
 
+func TestBatchOperation_PartialFailureReportsIDs(t *testing.T) {
+	messageIDs := []string{"msg1", "msg2", "msg3", "msg4"}
+
+	operation := func(ctx context.Context, messageID string) error {
+		if messageID == "msg2" || messageID == "msg4" {
+			return errors.New("rate limited")
+		}
+		return nil
+	}
+
+	err := BatchOperation(context.Background(), messageIDs, operation, "trash")
+
+	var batchErr *core.BatchError
+	require.ErrorAs(t, err, &batchErr)
+	assert.Equal(t, 4, batchErr.Total)
+	assert.Equal(t, []string{"msg1", "msg3"}, batchErr.SucceededIDs())
+	assert.Equal(t, []string{"msg2", "msg4"}, batchErr.FailedIDs())
+}
+
 func TestBatchOperation_AllFailed(t *testing.T) {
 	ctx := context.Background()
 	messageIDs := []string{"msg1", "msg2"}
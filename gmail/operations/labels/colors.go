@@ -0,0 +1,63 @@
+package labels
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/danielrivera/mailbridge-go/core"
+)
+
+const (
+	labelListVisibilityShowIfUnread = "labelShowIfUnread"
+	labelListVisibilityHide         = "labelHide"
+	messageListVisibilityHide       = "hide"
+)
+
+// labelPalette holds the colors Gmail accepts for label text and background.
+// The API rejects any other value
+var labelPalette = map[string]bool{
+	"#000000": true, "#434343": true, "#666666": true, "#999999": true, "#cccccc": true, "#efefef": true, "#f3f3f3": true, "#ffffff": true,
+	"#fb4c2f": true, "#ffad47": true, "#fad165": true, "#16a766": true, "#43d692": true, "#4a86e8": true, "#a479e2": true, "#f691b3": true,
+	"#f6c5be": true, "#ffe6c7": true, "#fef1d1": true, "#b9e4d0": true, "#c6f3de": true, "#c9daf8": true, "#e4d7f5": true, "#fcdee8": true,
+	"#efa093": true, "#ffd6a2": true, "#fce8b3": true, "#89d3b2": true, "#a0eac9": true, "#a4c2f4": true, "#d0bcf1": true, "#fbc8d9": true,
+	"#e66550": true, "#ffbc6b": true, "#fcda83": true, "#44b984": true, "#68dfa9": true, "#6d9eeb": true, "#b694e8": true, "#f7a7c0": true,
+	"#cc3a21": true, "#eaa041": true, "#f2c960": true, "#149e60": true, "#3dc789": true, "#3c78d8": true, "#8e63ce": true, "#e07798": true,
+	"#ac2b16": true, "#cf8933": true, "#d5ae49": true, "#0b804b": true, "#2a9c68": true, "#285bac": true, "#653e9b": true, "#b65775": true,
+	"#822111": true, "#a46a21": true, "#aa8831": true, "#076239": true, "#1a764d": true, "#1c4587": true, "#41236d": true, "#83334c": true,
+	"#464646": true, "#e7e7e7": true, "#0d3472": true, "#b6cffa": true, "#0d3b44": true, "#98d7e4": true, "#3d188e": true, "#e3d7ff": true,
+	"#711a36": true, "#fbd3e0": true, "#8a1c0a": true, "#f2b2a8": true, "#7a2e0b": true, "#ffc8af": true, "#7a4706": true, "#ffdeb5": true,
+	"#594c05": true, "#fbe983": true, "#684e07": true, "#fdedc1": true, "#0b4f30": true, "#b3efd3": true, "#04502e": true, "#a2dcc1": true,
+	"#c2c2c2": true, "#4986e7": true, "#2da2bb": true, "#b99aff": true, "#994a64": true, "#f691b2": true, "#ff7537": true, "#ffad46": true,
+	"#662e37": true, "#ebdbde": true, "#cca6ac": true, "#094228": true, "#42d692": true, "#16a765": true,
+}
+
+// validateLabelOptions checks the name, colors and visibilities of a label to create.
+// Gmail needs the text and background colors together, both from its palette
+func validateLabelOptions(opts core.LabelOptions) error {
+	if strings.TrimSpace(opts.Name) == "" {
+		return fmt.Errorf("label name is required")
+	}
+
+	if (opts.TextColor == "") != (opts.BackgroundColor == "") {
+		return fmt.Errorf("label text and background colors must be set together")
+	}
+	for _, color := range []string{opts.TextColor, opts.BackgroundColor} {
+		if color != "" && !labelPalette[strings.ToLower(color)] {
+			return fmt.Errorf("unsupported label color %q: Gmail only accepts colors from its label palette", color)
+		}
+	}
+
+	switch opts.LabelListVisibility {
+	case "", labelListVisibilityShow, labelListVisibilityShowIfUnread, labelListVisibilityHide:
+	default:
+		return fmt.Errorf("invalid label list visibility %q: use %q, %q or %q", opts.LabelListVisibility,
+			labelListVisibilityShow, labelListVisibilityShowIfUnread, labelListVisibilityHide)
+	}
+	switch opts.MessageListVisibility {
+	case "", messageListVisibilityShow, messageListVisibilityHide:
+	default:
+		return fmt.Errorf("invalid message list visibility %q: use %q or %q", opts.MessageListVisibility,
+			messageListVisibilityShow, messageListVisibilityHide)
+	}
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/danielrivera/mailbridge-go/core"
 	"github.com/danielrivera/mailbridge-go/gmail/internal"
 	"github.com/danielrivera/mailbridge-go/gmail/operations"
 	"google.golang.org/api/gmail/v1"
@@ -19,12 +20,15 @@ const (
 
 // Label represents a Gmail label (folder/tag)
 type Label struct {
-	ID   string
-	Name string
-	Type string
+	ID       string
+	Name     string
+	Type     string
+	ParentID string // ID of the parent label ("Work" for "Work/Projects"); empty at the top level or if the parent does not exist
+	Depth    int    // Number of "/" separated levels above the label: 0 for "Work", 1 for "Work/Projects"
 }
 
-// ListLabels lists all labels in the user's mailbox
+// ListLabels lists all labels in the user's mailbox.
+// Gmail nests labels by name, so ParentID and Depth are derived by splitting names on "/"
 func ListLabels(ctx context.Context, service internal.GmailService) ([]*Label, error) {
 	labelsService := service.GetUsersService().GetLabelsService()
 	call := labelsService.List(operations.UserIDMe)
@@ -41,10 +45,26 @@ func ListLabels(ctx context.Context, service internal.GmailService) ([]*Label, e
 			Type: l.Type,
 		})
 	}
+	applyHierarchy(labels)
 
 	return labels, nil
 }
 
+// applyHierarchy sets ParentID and Depth from the "/" separated label names
+func applyHierarchy(labels []*Label) {
+	idsByName := make(map[string]string, len(labels))
+	for _, label := range labels {
+		idsByName[label.Name] = label.ID
+	}
+
+	for _, label := range labels {
+		label.Depth = strings.Count(label.Name, "/")
+		if i := strings.LastIndex(label.Name, "/"); i > 0 {
+			label.ParentID = idsByName[label.Name[:i]]
+		}
+	}
+}
+
 // GetLabel gets a specific label by ID
 func GetLabel(ctx context.Context, service internal.GmailService, labelID string) (*Label, error) {
 	labelsService := service.GetUsersService().GetLabelsService()
@@ -61,6 +81,55 @@ func GetLabel(ctx context.Context, service internal.GmailService, labelID string
 	}, nil
 }
 
+// GetMessageLabels resolves the labels applied to a message to full labels with their
+// message counts, in the order Gmail lists them on the message. Only the message's label
+// IDs are fetched; the mailbox's labels are listed once to fill in ParentID and Depth,
+// and each label is then fetched for its counts, which the list endpoint leaves out
+func GetMessageLabels(ctx context.Context, service internal.GmailService, messageID string) ([]core.Label, error) {
+	msg, err := operations.GetMessagesService(service).Get(operations.UserIDMe, messageID).
+		Format("minimal").Fields("labelIds").Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get message labels: %w", err)
+	}
+
+	result := make([]core.Label, 0, len(msg.LabelIds))
+	if len(msg.LabelIds) == 0 {
+		return result, nil
+	}
+
+	all, err := ListLabels(ctx, service)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get message labels: %w", err)
+	}
+	byID := make(map[string]*Label, len(all))
+	for _, label := range all {
+		byID[label.ID] = label
+	}
+
+	labelsService := service.GetUsersService().GetLabelsService()
+	for _, labelID := range msg.LabelIds {
+		l, err := labelsService.Get(operations.UserIDMe, labelID).Context(ctx).Do()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get label %s: %w", labelID, err)
+		}
+
+		label := core.Label{
+			ID:             l.Id,
+			Name:           l.Name,
+			Type:           l.Type,
+			TotalMessages:  int(l.MessagesTotal),
+			UnreadMessages: int(l.MessagesUnread),
+			Depth:          strings.Count(l.Name, "/"),
+		}
+		if listed, ok := byID[labelID]; ok {
+			label.ParentID = listed.ParentID
+		}
+		result = append(result, label)
+	}
+
+	return result, nil
+}
+
 // FindLabelByName finds a label by its name
 func FindLabelByName(ctx context.Context, service internal.GmailService, name string) (*Label, error) {
 	labels, err := ListLabels(ctx, service)
@@ -91,14 +160,36 @@ func findOrCreateLabel(ctx context.Context, service internal.GmailService, name
 	return label, nil
 }
 
-// CreateLabel creates a new label (folder)
+// CreateLabel creates a new label (folder), shown in the label and message lists
 func CreateLabel(ctx context.Context, service internal.GmailService, name string) (*Label, error) {
+	return CreateLabelWithOptions(ctx, service, core.LabelOptions{Name: name})
+}
+
+// CreateLabelWithOptions creates a new label with the given color and visibility.
+// Colors must come from Gmail's label palette; empty visibilities default to shown
+func CreateLabelWithOptions(ctx context.Context, service internal.GmailService, opts core.LabelOptions) (*Label, error) {
+	if err := validateLabelOptions(opts); err != nil {
+		return nil, err
+	}
+
 	label := &gmail.Label{
-		Name:                  name,
+		Name:                  opts.Name,
 		LabelListVisibility:   labelListVisibilityShow,
 		MessageListVisibility: messageListVisibilityShow,
 		Type:                  labelTypeUser,
 	}
+	if opts.LabelListVisibility != "" {
+		label.LabelListVisibility = opts.LabelListVisibility
+	}
+	if opts.MessageListVisibility != "" {
+		label.MessageListVisibility = opts.MessageListVisibility
+	}
+	if opts.TextColor != "" {
+		label.Color = &gmail.LabelColor{
+			TextColor:       strings.ToLower(opts.TextColor),
+			BackgroundColor: strings.ToLower(opts.BackgroundColor),
+		}
+	}
 
 	labelsService := service.GetUsersService().GetLabelsService()
 	call := labelsService.Create(operations.UserIDMe, label)
@@ -114,6 +205,30 @@ func CreateLabel(ctx context.Context, service internal.GmailService, name string
 	}, nil
 }
 
+// UpdateLabel renames a label. Only the name is sent, so the label keeps its color,
+// visibility and messages
+func UpdateLabel(ctx context.Context, service internal.GmailService, labelID, newName string) (*Label, error) {
+	if labelID == "" {
+		return nil, fmt.Errorf("label ID is required")
+	}
+	if strings.TrimSpace(newName) == "" {
+		return nil, fmt.Errorf("label name is required")
+	}
+
+	labelsService := service.GetUsersService().GetLabelsService()
+	call := labelsService.Patch(operations.UserIDMe, labelID, &gmail.Label{Name: newName})
+	updated, err := call.Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to update label: %w", err)
+	}
+
+	return &Label{
+		ID:   updated.Id,
+		Name: updated.Name,
+		Type: updated.Type,
+	}, nil
+}
+
 // DeleteLabel deletes a label
 func DeleteLabel(ctx context.Context, service internal.GmailService, labelID string) error {
 	labelsService := service.GetUsersService().GetLabelsService()
@@ -158,6 +273,67 @@ func RemoveLabelFromMessage(ctx context.Context, service internal.GmailService,
 	return nil
 }
 
+// SetMessageLabels sets the labels on a message to exactly the desired label IDs.
+// The current labels are fetched and a single modify is issued with the computed
+// add and remove sets; nothing is sent when the labels already match.
+// System labels such as INBOX and UNREAD are included in the diff, so they are
+// removed unless present in desired. The immutable SENT, DRAFT and CHAT labels are
+// left out of both sets, as Gmail rejects adding or removing them.
+func SetMessageLabels(ctx context.Context, service internal.GmailService, messageID string, desired []string) error {
+	messagesService := service.GetUsersService().GetMessagesService()
+	msg, err := messagesService.Get(operations.UserIDMe, messageID).Format("minimal").Fields("labelIds").Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("failed to get message labels: %w", err)
+	}
+
+	add, remove := diffLabels(msg.LabelIds, desired)
+	if len(add) == 0 && len(remove) == 0 {
+		return nil
+	}
+
+	req := &gmail.ModifyMessageRequest{
+		AddLabelIds:    add,
+		RemoveLabelIds: remove,
+	}
+	call := messagesService.Modify(operations.UserIDMe, messageID, req)
+	if _, err := call.Context(ctx).Do(); err != nil {
+		return fmt.Errorf("failed to set message labels: %w", err)
+	}
+
+	return nil
+}
+
+// immutableLabels are the system labels Gmail sets itself and refuses to add to or
+// remove from a message
+var immutableLabels = map[string]bool{
+	"SENT":  true,
+	"DRAFT": true,
+	"CHAT":  true,
+}
+
+// diffLabels returns the labels to add and remove to turn current into desired,
+// in the order they appear in desired and current respectively. Immutable labels
+// are never added or removed
+func diffLabels(current, desired []string) (add, remove []string) {
+	currentSet := make(map[string]bool, len(current))
+	for _, id := range current {
+		currentSet[id] = true
+	}
+	desiredSet := make(map[string]bool, len(desired))
+	for _, id := range desired {
+		if !desiredSet[id] && !currentSet[id] && !immutableLabels[id] {
+			add = append(add, id)
+		}
+		desiredSet[id] = true
+	}
+	for _, id := range current {
+		if !desiredSet[id] && !immutableLabels[id] {
+			remove = append(remove, id)
+		}
+	}
+	return add, remove
+}
+
 // MarkAsRead marks a message as read
 func MarkAsRead(ctx context.Context, service internal.GmailService, messageID string) error {
 	req := &gmail.ModifyMessageRequest{
@@ -190,6 +366,40 @@ func MarkAsUnread(ctx context.Context, service internal.GmailService, messageID
 	return nil
 }
 
+// ReportSpam marks a message as spam (adds SPAM, removes INBOX)
+func ReportSpam(ctx context.Context, service internal.GmailService, messageID string) error {
+	req := &gmail.ModifyMessageRequest{
+		AddLabelIds:    []string{"SPAM"},
+		RemoveLabelIds: []string{"INBOX"},
+	}
+
+	messagesService := service.GetUsersService().GetMessagesService()
+	call := messagesService.Modify(operations.UserIDMe, messageID, req)
+	_, err := call.Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("failed to report message as spam: %w", err)
+	}
+
+	return nil
+}
+
+// NotSpam moves a message out of spam back to the inbox
+func NotSpam(ctx context.Context, service internal.GmailService, messageID string) error {
+	req := &gmail.ModifyMessageRequest{
+		AddLabelIds:    []string{"INBOX"},
+		RemoveLabelIds: []string{"SPAM"},
+	}
+
+	messagesService := service.GetUsersService().GetMessagesService()
+	call := messagesService.Modify(operations.UserIDMe, messageID, req)
+	_, err := call.Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("failed to mark message as not spam: %w", err)
+	}
+
+	return nil
+}
+
 // MoveMessageToFolder moves a message to a specific folder/label
 // Creates the label if it doesn't exist
 func MoveMessageToFolder(ctx context.Context, service internal.GmailService, messageID string, folderName string) error {
@@ -248,31 +458,17 @@ func BatchTrashMessages(ctx context.Context, service internal.GmailService, mess
 
 // BatchModifyMessages modifies labels on multiple messages
 func BatchModifyMessages(ctx context.Context, service internal.GmailService, messageIDs []string, addLabelIDs []string, removeLabelIDs []string) error {
-	if len(messageIDs) == 0 {
-		return nil
-	}
-
 	// Gmail does not have a native batch modify API, so we modify each message individually
-	var errors []string
-	for _, messageID := range messageIDs {
+	return operations.BatchOperation(ctx, messageIDs, func(ctx context.Context, messageID string) error {
 		req := &gmail.ModifyMessageRequest{
 			AddLabelIds:    addLabelIDs,
 			RemoveLabelIds: removeLabelIDs,
 		}
 
 		messagesService := service.GetUsersService().GetMessagesService()
-		call := messagesService.Modify(operations.UserIDMe, messageID, req)
-		_, err := call.Context(ctx).Do()
-		if err != nil {
-			errors = append(errors, fmt.Sprintf("%s: %v", messageID, err))
-		}
-	}
-
-	if len(errors) > 0 {
-		return fmt.Errorf("failed to modify %d messages: %s", len(errors), strings.Join(errors, "; "))
-	}
-
-	return nil
+		_, err := messagesService.Modify(operations.UserIDMe, messageID, req).Context(ctx).Do()
+		return err
+	}, "modify")
 }
 
 // BatchMarkAsRead marks multiple messages as read
@@ -302,24 +498,14 @@ func BatchMoveToFolder(ctx context.Context, service internal.GmailService, messa
 	}
 
 	// Move all messages to the folder
-	var errors []string
-	for _, messageID := range messageIDs {
+	return operations.BatchOperation(ctx, messageIDs, func(ctx context.Context, messageID string) error {
 		req := &gmail.ModifyMessageRequest{
 			AddLabelIds:    []string{label.ID},
 			RemoveLabelIds: []string{"INBOX"},
 		}
 
 		messagesService := service.GetUsersService().GetMessagesService()
-		call := messagesService.Modify(operations.UserIDMe, messageID, req)
-		_, err := call.Context(ctx).Do()
-		if err != nil {
-			errors = append(errors, fmt.Sprintf("%s: %v", messageID, err))
-		}
-	}
-
-	if len(errors) > 0 {
-		return fmt.Errorf("failed to move %d messages: %s", len(errors), strings.Join(errors, "; "))
-	}
-
-	return nil
+		_, err := messagesService.Modify(operations.UserIDMe, messageID, req).Context(ctx).Do()
+		return err
+	}, "move")
 }
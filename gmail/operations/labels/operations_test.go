@@ -3,13 +3,17 @@ package labels
 import (
 	"context"
 	"errors"
+	"net/http"
 	"testing"
 
+	"github.com/danielrivera/mailbridge-go/core"
 	"github.com/danielrivera/mailbridge-go/gmail/internal"
 	gmailtest "github.com/danielrivera/mailbridge-go/gmail/testing"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/api/gmail/v1"
+	"google.golang.org/api/googleapi"
 )
 
 func TestListLabels_Success(t *testing.T) {
@@ -33,6 +37,40 @@ func TestListLabels_Success(t *testing.T) {
 	assert.Equal(t, "Custom", labels[1].Name)
 }
 
+func TestListLabels_Nested(t *testing.T) {
+	mockGmailService, mockLabelsService := setupMockLabelsService()
+	mockLabelsListCall := &gmailtest.MockLabelsListCall{}
+
+	mockLabelsService.On("List", "me").Return(mockLabelsListCall)
+	mockLabelsListCall.On("Context", context.Background()).Return(mockLabelsListCall)
+	mockLabelsListCall.On("Do").Return(&gmail.ListLabelsResponse{
+		Labels: []*gmail.Label{
+			{Id: "Label_3", Name: "Work/Projects/Apollo", Type: "user"},
+			{Id: "INBOX", Name: "INBOX", Type: "system"},
+			{Id: "Label_1", Name: "Work", Type: "user"},
+			{Id: "Label_2", Name: "Work/Projects", Type: "user"},
+			{Id: "Label_4", Name: "Archive/2023", Type: "user"}, // parent label does not exist
+		},
+	}, nil)
+
+	labels, err := ListLabels(context.Background(), mockGmailService)
+
+	require.NoError(t, err)
+	byID := make(map[string]*Label)
+	for _, label := range labels {
+		byID[label.ID] = label
+	}
+	assert.Equal(t, Label{ID: "INBOX", Name: "INBOX", Type: "system"}, *byID["INBOX"])
+	assert.Equal(t, "", byID["Label_1"].ParentID)
+	assert.Equal(t, 0, byID["Label_1"].Depth)
+	assert.Equal(t, "Label_1", byID["Label_2"].ParentID)
+	assert.Equal(t, 1, byID["Label_2"].Depth)
+	assert.Equal(t, "Label_2", byID["Label_3"].ParentID)
+	assert.Equal(t, 2, byID["Label_3"].Depth)
+	assert.Equal(t, "", byID["Label_4"].ParentID)
+	assert.Equal(t, 1, byID["Label_4"].Depth)
+}
+
 func TestGetLabel_Success(t *testing.T) {
 	mockGmailService, mockLabelsService := setupMockLabelsService()
 	mockLabelsGetCall := &gmailtest.MockLabelsGetCall{}
@@ -77,6 +115,129 @@ func TestCreateLabel_Success(t *testing.T) {
 	assert.Equal(t, "Projects", label.Name)
 }
 
+func TestCreateLabelWithOptions_Success(t *testing.T) {
+	mockGmailService, mockLabelsService := setupMockLabelsService()
+	mockLabelsCreateCall := &gmailtest.MockLabelsCreateCall{}
+
+	var sent *gmail.Label
+	mockLabelsService.On("Create", "me", mock.AnythingOfType("*gmail.Label")).
+		Run(func(args mock.Arguments) { sent = args.Get(1).(*gmail.Label) }).
+		Return(mockLabelsCreateCall)
+	mockLabelsCreateCall.On("Context", context.Background()).Return(mockLabelsCreateCall)
+	mockLabelsCreateCall.On("Do").Return(&gmail.Label{Id: "label-new", Name: "Triage/Urgent", Type: "user"}, nil)
+
+	label, err := CreateLabelWithOptions(context.Background(), mockGmailService, core.LabelOptions{
+		Name:                  "Triage/Urgent",
+		TextColor:             "#FFFFFF",
+		BackgroundColor:       "#cc3a21",
+		LabelListVisibility:   "labelShowIfUnread",
+		MessageListVisibility: "hide",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "label-new", label.ID)
+	assert.Equal(t, &gmail.LabelColor{TextColor: "#ffffff", BackgroundColor: "#cc3a21"}, sent.Color, "colors are sent in Gmail's lowercase form")
+	assert.Equal(t, "labelShowIfUnread", sent.LabelListVisibility)
+	assert.Equal(t, "hide", sent.MessageListVisibility)
+	assert.Equal(t, "user", sent.Type)
+}
+
+func TestCreateLabelWithOptions_Defaults(t *testing.T) {
+	mockGmailService, mockLabelsService := setupMockLabelsService()
+	mockLabelsCreateCall := &gmailtest.MockLabelsCreateCall{}
+
+	mockLabelsService.On("Create", "me", &gmail.Label{
+		Name:                  "Plain",
+		LabelListVisibility:   "labelShow",
+		MessageListVisibility: "show",
+		Type:                  "user",
+	}).Return(mockLabelsCreateCall)
+	mockLabelsCreateCall.On("Context", context.Background()).Return(mockLabelsCreateCall)
+	mockLabelsCreateCall.On("Do").Return(&gmail.Label{Id: "label-plain", Name: "Plain", Type: "user"}, nil)
+
+	_, err := CreateLabelWithOptions(context.Background(), mockGmailService, core.LabelOptions{Name: "Plain"})
+
+	require.NoError(t, err, "no color is sent when none is requested")
+}
+
+func TestCreateLabelWithOptions_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   core.LabelOptions
+		errMsg string
+	}{
+		{"missing name", core.LabelOptions{TextColor: "#ffffff", BackgroundColor: "#000000"}, "label name is required"},
+		{"color outside the palette", core.LabelOptions{Name: "x", TextColor: "#ffffff", BackgroundColor: "#123456"}, `unsupported label color "#123456"`},
+		{"malformed color", core.LabelOptions{Name: "x", TextColor: "white", BackgroundColor: "#000000"}, `unsupported label color "white"`},
+		{"text color alone", core.LabelOptions{Name: "x", TextColor: "#ffffff"}, "must be set together"},
+		{"background color alone", core.LabelOptions{Name: "x", BackgroundColor: "#000000"}, "must be set together"},
+		{"label list visibility", core.LabelOptions{Name: "x", LabelListVisibility: "visible"}, `invalid label list visibility "visible"`},
+		{"message list visibility", core.LabelOptions{Name: "x", MessageListVisibility: "labelShow"}, `invalid message list visibility "labelShow"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockGmailService, mockLabelsService := setupMockLabelsService()
+
+			_, err := CreateLabelWithOptions(context.Background(), mockGmailService, tt.opts)
+
+			assert.ErrorContains(t, err, tt.errMsg)
+			mockLabelsService.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestUpdateLabel_Rename(t *testing.T) {
+	mockGmailService, mockLabelsService := setupMockLabelsService()
+	mockLabelsPatchCall := &gmailtest.MockLabelsPatchCall{}
+
+	// Only the name is patched, so Gmail keeps the label's color and visibility
+	mockLabelsService.On("Patch", "me", "Label_7", &gmail.Label{Name: "Clients/Acme"}).Return(mockLabelsPatchCall)
+	mockLabelsPatchCall.On("Context", context.Background()).Return(mockLabelsPatchCall)
+	mockLabelsPatchCall.On("Do").Return(&gmail.Label{
+		Id:                  "Label_7",
+		Name:                "Clients/Acme",
+		Type:                "user",
+		LabelListVisibility: "labelShowIfUnread",
+		Color:               &gmail.LabelColor{TextColor: "#ffffff", BackgroundColor: "#cc3a21"},
+	}, nil)
+
+	label, err := UpdateLabel(context.Background(), mockGmailService, "Label_7", "Clients/Acme")
+
+	require.NoError(t, err)
+	assert.Equal(t, &Label{ID: "Label_7", Name: "Clients/Acme", Type: "user"}, label)
+	mockLabelsPatchCall.AssertExpectations(t)
+}
+
+func TestUpdateLabel_NotFound(t *testing.T) {
+	mockGmailService, mockLabelsService := setupMockLabelsService()
+	mockLabelsPatchCall := &gmailtest.MockLabelsPatchCall{}
+
+	mockLabelsService.On("Patch", "me", "Label_missing", mock.Anything).Return(mockLabelsPatchCall)
+	mockLabelsPatchCall.On("Context", context.Background()).Return(mockLabelsPatchCall)
+	mockLabelsPatchCall.On("Do").Return(nil, &googleapi.Error{Code: http.StatusNotFound, Message: "Requested entity was not found."})
+
+	label, err := UpdateLabel(context.Background(), mockGmailService, "Label_missing", "Renamed")
+
+	assert.Nil(t, label)
+	assert.ErrorContains(t, err, "failed to update label")
+	var apiErr *googleapi.Error
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusNotFound, apiErr.Code)
+}
+
+func TestUpdateLabel_InvalidArguments(t *testing.T) {
+	mockGmailService, mockLabelsService := setupMockLabelsService()
+
+	_, err := UpdateLabel(context.Background(), mockGmailService, "", "Renamed")
+	assert.EqualError(t, err, "label ID is required")
+
+	_, err = UpdateLabel(context.Background(), mockGmailService, "Label_7", " ")
+	assert.EqualError(t, err, "label name is required")
+
+	mockLabelsService.AssertNotCalled(t, "Patch", mock.Anything, mock.Anything, mock.Anything)
+}
+
 func TestDeleteLabel_Success(t *testing.T) {
 	mockGmailService, mockLabelsService := setupMockLabelsService()
 	mockLabelsDeleteCall := &gmailtest.MockLabelsDeleteCall{}
@@ -110,6 +271,22 @@ func TestMarkReadStatus(t *testing.T) {
 				AddLabelIds: []string{"UNREAD"},
 			},
 		},
+		{
+			name:   "ReportSpam",
+			method: ReportSpam,
+			request: &gmail.ModifyMessageRequest{
+				AddLabelIds:    []string{"SPAM"},
+				RemoveLabelIds: []string{"INBOX"},
+			},
+		},
+		{
+			name:   "NotSpam",
+			method: NotSpam,
+			request: &gmail.ModifyMessageRequest{
+				AddLabelIds:    []string{"INBOX"},
+				RemoveLabelIds: []string{"SPAM"},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -195,6 +372,64 @@ func TestMoveMessageToFolder_CreateNewLabel(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestGetMessageLabels(t *testing.T) {
+	mockGmailService, mockLabelsService, mockMessagesService := setupMockLabelsAndMessagesService()
+	mockMessagesGetCall := &gmailtest.MockMessagesGetCall{}
+	mockLabelsListCall := &gmailtest.MockLabelsListCall{}
+
+	mockMessagesService.On("Get", "me", "msg-123").Return(mockMessagesGetCall)
+	mockMessagesGetCall.On("Format", "minimal").Return(mockMessagesGetCall)
+	mockMessagesGetCall.On("Fields", []googleapi.Field{"labelIds"}).Return(mockMessagesGetCall)
+	mockMessagesGetCall.On("Context", context.Background()).Return(mockMessagesGetCall)
+	mockMessagesGetCall.On("Do").Return(&gmail.Message{LabelIds: []string{"INBOX", "Label_2"}}, nil)
+
+	mockLabelsService.On("List", "me").Return(mockLabelsListCall)
+	mockLabelsListCall.On("Context", context.Background()).Return(mockLabelsListCall)
+	mockLabelsListCall.On("Do").Return(&gmail.ListLabelsResponse{
+		Labels: []*gmail.Label{
+			{Id: "INBOX", Name: "INBOX", Type: "system"},
+			{Id: "Label_1", Name: "Work", Type: "user"},
+			{Id: "Label_2", Name: "Work/Projects", Type: "user"},
+		},
+	}, nil)
+
+	for _, l := range []*gmail.Label{
+		{Id: "INBOX", Name: "INBOX", Type: "system", MessagesTotal: 120, MessagesUnread: 7},
+		{Id: "Label_2", Name: "Work/Projects", Type: "user", MessagesTotal: 15, MessagesUnread: 2},
+	} {
+		mockLabelsGetCall := &gmailtest.MockLabelsGetCall{}
+		mockLabelsService.On("Get", "me", l.Id).Return(mockLabelsGetCall)
+		mockLabelsGetCall.On("Context", context.Background()).Return(mockLabelsGetCall)
+		mockLabelsGetCall.On("Do").Return(l, nil)
+	}
+
+	result, err := GetMessageLabels(context.Background(), mockGmailService, "msg-123")
+
+	require.NoError(t, err)
+	assert.Equal(t, []core.Label{
+		{ID: "INBOX", Name: "INBOX", Type: "system", TotalMessages: 120, UnreadMessages: 7},
+		{ID: "Label_2", Name: "Work/Projects", Type: "user", TotalMessages: 15, UnreadMessages: 2, ParentID: "Label_1", Depth: 1},
+	}, result)
+	mockLabelsService.AssertNotCalled(t, "Get", "me", "Label_1")
+}
+
+func TestGetMessageLabels_NoLabels(t *testing.T) {
+	mockGmailService, mockLabelsService, mockMessagesService := setupMockLabelsAndMessagesService()
+	mockMessagesGetCall := &gmailtest.MockMessagesGetCall{}
+
+	mockMessagesService.On("Get", "me", "msg-123").Return(mockMessagesGetCall)
+	mockMessagesGetCall.On("Format", "minimal").Return(mockMessagesGetCall)
+	mockMessagesGetCall.On("Fields", mock.Anything).Return(mockMessagesGetCall)
+	mockMessagesGetCall.On("Context", context.Background()).Return(mockMessagesGetCall)
+	mockMessagesGetCall.On("Do").Return(&gmail.Message{}, nil)
+
+	result, err := GetMessageLabels(context.Background(), mockGmailService, "msg-123")
+
+	require.NoError(t, err)
+	assert.Empty(t, result)
+	mockLabelsService.AssertNotCalled(t, "List", "me")
+}
+
 func TestFindLabelByName_NotFound(t *testing.T) {
 	mockGmailService, mockLabelsService := setupMockLabelsService()
 	mockLabelsListCall := &gmailtest.MockLabelsListCall{}
@@ -251,6 +486,105 @@ func TestModifyMessageLabels(t *testing.T) {
 	}
 }
 
+func TestDiffLabels(t *testing.T) {
+	tests := []struct {
+		name       string
+		current    []string
+		desired    []string
+		wantAdd    []string
+		wantRemove []string
+	}{
+		{
+			name:       "add and remove",
+			current:    []string{"INBOX", "UNREAD", "Label_1"},
+			desired:    []string{"INBOX", "Label_2"},
+			wantAdd:    []string{"Label_2"},
+			wantRemove: []string{"UNREAD", "Label_1"},
+		},
+		{
+			name:    "already matching",
+			current: []string{"INBOX", "Label_1"},
+			desired: []string{"Label_1", "INBOX"},
+		},
+		{
+			name:       "clear all",
+			current:    []string{"INBOX", "Label_1"},
+			desired:    nil,
+			wantRemove: []string{"INBOX", "Label_1"},
+		},
+		{
+			name:    "duplicates in desired",
+			current: nil,
+			desired: []string{"Label_1", "Label_1"},
+			wantAdd: []string{"Label_1"},
+		},
+		{
+			name:       "immutable labels kept",
+			current:    []string{"SENT", "INBOX", "CHAT"},
+			desired:    []string{"Label_1"},
+			wantAdd:    []string{"Label_1"},
+			wantRemove: []string{"INBOX"},
+		},
+		{
+			name:    "immutable labels not added",
+			current: []string{"INBOX"},
+			desired: []string{"INBOX", "DRAFT", "SENT"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			add, remove := diffLabels(tt.current, tt.desired)
+			assert.Equal(t, tt.wantAdd, add)
+			assert.Equal(t, tt.wantRemove, remove)
+		})
+	}
+}
+
+func TestSetMessageLabels(t *testing.T) {
+	mockGmailService, mockMessagesService := setupMockMessagesService()
+	mockMessagesGetCall := &gmailtest.MockMessagesGetCall{}
+	mockMessagesModifyCall := &gmailtest.MockMessagesModifyCall{}
+
+	mockMessagesService.On("Get", "me", "msg-123").Return(mockMessagesGetCall)
+	mockMessagesGetCall.On("Format", "minimal").Return(mockMessagesGetCall)
+	mockMessagesGetCall.On("Fields", []googleapi.Field{"labelIds"}).Return(mockMessagesGetCall)
+	mockMessagesGetCall.On("Context", context.Background()).Return(mockMessagesGetCall)
+	mockMessagesGetCall.On("Do").Return(&gmail.Message{
+		Id:       "msg-123",
+		LabelIds: []string{"INBOX", "UNREAD", "Label_1"},
+	}, nil)
+
+	expectedReq := &gmail.ModifyMessageRequest{
+		AddLabelIds:    []string{"Label_2"},
+		RemoveLabelIds: []string{"UNREAD", "Label_1"},
+	}
+	mockMessagesService.On("Modify", "me", "msg-123", expectedReq).Return(mockMessagesModifyCall)
+	mockMessagesModifyCall.On("Context", context.Background()).Return(mockMessagesModifyCall)
+	mockMessagesModifyCall.On("Do").Return(&gmail.Message{Id: "msg-123"}, nil)
+
+	err := SetMessageLabels(context.Background(), mockGmailService, "msg-123", []string{"INBOX", "Label_2"})
+
+	require.NoError(t, err)
+	mockMessagesService.AssertNumberOfCalls(t, "Modify", 1)
+}
+
+func TestSetMessageLabels_NoChange(t *testing.T) {
+	mockGmailService, mockMessagesService := setupMockMessagesService()
+	mockMessagesGetCall := &gmailtest.MockMessagesGetCall{}
+
+	mockMessagesService.On("Get", "me", "msg-123").Return(mockMessagesGetCall)
+	mockMessagesGetCall.On("Format", "minimal").Return(mockMessagesGetCall)
+	mockMessagesGetCall.On("Fields", []googleapi.Field{"labelIds"}).Return(mockMessagesGetCall)
+	mockMessagesGetCall.On("Context", context.Background()).Return(mockMessagesGetCall)
+	mockMessagesGetCall.On("Do").Return(&gmail.Message{Id: "msg-123", LabelIds: []string{"INBOX"}}, nil)
+
+	err := SetMessageLabels(context.Background(), mockGmailService, "msg-123", []string{"INBOX"})
+
+	require.NoError(t, err)
+	mockMessagesService.AssertNotCalled(t, "Modify", "me", "msg-123", mock.Anything)
+}
+
 func TestLabelsAPIErrors(t *testing.T) {
 	t.Run("ListLabels error", func(t *testing.T) {
 		mockGmailService, mockLabelsService := setupMockLabelsService()
@@ -580,6 +914,33 @@ func TestBatchModifyMessages(t *testing.T) {
 	}
 }
 
+func TestBatchModifyMessages_PartialFailure(t *testing.T) {
+	ctx := context.Background()
+	mockGmailService, mockMessagesService := setupMockMessagesService()
+	req := &gmail.ModifyMessageRequest{AddLabelIds: []string{"label-1"}}
+
+	for _, msgID := range []string{"msg-1", "msg-2", "msg-3"} {
+		mockModifyCall := &gmailtest.MockMessagesModifyCall{}
+		mockMessagesService.On("Modify", "me", msgID, req).Return(mockModifyCall).Once()
+		mockModifyCall.On("Context", ctx).Return(mockModifyCall).Once()
+		if msgID == "msg-2" {
+			mockModifyCall.On("Do").Return(nil, errors.New("API error")).Once()
+		} else {
+			mockModifyCall.On("Do").Return(&gmail.Message{Id: msgID}, nil).Once()
+		}
+	}
+
+	err := BatchModifyMessages(ctx, mockGmailService, []string{"msg-1", "msg-2", "msg-3"}, []string{"label-1"}, nil)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to modify 1 messages")
+	var batchErr *core.BatchError
+	require.ErrorAs(t, err, &batchErr)
+	assert.Equal(t, []string{"msg-1", "msg-3"}, batchErr.SucceededIDs())
+	assert.Equal(t, []string{"msg-2"}, batchErr.FailedIDs())
+	mockMessagesService.AssertExpectations(t)
+}
+
 func TestBatchMarkAsRead(t *testing.T) {
 	ctx := context.Background()
 
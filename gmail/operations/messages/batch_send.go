@@ -0,0 +1,75 @@
+package messages
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/danielrivera/mailbridge-go/core"
+	"github.com/danielrivera/mailbridge-go/gmail/internal"
+)
+
+// DefaultBatchSendConcurrency is the number of drafts sent in parallel by BatchSend
+// when no concurrency is given
+const DefaultBatchSendConcurrency = 4
+
+// BatchSend sends each draft with at most concurrency sends in flight. Sends throttled
+// with 429 or 503 are retried by the client's RetryPolicy transport, not here, so a
+// send is never retried twice over. It continues past failed drafts: the returned
+// slice has one entry per draft, in order, left empty for drafts that failed, and the
+// failures are reported together as a *core.BatchError.
+// When opts sets an IdempotencyKey, each draft uses the key suffixed with its index
+// so the drafts get distinct Message-IDs.
+func BatchSend(ctx context.Context, service internal.GmailService, drafts []*core.Draft, opts *core.SendOptions, clock core.Clock, concurrency int) ([]core.SendResponse, error) {
+	if concurrency <= 0 {
+		concurrency = DefaultBatchSendConcurrency
+	}
+
+	responses := make([]core.SendResponse, len(drafts))
+	failures := make(map[int]error)
+	var mu sync.Mutex // guards failures
+
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, draft := range drafts {
+		wg.Add(1)
+		go func(i int, draft *core.Draft) {
+			defer wg.Done()
+
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				mu.Lock()
+				failures[i] = ctx.Err()
+				mu.Unlock()
+				return
+			}
+
+			resp, err := SendMessage(ctx, service, draft, batchSendOptions(opts, i), clock)
+			if err != nil {
+				mu.Lock()
+				failures[i] = err
+				mu.Unlock()
+				return
+			}
+			responses[i] = *resp
+		}(i, draft)
+	}
+	wg.Wait()
+
+	if len(failures) > 0 {
+		return responses, &core.BatchError{Total: len(drafts), Errors: failures}
+	}
+	return responses, nil
+}
+
+// batchSendOptions returns the send options for the draft at index i
+func batchSendOptions(opts *core.SendOptions, i int) *core.SendOptions {
+	if opts == nil || opts.IdempotencyKey == "" {
+		return opts
+	}
+	perDraft := *opts
+	perDraft.IdempotencyKey = fmt.Sprintf("%s-%d", opts.IdempotencyKey, i)
+	return &perDraft
+}
@@ -0,0 +1,176 @@
+package messages
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/danielrivera/mailbridge-go/core"
+	gmailtest "github.com/danielrivera/mailbridge-go/gmail/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	gmailapi "google.golang.org/api/gmail/v1"
+	"google.golang.org/api/googleapi"
+)
+
+// rawContains returns a matcher for sent messages whose raw content contains s
+func rawContains(s string) interface{} {
+	return mock.MatchedBy(func(m *gmailapi.Message) bool {
+		raw, err := base64.URLEncoding.DecodeString(m.Raw)
+		return err == nil && strings.Contains(string(raw), s)
+	})
+}
+
+func newBatchDrafts(n int) []*core.Draft {
+	drafts := make([]*core.Draft, n)
+	for i := range drafts {
+		drafts[i] = &core.Draft{
+			To:      []core.EmailAddress{{Email: fmt.Sprintf("user%d@example.com", i)}},
+			Subject: fmt.Sprintf("Newsletter %d", i),
+			Body:    core.EmailBody{Text: "Hello"},
+		}
+	}
+	return drafts
+}
+
+func setupBatchSendMocks() (*gmailtest.MockGmailService, *gmailtest.MockMessagesService) {
+	mockService := &gmailtest.MockGmailService{}
+	mockUsersService := &gmailtest.MockUsersService{}
+	mockMessagesService := &gmailtest.MockMessagesService{}
+	mockService.On("GetUsersService").Return(mockUsersService)
+	mockUsersService.On("GetMessagesService").Return(mockMessagesService)
+	return mockService, mockMessagesService
+}
+
+// expectSend makes sends whose raw content contains match return the given result
+func expectSend(ctx context.Context, messagesService *gmailtest.MockMessagesService, match string, sent *gmailapi.Message, err error) *gmailtest.MockMessagesSendCall {
+	call := &gmailtest.MockMessagesSendCall{}
+	call.On("Context", ctx).Return(call)
+	call.On("Do").Return(sent, err)
+	messagesService.On("Send", "me", rawContains(match)).Return(call)
+	return call
+}
+
+func TestBatchSend_ContinuesPastFailures(t *testing.T) {
+	ctx := context.Background()
+	mockService, mockMessagesService := setupBatchSendMocks()
+
+	expectSend(ctx, mockMessagesService, "Subject: Newsletter 0", &gmailapi.Message{Id: "sent-0", ThreadId: "thread-0"}, nil)
+	expectSend(ctx, mockMessagesService, "Subject: Newsletter 1", nil, errors.New("invalid recipient"))
+	expectSend(ctx, mockMessagesService, "Subject: Newsletter 2", &gmailapi.Message{Id: "sent-2", ThreadId: "thread-2"}, nil)
+
+	responses, err := BatchSend(ctx, mockService, newBatchDrafts(3), nil, nil, 2)
+
+	require.Len(t, responses, 3)
+	assert.Equal(t, core.SendResponse{ID: "sent-0", ThreadID: "thread-0"}, responses[0])
+	assert.Empty(t, responses[1].ID)
+	assert.Equal(t, core.SendResponse{ID: "sent-2", ThreadID: "thread-2"}, responses[2])
+
+	var batchErr *core.BatchError
+	require.ErrorAs(t, err, &batchErr)
+	assert.Equal(t, 3, batchErr.Total)
+	require.Len(t, batchErr.Errors, 1)
+	assert.Contains(t, batchErr.Errors[1].Error(), "invalid recipient")
+
+	mockMessagesService.AssertNumberOfCalls(t, "Send", 3)
+}
+
+func TestBatchSend_InvalidDraft(t *testing.T) {
+	ctx := context.Background()
+	mockService, mockMessagesService := setupBatchSendMocks()
+
+	expectSend(ctx, mockMessagesService, "Subject: Newsletter 0", &gmailapi.Message{Id: "sent-0"}, nil)
+	drafts := newBatchDrafts(1)
+	drafts = append(drafts, &core.Draft{Subject: "No recipients"})
+
+	responses, err := BatchSend(ctx, mockService, drafts, nil, nil, 0)
+
+	require.Len(t, responses, 2)
+	assert.Equal(t, "sent-0", responses[0].ID)
+	var batchErr *core.BatchError
+	require.ErrorAs(t, err, &batchErr)
+	assert.Contains(t, batchErr.Errors[1].Error(), "invalid draft")
+}
+
+func TestBatchSend_RateLimitNotRetried(t *testing.T) {
+	ctx := context.Background()
+	mockService, mockMessagesService := setupBatchSendMocks()
+
+	call := &gmailtest.MockMessagesSendCall{}
+	call.On("Context", ctx).Return(call)
+	call.On("Do").Return(nil, &googleapi.Error{Code: http.StatusTooManyRequests})
+	mockMessagesService.On("Send", "me", mock.Anything).Return(call)
+
+	_, err := BatchSend(ctx, mockService, newBatchDrafts(1), nil, nil, 1)
+
+	var batchErr *core.BatchError
+	require.ErrorAs(t, err, &batchErr)
+	// Throttled sends are retried by the RetryPolicy transport only
+	call.AssertNumberOfCalls(t, "Do", 1)
+}
+
+func TestBatchSend_BoundedConcurrency(t *testing.T) {
+	ctx := context.Background()
+	mockService, mockMessagesService := setupBatchSendMocks()
+
+	var inFlight, maxInFlight int32
+	call := &gmailtest.MockMessagesSendCall{}
+	call.On("Context", ctx).Return(call)
+	call.On("Do").
+		Run(func(mock.Arguments) {
+			n := atomic.AddInt32(&inFlight, 1)
+			for {
+				peak := atomic.LoadInt32(&maxInFlight)
+				if n <= peak || atomic.CompareAndSwapInt32(&maxInFlight, peak, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			atomic.AddInt32(&inFlight, -1)
+		}).
+		Return(&gmailapi.Message{Id: "sent"}, nil)
+	mockMessagesService.On("Send", "me", mock.Anything).Return(call)
+
+	responses, err := BatchSend(ctx, mockService, newBatchDrafts(8), nil, nil, 2)
+
+	require.NoError(t, err)
+	assert.Len(t, responses, 8)
+	assert.LessOrEqual(t, atomic.LoadInt32(&maxInFlight), int32(2))
+	mockMessagesService.AssertNumberOfCalls(t, "Send", 8)
+}
+
+func TestBatchSend_IdempotencyKeyPerDraft(t *testing.T) {
+	ctx := context.Background()
+	mockService, mockMessagesService := setupBatchSendMocks()
+
+	var mu sync.Mutex
+	messageIDs := map[string]bool{}
+	call := &gmailtest.MockMessagesSendCall{}
+	call.On("Context", ctx).Return(call)
+	call.On("Do").Return(&gmailapi.Message{Id: "sent"}, nil)
+	mockMessagesService.On("Send", "me", mock.Anything).
+		Run(func(args mock.Arguments) {
+			raw, _ := base64.URLEncoding.DecodeString(args.Get(1).(*gmailapi.Message).Raw)
+			for _, line := range strings.Split(string(raw), "\r\n") {
+				if strings.HasPrefix(line, "Message-ID: ") {
+					mu.Lock()
+					messageIDs[line] = true
+					mu.Unlock()
+				}
+			}
+		}).
+		Return(call)
+
+	_, err := BatchSend(ctx, mockService, newBatchDrafts(3), &core.SendOptions{IdempotencyKey: "campaign-42"}, nil, 3)
+
+	require.NoError(t, err)
+	assert.Len(t, messageIDs, 3, "each draft gets its own Message-ID")
+}
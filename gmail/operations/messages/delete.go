@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"github.com/danielrivera/mailbridge-go/gmail/operations"
-	"strings"
 
 	"github.com/danielrivera/mailbridge-go/gmail/internal"
 )
@@ -23,22 +22,8 @@ func DeleteMessage(ctx context.Context, service internal.GmailService, messageID
 
 // BatchDeleteMessages permanently deletes multiple messages
 func BatchDeleteMessages(ctx context.Context, service internal.GmailService, messageIDs []string) error {
-	if len(messageIDs) == 0 {
-		return nil
-	}
-
 	// Gmail doesn't have a native batch delete API, so we delete each message individually
-	// This could be optimized with goroutines for better performance
-	var errors []string
-	for _, messageID := range messageIDs {
-		if err := DeleteMessage(ctx, service, messageID); err != nil {
-			errors = append(errors, fmt.Sprintf("%s: %v", messageID, err))
-		}
-	}
-
-	if len(errors) > 0 {
-		return fmt.Errorf("failed to delete %d messages: %s", len(errors), strings.Join(errors, "; "))
-	}
-
-	return nil
+	return operations.BatchOperation(ctx, messageIDs, func(ctx context.Context, messageID string) error {
+		return DeleteMessage(ctx, service, messageID)
+	}, "delete")
 }
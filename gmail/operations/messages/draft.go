@@ -0,0 +1,50 @@
+package messages
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/danielrivera/mailbridge-go/core"
+	"github.com/danielrivera/mailbridge-go/gmail/internal"
+	"github.com/danielrivera/mailbridge-go/gmail/operations"
+	"google.golang.org/api/gmail/v1"
+)
+
+// SendDraftInThread sends an existing draft, attaching it to the given thread.
+// The draft is fetched in raw format and re-submitted with the thread ID set,
+// so drafts created without a thread still land in the conversation.
+func SendDraftInThread(ctx context.Context, service internal.GmailService, draftID, threadID string) (*core.SendResponse, error) {
+	if draftID == "" {
+		return nil, fmt.Errorf("draft ID is required")
+	}
+	if threadID == "" {
+		return nil, fmt.Errorf("thread ID is required")
+	}
+
+	draftsService := service.GetUsersService().GetDraftsService()
+	existing, err := draftsService.Get(operations.UserIDMe, draftID).Format("raw").Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get draft: %w", err)
+	}
+	if existing.Message == nil {
+		return nil, fmt.Errorf("draft %s has no message", draftID)
+	}
+
+	draft := &gmail.Draft{
+		Id: draftID,
+		Message: &gmail.Message{
+			Raw:      existing.Message.Raw,
+			ThreadId: threadID,
+		},
+	}
+
+	sent, err := draftsService.Send(operations.UserIDMe, draft).Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to send draft: %w", err)
+	}
+
+	return &core.SendResponse{
+		ID:       sent.Id,
+		ThreadID: sent.ThreadId,
+	}, nil
+}
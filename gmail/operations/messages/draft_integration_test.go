@@ -0,0 +1,100 @@
+package messages
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	gmailtest "github.com/danielrivera/mailbridge-go/gmail/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/gmail/v1"
+)
+
+func TestSendDraftInThread_Success(t *testing.T) {
+	mockGmailService, mockDraftsService := setupMockDraftsService()
+	mockGetCall := &gmailtest.MockDraftsGetCall{}
+	mockSendCall := &gmailtest.MockDraftsSendCall{}
+
+	mockDraftsService.On("Get", "me", "draft-1").Return(mockGetCall)
+	mockGetCall.On("Format", "raw").Return(mockGetCall)
+	mockGetCall.On("Context", context.Background()).Return(mockGetCall)
+	mockGetCall.On("Do").Return(&gmail.Draft{
+		Id:      "draft-1",
+		Message: &gmail.Message{Id: "msg-draft", Raw: "cmF3LW1lc3NhZ2U"},
+	}, nil)
+
+	var sentDraft *gmail.Draft
+	mockDraftsService.On("Send", "me", mock.AnythingOfType("*gmail.Draft")).
+		Run(func(args mock.Arguments) {
+			sentDraft = args.Get(1).(*gmail.Draft)
+		}).
+		Return(mockSendCall)
+	mockSendCall.On("Context", context.Background()).Return(mockSendCall)
+	mockSendCall.On("Do").Return(&gmail.Message{Id: "msg-1", ThreadId: "thread-1"}, nil)
+
+	resp, err := SendDraftInThread(context.Background(), mockGmailService, "draft-1", "thread-1")
+
+	require.NoError(t, err)
+	assert.Equal(t, "msg-1", resp.ID)
+	assert.Equal(t, "thread-1", resp.ThreadID)
+	require.NotNil(t, sentDraft)
+	assert.Equal(t, "draft-1", sentDraft.Id)
+	assert.Equal(t, "thread-1", sentDraft.Message.ThreadId)
+	assert.Equal(t, "cmF3LW1lc3NhZ2U", sentDraft.Message.Raw)
+}
+
+func TestSendDraftInThread_MissingIDs(t *testing.T) {
+	_, err := SendDraftInThread(context.Background(), &gmailtest.MockGmailService{}, "", "thread-1")
+	assert.ErrorContains(t, err, "draft ID is required")
+
+	_, err = SendDraftInThread(context.Background(), &gmailtest.MockGmailService{}, "draft-1", "")
+	assert.ErrorContains(t, err, "thread ID is required")
+}
+
+func TestSendDraftInThread_GetError(t *testing.T) {
+	mockGmailService, mockDraftsService := setupMockDraftsService()
+	mockGetCall := &gmailtest.MockDraftsGetCall{}
+
+	mockDraftsService.On("Get", "me", "draft-1").Return(mockGetCall)
+	mockGetCall.On("Format", "raw").Return(mockGetCall)
+	mockGetCall.On("Context", context.Background()).Return(mockGetCall)
+	mockGetCall.On("Do").Return(nil, errors.New("draft not found"))
+
+	resp, err := SendDraftInThread(context.Background(), mockGmailService, "draft-1", "thread-1")
+
+	assert.Nil(t, resp)
+	assert.ErrorContains(t, err, "failed to get draft")
+	mockDraftsService.AssertNotCalled(t, "Send", mock.Anything, mock.Anything)
+}
+
+func TestSendDraftInThread_SendError(t *testing.T) {
+	mockGmailService, mockDraftsService := setupMockDraftsService()
+	mockGetCall := &gmailtest.MockDraftsGetCall{}
+	mockSendCall := &gmailtest.MockDraftsSendCall{}
+
+	mockDraftsService.On("Get", "me", "draft-1").Return(mockGetCall)
+	mockGetCall.On("Format", "raw").Return(mockGetCall)
+	mockGetCall.On("Context", context.Background()).Return(mockGetCall)
+	mockGetCall.On("Do").Return(&gmail.Draft{Id: "draft-1", Message: &gmail.Message{Raw: "cmF3"}}, nil)
+	mockDraftsService.On("Send", "me", mock.Anything).Return(mockSendCall)
+	mockSendCall.On("Context", context.Background()).Return(mockSendCall)
+	mockSendCall.On("Do").Return(nil, errors.New("quota exceeded"))
+
+	resp, err := SendDraftInThread(context.Background(), mockGmailService, "draft-1", "thread-1")
+
+	assert.Nil(t, resp)
+	assert.ErrorContains(t, err, "failed to send draft")
+}
+
+func setupMockDraftsService() (*gmailtest.MockGmailService, *gmailtest.MockDraftsService) {
+	mockGmailService := &gmailtest.MockGmailService{}
+	mockUsersService := &gmailtest.MockUsersService{}
+	mockDraftsService := &gmailtest.MockDraftsService{}
+
+	mockGmailService.On("GetUsersService").Return(mockUsersService)
+	mockUsersService.On("GetDraftsService").Return(mockDraftsService)
+
+	return mockGmailService, mockDraftsService
+}
@@ -0,0 +1,197 @@
+package messages
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html"
+	"mime"
+	"net/mail"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/danielrivera/mailbridge-go/core"
+	"github.com/danielrivera/mailbridge-go/gmail/internal"
+	"github.com/danielrivera/mailbridge-go/gmail/operations"
+)
+
+// forwardSeparator introduces the quoted original in a forwarded message
+const forwardSeparator = "---------- Forwarded message ---------"
+
+// mimeTypeMessage is the MIME type of a message forwarded as an attachment
+const mimeTypeMessage = "message/rfc822"
+
+// ForwardMessage forwards a message to the given recipients. The original is fetched
+// and quoted below the draft's body with its From, Date, Subject, To and Cc headers.
+// The draft is optional and may set Cc, Bcc, a subject (default "Fwd: " plus the
+// original subject) and its own attachments. With opts.IncludeAttachments, the
+// original attachments are downloaded and re-attached
+func ForwardMessage(ctx context.Context, service internal.GmailService, messageID string, to []core.EmailAddress, draft *core.Draft, opts *core.ForwardOptions, clock core.Clock) (*core.SendResponse, error) {
+	if messageID == "" {
+		return nil, fmt.Errorf("message ID is required")
+	}
+	if len(to) == 0 {
+		return nil, fmt.Errorf("at least one forward recipient is required")
+	}
+	if draft == nil {
+		draft = &core.Draft{}
+	}
+
+	original, err := GetMessage(ctx, service, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	forward := buildForward(original, to, draft)
+	if opts != nil && opts.IncludeAttachments {
+		for _, att := range original.Attachments {
+			data := att.Data
+			if data == nil && att.ID != "" {
+				data, err = GetAttachment(ctx, service, messageID, att.ID)
+				if err != nil {
+					return nil, fmt.Errorf("failed to download attachment %s: %w", att.Filename, err)
+				}
+			}
+			mimeType := att.MimeType
+			if mimeType == "" {
+				mimeType = "application/octet-stream"
+			}
+			forward.Attachments = append(forward.Attachments, core.Attachment{Filename: att.Filename, MimeType: mimeType, Data: data})
+		}
+	}
+
+	return SendMessage(ctx, service, forward, nil, clock)
+}
+
+// buildForward returns a copy of draft addressed to to, with the original message
+// quoted below the draft's body. An HTML body is built when either side has HTML
+func buildForward(original *core.Email, to []core.EmailAddress, draft *core.Draft) *core.Draft {
+	forward := *draft
+	forward.To = to
+	forward.Subject = forwardSubject(draft.Subject, original.Subject)
+	forward.Attachments = append([]core.Attachment(nil), draft.Attachments...)
+
+	headers := forwardHeaders(original)
+
+	var text strings.Builder
+	if draft.Body.Text != "" {
+		text.WriteString(draft.Body.Text + "\n\n")
+	}
+	text.WriteString(forwardSeparator + "\n" + strings.Join(headers, "\n") + "\n\n" + original.Body.Text)
+	forward.Body = core.EmailBody{Text: text.String()}
+
+	if draft.Body.HTML != "" || original.Body.HTML != "" {
+		var b strings.Builder
+		switch {
+		case draft.Body.HTML != "":
+			b.WriteString(draft.Body.HTML)
+		case draft.Body.Text != "":
+			b.WriteString("<p>" + textToHTML(draft.Body.Text) + "</p>")
+		}
+		b.WriteString("<br><div>" + forwardSeparator + "<br>" + textToHTML(strings.Join(headers, "\n")) + "</div><br>")
+		if original.Body.HTML != "" {
+			b.WriteString(original.Body.HTML)
+		} else {
+			b.WriteString(textToHTML(original.Body.Text))
+		}
+		forward.Body.HTML = b.String()
+	}
+
+	return &forward
+}
+
+// forwardHeaders returns the original message headers quoted in a forward
+func forwardHeaders(original *core.Email) []string {
+	date := original.SentDate
+	if date.IsZero() {
+		date = original.Date
+	}
+
+	headers := []string{"From: " + formatEmailAddress(original.From)}
+	if !date.IsZero() {
+		headers = append(headers, "Date: "+date.Format(time.RFC1123Z))
+	}
+	headers = append(headers, "Subject: "+original.Subject)
+	if len(original.To) > 0 {
+		headers = append(headers, "To: "+formatEmailAddresses(original.To))
+	}
+	if len(original.Cc) > 0 {
+		headers = append(headers, "Cc: "+formatEmailAddresses(original.Cc))
+	}
+	return headers
+}
+
+// forwardSubject returns subject, or the original one when empty, with a "Fwd: "
+// prefix unless it already starts with "Fwd:" or "Fw:"
+func forwardSubject(subject, original string) string {
+	if subject == "" {
+		subject = original
+	}
+	lower := strings.ToLower(subject)
+	if strings.HasPrefix(lower, "fwd:") || strings.HasPrefix(lower, "fw:") {
+		return subject
+	}
+	return "Fwd: " + subject
+}
+
+// textToHTML escapes plain text for an HTML body, keeping its line breaks
+func textToHTML(text string) string {
+	return strings.ReplaceAll(html.EscapeString(text), "\n", "<br>")
+}
+
+// attachMessages returns a copy of draft with the messages named in AttachedMessages
+// downloaded in raw form and appended as message/rfc822 attachments, or draft itself
+// when it attaches no messages
+func attachMessages(ctx context.Context, service internal.GmailService, draft *core.Draft) (*core.Draft, error) {
+	if draft == nil || len(draft.AttachedMessages) == 0 {
+		return draft, nil
+	}
+
+	withMessages := *draft
+	withMessages.Attachments = append([]core.Attachment(nil), draft.Attachments...)
+
+	messagesService := operations.GetMessagesService(service)
+	for _, messageID := range draft.AttachedMessages {
+		if messageID == "" {
+			return nil, fmt.Errorf("attached message ID is required")
+		}
+		msg, err := messagesService.Get(operations.UserIDMe, messageID).Format("raw").Context(ctx).Do()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get attached message %s: %w", messageID, err)
+		}
+		raw, err := decodeBase64Data(msg.Raw)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode attached message %s: %w", messageID, err)
+		}
+		withMessages.Attachments = append(withMessages.Attachments, core.Attachment{
+			Filename: emlFilename(raw),
+			MimeType: mimeTypeMessage,
+			Data:     raw,
+		})
+	}
+
+	return &withMessages, nil
+}
+
+// emlFilename names an attached message after its subject, replacing characters that
+// are not allowed in filenames, or "message.eml" when it has no subject
+func emlFilename(raw []byte) string {
+	name := ""
+	if msg, err := mail.ReadMessage(bytes.NewReader(raw)); err == nil {
+		subject := msg.Header.Get("Subject")
+		if decoded, err := new(mime.WordDecoder).DecodeHeader(subject); err == nil {
+			subject = decoded
+		}
+		name = strings.TrimSpace(strings.Map(func(r rune) rune {
+			if strings.ContainsRune(`/\:*?"<>|`, r) || unicode.IsControl(r) {
+				return '_'
+			}
+			return r
+		}, subject))
+	}
+	if name == "" {
+		name = "message"
+	}
+	return name + ".eml"
+}
@@ -0,0 +1,175 @@
+package messages
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"strings"
+	"testing"
+
+	"github.com/danielrivera/mailbridge-go/core"
+	gmailtest "github.com/danielrivera/mailbridge-go/gmail/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	gmailapi "google.golang.org/api/gmail/v1"
+)
+
+func setupForwardMocks(ctx context.Context) (*gmailtest.MockGmailService, *gmailtest.MockMessagesService, *gmailapi.Message) {
+	mockService := &gmailtest.MockGmailService{}
+	mockUsersService := &gmailtest.MockUsersService{}
+	mockMessagesService := &gmailtest.MockMessagesService{}
+	mockGetCall := &gmailtest.MockMessagesGetCall{}
+	mockSendCall := &gmailtest.MockMessagesSendCall{}
+	sent := &gmailapi.Message{}
+
+	mockService.On("GetUsersService").Return(mockUsersService)
+	mockUsersService.On("GetMessagesService").Return(mockMessagesService)
+	mockMessagesService.On("Get", "me", "msg-123").Return(mockGetCall)
+	mockGetCall.On("Format", "full").Return(mockGetCall)
+	mockGetCall.On("Context", ctx).Return(mockGetCall)
+	mockGetCall.On("Do").Return(&gmailapi.Message{
+		Id: "msg-123",
+		Payload: &gmailapi.MessagePart{
+			MimeType: "multipart/mixed",
+			Headers: []*gmailapi.MessagePartHeader{
+				{Name: "From", Value: "alice@example.com"},
+				{Name: "Subject", Value: "Report"},
+			},
+			Parts: []*gmailapi.MessagePart{
+				{PartId: "0", MimeType: "text/plain", Body: &gmailapi.MessagePartBody{Data: base64.URLEncoding.EncodeToString([]byte("Attached"))}},
+				{PartId: "1", MimeType: "application/pdf", Filename: "report.pdf", Body: &gmailapi.MessagePartBody{AttachmentId: "att-1", Size: 3}},
+			},
+		},
+	}, nil)
+	mockMessagesService.On("Send", "me", mock.AnythingOfType("*gmail.Message")).
+		Run(func(args mock.Arguments) { *sent = *args.Get(1).(*gmailapi.Message) }).
+		Return(mockSendCall)
+	mockSendCall.On("Context", ctx).Return(mockSendCall)
+	mockSendCall.On("Do").Return(&gmailapi.Message{Id: "fwd-789", ThreadId: "thread-fwd"}, nil)
+
+	return mockService, mockMessagesService, sent
+}
+
+func TestForwardMessage_IncludeAttachments(t *testing.T) {
+	ctx := context.Background()
+	mockService, mockMessagesService, sent := setupForwardMocks(ctx)
+
+	mockAttachmentCall := &gmailtest.MockMessagesAttachmentGetCall{}
+	mockMessagesService.On("GetAttachment", "me", "msg-123", "att-1").Return(mockAttachmentCall)
+	mockAttachmentCall.On("Context", ctx).Return(mockAttachmentCall)
+	mockAttachmentCall.On("Do").Return(&gmailapi.MessagePartBody{Data: base64.URLEncoding.EncodeToString([]byte("pdf"))}, nil)
+
+	response, err := ForwardMessage(ctx, mockService, "msg-123", []core.EmailAddress{{Email: "bob@example.com"}},
+		&core.Draft{Body: core.EmailBody{Text: "FYI"}}, &core.ForwardOptions{IncludeAttachments: true}, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, &core.SendResponse{ID: "fwd-789", ThreadID: "thread-fwd"}, response)
+
+	raw, err := base64.RawURLEncoding.DecodeString(sent.Raw)
+	require.NoError(t, err)
+	assert.Contains(t, string(raw), "To: bob@example.com\r\n")
+	assert.Contains(t, string(raw), "Subject: Fwd: Report\r\n")
+	assert.Contains(t, string(raw), "Forwarded message")
+	assert.Contains(t, string(raw), `filename="report.pdf"`)
+	assert.Contains(t, string(raw), base64.StdEncoding.EncodeToString([]byte("pdf")))
+	mockAttachmentCall.AssertExpectations(t)
+}
+
+func TestForwardMessage_WithoutAttachments(t *testing.T) {
+	ctx := context.Background()
+	mockService, mockMessagesService, sent := setupForwardMocks(ctx)
+
+	_, err := ForwardMessage(ctx, mockService, "msg-123", []core.EmailAddress{{Email: "bob@example.com"}}, nil, nil, nil)
+
+	require.NoError(t, err)
+	raw, err := base64.RawURLEncoding.DecodeString(sent.Raw)
+	require.NoError(t, err)
+	assert.NotContains(t, string(raw), "report.pdf")
+	mockMessagesService.AssertNotCalled(t, "GetAttachment", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestSendMessage_AttachedMessages(t *testing.T) {
+	ctx := context.Background()
+	mockService, mockMessagesService, sent := setupForwardMocks(ctx)
+
+	original := "From: alice@example.com\r\nSubject: =?UTF-8?q?Q3_report_caf=C3=A9?=\r\n\r\nNumbers attached.\r\n"
+	mockRawCall := &gmailtest.MockMessagesGetCall{}
+	mockMessagesService.On("Get", "me", "orig-1").Return(mockRawCall)
+	mockRawCall.On("Format", "raw").Return(mockRawCall)
+	mockRawCall.On("Context", ctx).Return(mockRawCall)
+	mockRawCall.On("Do").Return(&gmailapi.Message{Id: "orig-1", Raw: base64.RawURLEncoding.EncodeToString([]byte(original))}, nil)
+
+	_, err := SendMessage(ctx, mockService, &core.Draft{
+		To:               []core.EmailAddress{{Email: "bob@example.com"}},
+		Subject:          "See the original",
+		Body:             core.EmailBody{Text: "Forwarding as attachment"},
+		AttachedMessages: []string{"orig-1"},
+	}, nil, nil)
+
+	require.NoError(t, err)
+	raw, err := base64.RawURLEncoding.DecodeString(sent.Raw)
+	require.NoError(t, err)
+
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	require.NoError(t, err)
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	require.Equal(t, "multipart/mixed", mediaType)
+
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+	var attached *multipart.Part
+	var attachedBody []byte
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			break
+		}
+		if strings.HasPrefix(part.Header.Get("Content-Type"), "message/rfc822") {
+			attached = part
+			attachedBody, err = io.ReadAll(part)
+			require.NoError(t, err)
+		}
+	}
+	require.NotNil(t, attached, "the original is attached as a message/rfc822 part")
+	assert.Equal(t, "7bit", attached.Header.Get("Content-Transfer-Encoding"))
+	filename, err := new(mime.WordDecoder).DecodeHeader(attached.FileName())
+	require.NoError(t, err)
+	assert.Equal(t, "Q3 report café.eml", filename)
+	assert.Equal(t, original, string(attachedBody))
+	mockRawCall.AssertExpectations(t)
+}
+
+func TestSendMessage_AttachedMessageError(t *testing.T) {
+	ctx := context.Background()
+	mockService, mockMessagesService, _ := setupForwardMocks(ctx)
+
+	mockRawCall := &gmailtest.MockMessagesGetCall{}
+	mockMessagesService.On("Get", "me", "gone").Return(mockRawCall)
+	mockRawCall.On("Format", "raw").Return(mockRawCall)
+	mockRawCall.On("Context", ctx).Return(mockRawCall)
+	mockRawCall.On("Do").Return(nil, errors.New("not found"))
+
+	_, err := SendMessage(ctx, mockService, &core.Draft{
+		To:               []core.EmailAddress{{Email: "bob@example.com"}},
+		Subject:          "See the original",
+		Body:             core.EmailBody{Text: "Forwarding as attachment"},
+		AttachedMessages: []string{"gone"},
+	}, nil, nil)
+
+	assert.ErrorContains(t, err, "failed to get attached message gone")
+	mockMessagesService.AssertNotCalled(t, "Send", mock.Anything, mock.Anything)
+}
+
+func TestForwardMessage_Validation(t *testing.T) {
+	_, err := ForwardMessage(context.Background(), &gmailtest.MockGmailService{}, "", []core.EmailAddress{{Email: "bob@example.com"}}, nil, nil, nil)
+	assert.EqualError(t, err, "message ID is required")
+
+	_, err = ForwardMessage(context.Background(), &gmailtest.MockGmailService{}, "msg-123", nil, nil, nil, nil)
+	assert.EqualError(t, err, "at least one forward recipient is required")
+}
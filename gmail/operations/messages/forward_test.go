@@ -0,0 +1,86 @@
+package messages
+
+import (
+	"testing"
+	"time"
+
+	"github.com/danielrivera/mailbridge-go/core"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestForwardSubject(t *testing.T) {
+	tests := []struct {
+		name     string
+		subject  string
+		original string
+		expected string
+	}{
+		{"adds prefix to original", "", "Budget", "Fwd: Budget"},
+		{"keeps fwd prefix", "", "FWD: Budget", "FWD: Budget"},
+		{"keeps fw prefix", "", "Fw: Budget", "Fw: Budget"},
+		{"draft subject wins", "For you", "Budget", "Fwd: For you"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, forwardSubject(tt.subject, tt.original))
+		})
+	}
+}
+
+func TestBuildForward(t *testing.T) {
+	original := &core.Email{
+		From:     core.EmailAddress{Name: "Alice", Email: "alice@example.com"},
+		To:       []core.EmailAddress{{Email: "me@example.com"}},
+		Subject:  "Plans",
+		SentDate: time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC),
+		Body:     core.EmailBody{Text: "See you at 5"},
+	}
+	to := []core.EmailAddress{{Email: "bob@example.com"}}
+
+	t.Run("text", func(t *testing.T) {
+		forward := buildForward(original, to, &core.Draft{Body: core.EmailBody{Text: "FYI"}})
+
+		assert.Equal(t, to, forward.To)
+		assert.Equal(t, "Fwd: Plans", forward.Subject)
+		assert.Equal(t, "FYI\n\n"+forwardSeparator+"\n"+
+			"From: Alice <alice@example.com>\n"+
+			"Date: Fri, 01 Mar 2024 09:30:00 +0000\n"+
+			"Subject: Plans\n"+
+			"To: me@example.com\n\n"+
+			"See you at 5", forward.Body.Text)
+		assert.Empty(t, forward.Body.HTML)
+	})
+
+	t.Run("html original", func(t *testing.T) {
+		htmlOriginal := *original
+		htmlOriginal.Body = core.EmailBody{Text: "See you at 5", HTML: "<p>See you at <b>5</b></p>"}
+
+		forward := buildForward(&htmlOriginal, to, &core.Draft{Body: core.EmailBody{Text: "FYI <3"}})
+
+		assert.Contains(t, forward.Body.HTML, "<p>FYI &lt;3</p>")
+		assert.Contains(t, forward.Body.HTML, "From: Alice &lt;alice@example.com&gt;<br>")
+		assert.Contains(t, forward.Body.HTML, "<p>See you at <b>5</b></p>")
+		assert.Contains(t, forward.Body.Text, "See you at 5")
+	})
+}
+
+func TestEmlFilename(t *testing.T) {
+	tests := []struct {
+		name     string
+		raw      string
+		expected string
+	}{
+		{"subject", "Subject: Budget 2024\r\n\r\nbody", "Budget 2024.eml"},
+		{"encoded subject", "Subject: =?UTF-8?q?R=C3=A9sum=C3=A9?=\r\n\r\nbody", "Résumé.eml"},
+		{"unsafe characters", "Subject: Re: a/b?\r\n\r\nbody", "Re_ a_b_.eml"},
+		{"no subject", "From: alice@example.com\r\n\r\nbody", "message.eml"},
+		{"not a message", "", "message.eml"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, emlFilename([]byte(tt.raw)))
+		})
+	}
+}
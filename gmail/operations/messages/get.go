@@ -1,32 +1,92 @@
 package messages
 
 import (
+	"bufio"
 	"context"
 	"encoding/base64"
 	"fmt"
 	"github.com/danielrivera/mailbridge-go/gmail/operations"
+	"io"
+	"net/textproto"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/danielrivera/mailbridge-go/core"
 	"github.com/danielrivera/mailbridge-go/gmail/internal"
+	"github.com/danielrivera/mailbridge-go/gmail/operations/labels"
 	"google.golang.org/api/gmail/v1"
 )
 
 // GetMessage retrieves a specific message by ID
 func GetMessage(ctx context.Context, service internal.GmailService, messageID string) (*core.Email, error) {
+	msg, err := getFullMessage(ctx, service, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	return convertMessage(msg), nil
+}
+
+// getFullMessage fetches a message in full format, which includes all headers and the MIME tree
+func getFullMessage(ctx context.Context, service internal.GmailService, messageID string) (*gmail.Message, error) {
 	messagesService := service.GetUsersService().GetMessagesService()
 	call := messagesService.Get(operations.UserIDMe, messageID)
 	msg, err := call.Format("full").Context(ctx).Do()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get message: %w", err)
 	}
+	return msg, nil
+}
 
-	return convertMessage(msg), nil
+// GetMessageWithOptions retrieves a specific message by ID and applies the given options
+func GetMessageWithOptions(ctx context.Context, service internal.GmailService, messageID string, opts *core.GetOptions) (*core.Email, error) {
+	msg, err := getFullMessage(ctx, service, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	email := convertMessage(msg)
+	if opts != nil && opts.BlockRemoteContent {
+		email.Body.HTML, email.BlockedResources = core.BlockRemoteContent(email.Body.HTML)
+	}
+
+	if err := downloadAttachments(ctx, service, email, opts); err != nil {
+		return nil, err
+	}
+
+	if opts != nil && opts.MarkReadOnFetch && !email.IsRead {
+		// Best effort: the fetch already succeeded, so a failed mark is not reported
+		if err := labels.MarkAsRead(ctx, service, messageID); err == nil {
+			email.IsRead = true
+		}
+	}
+
+	return email, nil
+}
+
+// downloadAttachments fills in Data for the attachments named in opts.DownloadAttachments
+func downloadAttachments(ctx context.Context, service internal.GmailService, email *core.Email, opts *core.GetOptions) error {
+	for i, att := range email.Attachments {
+		if !opts.WantsAttachment(att) {
+			continue
+		}
+		data, err := GetAttachment(ctx, service, email.ID, att.ID)
+		if err != nil {
+			return fmt.Errorf("failed to download attachment %s: %w", att.Filename, err)
+		}
+		email.Attachments[i].Data = data
+	}
+	return nil
 }
 
-// GetAttachment downloads an attachment by its ID from a specific message
+// GetAttachment downloads an attachment by its ID from a specific message.
+// Malformed IDs are rejected before calling the API
 func GetAttachment(ctx context.Context, service internal.GmailService, messageID, attachmentID string) ([]byte, error) {
+	if err := validateAttachmentID(attachmentID); err != nil {
+		return nil, fmt.Errorf("failed to get attachment: %w", err)
+	}
+
 	messagesService := service.GetUsersService().GetMessagesService()
 	attachment, err := messagesService.GetAttachment(operations.UserIDMe, messageID, attachmentID).Context(ctx).Do()
 	if err != nil {
@@ -42,6 +102,268 @@ func GetAttachment(ctx context.Context, service internal.GmailService, messageID
 	return data, nil
 }
 
+// GetAttachmentStream returns a reader that decodes an attachment's content as it is read,
+// with its metadata (Data is nil). Gmail returns the content base64url-encoded inside a
+// JSON response; the response body is read and decoded incrementally, so neither the
+// encoded nor the decoded content is ever held in memory as a whole
+func GetAttachmentStream(ctx context.Context, service internal.GmailService, messageID, attachmentID string) (io.ReadCloser, *core.Attachment, error) {
+	if err := validateAttachmentID(attachmentID); err != nil {
+		return nil, nil, fmt.Errorf("failed to get attachment: %w", err)
+	}
+
+	attachment, err := GetAttachmentMetadata(ctx, service, messageID, attachmentID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	messagesService := service.GetUsersService().GetMessagesService()
+	body, err := messagesService.GetAttachmentContent(ctx, operations.UserIDMe, messageID, attachmentID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get attachment: %w", err)
+	}
+
+	data, err := newJSONDataReader(body)
+	if err != nil {
+		body.Close()
+		return nil, nil, fmt.Errorf("failed to read attachment data: %w", err)
+	}
+	// Gmail pads inconsistently; the data reader drops the padding, so decode without it
+	decoder := base64.NewDecoder(base64.RawURLEncoding, data)
+	return struct {
+		io.Reader
+		io.Closer
+	}{decoder, body}, attachment, nil
+}
+
+// jsonDataReader reads the value of the "data" string field from a JSON object as it
+// arrives, dropping base64 padding. It relies on the value being base64url, which
+// needs no JSON escaping
+type jsonDataReader struct {
+	r    *bufio.Reader
+	done bool
+}
+
+// newJSONDataReader positions a reader at the start of the "data" value of the JSON
+// object read from r
+func newJSONDataReader(r io.Reader) (*jsonDataReader, error) {
+	br := bufio.NewReader(r)
+	const key = `"data"`
+	for matched := 0; matched < len(key); {
+		b, err := br.ReadByte()
+		if err != nil {
+			return nil, fmt.Errorf("no data field in response: %w", err)
+		}
+		switch {
+		case b == key[matched]:
+			matched++
+		case b == key[0]:
+			matched = 1
+		default:
+			matched = 0
+		}
+	}
+	for _, want := range []byte{':', '"'} {
+		b, err := skipJSONSpace(br)
+		if err != nil {
+			return nil, fmt.Errorf("malformed data field: %w", err)
+		}
+		if b != want {
+			return nil, fmt.Errorf("malformed data field: unexpected %q", b)
+		}
+	}
+	return &jsonDataReader{r: br}, nil
+}
+
+// skipJSONSpace returns the next byte of br that is not JSON whitespace
+func skipJSONSpace(br *bufio.Reader) (byte, error) {
+	for {
+		b, err := br.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		if b != ' ' && b != '\t' && b != '\n' && b != '\r' {
+			return b, nil
+		}
+	}
+}
+
+// Read copies the string value into p up to its closing quote
+func (d *jsonDataReader) Read(p []byte) (int, error) {
+	if d.done {
+		return 0, io.EOF
+	}
+	n := 0
+	for n < len(p) {
+		b, err := d.r.ReadByte()
+		if err == io.EOF {
+			return n, io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return n, err
+		}
+		switch b {
+		case '"':
+			d.done = true
+			if n == 0 {
+				return 0, io.EOF
+			}
+			return n, nil
+		case '=':
+			continue
+		case '\\':
+			return n, fmt.Errorf("unexpected escape in attachment data")
+		}
+		p[n] = b
+		n++
+	}
+	return n, nil
+}
+
+// validateAttachmentID checks that an attachment ID is a base64url token, as Gmail
+// issues them, so a malformed ID fails with a clear error instead of an opaque 400
+func validateAttachmentID(attachmentID string) error {
+	if attachmentID == "" {
+		return fmt.Errorf("invalid attachment ID: empty")
+	}
+	for i, r := range strings.TrimRight(attachmentID, "=") {
+		if !isBase64URLChar(r) {
+			return fmt.Errorf("invalid attachment ID %q: character %q at offset %d is not base64url", attachmentID, r, i)
+		}
+	}
+	return nil
+}
+
+// isBase64URLChar reports whether r belongs to the base64url alphabet (RFC 4648 section 5)
+func isBase64URLChar(r rune) bool {
+	return r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' || r == '_'
+}
+
+// GetAttachmentMetadata returns an attachment's filename, MIME type and size without
+// downloading its content. Gmail has no per-attachment metadata endpoint, so the message
+// structure is fetched in metadata format, without the body content, and the attachment
+// is looked up in it; Data is always empty
+func GetAttachmentMetadata(ctx context.Context, service internal.GmailService, messageID, attachmentID string) (*core.Attachment, error) {
+	messagesService := service.GetUsersService().GetMessagesService()
+	msg, err := messagesService.Get(operations.UserIDMe, messageID).Format("metadata").Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get message: %w", err)
+	}
+
+	for _, attachment := range extractAttachments(msg.Payload) {
+		if attachment.ID == attachmentID {
+			attachment.Data = nil
+			return &attachment, nil
+		}
+	}
+	return nil, fmt.Errorf("attachment %s not found in message %s", attachmentID, messageID)
+}
+
+// GetMessageInlineHTML returns the HTML body of a message with its cid: references
+// rewritten to data: URLs, downloading the referenced inline attachments
+func GetMessageInlineHTML(ctx context.Context, service internal.GmailService, messageID string) (string, error) {
+	msg, err := getFullMessage(ctx, service, messageID)
+	if err != nil {
+		return "", err
+	}
+
+	email := convertMessage(msg)
+	if !core.HasCIDReferences(email.Body.HTML) {
+		return email.Body.HTML, nil
+	}
+
+	for i, att := range email.Attachments {
+		if att.ContentID == "" || att.Data != nil || att.ID == "" {
+			continue
+		}
+		data, err := GetAttachment(ctx, service, messageID, att.ID)
+		if err != nil {
+			return "", fmt.Errorf("failed to download inline attachment %s: %w", att.ContentID, err)
+		}
+		email.Attachments[i].Data = data
+	}
+
+	return core.EmbedInlineAttachments(email.Body.HTML, email.Attachments), nil
+}
+
+// GetAttachmentByIndex downloads the attachment at the given zero-based index,
+// in the order the attachments appear in the message
+func GetAttachmentByIndex(ctx context.Context, service internal.GmailService, messageID string, index int) (*core.Attachment, error) {
+	email, err := GetMessage(ctx, service, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	if index < 0 || index >= len(email.Attachments) {
+		return nil, fmt.Errorf("attachment index %d out of range: message %s has %d attachments", index, messageID, len(email.Attachments))
+	}
+
+	attachment := email.Attachments[index]
+	data, err := GetAttachment(ctx, service, messageID, attachment.ID)
+	if err != nil {
+		return nil, err
+	}
+	attachment.Data = data
+
+	return &attachment, nil
+}
+
+// GetMessageSize returns Gmail's size estimate of a message in bytes. Only the
+// sizeEstimate field is requested, so the body and attachments are not downloaded
+func GetMessageSize(ctx context.Context, service internal.GmailService, messageID string) (int64, error) {
+	messagesService := service.GetUsersService().GetMessagesService()
+	msg, err := messagesService.Get(operations.UserIDMe, messageID).Format("metadata").Fields("sizeEstimate").Context(ctx).Do()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get message size: %w", err)
+	}
+	return msg.SizeEstimate, nil
+}
+
+// GetMessagePart downloads the body of a single MIME part, identified by its part ID
+// (e.g. "0" or "1.2"). Large parts stored as attachments are fetched separately, so
+// callers can read one part (such as the HTML body) without downloading the rest.
+func GetMessagePart(ctx context.Context, service internal.GmailService, messageID, partID string) ([]byte, error) {
+	messagesService := service.GetUsersService().GetMessagesService()
+	msg, err := messagesService.Get(operations.UserIDMe, messageID).Format("full").Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get message: %w", err)
+	}
+
+	part := findPart(msg.Payload, partID)
+	if part == nil {
+		return nil, fmt.Errorf("part %s not found in message %s", partID, messageID)
+	}
+	if part.Body == nil {
+		return nil, nil
+	}
+
+	if part.Body.Data == "" && part.Body.AttachmentId != "" {
+		return GetAttachment(ctx, service, messageID, part.Body.AttachmentId)
+	}
+
+	data, err := decodeBase64Data(part.Body.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode part data: %w", err)
+	}
+
+	return data, nil
+}
+
+// findPart searches the MIME tree for the part with the given part ID
+func findPart(part *gmail.MessagePart, partID string) *gmail.MessagePart {
+	if part == nil {
+		return nil
+	}
+	if part.PartId == partID {
+		return part
+	}
+	for _, child := range part.Parts {
+		if found := findPart(child, partID); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
 // convertMessage converts a Gmail message to normalized Email type
 func convertMessage(msg *gmail.Message) *core.Email {
 	email := &core.Email{
@@ -51,41 +373,131 @@ func convertMessage(msg *gmail.Message) *core.Email {
 		Labels:   msg.LabelIds,
 	}
 
+	// Flags are derived from labels, so they are set even without a payload (format=minimal)
+	applyLabelFlags(email, msg.LabelIds)
+	email.ProviderMeta = imapIDs(msg)
+
+	// internalDate (ms since epoch) is when Gmail received the message; it is set in every format
+	if msg.InternalDate > 0 {
+		email.ReceivedDate = time.UnixMilli(msg.InternalDate).UTC()
+		email.Date = email.ReceivedDate
+	}
+	if msg.Payload == nil {
+		return email
+	}
+
 	// Parse headers
 	headers := make(map[string]string)
 	for _, header := range msg.Payload.Headers {
 		headers[strings.ToLower(header.Name)] = header.Value
 	}
+	email.RawHeaders = extractRawHeaders(msg.Payload.Headers)
 
 	// Extract basic fields
 	email.Subject = headers["subject"]
+	email.MessageID = strings.TrimSpace(headers["message-id"])
 	email.From = parseEmailAddress(headers["from"])
 	email.To = parseEmailAddresses(headers["to"])
 	email.Cc = parseEmailAddresses(headers["cc"])
 	email.Bcc = parseEmailAddresses(headers["bcc"])
 	email.ReplyTo = parseEmailAddresses(headers["reply-to"])
 
-	// Parse date
+	// Parse date: the Date header is the sent time; Date keeps the received time when known
 	if dateStr := headers["date"]; dateStr != "" {
 		if date, err := parseEmailDate(dateStr); err == nil {
-			email.Date = date
+			email.SentDate = date
+			if email.Date.IsZero() {
+				email.Date = date
+			}
 		}
 	}
 
+	// Signed or encrypted messages: the secure parts are kept as attachments, not decoded as body
+	email.Signed, email.Encrypted = detectSecureMIME(msg.Payload)
+
 	// Extract body
 	email.Body = extractBody(msg.Payload)
 
-	// Check flags
-	email.IsRead = !contains(msg.LabelIds, "UNREAD")
-	email.IsStarred = contains(msg.LabelIds, "STARRED")
-	email.IsDraft = contains(msg.LabelIds, "DRAFT")
-
 	// Extract attachments info (without data)
 	email.Attachments = extractAttachments(msg.Payload)
 
 	return email
 }
 
+// Keys of the IMAP identifiers in core.Email.ProviderMeta
+const (
+	metaGmailMessageID = "X-GM-MSGID"
+	metaGmailThreadID  = "X-GM-THRID"
+)
+
+// imapIDs returns the 64-bit Gmail IDs IMAP uses (X-GM-MSGID and X-GM-THRID), in decimal.
+// X-GM-* headers on the message take precedence; otherwise the IDs are derived from the
+// API's message and thread IDs, which are the same numbers in hexadecimal
+func imapIDs(msg *gmail.Message) map[string]string {
+	meta := make(map[string]string, 2)
+	if msg.Payload != nil {
+		for _, header := range msg.Payload.Headers {
+			name := strings.ToUpper(strings.TrimSpace(header.Name))
+			if name == metaGmailMessageID || name == metaGmailThreadID {
+				if _, exists := meta[name]; !exists {
+					meta[name] = strings.TrimSpace(header.Value)
+				}
+			}
+		}
+	}
+	for name, hexID := range map[string]string{metaGmailMessageID: msg.Id, metaGmailThreadID: msg.ThreadId} {
+		if _, exists := meta[name]; exists || hexID == "" {
+			continue
+		}
+		if id, err := strconv.ParseUint(hexID, 16, 64); err == nil {
+			meta[name] = strconv.FormatUint(id, 10)
+		}
+	}
+	if len(meta) == 0 {
+		return nil
+	}
+	return meta
+}
+
+// applyLabelFlags derives IsRead, IsStarred and IsDraft from the message's system labels
+func applyLabelFlags(email *core.Email, labelIDs []string) {
+	email.IsRead = !contains(labelIDs, "UNREAD")
+	email.IsStarred = contains(labelIDs, "STARRED")
+	email.IsDraft = contains(labelIDs, "DRAFT")
+}
+
+// extractHeaders returns the message headers keyed by canonical name.
+// Only the first occurrence of a repeated header (e.g. Received) is kept.
+func extractHeaders(parts []*gmail.MessagePartHeader) map[string]string {
+	if len(parts) == 0 {
+		return nil
+	}
+
+	headers := make(map[string]string, len(parts))
+	for _, header := range parts {
+		key := textproto.CanonicalMIMEHeaderKey(header.Name)
+		if _, exists := headers[key]; !exists {
+			headers[key] = header.Value
+		}
+	}
+	return headers
+}
+
+// extractRawHeaders returns all message headers keyed by canonical name, keeping
+// every occurrence of repeated headers in message order
+func extractRawHeaders(parts []*gmail.MessagePartHeader) map[string][]string {
+	if len(parts) == 0 {
+		return nil
+	}
+
+	headers := make(map[string][]string, len(parts))
+	for _, header := range parts {
+		key := textproto.CanonicalMIMEHeaderKey(header.Name)
+		headers[key] = append(headers[key], header.Value)
+	}
+	return headers
+}
+
 // extractBody extracts text and HTML body from message payload
 func extractBody(payload *gmail.MessagePart) core.EmailBody {
 	body := core.EmailBody{}
@@ -96,11 +508,13 @@ func extractBody(payload *gmail.MessagePart) core.EmailBody {
 			return
 		}
 
-		// Check current part
-		if part.MimeType == "text/plain" && body.Text == "" {
-			body.Text = decodeBody(part.Body.Data)
-		} else if part.MimeType == "text/html" && body.HTML == "" {
-			body.HTML = decodeBody(part.Body.Data)
+		// Check current part (multipart containers may have no body)
+		if part.Body != nil {
+			if part.MimeType == "text/plain" && body.Text == "" {
+				body.Text = decodeBody(part.Body.Data)
+			} else if part.MimeType == "text/html" && body.HTML == "" {
+				body.HTML = decodeBody(part.Body.Data)
+			}
 		}
 
 		// Recursively check parts
@@ -123,14 +537,41 @@ func extractAttachments(payload *gmail.MessagePart) []core.Attachment {
 			return
 		}
 
+		contentID := core.NormalizeContentID(extractHeaders(part.Headers)["Content-Id"])
+
 		// Check if this part is an attachment
 		if part.Filename != "" && part.Body != nil {
 			attachments = append(attachments, core.Attachment{
+				ID:        part.Body.AttachmentId,
+				Filename:  part.Filename,
+				MimeType:  part.MimeType,
+				Size:      part.Body.Size,
+				ContentID: contentID,
+			})
+		} else if contentID != "" && part.Body != nil && isInlineResourceType(part.MimeType) {
+			// Unnamed inline resources (e.g. images referenced as cid: from the HTML body)
+			attachment := core.Attachment{
+				ID:        part.Body.AttachmentId,
+				MimeType:  part.MimeType,
+				Size:      part.Body.Size,
+				ContentID: contentID,
+			}
+			if part.Body.AttachmentId == "" {
+				attachment.Data, _ = decodeBase64Data(part.Body.Data)
+			}
+			attachments = append(attachments, attachment)
+		} else if name := unnamedPartName(part.MimeType); name != "" && part.Body != nil {
+			// Unnamed S/MIME, PGP and calendar parts are kept raw; small ones arrive inline without an attachment ID
+			attachment := core.Attachment{
 				ID:       part.Body.AttachmentId,
-				Filename: part.Filename,
+				Filename: name,
 				MimeType: part.MimeType,
 				Size:     part.Body.Size,
-			})
+			}
+			if part.Body.AttachmentId == "" {
+				attachment.Data, _ = decodeBase64Data(part.Body.Data)
+			}
+			attachments = append(attachments, attachment)
 		}
 
 		// Recursively check parts
@@ -143,6 +584,63 @@ func extractAttachments(payload *gmail.MessagePart) []core.Attachment {
 	return attachments
 }
 
+// isInlineResourceType reports whether a part with a Content-ID is a resource rather
+// than one of the message's text bodies or a multipart container
+func isInlineResourceType(mimeType string) bool {
+	mimeType = strings.ToLower(mimeType)
+	return !strings.HasPrefix(mimeType, "text/") && !strings.HasPrefix(mimeType, "multipart/")
+}
+
+// detectSecureMIME reports whether any part of the payload is signed or encrypted,
+// judging by the part's Content-Type header (which carries smime-type) or MIME type
+func detectSecureMIME(part *gmail.MessagePart) (signed, encrypted bool) {
+	if part == nil {
+		return false, false
+	}
+
+	contentType := part.MimeType
+	for _, header := range part.Headers {
+		if strings.EqualFold(header.Name, "Content-Type") {
+			contentType = header.Value
+			break
+		}
+	}
+	signed, encrypted = core.SecureMIMEType(contentType)
+
+	for _, p := range part.Parts {
+		s, e := detectSecureMIME(p)
+		signed = signed || s
+		encrypted = encrypted || e
+	}
+	return signed, encrypted
+}
+
+// unnamedPartName returns the filename under which an unnamed part is kept as an
+// attachment: S/MIME and PGP parts, and the text/calendar part of an invitation's
+// body. It returns "" for other MIME types
+func unnamedPartName(mimeType string) string {
+	if strings.EqualFold(mimeType, "text/calendar") {
+		return "invite.ics"
+	}
+	return secureMIMEPartName(mimeType)
+}
+
+// secureMIMEPartName returns the conventional filename for an S/MIME or PGP part,
+// or "" if the MIME type is not one
+func secureMIMEPartName(mimeType string) string {
+	switch strings.ToLower(mimeType) {
+	case "application/pkcs7-mime", "application/x-pkcs7-mime":
+		return "smime.p7m"
+	case "application/pkcs7-signature", "application/x-pkcs7-signature":
+		return "smime.p7s"
+	case "application/pgp-signature":
+		return "signature.asc"
+	case "application/pgp-encrypted":
+		return "pgp-encrypted.asc"
+	}
+	return ""
+}
+
 // decodeBase64Data attempts to decode base64-encoded data using multiple strategies
 // It tries RawURLEncoding (Gmail default), URLEncoding, and StdEncoding in order
 func decodeBase64Data(data string) ([]byte, error) {
@@ -1,14 +1,23 @@
 package messages
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
 	"errors"
+	"io"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/danielrivera/mailbridge-go/core"
+	"github.com/danielrivera/mailbridge-go/gmail/internal"
 	gmailtest "github.com/danielrivera/mailbridge-go/gmail/testing"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/api/gmail/v1"
+	"google.golang.org/api/googleapi"
 )
 
 func TestGetMessage_Success(t *testing.T) {
@@ -47,6 +56,37 @@ func TestGetMessage_Success(t *testing.T) {
 	assert.False(t, email.IsRead)
 }
 
+func TestGetMessage_HeaderDateDiffersFromInternalDate(t *testing.T) {
+	// The Date header claims a send time a year in the future; internalDate is when Gmail received it
+	claimed := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	received := time.Date(2029, 1, 15, 9, 12, 30, 0, time.UTC)
+
+	mockGmailService, mockMessagesService := setupMockMessagesService()
+	mockMessagesGetCall := &gmailtest.MockMessagesGetCall{}
+
+	mockMessagesService.On("Get", "me", "msg-123").Return(mockMessagesGetCall)
+	mockMessagesGetCall.On("Format", "full").Return(mockMessagesGetCall)
+	mockMessagesGetCall.On("Context", context.Background()).Return(mockMessagesGetCall)
+	mockMessagesGetCall.On("Do").Return(&gmail.Message{
+		Id:           "msg-123",
+		InternalDate: received.UnixMilli(),
+		Payload: &gmail.MessagePart{
+			Headers: []*gmail.MessagePartHeader{
+				{Name: "Date", Value: claimed.Format(time.RFC1123Z)},
+			},
+			MimeType: "text/plain",
+			Body:     &gmail.MessagePartBody{Data: "SGVsbG8="},
+		},
+	}, nil)
+
+	email, err := GetMessage(context.Background(), mockGmailService, "msg-123")
+
+	require.NoError(t, err)
+	assert.True(t, claimed.Equal(email.SentDate), "SentDate = %v", email.SentDate)
+	assert.True(t, received.Equal(email.ReceivedDate), "ReceivedDate = %v", email.ReceivedDate)
+	assert.True(t, received.Equal(email.Date), "Date follows the receipt time, not the header")
+}
+
 func TestGetMessage_APIError(t *testing.T) {
 	mockGmailService, mockMessagesService := setupMockMessagesService()
 	mockMessagesGetCall := &gmailtest.MockMessagesGetCall{}
@@ -60,3 +100,654 @@ func TestGetMessage_APIError(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to get message")
 }
+
+func TestGetMessageWithOptions_MarkReadOnFetch(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       *core.GetOptions
+		markErr    error
+		expectMark bool
+		expectRead bool
+	}{
+		{
+			name:       "option set",
+			opts:       &core.GetOptions{MarkReadOnFetch: true},
+			expectMark: true,
+			expectRead: true,
+		},
+		{
+			name:       "option set but mark fails",
+			opts:       &core.GetOptions{MarkReadOnFetch: true},
+			markErr:    errors.New("modify failed"),
+			expectMark: true,
+			expectRead: false,
+		},
+		{
+			name:       "option not set",
+			opts:       &core.GetOptions{},
+			expectMark: false,
+			expectRead: false,
+		},
+		{
+			name:       "nil options",
+			opts:       nil,
+			expectMark: false,
+			expectRead: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockGmailService, mockMessagesService := setupMockMessagesService()
+			mockMessagesGetCall := &gmailtest.MockMessagesGetCall{}
+			mockMessagesModifyCall := &gmailtest.MockMessagesModifyCall{}
+
+			mockMessagesService.On("Get", "me", "msg-123").Return(mockMessagesGetCall)
+			mockMessagesGetCall.On("Format", "full").Return(mockMessagesGetCall)
+			mockMessagesGetCall.On("Context", context.Background()).Return(mockMessagesGetCall)
+			mockMessagesGetCall.On("Do").Return(&gmail.Message{
+				Id:       "msg-123",
+				LabelIds: []string{"INBOX", "UNREAD"},
+				Payload:  &gmail.MessagePart{},
+			}, nil)
+
+			mockMessagesService.On("Modify", "me", "msg-123", &gmail.ModifyMessageRequest{
+				RemoveLabelIds: []string{"UNREAD"},
+			}).Return(mockMessagesModifyCall)
+			mockMessagesModifyCall.On("Context", context.Background()).Return(mockMessagesModifyCall)
+			mockMessagesModifyCall.On("Do").Return(&gmail.Message{}, tt.markErr)
+
+			email, err := GetMessageWithOptions(context.Background(), mockGmailService, "msg-123", tt.opts)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectRead, email.IsRead)
+			if tt.expectMark {
+				mockMessagesService.AssertCalled(t, "Modify", "me", "msg-123", mock.Anything)
+			} else {
+				mockMessagesService.AssertNotCalled(t, "Modify", "me", "msg-123", mock.Anything)
+			}
+		})
+	}
+}
+
+func TestGetMessageWithOptions_NoMarkOnFetchError(t *testing.T) {
+	mockGmailService, mockMessagesService := setupMockMessagesService()
+	mockMessagesGetCall := &gmailtest.MockMessagesGetCall{}
+
+	mockMessagesService.On("Get", "me", "msg-123").Return(mockMessagesGetCall)
+	mockMessagesGetCall.On("Format", "full").Return(mockMessagesGetCall)
+	mockMessagesGetCall.On("Context", context.Background()).Return(mockMessagesGetCall)
+	mockMessagesGetCall.On("Do").Return(nil, errors.New("message not found"))
+
+	_, err := GetMessageWithOptions(context.Background(), mockGmailService, "msg-123", &core.GetOptions{MarkReadOnFetch: true})
+
+	assert.Error(t, err)
+	mockMessagesService.AssertNotCalled(t, "Modify", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestGetMessagePart(t *testing.T) {
+	payload := &gmail.MessagePart{
+		PartId:   "",
+		MimeType: "multipart/mixed",
+		Parts: []*gmail.MessagePart{
+			{
+				PartId:   "0",
+				MimeType: "multipart/alternative",
+				Parts: []*gmail.MessagePart{
+					{PartId: "0.0", MimeType: "text/plain", Body: &gmail.MessagePartBody{Data: "SGVsbG8"}},         // "Hello"
+					{PartId: "0.1", MimeType: "text/html", Body: &gmail.MessagePartBody{Data: "PHA-SGVsbG88L3A-"}}, // "<p>Hello</p>"
+				},
+			},
+			{
+				PartId:   "1",
+				MimeType: "application/pdf",
+				Filename: "report.pdf",
+				Body:     &gmail.MessagePartBody{AttachmentId: "att-1", Size: 1024},
+			},
+		},
+	}
+
+	t.Run("inline part is decoded", func(t *testing.T) {
+		mockGmailService, mockMessagesService := setupMockMessagesService()
+		mockGetCall := &gmailtest.MockMessagesGetCall{}
+
+		mockMessagesService.On("Get", "me", "msg-123").Return(mockGetCall)
+		mockGetCall.On("Format", "full").Return(mockGetCall)
+		mockGetCall.On("Context", context.Background()).Return(mockGetCall)
+		mockGetCall.On("Do").Return(&gmail.Message{Id: "msg-123", Payload: payload}, nil)
+
+		data, err := GetMessagePart(context.Background(), mockGmailService, "msg-123", "0.1")
+
+		require.NoError(t, err)
+		assert.Equal(t, "<p>Hello</p>", string(data))
+		mockMessagesService.AssertNotCalled(t, "GetAttachment", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("large part is fetched by attachment ID", func(t *testing.T) {
+		mockGmailService, mockMessagesService := setupMockMessagesService()
+		mockGetCall := &gmailtest.MockMessagesGetCall{}
+		mockAttachmentCall := &gmailtest.MockMessagesAttachmentGetCall{}
+
+		mockMessagesService.On("Get", "me", "msg-123").Return(mockGetCall)
+		mockGetCall.On("Format", "full").Return(mockGetCall)
+		mockGetCall.On("Context", context.Background()).Return(mockGetCall)
+		mockGetCall.On("Do").Return(&gmail.Message{Id: "msg-123", Payload: payload}, nil)
+		mockMessagesService.On("GetAttachment", "me", "msg-123", "att-1").Return(mockAttachmentCall)
+		mockAttachmentCall.On("Context", context.Background()).Return(mockAttachmentCall)
+		mockAttachmentCall.On("Do").Return(&gmail.MessagePartBody{Data: "JVBERg"}, nil) // "%PDF"
+
+		data, err := GetMessagePart(context.Background(), mockGmailService, "msg-123", "1")
+
+		require.NoError(t, err)
+		assert.Equal(t, "%PDF", string(data))
+		mockMessagesService.AssertExpectations(t)
+	})
+
+	t.Run("unknown part", func(t *testing.T) {
+		mockGmailService, mockMessagesService := setupMockMessagesService()
+		mockGetCall := &gmailtest.MockMessagesGetCall{}
+
+		mockMessagesService.On("Get", "me", "msg-123").Return(mockGetCall)
+		mockGetCall.On("Format", "full").Return(mockGetCall)
+		mockGetCall.On("Context", context.Background()).Return(mockGetCall)
+		mockGetCall.On("Do").Return(&gmail.Message{Id: "msg-123", Payload: payload}, nil)
+
+		data, err := GetMessagePart(context.Background(), mockGmailService, "msg-123", "7")
+
+		assert.Nil(t, data)
+		assert.ErrorContains(t, err, "part 7 not found")
+	})
+}
+
+func TestGetMessageWithOptions_DownloadAttachments(t *testing.T) {
+	mockGmailService, mockMessagesService := setupMockMessagesService()
+	mockGetCall := &gmailtest.MockMessagesGetCall{}
+	mockMessagesService.On("Get", "me", "msg-123").Return(mockGetCall)
+	mockGetCall.On("Format", "full").Return(mockGetCall)
+	mockGetCall.On("Context", context.Background()).Return(mockGetCall)
+	mockGetCall.On("Do").Return(&gmail.Message{
+		Id: "msg-123",
+		Payload: &gmail.MessagePart{
+			MimeType: "multipart/mixed",
+			Parts: []*gmail.MessagePart{
+				{PartId: "1", MimeType: "text/calendar", Filename: "invite.ics", Body: &gmail.MessagePartBody{AttachmentId: "att-1", Size: 5}},
+				{PartId: "2", MimeType: "application/pdf", Filename: "report.pdf", Body: &gmail.MessagePartBody{AttachmentId: "att-2", Size: 4}},
+			},
+		},
+	}, nil)
+
+	mockAttachmentCall := &gmailtest.MockMessagesAttachmentGetCall{}
+	mockMessagesService.On("GetAttachment", "me", "msg-123", "att-1").Return(mockAttachmentCall)
+	mockAttachmentCall.On("Context", context.Background()).Return(mockAttachmentCall)
+	mockAttachmentCall.On("Do").Return(&gmail.MessagePartBody{Data: "QkVHSU4"}, nil) // "BEGIN"
+
+	opts := &core.GetOptions{DownloadAttachments: []string{"invite.ics"}}
+	email, err := GetMessageWithOptions(context.Background(), mockGmailService, "msg-123", opts)
+
+	require.NoError(t, err)
+	require.Len(t, email.Attachments, 2)
+	assert.Equal(t, []byte("BEGIN"), email.Attachments[0].Data)
+	assert.Nil(t, email.Attachments[1].Data)
+	mockMessagesService.AssertNotCalled(t, "GetAttachment", "me", "msg-123", "att-2")
+}
+
+func TestGetMessageWithOptions_IncludeHeaders(t *testing.T) {
+	newMessage := func() *gmail.Message {
+		return &gmail.Message{
+			Id: "msg-123",
+			Payload: &gmail.MessagePart{
+				MimeType: "text/plain",
+				Headers: []*gmail.MessagePartHeader{
+					{Name: "Received", Value: "from mx2.example.com"},
+					{Name: "Received", Value: "from mx1.example.com"},
+					{Name: "subject", Value: "Hello"},
+				},
+				Body: &gmail.MessagePartBody{Data: "SGk="},
+			},
+		}
+	}
+
+	// The full-format fetch returns every header, so the option changes nothing
+	tests := []struct {
+		name string
+		opts *core.GetOptions
+	}{
+		{name: "requested", opts: &core.GetOptions{IncludeHeaders: true}},
+		{name: "not requested", opts: &core.GetOptions{}},
+		{name: "nil options", opts: nil},
+	}
+	expected := map[string][]string{
+		"Received": {"from mx2.example.com", "from mx1.example.com"},
+		"Subject":  {"Hello"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockGmailService, mockMessagesService := setupMockMessagesService()
+			mockGetCall := &gmailtest.MockMessagesGetCall{}
+			mockMessagesService.On("Get", "me", "msg-123").Return(mockGetCall)
+			mockGetCall.On("Format", "full").Return(mockGetCall)
+			mockGetCall.On("Context", context.Background()).Return(mockGetCall)
+			mockGetCall.On("Do").Return(newMessage(), nil)
+
+			email, err := GetMessageWithOptions(context.Background(), mockGmailService, "msg-123", tt.opts)
+
+			require.NoError(t, err)
+			assert.Equal(t, expected, email.RawHeaders)
+			assert.Equal(t, "from mx2.example.com", email.Header("received"))
+			assert.Equal(t, "Hello", email.Subject)
+			mockMessagesService.AssertNumberOfCalls(t, "Get", 1)
+		})
+	}
+}
+
+func TestGetAttachment_InvalidID(t *testing.T) {
+	tests := []struct {
+		name         string
+		attachmentID string
+		expected     string
+	}{
+		{"empty", "", "invalid attachment ID: empty"},
+		{"not base64url", "att/1+2", `invalid attachment ID "att/1+2": character '/' at offset 3 is not base64url`},
+		{"whitespace", "ANGjdJ8 xyz", `character ' ' at offset 7 is not base64url`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockGmailService, mockMessagesService := setupMockMessagesService()
+
+			data, err := GetAttachment(context.Background(), mockGmailService, "msg-123", tt.attachmentID)
+
+			assert.Nil(t, data)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "failed to get attachment: ")
+			assert.Contains(t, err.Error(), tt.expected)
+			mockMessagesService.AssertNotCalled(t, "GetAttachment", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestGetAttachmentMetadata(t *testing.T) {
+	mockGmailService, mockMessagesService := setupMockMessagesService()
+	mockGetCall := &gmailtest.MockMessagesGetCall{}
+	mockMessagesService.On("Get", "me", "msg-123").Return(mockGetCall)
+	mockGetCall.On("Format", "metadata").Return(mockGetCall)
+	mockGetCall.On("Context", context.Background()).Return(mockGetCall)
+	mockGetCall.On("Do").Return(&gmail.Message{
+		Id: "msg-123",
+		Payload: &gmail.MessagePart{
+			MimeType: "multipart/mixed",
+			Parts: []*gmail.MessagePart{
+				{PartId: "0", MimeType: "text/plain", Body: &gmail.MessagePartBody{Data: "SGk="}},
+				{PartId: "1", MimeType: "application/pdf", Filename: "report.pdf", Body: &gmail.MessagePartBody{AttachmentId: "att-1", Size: 482133}},
+			},
+		},
+	}, nil)
+
+	attachment, err := GetAttachmentMetadata(context.Background(), mockGmailService, "msg-123", "att-1")
+
+	require.NoError(t, err)
+	assert.Equal(t, "att-1", attachment.ID)
+	assert.Equal(t, "report.pdf", attachment.Filename)
+	assert.Equal(t, "application/pdf", attachment.MimeType)
+	assert.Equal(t, int64(482133), attachment.Size)
+	assert.Empty(t, attachment.Data)
+	mockMessagesService.AssertNotCalled(t, "GetAttachment", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestGetAttachmentMetadata_NotFound(t *testing.T) {
+	mockGmailService, mockMessagesService := setupMockMessagesService()
+	mockGetCall := &gmailtest.MockMessagesGetCall{}
+	mockMessagesService.On("Get", "me", "msg-123").Return(mockGetCall)
+	mockGetCall.On("Format", "metadata").Return(mockGetCall)
+	mockGetCall.On("Context", context.Background()).Return(mockGetCall)
+	mockGetCall.On("Do").Return(&gmail.Message{Id: "msg-123", Payload: &gmail.MessagePart{MimeType: "text/plain"}}, nil)
+
+	attachment, err := GetAttachmentMetadata(context.Background(), mockGmailService, "msg-123", "att-missing")
+
+	assert.Nil(t, attachment)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "attachment att-missing not found in message msg-123")
+}
+
+// chunkRecorder is an io.Writer that records the largest single write it receives
+type chunkRecorder struct {
+	total, largest int
+}
+
+func (w *chunkRecorder) Write(p []byte) (int, error) {
+	w.total += len(p)
+	w.largest = max(w.largest, len(p))
+	return len(p), nil
+}
+
+func TestGetAttachmentStream(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789abcdef"), 64<<10) // 1 MiB
+
+	tests := []struct {
+		name    string
+		encoded string
+	}{
+		{"unpadded", base64.RawURLEncoding.EncodeToString(content[:len(content)-1])},
+		{"padded", base64.URLEncoding.EncodeToString(content[:len(content)-1])},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockGmailService, mockMessagesService := setupMockMessagesService()
+			mockGetCall := &gmailtest.MockMessagesGetCall{}
+			mockMessagesService.On("Get", "me", "msg-123").Return(mockGetCall)
+			mockGetCall.On("Format", "metadata").Return(mockGetCall)
+			mockGetCall.On("Context", context.Background()).Return(mockGetCall)
+			mockGetCall.On("Do").Return(&gmail.Message{
+				Id: "msg-123",
+				Payload: &gmail.MessagePart{
+					MimeType: "multipart/mixed",
+					Parts: []*gmail.MessagePart{
+						{PartId: "1", MimeType: "application/zip", Filename: "backup.zip", Body: &gmail.MessagePartBody{AttachmentId: "att-1", Size: int64(len(content) - 1)}},
+					},
+				},
+			}, nil)
+			body := io.NopCloser(strings.NewReader(`{"data": "` + tt.encoded + `"}`))
+			mockMessagesService.On("GetAttachmentContent", context.Background(), "me", "msg-123", "att-1").Return(body, nil)
+
+			reader, attachment, err := GetAttachmentStream(context.Background(), mockGmailService, "msg-123", "att-1")
+
+			require.NoError(t, err)
+			assert.Equal(t, "backup.zip", attachment.Filename)
+			assert.Equal(t, "application/zip", attachment.MimeType)
+			assert.Equal(t, int64(len(content)-1), attachment.Size)
+			assert.Nil(t, attachment.Data)
+
+			// The decoded content must arrive in chunks rather than as one materialized slice
+			var recorder chunkRecorder
+			_, err = io.Copy(&recorder, reader)
+			require.NoError(t, err)
+			assert.Equal(t, len(content)-1, recorder.total)
+			assert.Less(t, recorder.largest, len(content)/2)
+			assert.NoError(t, reader.Close())
+		})
+	}
+}
+
+func TestGetAttachmentStream_Content(t *testing.T) {
+	mockGmailService, mockMessagesService := setupMockMessagesService()
+	mockGetCall := &gmailtest.MockMessagesGetCall{}
+	mockMessagesService.On("Get", "me", "msg-123").Return(mockGetCall)
+	mockGetCall.On("Format", "metadata").Return(mockGetCall)
+	mockGetCall.On("Context", context.Background()).Return(mockGetCall)
+	mockGetCall.On("Do").Return(&gmail.Message{
+		Id: "msg-123",
+		Payload: &gmail.MessagePart{
+			MimeType: "multipart/mixed",
+			Parts: []*gmail.MessagePart{
+				{PartId: "1", MimeType: "application/pdf", Filename: "report.pdf", Body: &gmail.MessagePartBody{AttachmentId: "att-1", Size: 4}},
+			},
+		},
+	}, nil)
+	body := io.NopCloser(strings.NewReader(`{"data":"JVBERg=="}`)) // "%PDF"
+	mockMessagesService.On("GetAttachmentContent", context.Background(), "me", "msg-123", "att-1").Return(body, nil)
+
+	reader, _, err := GetAttachmentStream(context.Background(), mockGmailService, "msg-123", "att-1")
+
+	require.NoError(t, err)
+	data, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, "%PDF", string(data))
+}
+
+func TestGetAttachmentStream_Errors(t *testing.T) {
+	t.Run("invalid ID", func(t *testing.T) {
+		mockGmailService, mockMessagesService := setupMockMessagesService()
+
+		reader, attachment, err := GetAttachmentStream(context.Background(), mockGmailService, "msg-123", "att/1")
+
+		assert.Nil(t, reader)
+		assert.Nil(t, attachment)
+		assert.ErrorContains(t, err, `invalid attachment ID "att/1"`)
+		mockMessagesService.AssertNotCalled(t, "Get", mock.Anything, mock.Anything)
+	})
+
+	t.Run("not in message", func(t *testing.T) {
+		mockGmailService, mockMessagesService := setupMockMessagesService()
+		mockGetCall := &gmailtest.MockMessagesGetCall{}
+		mockMessagesService.On("Get", "me", "msg-123").Return(mockGetCall)
+		mockGetCall.On("Format", "metadata").Return(mockGetCall)
+		mockGetCall.On("Context", context.Background()).Return(mockGetCall)
+		mockGetCall.On("Do").Return(&gmail.Message{Id: "msg-123", Payload: &gmail.MessagePart{MimeType: "text/plain"}}, nil)
+
+		reader, attachment, err := GetAttachmentStream(context.Background(), mockGmailService, "msg-123", "att-1")
+
+		assert.Nil(t, reader)
+		assert.Nil(t, attachment)
+		assert.ErrorContains(t, err, "attachment att-1 not found in message msg-123")
+		mockMessagesService.AssertNotCalled(t, "GetAttachmentContent", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("download fails", func(t *testing.T) {
+		mockGmailService, mockMessagesService := setupMockMessagesService()
+		mockGetCall := &gmailtest.MockMessagesGetCall{}
+		mockMessagesService.On("Get", "me", "msg-123").Return(mockGetCall)
+		mockGetCall.On("Format", "metadata").Return(mockGetCall)
+		mockGetCall.On("Context", context.Background()).Return(mockGetCall)
+		mockGetCall.On("Do").Return(&gmail.Message{
+			Id: "msg-123",
+			Payload: &gmail.MessagePart{
+				MimeType: "multipart/mixed",
+				Parts: []*gmail.MessagePart{
+					{PartId: "1", MimeType: "application/pdf", Filename: "report.pdf", Body: &gmail.MessagePartBody{AttachmentId: "att-1", Size: 4}},
+				},
+			},
+		}, nil)
+		mockMessagesService.On("GetAttachmentContent", context.Background(), "me", "msg-123", "att-1").Return(nil, errors.New("boom"))
+
+		reader, attachment, err := GetAttachmentStream(context.Background(), mockGmailService, "msg-123", "att-1")
+
+		assert.Nil(t, reader)
+		assert.Nil(t, attachment)
+		assert.ErrorContains(t, err, "failed to get attachment: boom")
+	})
+}
+
+func TestJSONDataReader(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    string
+		wantErr string
+	}{
+		{"compact", `{"data":"YWJj"}`, "abc", ""},
+		{"spaced and padded", "{\n  \"data\" : \"YWI=\"\n}", "ab", ""},
+		{"other fields first", `{"size":3,"data":"YWJj"}`, "abc", ""},
+		{"empty value", `{"data":""}`, "", ""},
+		{"no data field", `{"size":3}`, "", "no data field"},
+		{"not a string", `{"data":3}`, "", "malformed data field"},
+		{"truncated", `{"data":"YWJj`, "", "unexpected EOF"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := newJSONDataReader(strings.NewReader(tt.body))
+			if err == nil {
+				var decoded []byte
+				decoded, err = io.ReadAll(base64.NewDecoder(base64.RawURLEncoding, data))
+				if err == nil {
+					assert.Equal(t, tt.want, string(decoded))
+				}
+			}
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestGetMessageSize(t *testing.T) {
+	mockGmailService, mockMessagesService := setupMockMessagesService()
+	mockGetCall := &gmailtest.MockMessagesGetCall{}
+	mockMessagesService.On("Get", "me", "msg-123").Return(mockGetCall)
+	mockGetCall.On("Format", "metadata").Return(mockGetCall)
+	mockGetCall.On("Fields", []googleapi.Field{"sizeEstimate"}).Return(mockGetCall)
+	mockGetCall.On("Context", context.Background()).Return(mockGetCall)
+	mockGetCall.On("Do").Return(&gmail.Message{Id: "msg-123", SizeEstimate: 48213}, nil)
+
+	size, err := GetMessageSize(context.Background(), mockGmailService, "msg-123")
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(48213), size)
+	mockGetCall.AssertNotCalled(t, "Format", "full")
+	mockMessagesService.AssertNotCalled(t, "GetAttachment", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestGetMessageSize_Error(t *testing.T) {
+	mockGmailService, mockMessagesService := setupMockMessagesService()
+	mockGetCall := &gmailtest.MockMessagesGetCall{}
+	mockMessagesService.On("Get", "me", "msg-123").Return(mockGetCall)
+	mockGetCall.On("Format", "metadata").Return(mockGetCall)
+	mockGetCall.On("Fields", []googleapi.Field{"sizeEstimate"}).Return(mockGetCall)
+	mockGetCall.On("Context", context.Background()).Return(mockGetCall)
+	mockGetCall.On("Do").Return(nil, errors.New("not found"))
+
+	_, err := GetMessageSize(context.Background(), mockGmailService, "msg-123")
+
+	assert.ErrorContains(t, err, "failed to get message size")
+}
+
+func TestGetAttachmentByIndex(t *testing.T) {
+	message := &gmail.Message{
+		Id: "msg-123",
+		Payload: &gmail.MessagePart{
+			MimeType: "multipart/mixed",
+			Parts: []*gmail.MessagePart{
+				{PartId: "0", MimeType: "text/plain", Body: &gmail.MessagePartBody{Data: "SGVsbG8"}},
+				{PartId: "1", MimeType: "text/plain", Filename: "notes.txt", Body: &gmail.MessagePartBody{AttachmentId: "att-1", Size: 5}},
+				{PartId: "2", MimeType: "application/pdf", Filename: "report.pdf", Body: &gmail.MessagePartBody{AttachmentId: "att-2", Size: 4}},
+			},
+		},
+	}
+
+	setup := func() (internal.GmailService, *gmailtest.MockMessagesService) {
+		mockGmailService, mockMessagesService := setupMockMessagesService()
+		mockGetCall := &gmailtest.MockMessagesGetCall{}
+		mockMessagesService.On("Get", "me", "msg-123").Return(mockGetCall)
+		mockGetCall.On("Format", "full").Return(mockGetCall)
+		mockGetCall.On("Context", context.Background()).Return(mockGetCall)
+		mockGetCall.On("Do").Return(message, nil)
+		return mockGmailService, mockMessagesService
+	}
+
+	t.Run("valid index", func(t *testing.T) {
+		mockGmailService, mockMessagesService := setup()
+		mockAttachmentCall := &gmailtest.MockMessagesAttachmentGetCall{}
+		mockMessagesService.On("GetAttachment", "me", "msg-123", "att-2").Return(mockAttachmentCall)
+		mockAttachmentCall.On("Context", context.Background()).Return(mockAttachmentCall)
+		mockAttachmentCall.On("Do").Return(&gmail.MessagePartBody{Data: "JVBERg"}, nil) // "%PDF"
+
+		attachment, err := GetAttachmentByIndex(context.Background(), mockGmailService, "msg-123", 1)
+
+		require.NoError(t, err)
+		assert.Equal(t, "att-2", attachment.ID)
+		assert.Equal(t, "report.pdf", attachment.Filename)
+		assert.Equal(t, "application/pdf", attachment.MimeType)
+		assert.Equal(t, []byte("%PDF"), attachment.Data)
+	})
+
+	t.Run("out of range index", func(t *testing.T) {
+		for _, index := range []int{-1, 2} {
+			mockGmailService, mockMessagesService := setup()
+
+			attachment, err := GetAttachmentByIndex(context.Background(), mockGmailService, "msg-123", index)
+
+			assert.Nil(t, attachment)
+			assert.ErrorContains(t, err, "out of range: message msg-123 has 2 attachments")
+			mockMessagesService.AssertNotCalled(t, "GetAttachment", mock.Anything, mock.Anything, mock.Anything)
+		}
+	})
+}
+
+func TestGetMessageWithOptions_BlockRemoteContent(t *testing.T) {
+	html := `<p>Hi</p><img src="https://cdn.example.com/logo.png"><img width="1" height="1" src="https://track.example.com/p.gif">`
+	mockGmailService, mockMessagesService := setupMockMessagesService()
+	mockGetCall := &gmailtest.MockMessagesGetCall{}
+	mockMessagesService.On("Get", "me", "msg-123").Return(mockGetCall)
+	mockGetCall.On("Format", "full").Return(mockGetCall)
+	mockGetCall.On("Context", context.Background()).Return(mockGetCall)
+	mockGetCall.On("Do").Return(&gmail.Message{
+		Id: "msg-123",
+		Payload: &gmail.MessagePart{
+			MimeType: "text/html",
+			Body:     &gmail.MessagePartBody{Data: base64.URLEncoding.EncodeToString([]byte(html))},
+		},
+	}, nil)
+
+	email, err := GetMessageWithOptions(context.Background(), mockGmailService, "msg-123", &core.GetOptions{BlockRemoteContent: true})
+
+	require.NoError(t, err)
+	assert.Equal(t, `<p>Hi</p><img src="`+core.RemoteContentPlaceholder+`">`, email.Body.HTML)
+	assert.Equal(t, []string{"https://cdn.example.com/logo.png", "https://track.example.com/p.gif"}, email.BlockedResources)
+}
+
+func TestGetMessageInlineHTML(t *testing.T) {
+	html := `<p>Hi</p><img src="cid:logo@example.com"><img src="cid:sig@example.com">`
+	mockGmailService, mockMessagesService := setupMockMessagesService()
+	mockGetCall := &gmailtest.MockMessagesGetCall{}
+	mockMessagesService.On("Get", "me", "msg-123").Return(mockGetCall)
+	mockGetCall.On("Format", "full").Return(mockGetCall)
+	mockGetCall.On("Context", context.Background()).Return(mockGetCall)
+	mockGetCall.On("Do").Return(&gmail.Message{
+		Id: "msg-123",
+		Payload: &gmail.MessagePart{
+			MimeType: "multipart/related",
+			Parts: []*gmail.MessagePart{
+				{PartId: "0", MimeType: "text/html", Body: &gmail.MessagePartBody{Data: base64.URLEncoding.EncodeToString([]byte(html))}},
+				{
+					PartId:   "1",
+					MimeType: "image/png",
+					Filename: "logo.png",
+					Headers:  []*gmail.MessagePartHeader{{Name: "Content-ID", Value: "<logo@example.com>"}},
+					Body:     &gmail.MessagePartBody{AttachmentId: "att-1", Size: 3},
+				},
+				{
+					PartId:   "2",
+					MimeType: "image/gif",
+					Headers:  []*gmail.MessagePartHeader{{Name: "Content-Id", Value: "<sig@example.com>"}},
+					Body:     &gmail.MessagePartBody{Data: base64.URLEncoding.EncodeToString([]byte("gif")), Size: 3},
+				},
+			},
+		},
+	}, nil)
+
+	mockAttachmentCall := &gmailtest.MockMessagesAttachmentGetCall{}
+	mockMessagesService.On("GetAttachment", "me", "msg-123", "att-1").Return(mockAttachmentCall)
+	mockAttachmentCall.On("Context", context.Background()).Return(mockAttachmentCall)
+	mockAttachmentCall.On("Do").Return(&gmail.MessagePartBody{Data: base64.URLEncoding.EncodeToString([]byte("png"))}, nil)
+
+	result, err := GetMessageInlineHTML(context.Background(), mockGmailService, "msg-123")
+
+	require.NoError(t, err)
+	assert.Equal(t, `<p>Hi</p><img src="data:image/png;base64,cG5n"><img src="data:image/gif;base64,Z2lm">`, result)
+	mockMessagesService.AssertExpectations(t)
+}
+
+func TestGetMessageInlineHTML_NoCIDReferences(t *testing.T) {
+	mockGmailService, mockMessagesService := setupMockMessagesService()
+	mockGetCall := &gmailtest.MockMessagesGetCall{}
+	mockMessagesService.On("Get", "me", "msg-123").Return(mockGetCall)
+	mockGetCall.On("Format", "full").Return(mockGetCall)
+	mockGetCall.On("Context", context.Background()).Return(mockGetCall)
+	mockGetCall.On("Do").Return(&gmail.Message{
+		Id: "msg-123",
+		Payload: &gmail.MessagePart{
+			MimeType: "text/html",
+			Body:     &gmail.MessagePartBody{Data: base64.URLEncoding.EncodeToString([]byte("<p>Plain</p>"))},
+		},
+	}, nil)
+
+	result, err := GetMessageInlineHTML(context.Background(), mockGmailService, "msg-123")
+
+	require.NoError(t, err)
+	assert.Equal(t, "<p>Plain</p>", result)
+	mockMessagesService.AssertNotCalled(t, "GetAttachment", mock.Anything, mock.Anything, mock.Anything)
+}
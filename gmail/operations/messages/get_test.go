@@ -1,6 +1,7 @@
 package messages
 
 import (
+	"encoding/base64"
 	"testing"
 	"time"
 
@@ -352,6 +353,29 @@ func TestExtractAttachments(t *testing.T) {
 	}
 }
 
+func TestExtractAttachments_InlineCalendar(t *testing.T) {
+	ics := "BEGIN:VCALENDAR\r\nMETHOD:REQUEST\r\nBEGIN:VEVENT\r\nUID:evt-1\r\nSUMMARY:Sync\r\nDTSTART:20240301T093000Z\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"
+	payload := &gmail.MessagePart{
+		MimeType: "multipart/alternative",
+		Parts: []*gmail.MessagePart{
+			{MimeType: "text/plain", Body: &gmail.MessagePartBody{Data: "SGVsbG8"}},
+			{MimeType: "text/calendar", Body: &gmail.MessagePartBody{Data: base64.URLEncoding.EncodeToString([]byte(ics)), Size: int64(len(ics))}},
+		},
+	}
+
+	attachments := extractAttachments(payload)
+
+	require.Len(t, attachments, 1)
+	assert.Equal(t, "invite.ics", attachments[0].Filename)
+	assert.Equal(t, "text/calendar", attachments[0].MimeType)
+
+	event, err := (&core.Email{Attachments: attachments}).CalendarEvent()
+	require.NoError(t, err)
+	require.NotNil(t, event, "the inline part is parsed without downloading")
+	assert.Equal(t, "evt-1", event.UID)
+	assert.Equal(t, core.CalendarMethodRequest, event.Method)
+}
+
 func TestConvertMessage(t *testing.T) {
 	now := time.Now()
 	dateStr := now.Format(time.RFC1123Z)
@@ -367,6 +391,7 @@ func TestConvertMessage(t *testing.T) {
 				{Name: "From", Value: "sender@example.com"},
 				{Name: "To", Value: "recipient@example.com"},
 				{Name: "Date", Value: dateStr},
+				{Name: "Message-ID", Value: " <CAB123@mail.gmail.com>"},
 			},
 			MimeType: "text/plain",
 			Body: &gmail.MessagePartBody{
@@ -380,6 +405,7 @@ func TestConvertMessage(t *testing.T) {
 	require.NotNil(t, email)
 	assert.Equal(t, "msg-123", email.ID)
 	assert.Equal(t, "thread-456", email.ThreadID)
+	assert.Equal(t, "<CAB123@mail.gmail.com>", email.MessageID)
 	assert.Equal(t, "Test Subject", email.Subject)
 	assert.Equal(t, "sender@example.com", email.From.Email)
 	assert.Equal(t, "Test message snippet", email.Snippet)
@@ -387,3 +413,188 @@ func TestConvertMessage(t *testing.T) {
 	assert.Contains(t, email.Labels, "INBOX")
 	assert.Contains(t, email.Labels, "UNREAD")
 }
+
+func TestConvertMessage_LabelFlags(t *testing.T) {
+	tests := []struct {
+		name        string
+		labels      []string
+		wantRead    bool
+		wantStarred bool
+		wantDraft   bool
+	}{
+		{"no labels", nil, true, false, false},
+		{"unread", []string{"INBOX", "UNREAD"}, false, false, false},
+		{"starred and read", []string{"INBOX", "STARRED"}, true, true, false},
+		{"draft", []string{"DRAFT"}, true, false, true},
+		{"unread starred draft", []string{"UNREAD", "STARRED", "DRAFT"}, false, true, true},
+		{"user labels only", []string{"Label_1", "Label_2"}, true, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			email := convertMessage(&gmail.Message{
+				Id:       "msg-123",
+				LabelIds: tt.labels,
+				Payload:  &gmail.MessagePart{MimeType: "text/plain", Body: &gmail.MessagePartBody{}},
+			})
+
+			assert.Equal(t, tt.wantRead, email.IsRead, "IsRead")
+			assert.Equal(t, tt.wantStarred, email.IsStarred, "IsStarred")
+			assert.Equal(t, tt.wantDraft, email.IsDraft, "IsDraft")
+		})
+	}
+}
+
+func TestConvertMessage_SignedMessage(t *testing.T) {
+	msg := &gmail.Message{
+		Id: "msg-signed",
+		Payload: &gmail.MessagePart{
+			MimeType: "multipart/signed",
+			Headers: []*gmail.MessagePartHeader{
+				{Name: "Content-Type", Value: `multipart/signed; protocol="application/pkcs7-signature"; micalg=sha-256; boundary="b1"`},
+			},
+			Parts: []*gmail.MessagePart{
+				{PartId: "0", MimeType: "text/plain", Body: &gmail.MessagePartBody{Data: "SGVsbG8"}}, // "Hello"
+				{PartId: "1", MimeType: "application/pkcs7-signature", Body: &gmail.MessagePartBody{Data: "MIIG", Size: 3}},
+			},
+		},
+	}
+
+	email := convertMessage(msg)
+
+	assert.True(t, email.Signed)
+	assert.False(t, email.Encrypted)
+	assert.Equal(t, "Hello", email.Body.Text)
+	require.Len(t, email.Attachments, 1)
+	assert.Equal(t, "smime.p7s", email.Attachments[0].Filename)
+	assert.Equal(t, []byte{0x30, 0x82, 0x06}, email.Attachments[0].Data)
+}
+
+func TestConvertMessage_EncryptedMessage(t *testing.T) {
+	msg := &gmail.Message{
+		Id: "msg-encrypted",
+		Payload: &gmail.MessagePart{
+			MimeType: "application/pkcs7-mime",
+			Filename: "smime.p7m",
+			Headers: []*gmail.MessagePartHeader{
+				{Name: "Content-Type", Value: `application/pkcs7-mime; smime-type=enveloped-data; name="smime.p7m"`},
+			},
+			Body: &gmail.MessagePartBody{AttachmentId: "att-p7m", Size: 4096},
+		},
+	}
+
+	email := convertMessage(msg)
+
+	assert.True(t, email.Encrypted)
+	assert.False(t, email.Signed)
+	assert.Empty(t, email.Body.Text)
+	assert.Empty(t, email.Body.HTML)
+	assert.Equal(t, []core.Attachment{
+		{ID: "att-p7m", Filename: "smime.p7m", MimeType: "application/pkcs7-mime", Size: 4096},
+	}, email.Attachments)
+}
+
+func TestConvertMessage_SentAndReceivedDates(t *testing.T) {
+	sent := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	received := time.Date(2024, 5, 1, 11, 45, 0, 0, time.UTC)
+
+	tests := []struct {
+		name             string
+		internalDate     int64
+		expectedDate     time.Time
+		expectedReceived time.Time
+	}{
+		{name: "with internal date", internalDate: received.UnixMilli(), expectedDate: received, expectedReceived: received},
+		{name: "without internal date", expectedDate: sent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			email := convertMessage(&gmail.Message{
+				Id:           "msg-123",
+				InternalDate: tt.internalDate,
+				Payload: &gmail.MessagePart{
+					Headers: []*gmail.MessagePartHeader{
+						{Name: "Date", Value: sent.Format(time.RFC1123Z)},
+					},
+				},
+			})
+
+			assert.True(t, tt.expectedDate.Equal(email.Date), "Date = %v", email.Date)
+			assert.True(t, sent.Equal(email.SentDate), "SentDate = %v", email.SentDate)
+			assert.True(t, tt.expectedReceived.Equal(email.ReceivedDate), "ReceivedDate = %v", email.ReceivedDate)
+		})
+	}
+}
+
+func TestConvertMessage_IMAPIDs(t *testing.T) {
+	tests := []struct {
+		name     string
+		msg      *gmail.Message
+		expected map[string]string
+	}{
+		{
+			name: "from headers",
+			msg: &gmail.Message{
+				Id:       "18c2f4e1a9b3d7f0",
+				ThreadId: "18c2f4e1a9b3d7f0",
+				Payload: &gmail.MessagePart{
+					Headers: []*gmail.MessagePartHeader{
+						{Name: "X-GM-MSGID", Value: "1784218349234567890"},
+						{Name: "x-gm-thrid", Value: " 1784218349234500000 "},
+					},
+				},
+			},
+			expected: map[string]string{
+				"X-GM-MSGID": "1784218349234567890",
+				"X-GM-THRID": "1784218349234500000",
+			},
+		},
+		{
+			name: "derived from hex IDs",
+			msg:  &gmail.Message{Id: "18c2f4e1a9b3d7f0", ThreadId: "18c2f4e1a9b30000"},
+			expected: map[string]string{
+				"X-GM-MSGID": "1784257652444092400",
+				"X-GM-THRID": "1784257652444037120",
+			},
+		},
+		{
+			name:     "non-hex IDs",
+			msg:      &gmail.Message{Id: "msg-123", ThreadId: "thread-456"},
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			email := convertMessage(tt.msg)
+			assert.Equal(t, tt.expected, email.ProviderMeta)
+		})
+	}
+}
+
+func TestConvertMessage_NoPayload(t *testing.T) {
+	email := convertMessage(&gmail.Message{
+		Id:           "msg-123",
+		LabelIds:     []string{"UNREAD", "STARRED"},
+		InternalDate: 1714552200000,
+	})
+
+	require.NotNil(t, email)
+	assert.False(t, email.IsRead)
+	assert.True(t, email.IsStarred)
+	assert.False(t, email.IsDraft)
+	assert.Equal(t, time.UnixMilli(1714552200000).UTC(), email.ReceivedDate)
+}
+
+func TestExtractHeaders(t *testing.T) {
+	headers := extractHeaders([]*gmail.MessagePartHeader{
+		{Name: "list-unsubscribe", Value: "<mailto:unsub@example.com>"},
+		{Name: "Received", Value: "first"},
+		{Name: "Received", Value: "second"},
+	})
+
+	assert.Equal(t, "<mailto:unsub@example.com>", headers["List-Unsubscribe"])
+	assert.Equal(t, "first", headers["Received"])
+	assert.Nil(t, extractHeaders(nil))
+}
@@ -2,13 +2,20 @@ package messages
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"strings"
 
 	"github.com/danielrivera/mailbridge-go/core"
 	"github.com/danielrivera/mailbridge-go/gmail/internal"
 	"github.com/danielrivera/mailbridge-go/gmail/operations"
+	"google.golang.org/api/googleapi"
 )
 
+// idsOnlyListFields is the partial response requested for ListOptions.IDsOnly
+var idsOnlyListFields = []googleapi.Field{"messages(id,threadId)", "nextPageToken", "resultSizeEstimate"}
+
 // ListMessages lists messages from Gmail
 func ListMessages(ctx context.Context, service internal.GmailService, opts *core.ListOptions) (*core.ListResponse, error) {
 	messagesService := operations.GetMessagesService(service)
@@ -21,19 +28,38 @@ func ListMessages(ctx context.Context, service internal.GmailService, opts *core
 		if opts.PageToken != "" {
 			call = call.PageToken(opts.PageToken)
 		}
-		if opts.Query != "" {
-			call = call.Q(opts.Query)
+		if query := BuildQuery(opts); query != "" {
+			call = call.Q(query)
 		}
 		if len(opts.Labels) > 0 {
 			call = call.LabelIds(opts.Labels...)
 		}
+		if opts.IDsOnly {
+			call = call.Fields(idsOnlyListFields...)
+		}
 	}
 
 	resp, err := call.Context(ctx).Do()
 	if err != nil {
+		if isRateLimited(err) {
+			return nil, fmt.Errorf("failed to list messages: %w: %w", core.ErrRateLimited, err)
+		}
 		return nil, fmt.Errorf("failed to list messages: %w", err)
 	}
 
+	// The list endpoint only returns ID stubs, so IDsOnly skips fetching each message
+	if opts != nil && opts.IDsOnly {
+		emails := make([]*core.Email, 0, len(resp.Messages))
+		for _, msg := range resp.Messages {
+			emails = append(emails, &core.Email{ID: msg.Id, ThreadID: msg.ThreadId})
+		}
+		return &core.ListResponse{
+			Emails:        emails,
+			NextPageToken: resp.NextPageToken,
+			TotalCount:    resp.ResultSizeEstimate,
+		}, nil
+	}
+
 	emails := make([]*core.Email, 0, len(resp.Messages))
 	for _, msg := range resp.Messages {
 		email, err := GetMessage(ctx, service, msg.Id)
@@ -44,9 +70,64 @@ func ListMessages(ctx context.Context, service internal.GmailService, opts *core
 		emails = append(emails, email)
 	}
 
+	// Gmail cannot search headers or order results, and fetching bodies separately loses any implicit order
+	if opts != nil {
+		emails = core.FilterByUnsubscribe(emails, opts.HasUnsubscribe)
+		core.SortEmails(emails, opts.OrderBy)
+	}
+
 	return &core.ListResponse{
 		Emails:        emails,
 		NextPageToken: resp.NextPageToken,
 		TotalCount:    resp.ResultSizeEstimate,
 	}, nil
 }
+
+// BuildQuery combines the free-form query with the structured list filters
+// into a single Gmail search query
+func BuildQuery(opts *core.ListOptions) string {
+	var parts []string
+	if opts.Query != "" {
+		parts = append(parts, opts.Query)
+	}
+	if opts.HasAttachments != nil {
+		if *opts.HasAttachments {
+			parts = append(parts, "has:attachment")
+		} else {
+			parts = append(parts, "-has:attachment")
+		}
+	}
+	if opts.ExcludeFromSelf {
+		parts = append(parts, "-from:me")
+	}
+	// Gmail has no invite search operator, so an attached .ics file stands in for one
+	if opts.HasCalendarInvite != nil {
+		if *opts.HasCalendarInvite {
+			parts = append(parts, "has:attachment filename:ics")
+		} else {
+			parts = append(parts, "-filename:ics")
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// isRateLimited reports whether err is a Gmail rate limit error: 429, or 403 with a
+// rateLimitExceeded or userRateLimitExceeded reason
+func isRateLimited(err error) bool {
+	var apiErr *googleapi.Error
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	if apiErr.Code == http.StatusTooManyRequests {
+		return true
+	}
+	if apiErr.Code != http.StatusForbidden {
+		return false
+	}
+	for _, item := range apiErr.Errors {
+		if item.Reason == "rateLimitExceeded" || item.Reason == "userRateLimitExceeded" {
+			return true
+		}
+	}
+	return false
+}
@@ -3,14 +3,17 @@ package messages
 import (
 	"context"
 	"errors"
+	"net/http"
 	"testing"
 
 	"github.com/danielrivera/mailbridge-go/core"
 	"github.com/danielrivera/mailbridge-go/gmail/internal"
 	gmailtest "github.com/danielrivera/mailbridge-go/gmail/testing"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/api/gmail/v1"
+	"google.golang.org/api/googleapi"
 )
 
 func TestListMessages_Success(t *testing.T) {
@@ -63,6 +66,36 @@ func TestListMessages_Success(t *testing.T) {
 	assert.Equal(t, int64(1), resp.TotalCount)
 }
 
+func TestListMessages_IDsOnly(t *testing.T) {
+	mockGmailService, mockMessagesService := setupMockMessagesService()
+	mockMessagesListCall := &gmailtest.MockMessagesListCall{}
+
+	mockMessagesService.On("List", "me").Return(mockMessagesListCall)
+	mockMessagesListCall.On("MaxResults", int64(2)).Return(mockMessagesListCall)
+	mockMessagesListCall.On("Fields", []googleapi.Field{"messages(id,threadId)", "nextPageToken", "resultSizeEstimate"}).Return(mockMessagesListCall)
+	mockMessagesListCall.On("Context", context.Background()).Return(mockMessagesListCall)
+	mockMessagesListCall.On("Do").Return(&gmail.ListMessagesResponse{
+		Messages: []*gmail.Message{
+			{Id: "msg-1", ThreadId: "thread-1"},
+			{Id: "msg-2", ThreadId: "thread-1"},
+		},
+		NextPageToken:      "next-token",
+		ResultSizeEstimate: 40,
+	}, nil)
+
+	resp, err := ListMessages(context.Background(), mockGmailService, &core.ListOptions{MaxResults: 2, IDsOnly: true})
+
+	require.NoError(t, err)
+	assert.Equal(t, []*core.Email{
+		{ID: "msg-1", ThreadID: "thread-1"},
+		{ID: "msg-2", ThreadID: "thread-1"},
+	}, resp.Emails)
+	assert.Equal(t, "next-token", resp.NextPageToken)
+	assert.Equal(t, int64(40), resp.TotalCount)
+	mockMessagesService.AssertNotCalled(t, "Get", mock.Anything, mock.Anything)
+	mockMessagesListCall.AssertExpectations(t)
+}
+
 func TestListMessages_APIError(t *testing.T) {
 	mockGmailService, mockMessagesService := setupMockMessagesService()
 	mockMessagesListCall := &gmailtest.MockMessagesListCall{}
@@ -76,6 +109,21 @@ func TestListMessages_APIError(t *testing.T) {
 	assert.Contains(t, err.Error(), "failed to list messages")
 }
 
+func TestListMessages_RateLimited(t *testing.T) {
+	mockGmailService, mockMessagesService := setupMockMessagesService()
+	mockMessagesListCall := &gmailtest.MockMessagesListCall{}
+
+	mockMessagesService.On("List", "me").Return(mockMessagesListCall)
+	mockMessagesListCall.On("Context", context.Background()).Return(mockMessagesListCall)
+	mockMessagesListCall.On("Do").Return(nil, &googleapi.Error{Code: http.StatusTooManyRequests})
+
+	_, err := ListMessages(context.Background(), mockGmailService, nil)
+
+	assert.ErrorIs(t, err, core.ErrRateLimited)
+	var apiErr *googleapi.Error
+	assert.ErrorAs(t, err, &apiErr)
+}
+
 func TestListMessages_WithAllOptions(t *testing.T) {
 	mockGmailService, mockMessagesService := setupMockMessagesService()
 	mockMessagesListCall := &gmailtest.MockMessagesListCall{}
@@ -102,6 +150,101 @@ func TestListMessages_WithAllOptions(t *testing.T) {
 
 	require.NoError(t, err)
 	assert.Empty(t, resp.Emails)
+	mockMessagesListCall.AssertNotCalled(t, "Fields", mock.Anything)
+}
+
+func TestListMessages_StructuredFilters(t *testing.T) {
+	hasAttachments := true
+	noAttachments := false
+
+	tests := []struct {
+		name          string
+		opts          *core.ListOptions
+		expectedQuery string
+	}{
+		{
+			name:          "with attachments",
+			opts:          &core.ListOptions{HasAttachments: &hasAttachments},
+			expectedQuery: "has:attachment",
+		},
+		{
+			name:          "without attachments",
+			opts:          &core.ListOptions{HasAttachments: &noAttachments},
+			expectedQuery: "-has:attachment",
+		},
+		{
+			name:          "combined with query",
+			opts:          &core.ListOptions{Query: "from:boss@example.com", HasAttachments: &hasAttachments},
+			expectedQuery: "from:boss@example.com has:attachment",
+		},
+		{
+			name:          "exclude from self",
+			opts:          &core.ListOptions{ExcludeFromSelf: true},
+			expectedQuery: "-from:me",
+		},
+		{
+			name:          "with calendar invite",
+			opts:          &core.ListOptions{HasCalendarInvite: &hasAttachments},
+			expectedQuery: "has:attachment filename:ics",
+		},
+		{
+			name:          "without calendar invite",
+			opts:          &core.ListOptions{HasCalendarInvite: &noAttachments},
+			expectedQuery: "-filename:ics",
+		},
+		{
+			name:          "calendar invite combined with query",
+			opts:          &core.ListOptions{Query: "from:boss@example.com", HasCalendarInvite: &hasAttachments},
+			expectedQuery: "from:boss@example.com has:attachment filename:ics",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockGmailService, mockMessagesService := setupMockMessagesService()
+			mockMessagesListCall := &gmailtest.MockMessagesListCall{}
+
+			mockMessagesService.On("List", "me").Return(mockMessagesListCall)
+			mockMessagesListCall.On("Q", tt.expectedQuery).Return(mockMessagesListCall)
+			mockMessagesListCall.On("Context", context.Background()).Return(mockMessagesListCall)
+			mockMessagesListCall.On("Do").Return(&gmail.ListMessagesResponse{}, nil)
+
+			_, err := ListMessages(context.Background(), mockGmailService, tt.opts)
+
+			require.NoError(t, err)
+			mockMessagesListCall.AssertExpectations(t)
+		})
+	}
+}
+
+func TestListMessages_HasAttachmentsNil(t *testing.T) {
+	mockGmailService, mockMessagesService := setupMockMessagesService()
+	mockMessagesListCall := &gmailtest.MockMessagesListCall{}
+
+	mockMessagesService.On("List", "me").Return(mockMessagesListCall)
+	mockMessagesListCall.On("Context", context.Background()).Return(mockMessagesListCall)
+	mockMessagesListCall.On("Do").Return(&gmail.ListMessagesResponse{}, nil)
+
+	_, err := ListMessages(context.Background(), mockGmailService, &core.ListOptions{})
+
+	require.NoError(t, err)
+	mockMessagesListCall.AssertNotCalled(t, "Q", mock.Anything)
+}
+
+func TestListMessages_FocusedIgnored(t *testing.T) {
+	// Gmail has no Focused Inbox, so the Focused filter is documented as a no-op
+	focused := true
+	mockGmailService, mockMessagesService := setupMockMessagesService()
+	mockMessagesListCall := &gmailtest.MockMessagesListCall{}
+
+	mockMessagesService.On("List", "me").Return(mockMessagesListCall)
+	mockMessagesListCall.On("Context", context.Background()).Return(mockMessagesListCall)
+	mockMessagesListCall.On("Do").Return(&gmail.ListMessagesResponse{}, nil)
+
+	_, err := ListMessages(context.Background(), mockGmailService, &core.ListOptions{Focused: &focused})
+
+	require.NoError(t, err)
+	mockMessagesListCall.AssertNotCalled(t, "Q", mock.Anything)
 }
 
 // Helper function to setup mock messages service
@@ -115,3 +258,91 @@ func setupMockMessagesService() (internal.GmailService, *gmailtest.MockMessagesS
 
 	return mockGmailService, mockMessagesService
 }
+
+func TestListMessages_OrderByDateDesc(t *testing.T) {
+	mockGmailService, mockMessagesService := setupMockMessagesService()
+	mockMessagesListCall := &gmailtest.MockMessagesListCall{}
+
+	mockMessagesService.On("List", "me").Return(mockMessagesListCall)
+	mockMessagesListCall.On("Context", context.Background()).Return(mockMessagesListCall)
+	mockMessagesListCall.On("Do").Return(&gmail.ListMessagesResponse{
+		Messages: []*gmail.Message{{Id: "msg-mid"}, {Id: "msg-old"}, {Id: "msg-new"}},
+	}, nil)
+
+	// The hydrated messages come back in a different order than their dates
+	dates := map[string]string{
+		"msg-mid": "Tue, 02 Jan 2024 10:00:00 +0000",
+		"msg-old": "Mon, 01 Jan 2024 10:00:00 +0000",
+		"msg-new": "Wed, 03 Jan 2024 10:00:00 +0000",
+	}
+	for id, date := range dates {
+		getCall := &gmailtest.MockMessagesGetCall{}
+		mockMessagesService.On("Get", "me", id).Return(getCall)
+		getCall.On("Format", "full").Return(getCall)
+		getCall.On("Context", context.Background()).Return(getCall)
+		getCall.On("Do").Return(&gmail.Message{
+			Id: id,
+			Payload: &gmail.MessagePart{
+				Headers: []*gmail.MessagePartHeader{{Name: "Date", Value: date}},
+			},
+		}, nil)
+	}
+
+	resp, err := ListMessages(context.Background(), mockGmailService, &core.ListOptions{OrderBy: core.OrderByDateDesc})
+
+	require.NoError(t, err)
+	require.Len(t, resp.Emails, 3)
+	assert.Equal(t, "msg-new", resp.Emails[0].ID)
+	assert.Equal(t, "msg-mid", resp.Emails[1].ID)
+	assert.Equal(t, "msg-old", resp.Emails[2].ID)
+}
+
+func TestListMessages_HasUnsubscribe(t *testing.T) {
+	headers := map[string][]*gmail.MessagePartHeader{
+		"msg-newsletter": {{Name: "List-Unsubscribe", Value: "<mailto:unsub@news.example.com>, <https://news.example.com/unsub>"}},
+		"msg-personal":   {{Name: "Subject", Value: "Lunch?"}},
+		"msg-promo":      {{Name: "list-unsubscribe", Value: "<https://promo.example.com/u>"}},
+		"msg-broken":     {{Name: "List-Unsubscribe", Value: "unsubscribe here"}},
+	}
+	order := []string{"msg-newsletter", "msg-personal", "msg-promo", "msg-broken"}
+
+	tests := []struct {
+		name     string
+		want     bool
+		expected []string
+	}{
+		{"with unsubscribe", true, []string{"msg-newsletter", "msg-promo"}},
+		{"without unsubscribe", false, []string{"msg-personal", "msg-broken"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockGmailService, mockMessagesService := setupMockMessagesService()
+			mockMessagesListCall := &gmailtest.MockMessagesListCall{}
+
+			stubs := make([]*gmail.Message, 0, len(order))
+			for _, id := range order {
+				stubs = append(stubs, &gmail.Message{Id: id})
+				getCall := &gmailtest.MockMessagesGetCall{}
+				mockMessagesService.On("Get", "me", id).Return(getCall)
+				getCall.On("Format", "full").Return(getCall)
+				getCall.On("Context", context.Background()).Return(getCall)
+				getCall.On("Do").Return(&gmail.Message{Id: id, Payload: &gmail.MessagePart{Headers: headers[id]}}, nil)
+			}
+			mockMessagesService.On("List", "me").Return(mockMessagesListCall)
+			mockMessagesListCall.On("Context", context.Background()).Return(mockMessagesListCall)
+			mockMessagesListCall.On("Do").Return(&gmail.ListMessagesResponse{Messages: stubs, NextPageToken: "next"}, nil)
+
+			resp, err := ListMessages(context.Background(), mockGmailService, &core.ListOptions{HasUnsubscribe: &tt.want})
+
+			require.NoError(t, err)
+			var ids []string
+			for _, email := range resp.Emails {
+				ids = append(ids, email.ID)
+			}
+			assert.Equal(t, tt.expected, ids)
+			assert.Equal(t, "next", resp.NextPageToken)
+			mockMessagesListCall.AssertNotCalled(t, "Q", mock.Anything)
+		})
+	}
+}
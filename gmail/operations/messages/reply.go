@@ -0,0 +1,149 @@
+package messages
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/danielrivera/mailbridge-go/core"
+	"github.com/danielrivera/mailbridge-go/gmail/internal"
+	"github.com/danielrivera/mailbridge-go/gmail/operations"
+)
+
+// ReplyToMessage replies to the sender of a message (its Reply-To, or From).
+// See ReplyAllToMessage for how the reply is threaded
+func ReplyToMessage(ctx context.Context, service internal.GmailService, messageID string, draft *core.Draft, opts *core.SendOptions, clock core.Clock) (*core.SendResponse, error) {
+	return reply(ctx, service, messageID, draft, opts, clock, false)
+}
+
+// ReplyAllToMessage replies to the sender and to the original To and Cc recipients,
+// minus the authenticated user. The original headers are fetched to set In-Reply-To
+// and References, the subject gets a "Re: " prefix when missing (an empty draft
+// subject reuses the original one), and the reply is sent in the original thread.
+// Recipients already set on the draft are kept, and only computed when To is empty
+func ReplyAllToMessage(ctx context.Context, service internal.GmailService, messageID string, draft *core.Draft, opts *core.SendOptions, clock core.Clock) (*core.SendResponse, error) {
+	return reply(ctx, service, messageID, draft, opts, clock, true)
+}
+
+// reply fetches the original message and sends draft as a reply to it
+func reply(ctx context.Context, service internal.GmailService, messageID string, draft *core.Draft, opts *core.SendOptions, clock core.Clock, all bool) (*core.SendResponse, error) {
+	if messageID == "" {
+		return nil, fmt.Errorf("message ID is required")
+	}
+	if draft == nil {
+		return nil, fmt.Errorf("reply draft is required")
+	}
+
+	call := service.GetUsersService().GetMessagesService().Get(operations.UserIDMe, messageID)
+	original, err := call.Format("metadata").Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get original message: %w", err)
+	}
+
+	headers := make(map[string]string)
+	if original.Payload != nil {
+		for _, header := range original.Payload.Headers {
+			headers[strings.ToLower(header.Name)] = header.Value
+		}
+	}
+
+	self := ""
+	if all {
+		profile, err := service.GetUsersService().GetProfile(operations.UserIDMe).Context(ctx).Do()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get profile: %w", err)
+		}
+		self = profile.EmailAddress
+	}
+
+	return sendInThread(ctx, service, buildReply(draft, headers, self, all), opts, clock, original.ThreadId)
+}
+
+// buildReply returns a copy of draft addressed and threaded as a reply to a message
+// with the given lowercased headers. self is excluded from reply-all recipients
+func buildReply(draft *core.Draft, headers map[string]string, self string, all bool) *core.Draft {
+	reply := *draft
+
+	reply.Subject = replySubject(draft.Subject, headers["subject"])
+
+	reply.Headers = make(map[string]string, len(draft.Headers)+2)
+	for key, value := range draft.Headers {
+		reply.Headers[key] = value
+	}
+	if messageID := strings.TrimSpace(headers["message-id"]); messageID != "" {
+		reply.Headers["In-Reply-To"] = messageID
+		reply.Headers["References"] = replyReferences(headers, messageID)
+	}
+
+	if len(draft.To) > 0 {
+		return &reply
+	}
+
+	sender := parseEmailAddresses(headers["reply-to"])
+	if len(sender) == 0 {
+		sender = parseEmailAddresses(headers["from"])
+	}
+	if !all {
+		reply.To = sender
+		return &reply
+	}
+
+	to, cc := sender, append(parseEmailAddresses(headers["to"]), parseEmailAddresses(headers["cc"])...)
+	if containsAddress(sender, self) {
+		// Replying to our own message goes back to its recipients
+		to, cc = parseEmailAddresses(headers["to"]), parseEmailAddresses(headers["cc"])
+	}
+
+	seen := map[string]bool{strings.ToLower(self): true}
+	for _, addr := range draft.Cc {
+		seen[strings.ToLower(addr.Email)] = true
+	}
+	reply.To = uniqueAddresses(to, seen)
+	reply.Cc = append(append([]core.EmailAddress(nil), draft.Cc...), uniqueAddresses(cc, seen)...)
+	return &reply
+}
+
+// replySubject returns subject, or the original one when empty, with a "Re: " prefix
+func replySubject(subject, original string) string {
+	if subject == "" {
+		subject = original
+	}
+	if len(subject) >= 3 && strings.EqualFold(subject[:3], "re:") {
+		return subject
+	}
+	return "Re: " + subject
+}
+
+// replyReferences chains messageID onto the original References (or In-Reply-To)
+// header, as RFC 5322 section 3.6.4 describes
+func replyReferences(headers map[string]string, messageID string) string {
+	references := strings.Fields(headers["references"])
+	if len(references) == 0 {
+		references = strings.Fields(headers["in-reply-to"])
+	}
+	return strings.Join(append(references, messageID), " ")
+}
+
+// uniqueAddresses returns the addresses not yet in seen, case-insensitively, and adds them to it
+func uniqueAddresses(addrs []core.EmailAddress, seen map[string]bool) []core.EmailAddress {
+	var result []core.EmailAddress
+	for _, addr := range addrs {
+		key := strings.ToLower(addr.Email)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, addr)
+	}
+	return result
+}
+
+// containsAddress reports whether addrs holds email, case-insensitively
+func containsAddress(addrs []core.EmailAddress, email string) bool {
+	for _, addr := range addrs {
+		if strings.EqualFold(addr.Email, email) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,122 @@
+package messages
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/danielrivera/mailbridge-go/core"
+	gmailtest "github.com/danielrivera/mailbridge-go/gmail/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	gmailapi "google.golang.org/api/gmail/v1"
+)
+
+func setupReplyMocks(ctx context.Context) (*gmailtest.MockGmailService, *gmailtest.MockUsersService, *gmailtest.MockMessagesService) {
+	mockService := &gmailtest.MockGmailService{}
+	mockUsersService := &gmailtest.MockUsersService{}
+	mockMessagesService := &gmailtest.MockMessagesService{}
+	mockGetCall := &gmailtest.MockMessagesGetCall{}
+
+	mockService.On("GetUsersService").Return(mockUsersService)
+	mockUsersService.On("GetMessagesService").Return(mockMessagesService)
+	mockMessagesService.On("Get", "me", "msg-123").Return(mockGetCall)
+	mockGetCall.On("Format", "metadata").Return(mockGetCall)
+	mockGetCall.On("Context", ctx).Return(mockGetCall)
+	mockGetCall.On("Do").Return(&gmailapi.Message{
+		Id:       "msg-123",
+		ThreadId: "thread-456",
+		Payload: &gmailapi.MessagePart{
+			Headers: []*gmailapi.MessagePartHeader{
+				{Name: "From", Value: "alice@example.com"},
+				{Name: "To", Value: "me@example.com, bob@example.com"},
+				{Name: "Subject", Value: "Plans"},
+				{Name: "Message-Id", Value: "<orig@example.com>"},
+			},
+		},
+	}, nil)
+
+	return mockService, mockUsersService, mockMessagesService
+}
+
+func TestReplyAllToMessage(t *testing.T) {
+	ctx := context.Background()
+	mockService, mockUsersService, mockMessagesService := setupReplyMocks(ctx)
+
+	mockProfileCall := &gmailtest.MockUsersGetProfileCall{}
+	mockUsersService.On("GetProfile", "me").Return(mockProfileCall)
+	mockProfileCall.On("Context", ctx).Return(mockProfileCall)
+	mockProfileCall.On("Do").Return(&gmailapi.Profile{EmailAddress: "me@example.com"}, nil)
+
+	var sent *gmailapi.Message
+	mockSendCall := &gmailtest.MockMessagesSendCall{}
+	mockMessagesService.On("Send", "me", mock.AnythingOfType("*gmail.Message")).
+		Run(func(args mock.Arguments) { sent = args.Get(1).(*gmailapi.Message) }).
+		Return(mockSendCall)
+	mockSendCall.On("Context", ctx).Return(mockSendCall)
+	mockSendCall.On("Do").Return(&gmailapi.Message{Id: "reply-789", ThreadId: "thread-456"}, nil)
+
+	response, err := ReplyAllToMessage(ctx, mockService, "msg-123", &core.Draft{Body: core.EmailBody{Text: "Sounds good"}}, nil, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, &core.SendResponse{ID: "reply-789", ThreadID: "thread-456"}, response)
+	assert.Equal(t, "thread-456", sent.ThreadId)
+
+	raw, err := base64.RawURLEncoding.DecodeString(sent.Raw)
+	require.NoError(t, err)
+	assert.Contains(t, string(raw), "To: alice@example.com\r\n")
+	assert.Contains(t, string(raw), "Cc: bob@example.com\r\n")
+	assert.Contains(t, string(raw), "Subject: Re: Plans\r\n")
+	assert.Contains(t, string(raw), "In-Reply-To: <orig@example.com>\r\n")
+	assert.Contains(t, string(raw), "References: <orig@example.com>\r\n")
+	mockProfileCall.AssertExpectations(t)
+}
+
+func TestReplyToMessage_SkipsProfile(t *testing.T) {
+	ctx := context.Background()
+	mockService, mockUsersService, mockMessagesService := setupReplyMocks(ctx)
+
+	mockSendCall := &gmailtest.MockMessagesSendCall{}
+	mockMessagesService.On("Send", "me", mock.MatchedBy(func(msg *gmailapi.Message) bool {
+		return msg.ThreadId == "thread-456"
+	})).Return(mockSendCall)
+	mockSendCall.On("Context", ctx).Return(mockSendCall)
+	mockSendCall.On("Do").Return(&gmailapi.Message{Id: "reply-789", ThreadId: "thread-456"}, nil)
+
+	response, err := ReplyToMessage(ctx, mockService, "msg-123", &core.Draft{Body: core.EmailBody{Text: "Thanks"}}, nil, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, "reply-789", response.ID)
+	mockUsersService.AssertNotCalled(t, "GetProfile", mock.Anything)
+}
+
+func TestReplyToMessage_GetError(t *testing.T) {
+	ctx := context.Background()
+	mockService := &gmailtest.MockGmailService{}
+	mockUsersService := &gmailtest.MockUsersService{}
+	mockMessagesService := &gmailtest.MockMessagesService{}
+	mockGetCall := &gmailtest.MockMessagesGetCall{}
+
+	mockService.On("GetUsersService").Return(mockUsersService)
+	mockUsersService.On("GetMessagesService").Return(mockMessagesService)
+	mockMessagesService.On("Get", "me", "msg-123").Return(mockGetCall)
+	mockGetCall.On("Format", "metadata").Return(mockGetCall)
+	mockGetCall.On("Context", ctx).Return(mockGetCall)
+	mockGetCall.On("Do").Return(nil, errors.New("not found"))
+
+	response, err := ReplyToMessage(ctx, mockService, "msg-123", &core.Draft{Body: core.EmailBody{Text: "Thanks"}}, nil, nil)
+
+	assert.Nil(t, response)
+	assert.EqualError(t, err, "failed to get original message: not found")
+	mockMessagesService.AssertNotCalled(t, "Send", mock.Anything, mock.Anything)
+}
+
+func TestReplyToMessage_Validation(t *testing.T) {
+	_, err := ReplyToMessage(context.Background(), &gmailtest.MockGmailService{}, "", &core.Draft{}, nil, nil)
+	assert.EqualError(t, err, "message ID is required")
+
+	_, err = ReplyAllToMessage(context.Background(), &gmailtest.MockGmailService{}, "msg-123", nil, nil, nil)
+	assert.EqualError(t, err, "reply draft is required")
+}
@@ -0,0 +1,107 @@
+package messages
+
+import (
+	"testing"
+
+	"github.com/danielrivera/mailbridge-go/core"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplySubject(t *testing.T) {
+	tests := []struct {
+		name     string
+		subject  string
+		original string
+		expected string
+	}{
+		{"adds prefix to original", "", "Budget", "Re: Budget"},
+		{"keeps existing prefix", "", "Re: Budget", "Re: Budget"},
+		{"prefix is case-insensitive", "", "RE: Budget", "RE: Budget"},
+		{"draft subject wins", "New topic", "Budget", "Re: New topic"},
+		{"empty subjects", "", "", "Re: "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, replySubject(tt.subject, tt.original))
+		})
+	}
+}
+
+func TestReplyReferences(t *testing.T) {
+	tests := []struct {
+		name     string
+		headers  map[string]string
+		expected string
+	}{
+		{"first reply", map[string]string{}, "<c@example.com>"},
+		{"chains references", map[string]string{"references": "<a@example.com> <b@example.com>"}, "<a@example.com> <b@example.com> <c@example.com>"},
+		{"falls back to in-reply-to", map[string]string{"in-reply-to": "<b@example.com>"}, "<b@example.com> <c@example.com>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, replyReferences(tt.headers, "<c@example.com>"))
+		})
+	}
+}
+
+func TestBuildReply(t *testing.T) {
+	headers := map[string]string{
+		"from":       "Alice <alice@example.com>",
+		"to":         "Me <me@example.com>, bob@example.com",
+		"cc":         "ALICE@example.com, carol@example.com, Bob@Example.com",
+		"subject":    "Plans",
+		"message-id": "<orig@example.com>",
+		"references": "<root@example.com>",
+	}
+	draft := &core.Draft{Body: core.EmailBody{Text: "Sounds good"}, Headers: map[string]string{"X-Tag": "1"}}
+
+	t.Run("reply", func(t *testing.T) {
+		reply := buildReply(draft, headers, "", false)
+
+		assert.Equal(t, []core.EmailAddress{{Name: "Alice", Email: "alice@example.com"}}, reply.To)
+		assert.Empty(t, reply.Cc)
+		assert.Equal(t, "Re: Plans", reply.Subject)
+		assert.Equal(t, map[string]string{
+			"X-Tag":       "1",
+			"In-Reply-To": "<orig@example.com>",
+			"References":  "<root@example.com> <orig@example.com>",
+		}, reply.Headers)
+		assert.Equal(t, map[string]string{"X-Tag": "1"}, draft.Headers)
+	})
+
+	t.Run("reply all excludes self and duplicates", func(t *testing.T) {
+		reply := buildReply(draft, headers, "ME@example.com", true)
+
+		assert.Equal(t, []core.EmailAddress{{Name: "Alice", Email: "alice@example.com"}}, reply.To)
+		assert.Equal(t, []core.EmailAddress{{Email: "bob@example.com"}, {Email: "carol@example.com"}}, reply.Cc)
+	})
+
+	t.Run("reply all to own message", func(t *testing.T) {
+		own := map[string]string{"from": "me@example.com", "to": "bob@example.com", "cc": "me@example.com, carol@example.com"}
+
+		reply := buildReply(draft, own, "me@example.com", true)
+
+		assert.Equal(t, []core.EmailAddress{{Email: "bob@example.com"}}, reply.To)
+		assert.Equal(t, []core.EmailAddress{{Email: "carol@example.com"}}, reply.Cc)
+	})
+
+	t.Run("reply to prefers reply-to", func(t *testing.T) {
+		withReplyTo := map[string]string{"from": "alice@example.com", "reply-to": "list@example.com"}
+
+		reply := buildReply(draft, withReplyTo, "", false)
+
+		assert.Equal(t, []core.EmailAddress{{Email: "list@example.com"}}, reply.To)
+		assert.NotContains(t, reply.Headers, "In-Reply-To")
+	})
+
+	t.Run("draft recipients are kept", func(t *testing.T) {
+		addressed := &core.Draft{To: []core.EmailAddress{{Email: "dave@example.com"}}}
+
+		reply := buildReply(addressed, headers, "me@example.com", true)
+
+		assert.Equal(t, addressed.To, reply.To)
+		assert.Empty(t, reply.Cc)
+	})
+}
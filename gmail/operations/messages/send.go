@@ -4,14 +4,15 @@ import (
 	"bytes"
 	"context"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/base64"
 	"fmt"
 	"github.com/danielrivera/mailbridge-go/gmail/operations"
+	"io"
 	"mime"
 	"mime/multipart"
 	"mime/quotedprintable"
 	"net/textproto"
-	"regexp"
 	"strings"
 	"time"
 
@@ -20,32 +21,37 @@ import (
 	"google.golang.org/api/gmail/v1"
 )
 
-var emailRegex = regexp.MustCompile(`^[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}$`)
+const (
+	// maxHeaderLineLen is the recommended maximum header line length (RFC 5322 section 2.1.1)
+	maxHeaderLineLen = 78
+	// maxEncodedWordLen keeps an encoded-word on the same line as "Subject: "
+	maxEncodedWordLen = maxHeaderLineLen - len("Subject: ")
 
-// SendMessage sends an email message
-func SendMessage(ctx context.Context, service internal.GmailService, draft *core.Draft, opts *core.SendOptions) (*core.SendResponse, error) {
-	if err := validateDraft(draft); err != nil {
-		return nil, fmt.Errorf("invalid draft: %w", err)
-	}
+	encodedWordPrefix = "=?UTF-8?q?"
+	encodedWordSuffix = "?="
+)
 
-	// Build RFC 2822 message
-	var rawMessage string
-	var err error
-	if len(draft.Attachments) > 0 || (draft.Body.Text != "" && draft.Body.HTML != "") {
-		rawMessage, err = createMIMEMessage(draft, opts)
-	} else {
-		rawMessage, err = buildSimpleMessage(draft, opts)
-	}
+// randReader is the random source for Message-IDs and MIME boundaries.
+// Tests replace it with a fixed reader to produce stable MIME output
+var randReader io.Reader = rand.Reader
+
+// SendMessage sends an email message.
+// The clock stamps the Date and Message-ID headers; nil uses core.SystemClock
+func SendMessage(ctx context.Context, service internal.GmailService, draft *core.Draft, opts *core.SendOptions, clock core.Clock) (*core.SendResponse, error) {
+	return sendInThread(ctx, service, draft, opts, clock, "")
+}
+
+// sendInThread sends an email message, attaching it to threadID unless it is empty
+func sendInThread(ctx context.Context, service internal.GmailService, draft *core.Draft, opts *core.SendOptions, clock core.Clock, threadID string) (*core.SendResponse, error) {
+	encoded, err := BuildRawMessage(ctx, service, draft, opts, clock)
 	if err != nil {
-		return nil, fmt.Errorf("failed to build message: %w", err)
+		return nil, err
 	}
 
-	// Encode to base64url (Gmail format)
-	encoded := encodeBase64URL([]byte(rawMessage))
-
 	// Create Gmail message
 	gmailMsg := &gmail.Message{
-		Raw: encoded,
+		Raw:      encoded,
+		ThreadId: threadID,
 	}
 
 	// Send via Gmail API
@@ -62,67 +68,63 @@ func SendMessage(ctx context.Context, service internal.GmailService, draft *core
 	}, nil
 }
 
-// validateDraft validates the draft before sending
-func validateDraft(draft *core.Draft) error {
-	if draft == nil {
-		return fmt.Errorf("draft is nil")
+// BuildRawMessage validates a draft and builds the RFC 2822 message SendMessage sends,
+// base64url-encoded for gmail.Message.Raw. Messages named in AttachedMessages are fetched
+// and attached. The clock stamps the Date and Message-ID headers; nil uses core.SystemClock
+func BuildRawMessage(ctx context.Context, service internal.GmailService, draft *core.Draft, opts *core.SendOptions, clock core.Clock) (string, error) {
+	// Attached messages are fetched first so they count towards the size limits
+	draft, err := attachMessages(ctx, service, draft)
+	if err != nil {
+		return "", err
 	}
-
-	// At least one recipient required
-	if len(draft.To) == 0 && len(draft.Cc) == 0 && len(draft.Bcc) == 0 {
-		return fmt.Errorf("at least one recipient required (To, Cc, or Bcc)")
+	if err := validateDraft(draft); err != nil {
+		return "", fmt.Errorf("invalid draft: %w", err)
 	}
-
-	// Validate all email addresses
-	allAddresses := make([]core.EmailAddress, 0, len(draft.To)+len(draft.Cc)+len(draft.Bcc)+len(draft.ReplyTo))
-	allAddresses = append(allAddresses, draft.To...)
-	allAddresses = append(allAddresses, draft.Cc...)
-	allAddresses = append(allAddresses, draft.Bcc...)
-	allAddresses = append(allAddresses, draft.ReplyTo...)
-	for _, addr := range allAddresses {
-		if !isValidEmail(addr.Email) {
-			return fmt.Errorf("invalid email address: %s", addr.Email)
-		}
+	if err := core.ValidateSendOptions(opts); err != nil {
+		return "", fmt.Errorf("invalid send options: %w", err)
 	}
 
-	// Subject required
-	if strings.TrimSpace(draft.Subject) == "" {
-		return fmt.Errorf("subject is required")
+	if clock == nil {
+		clock = core.SystemClock
 	}
 
-	// Body required (text or HTML)
-	if draft.Body.Text == "" && draft.Body.HTML == "" {
-		return fmt.Errorf("email body required (text or html)")
+	// Build RFC 2822 message
+	var rawMessage string
+	if len(draft.Attachments) > 0 || (draft.Body.Text != "" && draft.Body.HTML != "") {
+		rawMessage, err = createMIMEMessage(draft, opts, clock)
+	} else {
+		rawMessage, err = buildSimpleMessage(draft, opts, clock)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to build message: %w", err)
 	}
 
-	// Validate attachments
-	for _, att := range draft.Attachments {
-		if att.Filename == "" {
-			return fmt.Errorf("attachment filename required")
-		}
-		if att.MimeType == "" {
-			return fmt.Errorf("attachment MIME type required for %s", att.Filename)
-		}
-		if len(att.Data) == 0 {
-			return fmt.Errorf("attachment %s has no data", att.Filename)
-		}
-		// Gmail limit is 25MB per attachment
-		if len(att.Data) > 25*1024*1024 {
-			return fmt.Errorf("attachment %s exceeds 25MB limit (size: %d bytes)", att.Filename, len(att.Data))
-		}
+	// Encode to base64url (Gmail format)
+	return encodeBase64URL([]byte(rawMessage)), nil
+}
+
+// SendLimits returns Gmail's size limits for outgoing messages:
+// 25MB for an attachment and for the whole message
+func SendLimits() core.SendLimits {
+	return core.SendLimits{
+		MaxAttachmentSize: 25 * 1024 * 1024,
+		MaxTotalSize:      25 * 1024 * 1024,
 	}
+}
 
-	return nil
+// validateDraft validates the draft before sending, applying Gmail's size limits
+func validateDraft(draft *core.Draft) error {
+	return core.ValidateDraft(draft, SendLimits())
 }
 
 // buildSimpleMessage builds a simple RFC 2822 message (no attachments, single content type)
 //
 //nolint:unparam // error return kept for consistency with createMIMEMessage
-func buildSimpleMessage(draft *core.Draft, opts *core.SendOptions) (string, error) {
+func buildSimpleMessage(draft *core.Draft, opts *core.SendOptions, clock core.Clock) (string, error) {
 	var buf bytes.Buffer
 
 	// Write headers
-	writeHeaders(&buf, draft, opts)
+	writeHeaders(&buf, draft, opts, clock)
 
 	// Determine content type
 	if draft.Body.HTML != "" {
@@ -144,14 +146,14 @@ func buildSimpleMessage(draft *core.Draft, opts *core.SendOptions) (string, erro
 }
 
 // createMIMEMessage creates a multipart MIME message
-func createMIMEMessage(draft *core.Draft, opts *core.SendOptions) (string, error) {
+func createMIMEMessage(draft *core.Draft, opts *core.SendOptions, clock core.Clock) (string, error) {
 	var buf bytes.Buffer
 
 	// Generate boundary for multipart
 	boundary := generateBoundary()
 
 	// Write headers
-	writeHeaders(&buf, draft, opts)
+	writeHeaders(&buf, draft, opts, clock)
 
 	// Multipart content type
 	buf.WriteString(fmt.Sprintf("Content-Type: multipart/mixed; boundary=\"%s\"\r\n", boundary))
@@ -198,53 +200,96 @@ func createMIMEMessage(draft *core.Draft, opts *core.SendOptions) (string, error
 }
 
 // writeHeaders writes RFC 2822 headers
-func writeHeaders(buf *bytes.Buffer, draft *core.Draft, opts *core.SendOptions) {
+func writeHeaders(buf *bytes.Buffer, draft *core.Draft, opts *core.SendOptions, clock core.Clock) {
 	// From (required by RFC 2822, but Gmail uses authenticated user)
 	buf.WriteString("From: me\r\n")
 
+	// Return-Path (Gmail keeps it only for verified send-as addresses)
+	if opts != nil && opts.ReturnPath != "" {
+		buf.WriteString("Return-Path: <" + opts.ReturnPath + ">\r\n")
+	}
+
 	// To
 	if len(draft.To) > 0 {
-		buf.WriteString("To: " + formatEmailAddresses(draft.To) + "\r\n")
+		writeHeader(buf, "To", formatEmailAddresses(draft.To))
 	}
 
 	// Cc
 	if len(draft.Cc) > 0 {
-		buf.WriteString("Cc: " + formatEmailAddresses(draft.Cc) + "\r\n")
+		writeHeader(buf, "Cc", formatEmailAddresses(draft.Cc))
 	}
 
 	// Bcc
 	if len(draft.Bcc) > 0 {
-		buf.WriteString("Bcc: " + formatEmailAddresses(draft.Bcc) + "\r\n")
+		writeHeader(buf, "Bcc", formatEmailAddresses(draft.Bcc))
 	}
 
 	// Reply-To
 	if len(draft.ReplyTo) > 0 {
-		buf.WriteString("Reply-To: " + formatEmailAddresses(draft.ReplyTo) + "\r\n")
+		writeHeader(buf, "Reply-To", formatEmailAddresses(draft.ReplyTo))
 	}
 
 	// Subject
-	buf.WriteString("Subject: " + encodeMIMEHeader(draft.Subject) + "\r\n")
+	writeHeader(buf, "Subject", encodeMIMEHeader(draft.Subject))
 
 	// Date
-	buf.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	now := clock.Now()
+	buf.WriteString("Date: " + now.Format(time.RFC1123Z) + "\r\n")
 
-	// Message-ID
-	buf.WriteString("Message-ID: " + generateMessageID() + "\r\n")
+	// Message-ID (stable when an idempotency key is provided)
+	messageID := generateMessageID(now)
+	if opts != nil && opts.IdempotencyKey != "" {
+		messageID = messageIDFromKey(opts.IdempotencyKey)
+	}
+	buf.WriteString("Message-ID: " + messageID + "\r\n")
 
 	// MIME-Version
 	buf.WriteString("MIME-Version: 1.0\r\n")
 
 	// Custom headers from draft
 	for key, value := range draft.Headers {
-		fmt.Fprintf(buf, "%s: %s\r\n", key, value)
+		writeHeader(buf, key, value)
 	}
 
 	// Custom headers from options
 	if opts != nil {
 		for key, value := range opts.CustomHeaders {
-			fmt.Fprintf(buf, "%s: %s\r\n", key, value)
+			writeHeader(buf, key, value)
+		}
+	}
+}
+
+// writeHeader writes a header line, folded to maxHeaderLineLen
+func writeHeader(buf *bytes.Buffer, name, value string) {
+	buf.WriteString(foldHeader(name + ": " + value))
+	buf.WriteString("\r\n")
+}
+
+// foldHeader folds a header line longer than maxHeaderLineLen (RFC 5322 section 2.2.3)
+// by inserting CRLF before existing spaces. The first word of the value always stays on
+// the header name's line, and words longer than the limit are never split
+func foldHeader(line string) string {
+	if len(line) <= maxHeaderLineLen {
+		return line
+	}
+
+	words := strings.Split(line, " ")
+	var b strings.Builder
+	b.WriteString(words[0])
+	lineLen := len(words[0])
+	for i, word := range words[1:] {
+		if i > 0 && word != "" && lineLen+1+len(word) > maxHeaderLineLen {
+			b.WriteString("\r\n ")
+			lineLen = 1
+		} else {
+			b.WriteString(" ")
+			lineLen++
 		}
+		b.WriteString(word)
+		lineLen += len(word)
 	}
+
+	return b.String()
 }
 
 // writeAlternativeBody writes multipart/alternative body (text + HTML)
@@ -313,6 +358,18 @@ func writeAttachment(writer *multipart.Writer, att *core.Attachment) error {
 	headers := textproto.MIMEHeader{}
 	headers.Set("Content-Type", att.MimeType+"; name=\""+mime.QEncoding.Encode("UTF-8", att.Filename)+"\"")
 	headers.Set("Content-Disposition", "attachment; filename=\""+mime.QEncoding.Encode("UTF-8", att.Filename)+"\"")
+
+	// RFC 2046 section 5.2.1 forbids base64 for message/rfc822, so attached messages are written as is
+	if strings.EqualFold(att.MimeType, mimeTypeMessage) {
+		headers.Set("Content-Transfer-Encoding", messageTransferEncoding(att.Data))
+		part, err := writer.CreatePart(headers)
+		if err != nil {
+			return err
+		}
+		_, err = part.Write(att.Data)
+		return err
+	}
+
 	headers.Set("Content-Transfer-Encoding", "base64")
 
 	part, err := writer.CreatePart(headers)
@@ -337,6 +394,16 @@ func writeAttachment(writer *multipart.Writer, att *core.Attachment) error {
 	return nil
 }
 
+// messageTransferEncoding returns "7bit" for an attached message that is pure ASCII, "8bit" otherwise
+func messageTransferEncoding(data []byte) string {
+	for _, b := range data {
+		if b >= 0x80 {
+			return "8bit"
+		}
+	}
+	return "7bit"
+}
+
 // formatEmailAddress formats an EmailAddress to RFC 2822 format
 func formatEmailAddress(addr core.EmailAddress) string {
 	if addr.Name == "" {
@@ -370,7 +437,9 @@ func encodeBase64URL(data []byte) string {
 	return base64.RawURLEncoding.EncodeToString(data)
 }
 
-// encodeMIMEHeader encodes a header value using MIME Q-encoding if needed
+// encodeMIMEHeader encodes a header value using MIME Q-encoding if needed.
+// Encoded values are split into space-separated encoded-words short enough to
+// share a line with the header name, so foldHeader folds on encoded-word boundaries
 func encodeMIMEHeader(value string) string {
 	// Check if encoding is needed (non-ASCII characters)
 	needsEncoding := false
@@ -381,45 +450,83 @@ func encodeMIMEHeader(value string) string {
 		}
 	}
 
-	if needsEncoding {
-		return mime.QEncoding.Encode("UTF-8", value)
+	if !needsEncoding {
+		return value
 	}
 
-	return value
+	var words []string
+	var chunk strings.Builder
+	for _, r := range value {
+		encoded := qEncode(string(r))
+		if chunk.Len() > 0 && len(encodedWordPrefix)+chunk.Len()+len(encoded)+len(encodedWordSuffix) > maxEncodedWordLen {
+			words = append(words, encodedWordPrefix+chunk.String()+encodedWordSuffix)
+			chunk.Reset()
+		}
+		chunk.WriteString(encoded)
+	}
+	words = append(words, encodedWordPrefix+chunk.String()+encodedWordSuffix)
+
+	return strings.Join(words, " ")
+}
+
+// qEncode applies RFC 2047 Q-encoding to s, without the encoded-word delimiters
+func qEncode(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == ' ':
+			b.WriteByte('_')
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', strings.IndexByte("!*+-/", c) >= 0:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "=%02X", c)
+		}
+	}
+	return b.String()
 }
 
-// encodeQuotedPrintable encodes text using quoted-printable encoding
+// encodeQuotedPrintable encodes text using quoted-printable encoding.
+// Line endings are normalized to CRLF first, so callers may pass bodies with lone \n or \r
 func encodeQuotedPrintable(text string) string {
 	var buf bytes.Buffer
 	writer := quotedprintable.NewWriter(&buf)
-	_, _ = writer.Write([]byte(text))
+	_, _ = writer.Write([]byte(normalizeLineEndings(text)))
 	_ = writer.Close()
 	return buf.String()
 }
 
-// generateMessageID generates a unique RFC 2822 Message-ID
-func generateMessageID() string {
+// normalizeLineEndings converts lone \n and \r line endings to CRLF
+func normalizeLineEndings(text string) string {
+	if !strings.ContainsAny(text, "\r\n") {
+		return text
+	}
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+	return strings.ReplaceAll(text, "\n", "\r\n")
+}
+
+// generateMessageID generates a unique RFC 2822 Message-ID stamped with now
+func generateMessageID(now time.Time) string {
 	// Generate random bytes
 	b := make([]byte, 16)
-	_, _ = rand.Read(b)
+	_, _ = io.ReadFull(randReader, b)
 
 	// Format as Message-ID
 	return fmt.Sprintf("<%x.%d@mailbridge.local>",
 		b,
-		time.Now().UnixNano())
+		now.UnixNano())
+}
+
+// messageIDFromKey derives a deterministic RFC 2822 Message-ID from an idempotency key
+func messageIDFromKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return fmt.Sprintf("<%x@mailbridge.local>", sum[:16])
 }
 
 // generateBoundary generates a unique MIME boundary
 func generateBoundary() string {
 	b := make([]byte, 16)
-	_, _ = rand.Read(b)
+	_, _ = io.ReadFull(randReader, b)
 	return fmt.Sprintf("==boundary_%x==", b)
 }
-
-// isValidEmail validates an email address format
-func isValidEmail(email string) bool {
-	if email == "" {
-		return false
-	}
-	return emailRegex.MatchString(email)
-}
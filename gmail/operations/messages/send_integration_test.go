@@ -40,7 +40,7 @@ func TestSendMessage_Success(t *testing.T) {
 	}
 
 	// Send message
-	response, err := SendMessage(ctx, mockService, draft, nil)
+	response, err := SendMessage(ctx, mockService, draft, nil, nil)
 
 	// Assert
 	require.NoError(t, err)
@@ -76,7 +76,7 @@ func TestSendMessage_APIError(t *testing.T) {
 		Body:    core.EmailBody{Text: "Hello"},
 	}
 
-	response, err := SendMessage(ctx, mockService, draft, nil)
+	response, err := SendMessage(ctx, mockService, draft, nil, nil)
 
 	assert.Error(t, err)
 	assert.Nil(t, response)
@@ -118,7 +118,7 @@ func TestSendMessage_WithAttachments(t *testing.T) {
 		},
 	}
 
-	response, err := SendMessage(ctx, mockService, draft, nil)
+	response, err := SendMessage(ctx, mockService, draft, nil, nil)
 
 	require.NoError(t, err)
 	assert.NotNil(t, response)
@@ -152,7 +152,7 @@ func TestSendMessage_HTMLEmail(t *testing.T) {
 		Body:    core.EmailBody{HTML: "<p>Hello <b>World</b></p>"},
 	}
 
-	response, err := SendMessage(ctx, mockService, draft, nil)
+	response, err := SendMessage(ctx, mockService, draft, nil, nil)
 
 	require.NoError(t, err)
 	assert.NotNil(t, response)
@@ -188,7 +188,7 @@ func TestSendMessage_WithCCBCC(t *testing.T) {
 		Body:    core.EmailBody{Text: "Hello"},
 	}
 
-	response, err := SendMessage(ctx, mockService, draft, nil)
+	response, err := SendMessage(ctx, mockService, draft, nil, nil)
 
 	require.NoError(t, err)
 	assert.NotNil(t, response)
@@ -240,7 +240,7 @@ func TestSendMessage_ValidationErrors(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			response, err := SendMessage(ctx, mockService, tt.draft, nil)
+			response, err := SendMessage(ctx, mockService, tt.draft, nil, nil)
 
 			assert.Error(t, err)
 			assert.Nil(t, response)
@@ -283,7 +283,7 @@ func TestSendMessage_WithCustomHeaders(t *testing.T) {
 		},
 	}
 
-	response, err := SendMessage(ctx, mockService, draft, opts)
+	response, err := SendMessage(ctx, mockService, draft, opts, nil)
 
 	require.NoError(t, err)
 	assert.NotNil(t, response)
@@ -291,6 +291,21 @@ func TestSendMessage_WithCustomHeaders(t *testing.T) {
 	mockService.AssertExpectations(t)
 }
 
+func TestSendMessage_InvalidReturnPath(t *testing.T) {
+	mockService := &gmailtest.MockGmailService{}
+	draft := &core.Draft{
+		To:      []core.EmailAddress{{Email: "test@example.com"}},
+		Subject: "Test",
+		Body:    core.EmailBody{Text: "Hello"},
+	}
+
+	response, err := SendMessage(context.Background(), mockService, draft, &core.SendOptions{ReturnPath: "not-an-address"}, nil)
+
+	assert.Nil(t, response)
+	assert.ErrorContains(t, err, "invalid return path: not-an-address")
+	mockService.AssertNotCalled(t, "GetUsersService")
+}
+
 func TestSendMessage_TextAndHTML(t *testing.T) {
 	ctx := context.Background()
 
@@ -320,7 +335,7 @@ func TestSendMessage_TextAndHTML(t *testing.T) {
 		},
 	}
 
-	response, err := SendMessage(ctx, mockService, draft, nil)
+	response, err := SendMessage(ctx, mockService, draft, nil, nil)
 
 	require.NoError(t, err)
 	assert.NotNil(t, response)
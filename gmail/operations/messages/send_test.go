@@ -1,41 +1,17 @@
 package messages
 
 import (
+	"fmt"
+	"mime"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/danielrivera/mailbridge-go/core"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
-func TestIsValidEmail(t *testing.T) {
-	tests := []struct {
-		name  string
-		email string
-		valid bool
-	}{
-		{"valid simple", "test@example.com", true},
-		{"valid with plus", "user+tag@example.com", true},
-		{"valid with dash", "first-last@example.com", true},
-		{"valid with dot", "first.last@example.com", true},
-		{"valid subdomain", "user@mail.example.com", true},
-		{"invalid no at", "userexample.com", false},
-		{"invalid no domain", "user@", false},
-		{"invalid no user", "@example.com", false},
-		{"invalid no tld", "user@example", false},
-		{"invalid spaces", "user @example.com", false},
-		{"empty string", "", false},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := isValidEmail(tt.email)
-			assert.Equal(t, tt.valid, result, "Email: %s", tt.email)
-		})
-	}
-}
-
 func TestFormatEmailAddress(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -175,9 +151,162 @@ func TestEncodeMIMEHeader(t *testing.T) {
 	}
 }
 
+// headerLines returns the raw lines of header name in msg, including continuation lines
+func headerLines(t *testing.T, msg, name string) []string {
+	t.Helper()
+	head, _, _ := strings.Cut(msg, "\r\n\r\n")
+	var lines []string
+	for _, line := range strings.Split(head, "\r\n") {
+		switch {
+		case strings.HasPrefix(line, name+": "):
+			lines = append(lines, line)
+		case len(lines) > 0 && strings.HasPrefix(line, " "):
+			lines = append(lines, line)
+		case len(lines) > 0:
+			return lines
+		}
+	}
+	require.NotEmpty(t, lines, "header %s not found", name)
+	return lines
+}
+
+func TestWriteHeaders_FoldsLongRecipientList(t *testing.T) {
+	var to []core.EmailAddress
+	for i := 0; i < 10; i++ {
+		to = append(to, core.EmailAddress{Email: fmt.Sprintf("recipient%d@example.com", i), Name: fmt.Sprintf("Recipient Number %d", i)})
+	}
+	draft := &core.Draft{To: to, Subject: "Hello", Body: core.EmailBody{Text: "Hi"}}
+
+	msg, err := buildSimpleMessage(draft, nil, core.SystemClock)
+	require.NoError(t, err)
+
+	lines := headerLines(t, msg, "To")
+	assert.Greater(t, len(lines), 1, "long To header should be folded")
+	for _, line := range lines {
+		assert.LessOrEqual(t, len(line), maxHeaderLineLen)
+	}
+	for _, line := range lines[1:] {
+		assert.True(t, strings.HasPrefix(line, " "), "continuation lines start with whitespace")
+	}
+
+	// Unfolding restores the original value
+	assert.Equal(t, "To: "+formatEmailAddresses(to), strings.Join(lines, ""))
+}
+
+func TestWriteHeaders_ReturnPath(t *testing.T) {
+	draft := &core.Draft{To: []core.EmailAddress{{Email: "test@example.com"}}, Subject: "Hello", Body: core.EmailBody{Text: "Hi"}}
+
+	msg, err := buildSimpleMessage(draft, &core.SendOptions{ReturnPath: "bounces@example.com"}, core.SystemClock)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Return-Path: <bounces@example.com>"}, headerLines(t, msg, "Return-Path"))
+
+	msg, err = buildSimpleMessage(draft, nil, core.SystemClock)
+	require.NoError(t, err)
+	assert.NotContains(t, msg, "Return-Path")
+}
+
+func TestWriteHeaders_FoldsLongNonASCIISubject(t *testing.T) {
+	subject := strings.Repeat("Confirmación de envío número ñandú ", 4)
+	draft := &core.Draft{
+		To:      []core.EmailAddress{{Email: "test@example.com"}},
+		Subject: subject,
+		Body:    core.EmailBody{Text: "Hi"},
+	}
+
+	msg, err := buildSimpleMessage(draft, nil, core.SystemClock)
+	require.NoError(t, err)
+
+	lines := headerLines(t, msg, "Subject")
+	assert.Greater(t, len(lines), 1, "long Subject header should be folded")
+	for i, line := range lines {
+		assert.LessOrEqual(t, len(line), maxHeaderLineLen)
+		word := strings.TrimPrefix(line, " ")
+		if i == 0 {
+			word = strings.TrimPrefix(line, "Subject: ")
+		}
+		// Every line holds whole encoded-words
+		for _, w := range strings.Fields(word) {
+			assert.True(t, strings.HasPrefix(w, "=?UTF-8?q?") && strings.HasSuffix(w, "?="), "not an encoded-word: %q", w)
+		}
+	}
+
+	decoded, err := new(mime.WordDecoder).DecodeHeader(strings.TrimPrefix(strings.Join(lines, ""), "Subject: "))
+	require.NoError(t, err)
+	assert.Equal(t, subject, decoded)
+}
+
+func TestFoldHeader_ShortLineUnchanged(t *testing.T) {
+	assert.Equal(t, "Subject: Hello", foldHeader("Subject: Hello"))
+}
+
+func TestNormalizeLineEndings(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"no line breaks", "hello", "hello"},
+		{"lone LF", "a\nb\n", "a\r\nb\r\n"},
+		{"already CRLF", "a\r\nb", "a\r\nb"},
+		{"lone CR", "a\rb", "a\r\nb"},
+		{"mixed", "a\nb\r\nc\rd\n\n", "a\r\nb\r\nc\r\nd\r\n\r\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, normalizeLineEndings(tt.input))
+		})
+	}
+}
+
+// assertCRLF asserts that every line break in msg is CRLF
+func assertCRLF(t *testing.T, msg string) {
+	t.Helper()
+	for i := 0; i < len(msg); i++ {
+		switch msg[i] {
+		case '\n':
+			require.True(t, i > 0 && msg[i-1] == '\r', "lone LF at offset %d", i)
+		case '\r':
+			require.True(t, i+1 < len(msg) && msg[i+1] == '\n', "lone CR at offset %d", i)
+		}
+	}
+}
+
+func TestBuildSimpleMessage_NormalizesLineEndings(t *testing.T) {
+	draft := &core.Draft{
+		To:      []core.EmailAddress{{Email: "test@example.com"}},
+		Subject: "Line endings",
+		Body:    core.EmailBody{Text: "first\nsecond\r\nthird\rfourth\n"},
+	}
+
+	msg, err := buildSimpleMessage(draft, nil, core.SystemClock)
+	require.NoError(t, err)
+
+	assertCRLF(t, msg)
+	assert.Contains(t, msg, "first\r\nsecond\r\nthird\r\nfourth\r\n")
+}
+
+func TestCreateMIMEMessage_NormalizesLineEndings(t *testing.T) {
+	draft := &core.Draft{
+		To:      []core.EmailAddress{{Email: "test@example.com"}},
+		Subject: "Line endings",
+		Body:    core.EmailBody{Text: "text\nbody\r\n", HTML: "<p>html</p>\n<p>body</p>\r"},
+		Attachments: []core.Attachment{
+			{Filename: "big.bin", MimeType: "application/octet-stream", Data: []byte(strings.Repeat("x\n", 200))},
+		},
+	}
+
+	msg, err := createMIMEMessage(draft, nil, core.SystemClock)
+	require.NoError(t, err)
+
+	assertCRLF(t, msg)
+	assert.Contains(t, msg, "text\r\nbody\r\n")
+	assert.Contains(t, msg, "<p>html</p>\r\n<p>body</p>\r\n")
+}
+
 func TestGenerateMessageID(t *testing.T) {
-	id1 := generateMessageID()
-	id2 := generateMessageID()
+	id1 := generateMessageID(time.Now())
+	id2 := generateMessageID(time.Now())
 
 	// Should start and end with angle brackets
 	assert.True(t, strings.HasPrefix(id1, "<"))
@@ -190,6 +319,110 @@ func TestGenerateMessageID(t *testing.T) {
 	assert.Contains(t, id1, "@")
 }
 
+func TestBuildSimpleMessage_FakeClock(t *testing.T) {
+	draft := &core.Draft{
+		To:      []core.EmailAddress{{Email: "test@example.com"}},
+		Subject: "Test Subject",
+		Body:    core.EmailBody{Text: "Hello World"},
+	}
+	now := time.Date(2024, 3, 15, 9, 30, 0, 0, time.UTC)
+	clock := core.NewFakeClock(now)
+
+	msg, err := buildSimpleMessage(draft, nil, clock)
+	require.NoError(t, err)
+
+	assert.Contains(t, msg, "Date: Fri, 15 Mar 2024 09:30:00 +0000\r\n")
+	assert.Contains(t, msg, fmt.Sprintf(".%d@mailbridge.local>", now.UnixNano()))
+}
+
+// fixedReader is an io.Reader that returns the same byte forever
+type fixedReader byte
+
+func (r fixedReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = byte(r)
+	}
+	return len(p), nil
+}
+
+// useFixedRandom replaces the random source with a fixed one for the duration of the test
+func useFixedRandom(t *testing.T) {
+	t.Helper()
+	original := randReader
+	randReader = fixedReader(0x2a)
+	t.Cleanup(func() { randReader = original })
+}
+
+func TestBuildSimpleMessage_Deterministic(t *testing.T) {
+	useFixedRandom(t)
+	clock := core.NewFakeClock(time.Date(2024, 3, 15, 9, 30, 0, 0, time.UTC))
+	draft := &core.Draft{
+		To:      []core.EmailAddress{{Email: "test@example.com", Name: "Test User"}},
+		Subject: "Snapshot",
+		Body:    core.EmailBody{Text: "Hello World"},
+	}
+
+	msg1, err := buildSimpleMessage(draft, nil, clock)
+	require.NoError(t, err)
+	msg2, err := buildSimpleMessage(draft, nil, clock)
+	require.NoError(t, err)
+
+	assert.Equal(t, msg1, msg2)
+	assert.Contains(t, msg1, "Message-ID: <2a2a2a2a2a2a2a2a2a2a2a2a2a2a2a2a.")
+}
+
+func TestCreateMIMEMessage_Deterministic(t *testing.T) {
+	useFixedRandom(t)
+	clock := core.NewFakeClock(time.Date(2024, 3, 15, 9, 30, 0, 0, time.UTC))
+	draft := &core.Draft{
+		To:      []core.EmailAddress{{Email: "test@example.com"}},
+		Subject: "Snapshot",
+		Body:    core.EmailBody{Text: "Hello", HTML: "<p>Hello</p>"},
+		Attachments: []core.Attachment{
+			{Filename: "a.txt", MimeType: "text/plain", Data: []byte("data")},
+		},
+	}
+
+	msg1, err := createMIMEMessage(draft, nil, clock)
+	require.NoError(t, err)
+	msg2, err := createMIMEMessage(draft, nil, clock)
+	require.NoError(t, err)
+
+	assert.Equal(t, msg1, msg2)
+	assert.Contains(t, msg1, "boundary=\"==boundary_2a2a2a2a2a2a2a2a2a2a2a2a2a2a2a2a==\"")
+}
+
+func TestMessageIDFromKey(t *testing.T) {
+	id1 := messageIDFromKey("order-42")
+	id2 := messageIDFromKey("order-42")
+	id3 := messageIDFromKey("order-43")
+
+	// Same key produces the same Message-ID
+	assert.Equal(t, id1, id2)
+	assert.NotEqual(t, id1, id3)
+
+	assert.True(t, strings.HasPrefix(id1, "<"))
+	assert.True(t, strings.HasSuffix(id1, "@mailbridge.local>"))
+}
+
+func TestBuildSimpleMessage_IdempotencyKey(t *testing.T) {
+	draft := &core.Draft{
+		To:      []core.EmailAddress{{Email: "test@example.com"}},
+		Subject: "Test Subject",
+		Body:    core.EmailBody{Text: "Hello World"},
+	}
+	opts := &core.SendOptions{IdempotencyKey: "order-42"}
+
+	msg1, err := buildSimpleMessage(draft, opts, core.SystemClock)
+	require.NoError(t, err)
+	msg2, err := buildSimpleMessage(draft, opts, core.SystemClock)
+	require.NoError(t, err)
+
+	expected := "Message-ID: " + messageIDFromKey("order-42") + "\r\n"
+	assert.Contains(t, msg1, expected)
+	assert.Contains(t, msg2, expected)
+}
+
 func TestGenerateBoundary(t *testing.T) {
 	b1 := generateBoundary()
 	b2 := generateBoundary()
@@ -201,181 +434,31 @@ func TestGenerateBoundary(t *testing.T) {
 	assert.NotEqual(t, b1, b2)
 }
 
-func TestValidateDraft(t *testing.T) {
-	tests := []struct {
-		name    string
-		draft   *core.Draft
-		wantErr bool
-		errMsg  string
-	}{
-		{
-			name: "valid draft - To only",
-			draft: &core.Draft{
-				To:      []core.EmailAddress{{Email: "test@example.com"}},
-				Subject: "Test",
-				Body:    core.EmailBody{Text: "Hello"},
-			},
-			wantErr: false,
-		},
-		{
-			name: "valid draft - Cc only",
-			draft: &core.Draft{
-				Cc:      []core.EmailAddress{{Email: "test@example.com"}},
-				Subject: "Test",
-				Body:    core.EmailBody{Text: "Hello"},
-			},
-			wantErr: false,
-		},
-		{
-			name: "valid draft - Bcc only",
-			draft: &core.Draft{
-				Bcc:     []core.EmailAddress{{Email: "test@example.com"}},
-				Subject: "Test",
-				Body:    core.EmailBody{Text: "Hello"},
-			},
-			wantErr: false,
-		},
-		{
-			name: "valid draft - HTML body",
-			draft: &core.Draft{
-				To:      []core.EmailAddress{{Email: "test@example.com"}},
-				Subject: "Test",
-				Body:    core.EmailBody{HTML: "<p>Hello</p>"},
-			},
-			wantErr: false,
-		},
-		{
-			name: "valid draft - with attachment",
-			draft: &core.Draft{
-				To:      []core.EmailAddress{{Email: "test@example.com"}},
-				Subject: "Test",
-				Body:    core.EmailBody{Text: "Hello"},
-				Attachments: []core.Attachment{
-					{Filename: "test.txt", MimeType: "text/plain", Data: []byte("data")},
-				},
-			},
-			wantErr: false,
-		},
-		{
-			name:    "nil draft",
-			draft:   nil,
-			wantErr: true,
-			errMsg:  "draft is nil",
-		},
-		{
-			name: "no recipients",
-			draft: &core.Draft{
-				Subject: "Test",
-				Body:    core.EmailBody{Text: "Hello"},
-			},
-			wantErr: true,
-			errMsg:  "at least one recipient required",
-		},
-		{
-			name: "invalid email address",
-			draft: &core.Draft{
-				To:      []core.EmailAddress{{Email: "invalid-email"}},
-				Subject: "Test",
-				Body:    core.EmailBody{Text: "Hello"},
-			},
-			wantErr: true,
-			errMsg:  "invalid email address",
-		},
-		{
-			name: "empty subject",
-			draft: &core.Draft{
-				To:      []core.EmailAddress{{Email: "test@example.com"}},
-				Subject: "",
-				Body:    core.EmailBody{Text: "Hello"},
-			},
-			wantErr: true,
-			errMsg:  "subject is required",
-		},
-		{
-			name: "whitespace-only subject",
-			draft: &core.Draft{
-				To:      []core.EmailAddress{{Email: "test@example.com"}},
-				Subject: "   ",
-				Body:    core.EmailBody{Text: "Hello"},
-			},
-			wantErr: true,
-			errMsg:  "subject is required",
-		},
-		{
-			name: "empty body",
-			draft: &core.Draft{
-				To:      []core.EmailAddress{{Email: "test@example.com"}},
-				Subject: "Test",
-				Body:    core.EmailBody{},
-			},
-			wantErr: true,
-			errMsg:  "email body required",
-		},
-		{
-			name: "attachment without filename",
-			draft: &core.Draft{
-				To:      []core.EmailAddress{{Email: "test@example.com"}},
-				Subject: "Test",
-				Body:    core.EmailBody{Text: "Hello"},
-				Attachments: []core.Attachment{
-					{Filename: "", MimeType: "text/plain", Data: []byte("data")},
-				},
-			},
-			wantErr: true,
-			errMsg:  "attachment filename required",
-		},
-		{
-			name: "attachment without mime type",
-			draft: &core.Draft{
-				To:      []core.EmailAddress{{Email: "test@example.com"}},
-				Subject: "Test",
-				Body:    core.EmailBody{Text: "Hello"},
-				Attachments: []core.Attachment{
-					{Filename: "test.txt", MimeType: "", Data: []byte("data")},
-				},
-			},
-			wantErr: true,
-			errMsg:  "attachment MIME type required",
-		},
-		{
-			name: "attachment without data",
-			draft: &core.Draft{
-				To:      []core.EmailAddress{{Email: "test@example.com"}},
-				Subject: "Test",
-				Body:    core.EmailBody{Text: "Hello"},
-				Attachments: []core.Attachment{
-					{Filename: "test.txt", MimeType: "text/plain", Data: []byte{}},
-				},
-			},
-			wantErr: true,
-			errMsg:  "has no data",
-		},
-		{
-			name: "attachment too large",
-			draft: &core.Draft{
-				To:      []core.EmailAddress{{Email: "test@example.com"}},
-				Subject: "Test",
-				Body:    core.EmailBody{Text: "Hello"},
-				Attachments: []core.Attachment{
-					{Filename: "huge.bin", MimeType: "application/octet-stream", Data: make([]byte, 26*1024*1024)},
-				},
-			},
-			wantErr: true,
-			errMsg:  "exceeds 25MB limit",
-		},
+func TestValidateDraft_GmailLimits(t *testing.T) {
+	const mb = 1024 * 1024
+	newDraft := func(sizes ...int) *core.Draft {
+		draft := &core.Draft{
+			To:      []core.EmailAddress{{Email: "test@example.com"}},
+			Subject: "Test",
+			Body:    core.EmailBody{Text: "Hello"},
+		}
+		for _, size := range sizes {
+			draft.Attachments = append(draft.Attachments, core.Attachment{
+				Filename: "file.bin", MimeType: "application/octet-stream", Data: make([]byte, size),
+			})
+		}
+		return draft
 	}
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			err := validateDraft(tt.draft)
-			if tt.wantErr {
-				require.Error(t, err)
-				assert.Contains(t, err.Error(), tt.errMsg)
-			} else {
-				assert.NoError(t, err)
-			}
-		})
-	}
+	err := validateDraft(newDraft(26 * mb))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "exceeds 25MB limit")
+
+	err = validateDraft(newDraft(13*mb, 13*mb))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "exceeds 25MB total size limit")
+
+	assert.NoError(t, validateDraft(newDraft(12*mb, 12*mb)))
 }
 
 func TestBuildSimpleMessage(t *testing.T) {
@@ -474,7 +557,7 @@ func TestBuildSimpleMessage(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result, err := buildSimpleMessage(tt.draft, tt.opts)
+			result, err := buildSimpleMessage(tt.draft, tt.opts, core.SystemClock)
 			require.NoError(t, err)
 			assert.NotEmpty(t, result)
 
@@ -563,7 +646,7 @@ func TestCreateMIMEMessage(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result, err := createMIMEMessage(tt.draft, nil)
+			result, err := createMIMEMessage(tt.draft, nil, core.SystemClock)
 			require.NoError(t, err)
 			assert.NotEmpty(t, result)
 
@@ -0,0 +1,38 @@
+package messages
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/danielrivera/mailbridge-go/core"
+	"github.com/danielrivera/mailbridge-go/gmail/internal"
+	"github.com/danielrivera/mailbridge-go/gmail/operations"
+	"google.golang.org/api/gmail/v1"
+)
+
+// GetThreadMessages retrieves all messages in a thread, fully hydrated and ordered by date (oldest first)
+func GetThreadMessages(ctx context.Context, service internal.GmailService, threadID string) ([]*core.Email, error) {
+	threadsService := service.GetUsersService().GetThreadsService()
+	thread, err := threadsService.Get(operations.UserIDMe, threadID).Format("full").Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get thread: %w", err)
+	}
+
+	emails := make([]*core.Email, 0, len(thread.Messages))
+	for _, msg := range thread.Messages {
+		emails = append(emails, convertMessage(msg))
+	}
+
+	sort.SliceStable(emails, func(i, j int) bool {
+		return emails[i].Date.Before(emails[j].Date)
+	})
+
+	return emails, nil
+}
+
+// ConvertMessage converts a Gmail message to the normalized Email type, for operations
+// outside this package that receive full messages (e.g. inside a thread)
+func ConvertMessage(msg *gmail.Message) *core.Email {
+	return convertMessage(msg)
+}
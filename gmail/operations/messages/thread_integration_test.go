@@ -0,0 +1,76 @@
+package messages
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	gmailtest "github.com/danielrivera/mailbridge-go/gmail/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/gmail/v1"
+)
+
+func TestGetThreadMessages_OrderedByDate(t *testing.T) {
+	mockGmailService, mockThreadsService := setupMockThreadsService()
+	mockThreadsGetCall := &gmailtest.MockThreadsGetCall{}
+
+	newMessage := func(id, date string) *gmail.Message {
+		return &gmail.Message{
+			Id:       id,
+			ThreadId: "thread-1",
+			Payload: &gmail.MessagePart{
+				Headers: []*gmail.MessagePartHeader{
+					{Name: "Date", Value: date},
+				},
+			},
+		}
+	}
+
+	mockThreadsService.On("Get", "me", "thread-1").Return(mockThreadsGetCall)
+	mockThreadsGetCall.On("Format", "full").Return(mockThreadsGetCall)
+	mockThreadsGetCall.On("Context", context.Background()).Return(mockThreadsGetCall)
+	mockThreadsGetCall.On("Do").Return(&gmail.Thread{
+		Id: "thread-1",
+		Messages: []*gmail.Message{
+			newMessage("msg-3", "Wed, 3 Jan 2024 10:00:00 +0000"),
+			newMessage("msg-1", "Mon, 1 Jan 2024 10:00:00 +0000"),
+			newMessage("msg-2", "Tue, 2 Jan 2024 10:00:00 +0000"),
+		},
+	}, nil)
+
+	emails, err := GetThreadMessages(context.Background(), mockGmailService, "thread-1")
+
+	require.NoError(t, err)
+	require.Len(t, emails, 3)
+	assert.Equal(t, "msg-1", emails[0].ID)
+	assert.Equal(t, "msg-2", emails[1].ID)
+	assert.Equal(t, "msg-3", emails[2].ID)
+	assert.Equal(t, "thread-1", emails[0].ThreadID)
+}
+
+func TestGetThreadMessages_APIError(t *testing.T) {
+	mockGmailService, mockThreadsService := setupMockThreadsService()
+	mockThreadsGetCall := &gmailtest.MockThreadsGetCall{}
+
+	mockThreadsService.On("Get", "me", "thread-1").Return(mockThreadsGetCall)
+	mockThreadsGetCall.On("Format", "full").Return(mockThreadsGetCall)
+	mockThreadsGetCall.On("Context", context.Background()).Return(mockThreadsGetCall)
+	mockThreadsGetCall.On("Do").Return(nil, errors.New("thread not found"))
+
+	_, err := GetThreadMessages(context.Background(), mockGmailService, "thread-1")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to get thread")
+}
+
+// Helper function to setup mock threads service
+func setupMockThreadsService() (*gmailtest.MockGmailService, *gmailtest.MockThreadsService) {
+	mockGmailService := &gmailtest.MockGmailService{}
+	mockUsersService := &gmailtest.MockUsersService{}
+	mockThreadsService := &gmailtest.MockThreadsService{}
+
+	mockGmailService.On("GetUsersService").Return(mockUsersService)
+	mockUsersService.On("GetThreadsService").Return(mockThreadsService)
+
+	return mockGmailService, mockThreadsService
+}
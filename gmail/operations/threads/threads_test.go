@@ -0,0 +1,158 @@
+package threads
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/danielrivera/mailbridge-go/core"
+	gmailtest "github.com/danielrivera/mailbridge-go/gmail/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/gmail/v1"
+)
+
+func TestListThreads_Success(t *testing.T) {
+	mockGmailService, mockThreadsService := setupMockThreadsService()
+	mockThreadsListCall := &gmailtest.MockThreadsListCall{}
+	hasAttachments := true
+
+	mockThreadsService.On("List", "me").Return(mockThreadsListCall)
+	mockThreadsListCall.On("MaxResults", int64(2)).Return(mockThreadsListCall)
+	mockThreadsListCall.On("PageToken", "page-1").Return(mockThreadsListCall)
+	mockThreadsListCall.On("Q", "from:boss has:attachment").Return(mockThreadsListCall)
+	mockThreadsListCall.On("LabelIds", []string{"INBOX"}).Return(mockThreadsListCall)
+	mockThreadsListCall.On("Context", context.Background()).Return(mockThreadsListCall)
+	mockThreadsListCall.On("Do").Return(&gmail.ListThreadsResponse{
+		Threads: []*gmail.Thread{
+			{Id: "thread-1", Snippet: "Latest reply", HistoryId: 1234},
+			{Id: "thread-2", Snippet: "Hello"},
+		},
+		NextPageToken:      "page-2",
+		ResultSizeEstimate: 10,
+	}, nil)
+
+	resp, err := ListThreads(context.Background(), mockGmailService, &core.ListOptions{
+		MaxResults:     2,
+		PageToken:      "page-1",
+		Query:          "from:boss",
+		Labels:         []string{"INBOX"},
+		HasAttachments: &hasAttachments,
+	})
+
+	require.NoError(t, err)
+	require.Len(t, resp.Threads, 2)
+	assert.Equal(t, core.Thread{ID: "thread-1", Snippet: "Latest reply", HistoryID: "1234"}, *resp.Threads[0])
+	assert.Equal(t, core.Thread{ID: "thread-2", Snippet: "Hello"}, *resp.Threads[1])
+	assert.Equal(t, "page-2", resp.NextPageToken)
+	assert.Equal(t, int64(10), resp.TotalCount)
+	mockThreadsListCall.AssertExpectations(t)
+}
+
+func TestListThreads_NilOptions(t *testing.T) {
+	mockGmailService, mockThreadsService := setupMockThreadsService()
+	mockThreadsListCall := &gmailtest.MockThreadsListCall{}
+
+	mockThreadsService.On("List", "me").Return(mockThreadsListCall)
+	mockThreadsListCall.On("Context", context.Background()).Return(mockThreadsListCall)
+	mockThreadsListCall.On("Do").Return(&gmail.ListThreadsResponse{}, nil)
+
+	resp, err := ListThreads(context.Background(), mockGmailService, nil)
+
+	require.NoError(t, err)
+	assert.Empty(t, resp.Threads)
+	mockThreadsListCall.AssertNotCalled(t, "MaxResults")
+	mockThreadsListCall.AssertNotCalled(t, "Q")
+}
+
+func TestGetThread_OrderedByDate(t *testing.T) {
+	mockGmailService, mockThreadsService := setupMockThreadsService()
+	mockThreadsGetCall := &gmailtest.MockThreadsGetCall{}
+
+	newMessage := func(id, date, snippet string) *gmail.Message {
+		return &gmail.Message{
+			Id:       id,
+			ThreadId: "thread-1",
+			Snippet:  snippet,
+			Payload: &gmail.MessagePart{
+				Headers: []*gmail.MessagePartHeader{
+					{Name: "Date", Value: date},
+				},
+			},
+		}
+	}
+
+	mockThreadsService.On("Get", "me", "thread-1").Return(mockThreadsGetCall)
+	mockThreadsGetCall.On("Format", "full").Return(mockThreadsGetCall)
+	mockThreadsGetCall.On("Context", context.Background()).Return(mockThreadsGetCall)
+	mockThreadsGetCall.On("Do").Return(&gmail.Thread{
+		Id:        "thread-1",
+		HistoryId: 99,
+		Messages: []*gmail.Message{
+			newMessage("msg-3", "Wed, 3 Jan 2024 10:00:00 +0000", "third"),
+			newMessage("msg-1", "Mon, 1 Jan 2024 10:00:00 +0000", "first"),
+			newMessage("msg-2", "Tue, 2 Jan 2024 10:00:00 +0000", "second"),
+		},
+	}, nil)
+
+	thread, err := GetThread(context.Background(), mockGmailService, "thread-1")
+
+	require.NoError(t, err)
+	assert.Equal(t, "thread-1", thread.ID)
+	assert.Equal(t, "99", thread.HistoryID)
+	assert.Equal(t, "third", thread.Snippet)
+	require.Len(t, thread.Messages, 3)
+	assert.Equal(t, "msg-1", thread.Messages[0].ID)
+	assert.Equal(t, "msg-2", thread.Messages[1].ID)
+	assert.Equal(t, "msg-3", thread.Messages[2].ID)
+	assert.Equal(t, "thread-1", thread.Messages[0].ThreadID)
+}
+
+func TestThreadsAPIErrors(t *testing.T) {
+	t.Run("list", func(t *testing.T) {
+		mockGmailService, mockThreadsService := setupMockThreadsService()
+		mockThreadsListCall := &gmailtest.MockThreadsListCall{}
+
+		mockThreadsService.On("List", "me").Return(mockThreadsListCall)
+		mockThreadsListCall.On("Context", context.Background()).Return(mockThreadsListCall)
+		mockThreadsListCall.On("Do").Return(nil, errors.New("API error"))
+
+		_, err := ListThreads(context.Background(), mockGmailService, nil)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to list threads")
+	})
+
+	t.Run("get", func(t *testing.T) {
+		mockGmailService, mockThreadsService := setupMockThreadsService()
+		mockThreadsGetCall := &gmailtest.MockThreadsGetCall{}
+
+		mockThreadsService.On("Get", "me", "thread-1").Return(mockThreadsGetCall)
+		mockThreadsGetCall.On("Format", "full").Return(mockThreadsGetCall)
+		mockThreadsGetCall.On("Context", context.Background()).Return(mockThreadsGetCall)
+		mockThreadsGetCall.On("Do").Return(nil, errors.New("thread not found"))
+
+		_, err := GetThread(context.Background(), mockGmailService, "thread-1")
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to get thread")
+	})
+
+	t.Run("empty thread ID", func(t *testing.T) {
+		mockGmailService, _ := setupMockThreadsService()
+
+		_, err := GetThread(context.Background(), mockGmailService, "")
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "thread ID is required")
+	})
+}
+
+// Helper function to setup mock threads service
+func setupMockThreadsService() (*gmailtest.MockGmailService, *gmailtest.MockThreadsService) {
+	mockGmailService := &gmailtest.MockGmailService{}
+	mockUsersService := &gmailtest.MockUsersService{}
+	mockThreadsService := &gmailtest.MockThreadsService{}
+
+	mockGmailService.On("GetUsersService").Return(mockUsersService)
+	mockUsersService.On("GetThreadsService").Return(mockThreadsService)
+
+	return mockGmailService, mockThreadsService
+}
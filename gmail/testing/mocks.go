@@ -2,10 +2,12 @@ package testing
 
 import (
 	"context"
+	"io"
 
 	"github.com/danielrivera/mailbridge-go/gmail/internal"
 	"github.com/stretchr/testify/mock"
 	gmailapi "google.golang.org/api/gmail/v1"
+	"google.golang.org/api/googleapi"
 )
 
 // MockGmailService is a mock for GmailService
@@ -33,6 +35,16 @@ func (m *MockUsersService) GetLabelsService() internal.LabelsService {
 	return args.Get(0).(internal.LabelsService)
 }
 
+func (m *MockUsersService) GetThreadsService() internal.ThreadsService {
+	args := m.Called()
+	return args.Get(0).(internal.ThreadsService)
+}
+
+func (m *MockUsersService) GetDraftsService() internal.DraftsService {
+	args := m.Called()
+	return args.Get(0).(internal.DraftsService)
+}
+
 func (m *MockUsersService) Watch(userID string, req *gmailapi.WatchRequest) internal.UsersWatchCall {
 	args := m.Called(userID, req)
 	return args.Get(0).(internal.UsersWatchCall)
@@ -48,6 +60,11 @@ func (m *MockUsersService) GetHistory(userID string) internal.UsersHistoryListCa
 	return args.Get(0).(internal.UsersHistoryListCall)
 }
 
+func (m *MockUsersService) GetProfile(userID string) internal.UsersGetProfileCall {
+	args := m.Called(userID)
+	return args.Get(0).(internal.UsersGetProfileCall)
+}
+
 // MockMessagesService is a mock for MessagesService
 type MockMessagesService struct {
 	mock.Mock
@@ -73,6 +90,14 @@ func (m *MockMessagesService) GetAttachment(userID, messageID, attachmentID stri
 	return args.Get(0).(internal.MessagesAttachmentGetCall)
 }
 
+func (m *MockMessagesService) GetAttachmentContent(ctx context.Context, userID, messageID, attachmentID string) (io.ReadCloser, error) {
+	args := m.Called(ctx, userID, messageID, attachmentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(io.ReadCloser), args.Error(1)
+}
+
 func (m *MockMessagesService) Send(userID string, message *gmailapi.Message) internal.MessagesSendCall {
 	args := m.Called(userID, message)
 	return args.Get(0).(internal.MessagesSendCall)
@@ -113,6 +138,11 @@ func (m *MockLabelsService) Create(userID string, label *gmailapi.Label) interna
 	return args.Get(0).(internal.LabelsCreateCall)
 }
 
+func (m *MockLabelsService) Patch(userID, labelID string, label *gmailapi.Label) internal.LabelsPatchCall {
+	args := m.Called(userID, labelID, label)
+	return args.Get(0).(internal.LabelsPatchCall)
+}
+
 func (m *MockLabelsService) Delete(userID, labelID string) internal.LabelsDeleteCall {
 	args := m.Called(userID, labelID)
 	return args.Get(0).(internal.LabelsDeleteCall)
@@ -143,6 +173,11 @@ func (m *MockMessagesListCall) LabelIds(labelIds ...string) internal.MessagesLis
 	return m
 }
 
+func (m *MockMessagesListCall) Fields(fields ...googleapi.Field) internal.MessagesListCall {
+	m.Called(fields)
+	return m
+}
+
 func (m *MockMessagesListCall) Context(ctx context.Context) internal.MessagesListCall {
 	m.Called(ctx)
 	return m
@@ -166,6 +201,11 @@ func (m *MockMessagesGetCall) Format(format string) internal.MessagesGetCall {
 	return m
 }
 
+func (m *MockMessagesGetCall) Fields(fields ...googleapi.Field) internal.MessagesGetCall {
+	m.Called(fields)
+	return m
+}
+
 func (m *MockMessagesGetCall) Context(ctx context.Context) internal.MessagesGetCall {
 	m.Called(ctx)
 	return m
@@ -269,6 +309,24 @@ func (m *MockLabelsCreateCall) Do() (*gmailapi.Label, error) {
 	return args.Get(0).(*gmailapi.Label), args.Error(1)
 }
 
+// MockLabelsPatchCall is a mock for LabelsPatchCall
+type MockLabelsPatchCall struct {
+	mock.Mock
+}
+
+func (m *MockLabelsPatchCall) Context(ctx context.Context) internal.LabelsPatchCall {
+	m.Called(ctx)
+	return m
+}
+
+func (m *MockLabelsPatchCall) Do() (*gmailapi.Label, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*gmailapi.Label), args.Error(1)
+}
+
 // MockLabelsDeleteCall is a mock for LabelsDeleteCall
 type MockLabelsDeleteCall struct {
 	mock.Mock
@@ -353,6 +411,242 @@ func (m *MockMessagesDeleteCall) Do() error {
 	return args.Error(0)
 }
 
+// MockThreadsService is a mock for ThreadsService
+type MockThreadsService struct {
+	mock.Mock
+}
+
+func (m *MockThreadsService) List(userID string) internal.ThreadsListCall {
+	args := m.Called(userID)
+	return args.Get(0).(internal.ThreadsListCall)
+}
+
+func (m *MockThreadsService) Get(userID, threadID string) internal.ThreadsGetCall {
+	args := m.Called(userID, threadID)
+	return args.Get(0).(internal.ThreadsGetCall)
+}
+
+// MockThreadsListCall is a mock for ThreadsListCall
+type MockThreadsListCall struct {
+	mock.Mock
+}
+
+func (m *MockThreadsListCall) MaxResults(maxResults int64) internal.ThreadsListCall {
+	m.Called(maxResults)
+	return m
+}
+
+func (m *MockThreadsListCall) PageToken(token string) internal.ThreadsListCall {
+	m.Called(token)
+	return m
+}
+
+func (m *MockThreadsListCall) Q(query string) internal.ThreadsListCall {
+	m.Called(query)
+	return m
+}
+
+func (m *MockThreadsListCall) LabelIds(labelIds ...string) internal.ThreadsListCall {
+	m.Called(labelIds)
+	return m
+}
+
+func (m *MockThreadsListCall) Context(ctx context.Context) internal.ThreadsListCall {
+	m.Called(ctx)
+	return m
+}
+
+func (m *MockThreadsListCall) Do() (*gmailapi.ListThreadsResponse, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*gmailapi.ListThreadsResponse), args.Error(1)
+}
+
+// MockThreadsGetCall is a mock for ThreadsGetCall
+type MockThreadsGetCall struct {
+	mock.Mock
+}
+
+func (m *MockThreadsGetCall) Format(format string) internal.ThreadsGetCall {
+	m.Called(format)
+	return m
+}
+
+func (m *MockThreadsGetCall) Context(ctx context.Context) internal.ThreadsGetCall {
+	m.Called(ctx)
+	return m
+}
+
+func (m *MockThreadsGetCall) Do() (*gmailapi.Thread, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*gmailapi.Thread), args.Error(1)
+}
+
+// MockDraftsService is a mock for DraftsService
+type MockDraftsService struct {
+	mock.Mock
+}
+
+func (m *MockDraftsService) List(userID string) internal.DraftsListCall {
+	args := m.Called(userID)
+	return args.Get(0).(internal.DraftsListCall)
+}
+
+func (m *MockDraftsService) Get(userID, draftID string) internal.DraftsGetCall {
+	args := m.Called(userID, draftID)
+	return args.Get(0).(internal.DraftsGetCall)
+}
+
+func (m *MockDraftsService) Create(userID string, draft *gmailapi.Draft) internal.DraftsCreateCall {
+	args := m.Called(userID, draft)
+	return args.Get(0).(internal.DraftsCreateCall)
+}
+
+func (m *MockDraftsService) Update(userID, draftID string, draft *gmailapi.Draft) internal.DraftsUpdateCall {
+	args := m.Called(userID, draftID, draft)
+	return args.Get(0).(internal.DraftsUpdateCall)
+}
+
+func (m *MockDraftsService) Send(userID string, draft *gmailapi.Draft) internal.DraftsSendCall {
+	args := m.Called(userID, draft)
+	return args.Get(0).(internal.DraftsSendCall)
+}
+
+func (m *MockDraftsService) Delete(userID, draftID string) internal.DraftsDeleteCall {
+	args := m.Called(userID, draftID)
+	return args.Get(0).(internal.DraftsDeleteCall)
+}
+
+// MockDraftsListCall is a mock for DraftsListCall
+type MockDraftsListCall struct {
+	mock.Mock
+}
+
+func (m *MockDraftsListCall) MaxResults(maxResults int64) internal.DraftsListCall {
+	m.Called(maxResults)
+	return m
+}
+
+func (m *MockDraftsListCall) PageToken(token string) internal.DraftsListCall {
+	m.Called(token)
+	return m
+}
+
+func (m *MockDraftsListCall) Q(query string) internal.DraftsListCall {
+	m.Called(query)
+	return m
+}
+
+func (m *MockDraftsListCall) Context(ctx context.Context) internal.DraftsListCall {
+	m.Called(ctx)
+	return m
+}
+
+func (m *MockDraftsListCall) Do() (*gmailapi.ListDraftsResponse, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*gmailapi.ListDraftsResponse), args.Error(1)
+}
+
+// MockDraftsGetCall is a mock for DraftsGetCall
+type MockDraftsGetCall struct {
+	mock.Mock
+}
+
+func (m *MockDraftsGetCall) Format(format string) internal.DraftsGetCall {
+	m.Called(format)
+	return m
+}
+
+func (m *MockDraftsGetCall) Context(ctx context.Context) internal.DraftsGetCall {
+	m.Called(ctx)
+	return m
+}
+
+func (m *MockDraftsGetCall) Do() (*gmailapi.Draft, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*gmailapi.Draft), args.Error(1)
+}
+
+// MockDraftsCreateCall is a mock for DraftsCreateCall
+type MockDraftsCreateCall struct {
+	mock.Mock
+}
+
+func (m *MockDraftsCreateCall) Context(ctx context.Context) internal.DraftsCreateCall {
+	m.Called(ctx)
+	return m
+}
+
+func (m *MockDraftsCreateCall) Do() (*gmailapi.Draft, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*gmailapi.Draft), args.Error(1)
+}
+
+// MockDraftsUpdateCall is a mock for DraftsUpdateCall
+type MockDraftsUpdateCall struct {
+	mock.Mock
+}
+
+func (m *MockDraftsUpdateCall) Context(ctx context.Context) internal.DraftsUpdateCall {
+	m.Called(ctx)
+	return m
+}
+
+func (m *MockDraftsUpdateCall) Do() (*gmailapi.Draft, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*gmailapi.Draft), args.Error(1)
+}
+
+// MockDraftsSendCall is a mock for DraftsSendCall
+type MockDraftsSendCall struct {
+	mock.Mock
+}
+
+func (m *MockDraftsSendCall) Context(ctx context.Context) internal.DraftsSendCall {
+	m.Called(ctx)
+	return m
+}
+
+func (m *MockDraftsSendCall) Do() (*gmailapi.Message, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*gmailapi.Message), args.Error(1)
+}
+
+// MockDraftsDeleteCall is a mock for DraftsDeleteCall
+type MockDraftsDeleteCall struct {
+	mock.Mock
+}
+
+func (m *MockDraftsDeleteCall) Context(ctx context.Context) internal.DraftsDeleteCall {
+	m.Called(ctx)
+	return m
+}
+
+func (m *MockDraftsDeleteCall) Do() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
 // MockUsersWatchCall is a mock for UsersWatchCall
 type MockUsersWatchCall struct {
 	mock.Mock
@@ -386,6 +680,24 @@ func (m *MockUsersStopCall) Do() error {
 	return args.Error(0)
 }
 
+// MockUsersGetProfileCall is a mock for UsersGetProfileCall
+type MockUsersGetProfileCall struct {
+	mock.Mock
+}
+
+func (m *MockUsersGetProfileCall) Context(ctx context.Context) internal.UsersGetProfileCall {
+	m.Called(ctx)
+	return m
+}
+
+func (m *MockUsersGetProfileCall) Do() (*gmailapi.Profile, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*gmailapi.Profile), args.Error(1)
+}
+
 // MockUsersHistoryListCall is a mock for UsersHistoryListCall
 type MockUsersHistoryListCall struct {
 	mock.Mock
@@ -0,0 +1,61 @@
+// Package mailbridge exposes library-wide metadata such as the release version.
+//
+// Provider functionality lives in the core, gmail and outlook packages.
+package mailbridge
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// Version is the current mailbridge-go release
+const Version = "1.0.0"
+
+// ModulePath is the Go module path of mailbridge-go
+const ModulePath = "github.com/danielrivera/mailbridge-go"
+
+// Info describes the mailbridge-go build linked into the running binary.
+// Include it in bug reports.
+type Info struct {
+	Version       string `json:"version"`        // Library release (Version)
+	Module        string `json:"module"`         // Module path as resolved from build info
+	ModuleVersion string `json:"module_version"` // Module version from build info, "(devel)" for local builds
+	GoVersion     string `json:"go_version"`     // Go toolchain used to build the binary
+}
+
+// BuildInfo returns build information for mailbridge-go read from the running binary.
+// Fields not available from runtime/debug.ReadBuildInfo fall back to compile-time values.
+func BuildInfo() Info {
+	info := Info{
+		Version:   Version,
+		Module:    ModulePath,
+		GoVersion: runtime.Version(),
+	}
+
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+
+	info.GoVersion = bi.GoVersion
+
+	if bi.Main.Path == ModulePath {
+		info.Module = bi.Main.Path
+		info.ModuleVersion = bi.Main.Version
+		return info
+	}
+
+	for _, dep := range bi.Deps {
+		if dep.Path != ModulePath {
+			continue
+		}
+		info.Module = dep.Path
+		info.ModuleVersion = dep.Version
+		if dep.Replace != nil {
+			info.ModuleVersion = dep.Replace.Version
+		}
+		break
+	}
+
+	return info
+}
@@ -0,0 +1,21 @@
+package mailbridge
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVersion(t *testing.T) {
+	assert.NotEmpty(t, Version)
+	assert.False(t, strings.HasPrefix(Version, "v"))
+}
+
+func TestBuildInfo(t *testing.T) {
+	info := BuildInfo()
+
+	assert.Equal(t, "github.com/danielrivera/mailbridge-go", info.Module)
+	assert.Equal(t, Version, info.Version)
+	assert.NotEmpty(t, info.GoVersion)
+}
@@ -0,0 +1,132 @@
+package outlook
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+
+	"github.com/danielrivera/mailbridge-go/core"
+)
+
+// messageCache holds messages fetched by GetMessage together with their ETag, so a
+// repeated fetch can be revalidated with If-None-Match. Entries are evicted oldest first.
+// It is guarded by Client.mu.
+type messageCache struct {
+	entries map[string]cachedMessage
+	order   []string // keys in insertion order, oldest first
+}
+
+// cachedMessage is a cached email and the ETag it was fetched with.
+type cachedMessage struct {
+	etag  string
+	email *core.Email
+}
+
+// messageCacheKey identifies a message within a mailbox; "" is the signed-in user's mailbox.
+func messageCacheKey(mailbox, messageID string) string {
+	return mailbox + "/" + messageID
+}
+
+// cachedMessage returns the cached entry for key, if any.
+func (c *Client) cachedMessage(key string) (cachedMessage, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	entry, ok := c.messageCache.entries[key]
+	return entry, ok
+}
+
+// cacheMessage stores email under key, evicting the oldest entries beyond
+// Config.MessageCacheSize. Messages without an ETag are not cached.
+func (c *Client) cacheMessage(key, etag string, email *core.Email) {
+	size := c.config.messageCacheSize()
+	if size == 0 || etag == "" {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cache := &c.messageCache
+	if cache.entries == nil {
+		cache.entries = make(map[string]cachedMessage)
+	}
+	if _, exists := cache.entries[key]; !exists {
+		cache.order = append(cache.order, key)
+	}
+	cache.entries[key] = cachedMessage{etag: etag, email: copyEmail(email)}
+
+	for len(cache.order) > size {
+		delete(cache.entries, cache.order[0])
+		cache.order = cache.order[1:]
+	}
+}
+
+// uncacheMessage drops the entry for key, e.g. after the message was deleted.
+func (c *Client) uncacheMessage(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cache := &c.messageCache
+	if _, exists := cache.entries[key]; !exists {
+		return
+	}
+	delete(cache.entries, key)
+	for i, k := range cache.order {
+		if k == key {
+			cache.order = append(cache.order[:i], cache.order[i+1:]...)
+			break
+		}
+	}
+}
+
+// copyEmail returns a deep copy of email: its fields, including the slices, maps and
+// event it holds, can be changed without affecting the original. An attachment's
+// Reader is shared, as a stream cannot be copied.
+func copyEmail(email *core.Email) *core.Email {
+	copied := *email
+	copied.To = slices.Clone(email.To)
+	copied.Cc = slices.Clone(email.Cc)
+	copied.Bcc = slices.Clone(email.Bcc)
+	copied.ReplyTo = slices.Clone(email.ReplyTo)
+	copied.Labels = slices.Clone(email.Labels)
+	copied.BlockedResources = slices.Clone(email.BlockedResources)
+	copied.ExtendedProperties = maps.Clone(email.ExtendedProperties)
+	copied.ProviderMeta = maps.Clone(email.ProviderMeta)
+
+	if email.RawHeaders != nil {
+		copied.RawHeaders = make(map[string][]string, len(email.RawHeaders))
+		for name, values := range email.RawHeaders {
+			copied.RawHeaders[name] = slices.Clone(values)
+		}
+	}
+
+	copied.Attachments = slices.Clone(email.Attachments)
+	for i := range copied.Attachments {
+		copied.Attachments[i].Data = slices.Clone(email.Attachments[i].Data)
+	}
+
+	if email.Event != nil {
+		event := *email.Event
+		event.Attendees = slices.Clone(email.Event.Attendees)
+		copied.Event = &event
+	}
+	return &copied
+}
+
+// messageETag returns the ETag of a message. Graph sends it as @odata.etag; for
+// messages it is the weak form of the change key, which is used as a fallback.
+func messageETag(msg models.Messageable) string {
+	switch etag := msg.GetAdditionalData()["@odata.etag"].(type) {
+	case *string:
+		if etag != nil && *etag != "" {
+			return *etag
+		}
+	case string:
+		if etag != "" {
+			return etag
+		}
+	}
+	if changeKey := derefString(msg.GetChangeKey()); changeKey != "" {
+		return `W/"` + changeKey + `"`
+	}
+	return ""
+}
@@ -0,0 +1,82 @@
+package outlook
+
+import (
+	"time"
+
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+
+	"github.com/danielrivera/mailbridge-go/core"
+)
+
+// graphDateTimeLayout is the layout of DateTimeTimeZone.dateTime values, which carry
+// up to seven fractional digits and no offset.
+const graphDateTimeLayout = "2006-01-02T15:04:05.9999999"
+
+// convertEventMessage maps a meeting request, cancellation or response into a
+// core.Event. Graph reports these as eventMessage resources instead of exposing
+// their iCalendar part. email is the already converted message, whose subject,
+// sender and recipients describe the meeting.
+func convertEventMessage(msg models.EventMessageable, email *core.Email) *core.Event {
+	event := &core.Event{
+		Summary:   email.Subject,
+		Organizer: email.From,
+		Start:     graphDateTime(msg.GetStartDateTime()),
+		End:       graphDateTime(msg.GetEndDateTime()),
+	}
+	if allDay := msg.GetIsAllDay(); allDay != nil {
+		event.AllDay = *allDay
+	}
+	if location := msg.GetLocation(); location != nil {
+		event.Location = derefString(location.GetDisplayName())
+	}
+	if expanded := msg.GetEvent(); expanded != nil {
+		event.UID = derefString(expanded.GetICalUId())
+	}
+
+	var status string
+	if messageType := msg.GetMeetingMessageType(); messageType != nil {
+		switch *messageType {
+		case models.MEETINGREQUEST_MEETINGMESSAGETYPE:
+			event.Method = core.CalendarMethodRequest
+		case models.MEETINGCANCELLED_MEETINGMESSAGETYPE:
+			event.Method = core.CalendarMethodCancel
+		case models.MEETINGACCEPTED_MEETINGMESSAGETYPE:
+			event.Method, status = core.CalendarMethodReply, "ACCEPTED"
+		case models.MEETINGTENATIVELYACCEPTED_MEETINGMESSAGETYPE:
+			event.Method, status = core.CalendarMethodReply, "TENTATIVE"
+		case models.MEETINGDECLINED_MEETINGMESSAGETYPE:
+			event.Method, status = core.CalendarMethodReply, "DECLINED"
+		}
+	}
+
+	// A response comes from the attendee and goes to the organizer
+	if event.Method == core.CalendarMethodReply {
+		event.Attendees = []core.EventAttendee{{EmailAddress: email.From, Status: status}}
+		if len(email.To) > 0 {
+			event.Organizer = email.To[0]
+		}
+		return event
+	}
+	for _, recipient := range append(append([]core.EmailAddress{}, email.To...), email.Cc...) {
+		event.Attendees = append(event.Attendees, core.EventAttendee{EmailAddress: recipient})
+	}
+	return event
+}
+
+// graphDateTime converts a Graph DateTimeTimeZone to a time.Time. Graph reports UTC
+// unless another time zone was requested; zones Go does not know (such as Windows
+// names) are read as UTC. A missing or malformed value yields the zero time.
+func graphDateTime(value models.DateTimeTimeZoneable) time.Time {
+	if value == nil {
+		return time.Time{}
+	}
+	loc, err := time.LoadLocation(derefString(value.GetTimeZone()))
+	if err != nil {
+		loc = time.UTC
+	}
+	t, err := time.ParseInLocation(graphDateTimeLayout, derefString(value.GetDateTime()), loc)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
@@ -8,12 +8,16 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"sync"
 
 	abstractions "github.com/microsoft/kiota-abstractions-go"
+	khttp "github.com/microsoft/kiota-http-go"
 	msgraphsdk "github.com/microsoftgraph/msgraph-sdk-go"
+	msgraphcore "github.com/microsoftgraph/msgraph-sdk-go-core"
 	"github.com/microsoftgraph/msgraph-sdk-go/models/odataerrors"
 	"golang.org/x/oauth2"
 
+	"github.com/danielrivera/mailbridge-go/core"
 	"github.com/danielrivera/mailbridge-go/outlook/internal"
 )
 
@@ -22,8 +26,14 @@ import (
 type Client struct {
 	config       *Config
 	oauth2Config *oauth2.Config
+
+	mu           sync.RWMutex // guards token, service, transport, selfAddress, folderNames and messageCache
 	token        *oauth2.Token
 	service      internal.GraphService
+	transport    http.RoundTripper // base transport of the Graph HTTP client, whose idle connections are closed on reconnect and Close
+	selfAddress  string            // cached address of the authenticated user
+	folderNames  map[string]string // cached folder ID to display name map, used with ResolveFolderNames
+	messageCache messageCache      // messages fetched by GetMessage, used with MessageCacheSize
 }
 
 // New creates a new Outlook client with the given configuration.
@@ -64,19 +74,17 @@ func (c *Client) ConnectWithToken(ctx context.Context, token *oauth2.Token) erro
 		return fmt.Errorf("token cannot be nil")
 	}
 
-	c.token = token
-
-	// Create an HTTP client with the OAuth2 token
-	httpClient := c.oauth2Config.Client(ctx, token)
-
-	// Create authentication provider using the token
+	// Create authentication provider that refreshes the token when it expires
 	authProvider := &oauth2AuthProvider{
-		token:      token,
-		httpClient: httpClient,
+		tokenSource: c.tokenSource(ctx, token),
 	}
 
 	// Create Graph client
-	adapter, err := msgraphsdk.NewGraphRequestAdapter(authProvider)
+	transport := khttp.GetDefaultTransport()
+	graphHTTPClient := newGraphHTTPClient(c.config.userAgent(), transport, c.config.retryPolicy())
+	adapter, err := msgraphsdk.NewGraphRequestAdapterWithParseNodeFactoryAndSerializationWriterFactoryAndHttpClient(
+		authProvider, nil, nil, graphHTTPClient,
+	)
 	if err != nil {
 		return fmt.Errorf("failed to create request adapter: %w", err)
 	}
@@ -84,32 +92,85 @@ func (c *Client) ConnectWithToken(ctx context.Context, token *oauth2.Token) erro
 	graphClient := msgraphsdk.NewGraphServiceClient(adapter)
 
 	// Wrap in our interface
-	c.service = internal.NewRealGraphService(graphClient)
+	c.mu.Lock()
+	previous := c.transport
+	c.token = token
+	c.service = internal.NewRealGraphService(graphClient, graphHTTPClient)
+	c.transport = transport
+	c.selfAddress = ""
+	c.folderNames = nil
+	c.messageCache = messageCache{}
+	c.mu.Unlock()
+
+	// Requests in flight on the previous connection keep their connections
+	closeIdleConnections(previous)
+	return nil
+}
+
+// Close disconnects the client and releases its idle HTTP connections.
+// Operations called after Close fail until the client connects again.
+func (c *Client) Close() error {
+	c.mu.Lock()
+	transport := c.transport
+	c.token = nil
+	c.service = nil
+	c.transport = nil
+	c.selfAddress = ""
+	c.folderNames = nil
+	c.messageCache = messageCache{}
+	c.mu.Unlock()
 
+	closeIdleConnections(transport)
 	return nil
 }
 
+// closeIdleConnections closes the idle connections of transport, if it keeps any.
+func closeIdleConnections(transport http.RoundTripper) {
+	if closer, ok := transport.(interface{ CloseIdleConnections() }); ok {
+		closer.CloseIdleConnections()
+	}
+}
+
 // IsConnected returns true if the client is connected to Microsoft Graph API.
 func (c *Client) IsConnected() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	return c.service != nil
 }
 
-// GetToken returns the current OAuth2 token.
-// Users should persist this token for future use.
+// GetToken returns the current OAuth2 token, including any refreshed since the client connected.
+// Users should persist this token for future use, or set Config.OnTokenRefresh.
 func (c *Client) GetToken() *oauth2.Token {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	return c.token
 }
 
+// GrantedScopes returns the scopes granted to the current token, read from
+// the token's "scope" field, falling back to the "scp" claim of the Graph access token.
+// It returns nil when there is no token or the scopes cannot be determined.
+func (c *Client) GrantedScopes() []string {
+	token := c.GetToken()
+	if token == nil {
+		return nil
+	}
+	scopeField, _ := token.Extra("scope").(string)
+	return core.GrantedScopes(scopeField, token.AccessToken)
+}
+
 // RefreshToken refreshes the OAuth2 token if it has expired or is about to expire.
 // Returns the new token, which should be persisted.
 func (c *Client) RefreshToken(ctx context.Context) (*oauth2.Token, error) {
-	if c.token == nil {
+	token := c.GetToken()
+	if token == nil {
 		return nil, fmt.Errorf("no token to refresh")
 	}
 
-	tokenSource := c.oauth2Config.TokenSource(ctx, c.token)
-	newToken, err := tokenSource.Token()
+	newToken, err := c.tokenSource(ctx, token).Token()
 	if err != nil {
+		if isInvalidGrant(err) {
+			return nil, fmt.Errorf("failed to refresh token: %w: %w", core.ErrReauthRequired, err)
+		}
 		return nil, fmt.Errorf("failed to refresh token: %w", err)
 	}
 
@@ -121,9 +182,80 @@ func (c *Client) RefreshToken(ctx context.Context) (*oauth2.Token, error) {
 	return newToken, nil
 }
 
+// tokenSource returns a source that refreshes token on expiry, storing each new token
+// on the client and passing it to Config.OnTokenRefresh. Expiry is checked against
+// Config.Clock, not the wall clock. Refreshes outlive ctx's cancellation, as they
+// happen long after the client connected.
+func (c *Client) tokenSource(ctx context.Context, token *oauth2.Token) oauth2.TokenSource {
+	refreshCtx := context.WithoutCancel(ctx)
+	source := core.ClockTokenSource(c.config.clock(), token, func(current *oauth2.Token) (*oauth2.Token, error) {
+		return c.oauth2Config.TokenSource(refreshCtx, &oauth2.Token{RefreshToken: current.RefreshToken}).Token()
+	})
+	return core.NotifyingTokenSource(source, token, func(newToken *oauth2.Token) {
+		c.mu.Lock()
+		c.token = newToken
+		c.mu.Unlock()
+		if c.config.OnTokenRefresh != nil {
+			c.config.OnTokenRefresh(newToken)
+		}
+	})
+}
+
+// isInvalidGrant reports whether err is an OAuth2 "invalid_grant" error,
+// which means the refresh token was revoked or has expired.
+func isInvalidGrant(err error) bool {
+	var retrieveErr *oauth2.RetrieveError
+	return errors.As(err, &retrieveErr) && retrieveErr.ErrorCode == "invalid_grant"
+}
+
 // SetService sets the internal Graph service (for testing).
+// It is safe to call concurrently with other client methods.
 func (c *Client) SetService(service internal.GraphService) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.service = service
+	c.selfAddress = ""
+	c.folderNames = nil
+	c.messageCache = messageCache{}
+}
+
+// getService returns the current Graph service, or an error if the client is not connected.
+func (c *Client) getService() (internal.GraphService, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.service == nil {
+		return nil, fmt.Errorf("client not connected")
+	}
+	return c.service, nil
+}
+
+// getSelfAddress returns the email address of the authenticated user.
+// The address is fetched once and cached for the lifetime of the connection.
+func (c *Client) getSelfAddress(ctx context.Context, service internal.GraphService) (string, error) {
+	c.mu.RLock()
+	cached := c.selfAddress
+	c.mu.RUnlock()
+	if cached != "" {
+		return cached, nil
+	}
+
+	user, err := service.GetMeService().Get(ctx)
+	if err != nil {
+		return "", handleODataError(fmt.Errorf("failed to get user profile: %w", err))
+	}
+
+	address := derefString(user.GetMail())
+	if address == "" {
+		address = derefString(user.GetUserPrincipalName())
+	}
+	if address == "" {
+		return "", fmt.Errorf("user profile has no email address")
+	}
+
+	c.mu.Lock()
+	c.selfAddress = address
+	c.mu.Unlock()
+	return address, nil
 }
 
 // handleODataError converts OData errors to readable error messages.
@@ -134,6 +266,7 @@ func handleODataError(err error) error {
 
 	var odataErr *odataerrors.ODataError
 	if errors.As(err, &odataErr) {
+		var graphErr error
 		if terr := odataErr.GetErrorEscaped(); terr != nil {
 			code := ""
 			message := ""
@@ -143,28 +276,76 @@ func handleODataError(err error) error {
 			if terr.GetMessage() != nil {
 				message = *terr.GetMessage()
 			}
-			return fmt.Errorf("microsoft graph error [%s]: %s", code, message)
+			graphErr = fmt.Errorf("microsoft graph error [%s]: %s", code, message)
+		} else {
+			graphErr = fmt.Errorf("microsoft graph error: %s", odataErr.Error())
 		}
-		return fmt.Errorf("microsoft graph error: %s", odataErr.Error())
+		// Keep throttling detectable, since the Graph error itself is flattened
+		if odataErr.ResponseStatusCode == http.StatusTooManyRequests {
+			return fmt.Errorf("%w: %w", core.ErrRateLimited, graphErr)
+		}
+		return graphErr
 	}
 
 	return err
 }
 
+// isNotFound reports whether err is a Graph 404 response.
+func isNotFound(err error) bool {
+	var odataErr *odataerrors.ODataError
+	return errors.As(err, &odataErr) && odataErr.ResponseStatusCode == http.StatusNotFound
+}
+
+// newGraphHTTPClient creates the HTTP client used by the Graph request adapter.
+// It uses the default Graph middleware pipeline with a User-Agent middleware in front.
+// The SDK's retry handler is replaced by retry, which wraps the transport so throttled
+// requests are retried below the middlewares. A nil transport uses the Kiota default transport.
+func newGraphHTTPClient(userAgent string, transport http.RoundTripper, retry *core.RetryPolicy) *http.Client {
+	options := msgraphsdk.GetDefaultClientOptions()
+	middlewares := []khttp.Middleware{&userAgentMiddleware{userAgent: userAgent}}
+	for _, middleware := range msgraphcore.GetDefaultMiddlewaresWithOptions(&options) {
+		if _, ok := middleware.(*khttp.RetryHandler); !ok {
+			middlewares = append(middlewares, middleware)
+		}
+	}
+
+	if transport == nil {
+		transport = khttp.GetDefaultTransport()
+	}
+	client := khttp.GetDefaultClient(middlewares...)
+	client.Transport = khttp.NewCustomTransportWithParentTransport(retry.Transport(transport), middlewares...)
+	return client
+}
+
+// userAgentMiddleware sets the User-Agent header on outgoing Graph requests.
+type userAgentMiddleware struct {
+	userAgent string
+}
+
+// Intercept sets the User-Agent header and passes the request down the pipeline.
+func (m *userAgentMiddleware) Intercept(pipeline khttp.Pipeline, middlewareIndex int, req *http.Request) (*http.Response, error) {
+	req.Header.Set("User-Agent", m.userAgent)
+	return pipeline.Next(req, middlewareIndex)
+}
+
 // oauth2AuthProvider implements the Kiota authentication provider interface
-// using an OAuth2 token for delegated authentication flow.
+// using an OAuth2 token source for delegated authentication flow.
 type oauth2AuthProvider struct {
-	token      *oauth2.Token
-	httpClient *http.Client
+	tokenSource oauth2.TokenSource
 }
 
-// AuthenticateRequest adds the OAuth2 bearer token to the request.
+// AuthenticateRequest adds the OAuth2 bearer token to the request,
+// refreshing the token first if it has expired.
 func (p *oauth2AuthProvider) AuthenticateRequest(ctx context.Context, request *abstractions.RequestInformation, additionalAuthenticationContext map[string]interface{}) error {
-	if p.token == nil {
+	if p.tokenSource == nil {
 		return fmt.Errorf("no token available")
 	}
+	token, err := p.tokenSource.Token()
+	if err != nil {
+		return fmt.Errorf("failed to get token: %w", err)
+	}
 
 	// Add the bearer token to the Authorization header
-	request.Headers.Add("Authorization", "Bearer "+p.token.AccessToken)
+	request.Headers.Add("Authorization", "Bearer "+token.AccessToken)
 	return nil
 }
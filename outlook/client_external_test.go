@@ -0,0 +1,48 @@
+package outlook_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/danielrivera/mailbridge-go/outlook"
+	outlooktest "github.com/danielrivera/mailbridge-go/outlook/testing"
+)
+
+func TestClient_SetService_External(t *testing.T) {
+	client, err := outlook.New(&outlook.Config{
+		ClientID:     "test-client-id",
+		ClientSecret: "test-secret",
+		TenantID:     "consumers",
+		RedirectURL:  "http://localhost:8080/callback",
+	})
+	require.NoError(t, err)
+	assert.False(t, client.IsConnected())
+
+	mockGraphService := &outlooktest.MockGraphService{}
+	mockMeService := &outlooktest.MockMeService{}
+	mockFoldersService := &outlooktest.MockMailFoldersService{}
+	mockGraphService.On("GetMeService").Return(mockMeService)
+	mockMeService.On("GetMailFoldersService").Return(mockFoldersService)
+
+	folder := models.NewMailFolder()
+	folderID := "inbox-id"
+	displayName := "Inbox"
+	folder.SetId(&folderID)
+	folder.SetDisplayName(&displayName)
+	mockFoldersService.On("Get", context.Background(), folderID).Return(folder, nil)
+
+	var service outlooktest.GraphService = mockGraphService
+	client.SetService(service)
+	assert.True(t, client.IsConnected())
+
+	label, err := client.GetFolder(context.Background(), folderID)
+	require.NoError(t, err)
+	assert.Equal(t, folderID, label.ID)
+	assert.Equal(t, displayName, label.Name)
+
+	mockFoldersService.AssertExpectations(t)
+}
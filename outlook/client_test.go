@@ -1,18 +1,41 @@
 package outlook
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	mailbridge "github.com/danielrivera/mailbridge-go"
 	"github.com/danielrivera/mailbridge-go/core"
+	"github.com/danielrivera/mailbridge-go/outlook/internal"
 	outlooktest "github.com/danielrivera/mailbridge-go/outlook/testing"
+	abstractions "github.com/microsoft/kiota-abstractions-go"
+	"github.com/microsoft/kiota-abstractions-go/authentication"
+	msgraphsdk "github.com/microsoftgraph/msgraph-sdk-go"
 	"github.com/microsoftgraph/msgraph-sdk-go/models/odataerrors"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"golang.org/x/oauth2"
 )
 
+// Client must be usable with core.RunInteractiveAuth
+var _ core.AuthClient = (*Client)(nil)
+
+// Client must be usable as a core.Provider
+var _ core.Provider = (*Client)(nil)
+
 func TestNew(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -128,10 +151,66 @@ func TestClient_IsConnected(t *testing.T) {
 
 	// After setting service, should be connected
 	mockService := &outlooktest.MockGraphService{}
-	client.service = mockService
+	client.SetService(mockService)
 	assert.True(t, client.IsConnected())
 }
 
+// idleConnTransport records calls to CloseIdleConnections
+type idleConnTransport struct {
+	http.RoundTripper
+	closed int
+}
+
+func (t *idleConnTransport) CloseIdleConnections() {
+	t.closed++
+}
+
+func TestClient_Close(t *testing.T) {
+	client, err := New(&Config{
+		ClientID:     "test-client-id",
+		ClientSecret: "test-secret",
+		TenantID:     "consumers",
+		RedirectURL:  "http://localhost:8080/callback",
+	})
+	require.NoError(t, err)
+	require.NoError(t, client.ConnectWithToken(context.Background(), &oauth2.Token{AccessToken: "test-token"}))
+	require.True(t, client.IsConnected())
+
+	transport := &idleConnTransport{}
+	client.transport = transport
+
+	require.NoError(t, client.Close())
+
+	assert.False(t, client.IsConnected())
+	assert.Nil(t, client.GetToken())
+	assert.Equal(t, 1, transport.closed, "idle connections are released")
+
+	_, err = client.ListMessages(context.Background(), nil)
+	assert.EqualError(t, err, "client not connected")
+	_, err = client.GetMessage(context.Background(), "msg-1")
+	assert.EqualError(t, err, "client not connected")
+
+	assert.NoError(t, client.Close(), "closing twice is harmless")
+	assert.Equal(t, 1, transport.closed)
+}
+
+func TestClient_ConnectWithToken_ReleasesPreviousConnection(t *testing.T) {
+	client, err := New(&Config{
+		ClientID:     "test-client-id",
+		ClientSecret: "test-secret",
+		TenantID:     "consumers",
+		RedirectURL:  "http://localhost:8080/callback",
+	})
+	require.NoError(t, err)
+	transport := &idleConnTransport{}
+	client.transport = transport
+
+	require.NoError(t, client.ConnectWithToken(context.Background(), &oauth2.Token{AccessToken: "test-token"}))
+
+	assert.Equal(t, 1, transport.closed)
+	assert.NotSame(t, transport, client.transport)
+}
+
 func TestClient_GetToken(t *testing.T) {
 	config := &Config{
 		ClientID:     "test-client-id",
@@ -241,6 +320,20 @@ func TestHandleODataError_ODataError_EmptyFields(t *testing.T) {
 	assert.Contains(t, result.Error(), "microsoft graph error")
 }
 
+func TestHandleODataError_TooManyRequests(t *testing.T) {
+	odataErr := odataerrors.NewODataError()
+	odataErr.ResponseStatusCode = http.StatusTooManyRequests
+	mainErr := odataerrors.NewMainError()
+	code := "ApplicationThrottled"
+	mainErr.SetCode(&code)
+	odataErr.SetErrorEscaped(mainErr)
+
+	result := handleODataError(odataErr)
+
+	assert.ErrorIs(t, result, core.ErrRateLimited)
+	assert.Contains(t, result.Error(), "ApplicationThrottled")
+}
+
 func TestClient_SetService(t *testing.T) {
 	config := &Config{
 		ClientID:     "test-client-id",
@@ -261,6 +354,26 @@ func TestClient_SetService(t *testing.T) {
 	assert.Equal(t, mockService, client.service)
 }
 
+func TestClient_SetService_Concurrent(t *testing.T) {
+	client, mockGraphService, mockMessagesService := createTestClient()
+	mockMessagesService.On("Delete", mock.Anything, mock.Anything).Return(nil)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			client.SetService(mockGraphService)
+		}()
+		go func() {
+			defer wg.Done()
+			assert.True(t, client.IsConnected())
+			assert.NoError(t, client.DeleteMessage(context.Background(), "msg-1"))
+		}()
+	}
+	wg.Wait()
+}
+
 func TestConfigError_Compatibility(t *testing.T) {
 	// Test that config errors are core.ConfigError
 	config := &Config{}
@@ -309,3 +422,534 @@ func TestClient_DefaultScopes(t *testing.T) {
 	// Should have default scopes
 	assert.Equal(t, DefaultScopes(), client.oauth2Config.Scopes)
 }
+
+// recordingTransport records outgoing requests and returns an empty JSON response
+type recordingTransport struct {
+	requests []*http.Request
+}
+
+func (r *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	r.requests = append(r.requests, req)
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader("{}")),
+		Request:    req,
+	}, nil
+}
+
+func TestNewGraphHTTPClient_UserAgent(t *testing.T) {
+	transport := &recordingTransport{}
+	httpClient := newGraphHTTPClient("my-app/2.1", transport, nil)
+
+	resp, err := httpClient.Get("https://graph.microsoft.com/v1.0/me")
+	assert.NoError(t, err)
+	_ = resp.Body.Close()
+
+	assert.Len(t, transport.requests, 1)
+	assert.True(t, strings.HasPrefix(transport.requests[0].Header.Get("User-Agent"), "my-app/2.1"))
+}
+
+// messageTransport records request paths and responds with a minimal message,
+// or with 304 Not Modified when If-None-Match matches the message's ETag
+type messageTransport struct {
+	paths []string
+}
+
+const messageTransportETag = `W/"v1"`
+
+func (m *messageTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	m.paths = append(m.paths, req.URL.Path)
+	if req.Header.Get("If-None-Match") == messageTransportETag {
+		return &http.Response{
+			StatusCode: http.StatusNotModified,
+			Header:     http.Header{},
+			Body:       io.NopCloser(strings.NewReader("")),
+			Request:    req,
+		}, nil
+	}
+	body := `{"id":"msg-1","@odata.etag":"W/\"v1\""}`
+	return &http.Response{
+		StatusCode:    http.StatusOK,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          io.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+func TestClient_GetMessageWithOptions_WithMailbox_RequestPath(t *testing.T) {
+	transport := &messageTransport{}
+	httpClient := newGraphHTTPClient("test", transport, nil)
+	adapter, err := msgraphsdk.NewGraphRequestAdapterWithParseNodeFactoryAndSerializationWriterFactoryAndHttpClient(
+		&authentication.AnonymousAuthenticationProvider{}, nil, nil, httpClient,
+	)
+	require.NoError(t, err)
+
+	client := &Client{config: &Config{}}
+	client.SetService(internal.NewRealGraphService(msgraphsdk.NewGraphServiceClient(adapter), httpClient))
+
+	_, err = client.GetMessageWithOptions(context.Background(), "msg-1", nil, WithMailbox("shared@example.com"))
+	require.NoError(t, err)
+
+	_, err = client.GetMessage(context.Background(), "msg-1")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"/v1.0/users/shared@example.com/messages/msg-1",
+		"/v1.0/me/messages/msg-1",
+	}, transport.paths)
+}
+
+// throttlingTransport answers the first throttled requests with a Graph 429 error and
+// a Retry-After header, then with a minimal message
+type throttlingTransport struct {
+	throttled int
+	attempts  int
+}
+
+func (r *throttlingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	r.attempts++
+	if r.attempts <= r.throttled {
+		body := `{"error":{"code":"TooManyRequests","message":"Application is over its MailboxConcurrency limit."}}`
+		return &http.Response{
+			StatusCode:    http.StatusTooManyRequests,
+			Header:        http.Header{"Content-Type": []string{"application/json"}, "Retry-After": []string{"0"}},
+			Body:          io.NopCloser(strings.NewReader(body)),
+			ContentLength: int64(len(body)),
+			Request:       req,
+		}, nil
+	}
+	body := `{"id":"msg-1"}`
+	return &http.Response{
+		StatusCode:    http.StatusOK,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          io.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+func TestNewGraphHTTPClient_RetryPolicy(t *testing.T) {
+	tests := []struct {
+		name             string
+		policy           *core.RetryPolicy
+		expectedAttempts int
+		wantErr          bool
+	}{
+		{"transient then success", core.DefaultRetryPolicy(), 3, false},
+		{"gives up after max retries", &core.RetryPolicy{MaxRetries: 1, BaseDelay: time.Millisecond, MaxDelay: time.Second}, 2, true},
+		{"disabled, without the SDK retry handler", &core.RetryPolicy{}, 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &throttlingTransport{throttled: 2}
+			httpClient := newGraphHTTPClient("test", transport, tt.policy)
+			adapter, err := msgraphsdk.NewGraphRequestAdapterWithParseNodeFactoryAndSerializationWriterFactoryAndHttpClient(
+				&authentication.AnonymousAuthenticationProvider{}, nil, nil, httpClient,
+			)
+			require.NoError(t, err)
+
+			client := &Client{config: &Config{}}
+			client.SetService(internal.NewRealGraphService(msgraphsdk.NewGraphServiceClient(adapter), httpClient))
+
+			email, err := client.GetMessage(context.Background(), "msg-1")
+
+			if tt.wantErr {
+				assert.ErrorIs(t, err, core.ErrRateLimited)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, "msg-1", email.ID)
+			}
+			assert.Equal(t, tt.expectedAttempts, transport.attempts)
+		})
+	}
+}
+
+// attachmentTransport answers attachment metadata requests with a file attachment and
+// downloads of its content with status and body
+type attachmentTransport struct {
+	status int
+	body   string
+}
+
+func (a *attachmentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	status, body := http.StatusOK, `{"@odata.type":"#microsoft.graph.fileAttachment","id":"att-1","name":"report.pdf","size":10}`
+	if strings.HasSuffix(req.URL.Path, "/$value") {
+		status, body = a.status, a.body
+	}
+	return &http.Response{
+		StatusCode:    status,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          io.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+func TestClient_GetAttachmentStream_Throttled(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		message string
+	}{
+		{"graph error", `{"error":{"code":"TooManyRequests","message":"Application is over its MailboxConcurrency limit."}}`, "MailboxConcurrency"},
+		{"non-graph body", "Too Many Requests", "unexpected status 429"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &attachmentTransport{status: http.StatusTooManyRequests, body: tt.body}
+			httpClient := newGraphHTTPClient("test", transport, &core.RetryPolicy{})
+			adapter, err := msgraphsdk.NewGraphRequestAdapterWithParseNodeFactoryAndSerializationWriterFactoryAndHttpClient(
+				&authentication.AnonymousAuthenticationProvider{}, nil, nil, httpClient,
+			)
+			require.NoError(t, err)
+
+			client := &Client{config: &Config{}}
+			client.SetService(internal.NewRealGraphService(msgraphsdk.NewGraphServiceClient(adapter), httpClient))
+
+			_, _, err = client.GetAttachmentStream(context.Background(), "msg-1", "att-1")
+
+			require.ErrorIs(t, err, core.ErrRateLimited)
+			assert.Contains(t, err.Error(), tt.message)
+		})
+	}
+}
+
+// batchCountsTransport answers $batch folder requests, throttling each folder in
+// throttled with 429 and Retry-After: 0 as many times as its count
+type batchCountsTransport struct {
+	throttled map[string]int
+	batches   [][]string // folder IDs of each $batch request
+}
+
+func (b *batchCountsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var batch struct {
+		Requests []struct {
+			ID  string `json:"id"`
+			URL string `json:"url"`
+		} `json:"requests"`
+	}
+	body := io.Reader(req.Body)
+	if req.Header.Get("Content-Encoding") == "gzip" {
+		gz, err := gzip.NewReader(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		body = gz
+	}
+	if err := json.NewDecoder(body).Decode(&batch); err != nil {
+		return nil, err
+	}
+
+	var folderIDs []string
+	var responses []map[string]any
+	for _, item := range batch.Requests {
+		path, _, _ := strings.Cut(item.URL, "?")
+		folderID := path[strings.LastIndex(path, "/")+1:]
+		folderIDs = append(folderIDs, folderID)
+		if b.throttled[folderID] > 0 {
+			b.throttled[folderID]--
+			responses = append(responses, map[string]any{
+				"id":      item.ID,
+				"status":  http.StatusTooManyRequests,
+				"headers": map[string]string{"Retry-After": "0"},
+				"body":    map[string]any{"error": map[string]string{"code": "TooManyRequests", "message": "throttled"}},
+			})
+			continue
+		}
+		responses = append(responses, map[string]any{
+			"id":      item.ID,
+			"status":  http.StatusOK,
+			"headers": map[string]string{"Content-Type": "application/json"},
+			"body":    map[string]any{"id": folderID, "displayName": folderID},
+		})
+	}
+	b.batches = append(b.batches, folderIDs)
+
+	payload, err := json.Marshal(map[string]any{"responses": responses})
+	if err != nil {
+		return nil, err
+	}
+	return &http.Response{
+		StatusCode:    http.StatusOK,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          io.NopCloser(bytes.NewReader(payload)),
+		ContentLength: int64(len(payload)),
+		Request:       req,
+	}, nil
+}
+
+func newBatchCountsClient(t *testing.T, transport *batchCountsTransport) *Client {
+	t.Helper()
+	httpClient := newGraphHTTPClient("test", transport, nil)
+	adapter, err := msgraphsdk.NewGraphRequestAdapterWithParseNodeFactoryAndSerializationWriterFactoryAndHttpClient(
+		&authentication.AnonymousAuthenticationProvider{}, nil, nil, httpClient,
+	)
+	require.NoError(t, err)
+
+	client := &Client{config: &Config{}}
+	client.SetService(internal.NewRealGraphService(msgraphsdk.NewGraphServiceClient(adapter), httpClient))
+	return client
+}
+
+func TestClient_GetFolderCounts_RetriesThrottledBatchItems(t *testing.T) {
+	transport := &batchCountsTransport{throttled: map[string]int{"folder-b": 1}}
+	client := newBatchCountsClient(t, transport)
+
+	counts, err := client.GetFolderCounts(context.Background(), []string{"folder-a", "folder-b"})
+
+	require.NoError(t, err)
+	require.Len(t, counts, 2)
+	assert.Equal(t, "folder-a", counts["folder-a"].Name)
+	assert.Equal(t, "folder-b", counts["folder-b"].Name)
+	require.Len(t, transport.batches, 2)
+	assert.ElementsMatch(t, []string{"folder-a", "folder-b"}, transport.batches[0])
+	assert.Equal(t, []string{"folder-b"}, transport.batches[1], "only the throttled item is resent")
+}
+
+func TestClient_GetFolderCounts_ThrottledBatchItemsExhausted(t *testing.T) {
+	transport := &batchCountsTransport{throttled: map[string]int{"folder-b": 100}}
+	client := newBatchCountsClient(t, transport)
+
+	_, err := client.GetFolderCounts(context.Background(), []string{"folder-a", "folder-b"})
+
+	assert.ErrorIs(t, err, core.ErrRateLimited)
+	assert.ErrorContains(t, err, "batch request throttled for folders: folder-b")
+	assert.Len(t, transport.batches, 4, "the first batch and three retries")
+}
+
+func TestClient_GetMessage_ETagRevalidation(t *testing.T) {
+	transport := &messageTransport{}
+	httpClient := newGraphHTTPClient("test", transport, nil)
+	adapter, err := msgraphsdk.NewGraphRequestAdapterWithParseNodeFactoryAndSerializationWriterFactoryAndHttpClient(
+		&authentication.AnonymousAuthenticationProvider{}, nil, nil, httpClient,
+	)
+	require.NoError(t, err)
+
+	client := &Client{config: &Config{MessageCacheSize: 10}}
+	client.SetService(internal.NewRealGraphService(msgraphsdk.NewGraphServiceClient(adapter), httpClient))
+
+	first, err := client.GetMessage(context.Background(), "msg-1")
+	require.NoError(t, err)
+	second, err := client.GetMessage(context.Background(), "msg-1")
+	require.NoError(t, err)
+
+	assert.Equal(t, first, second)
+	assert.Len(t, transport.paths, 2, "the second fetch is a conditional request answered with 304")
+}
+
+func TestConfig_UserAgentDefault(t *testing.T) {
+	config := &Config{}
+	assert.Equal(t, core.DefaultUserAgent, config.userAgent())
+	assert.Contains(t, config.userAgent(), mailbridge.Version)
+
+	config.UserAgent = "my-app/2.1"
+	assert.Equal(t, "my-app/2.1", config.userAgent())
+}
+
+func TestClient_GrantedScopes(t *testing.T) {
+	claims := base64.RawURLEncoding.EncodeToString([]byte(`{"aud":"https://graph.microsoft.com","scp":"Mail.Read Mail.Send User.Read"}`))
+	jwt := "eyJ0eXAiOiJKV1QiLCJhbGciOiJSUzI1NiJ9." + claims + ".signature"
+
+	tests := []struct {
+		name     string
+		token    *oauth2.Token
+		expected []string
+	}{
+		{
+			name:     "no token",
+			expected: nil,
+		},
+		{
+			name:     "scp claim from access token",
+			token:    &oauth2.Token{AccessToken: jwt},
+			expected: []string{"Mail.Read", "Mail.Send", "User.Read"},
+		},
+		{
+			name: "scope field from token response",
+			token: (&oauth2.Token{AccessToken: jwt}).WithExtra(map[string]interface{}{
+				"scope": "Mail.Read offline_access",
+			}),
+			expected: []string{"Mail.Read", "offline_access"},
+		},
+		{
+			name:     "opaque access token",
+			token:    &oauth2.Token{AccessToken: "EwB4A8l6BAAU"},
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &Client{token: tt.token}
+			assert.Equal(t, tt.expected, client.GrantedScopes())
+		})
+	}
+}
+
+func TestClient_RefreshToken_ReauthRequired(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		body       string
+		wantReauth bool
+	}{
+		{
+			name:       "invalid grant",
+			status:     http.StatusBadRequest,
+			body:       `{"error":"invalid_grant","error_description":"Token has been expired or revoked."}`,
+			wantReauth: true,
+		},
+		{
+			name:       "server error",
+			status:     http.StatusInternalServerError,
+			body:       `{"error":"server_error"}`,
+			wantReauth: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client, err := New(&Config{
+				ClientID:     "test-client-id",
+				ClientSecret: "test-secret",
+				TenantID:     "consumers",
+				RedirectURL:  "http://localhost:8080/callback",
+			})
+			require.NoError(t, err)
+			client.oauth2Config.Endpoint.TokenURL = server.URL
+			client.token = &oauth2.Token{
+				AccessToken:  "expired-access",
+				RefreshToken: "revoked-refresh",
+				Expiry:       time.Now().Add(-time.Hour),
+			}
+
+			token, err := client.RefreshToken(context.Background())
+
+			assert.Nil(t, token)
+			require.Error(t, err)
+			assert.Equal(t, tt.wantReauth, errors.Is(err, core.ErrReauthRequired))
+		})
+	}
+}
+
+// newExpiringTokenServer serves an OAuth2 token endpoint that mints a new access token
+// on every refresh, expiring at once: tokens expiring within oauth2's 10s expiry margin
+// are already considered expired
+func newExpiringTokenServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	minted := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		minted++
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"access_token":"access-%d","token_type":"Bearer","refresh_token":"refresh","expires_in":1}`, minted)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestClient_AutoRefresh(t *testing.T) {
+	server := newExpiringTokenServer(t)
+	var persisted []*oauth2.Token
+	client, err := New(&Config{
+		ClientID:       "test-client-id",
+		ClientSecret:   "test-secret",
+		TenantID:       "consumers",
+		RedirectURL:    "http://localhost:8080/callback",
+		OnTokenRefresh: func(token *oauth2.Token) { persisted = append(persisted, token) },
+	})
+	require.NoError(t, err)
+	client.oauth2Config.Endpoint.TokenURL = server.URL
+	expired := &oauth2.Token{AccessToken: "expired-access", RefreshToken: "refresh", Expiry: time.Now().Add(-time.Minute)}
+	require.NoError(t, client.ConnectWithToken(context.Background(), expired))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	provider := &oauth2AuthProvider{tokenSource: client.tokenSource(ctx, expired)}
+	cancel() // refreshes must not depend on the context the client connected with
+
+	var headers []string
+	for range 2 {
+		request := abstractions.NewRequestInformation()
+		require.NoError(t, provider.AuthenticateRequest(context.Background(), request, nil))
+		headers = append(headers, request.Headers.Get("Authorization")...)
+	}
+
+	assert.Equal(t, []string{"Bearer access-1", "Bearer access-2"}, headers)
+	require.Len(t, persisted, 2, "every refresh reaches OnTokenRefresh")
+	assert.Equal(t, "access-1", persisted[0].AccessToken)
+	assert.Same(t, persisted[1], client.GetToken())
+}
+
+func TestClient_AutoRefresh_UsesConfiguredClock(t *testing.T) {
+	server := newExpiringTokenServer(t)
+	clock := core.NewFakeClock(time.Now())
+	client, err := New(&Config{
+		ClientID:     "test-client-id",
+		ClientSecret: "test-secret",
+		TenantID:     "consumers",
+		RedirectURL:  "http://localhost:8080/callback",
+		Clock:        clock,
+	})
+	require.NoError(t, err)
+	client.oauth2Config.Endpoint.TokenURL = server.URL
+	token := &oauth2.Token{AccessToken: "access-0", RefreshToken: "refresh", Expiry: clock.Now().Add(time.Hour)}
+	provider := &oauth2AuthProvider{tokenSource: client.tokenSource(context.Background(), token)}
+
+	authorize := func() string {
+		request := abstractions.NewRequestInformation()
+		require.NoError(t, provider.AuthenticateRequest(context.Background(), request, nil))
+		return request.Headers.Get("Authorization")[0]
+	}
+
+	assert.Equal(t, "Bearer access-0", authorize())
+	clock.Advance(2 * time.Hour)
+	assert.Equal(t, "Bearer access-1", authorize(), "expiry follows the configured clock")
+}
+
+func TestClient_RefreshToken_NotifiesCallback(t *testing.T) {
+	server := newExpiringTokenServer(t)
+	var persisted *oauth2.Token
+	client, err := New(&Config{
+		ClientID:       "test-client-id",
+		ClientSecret:   "test-secret",
+		TenantID:       "consumers",
+		RedirectURL:    "http://localhost:8080/callback",
+		OnTokenRefresh: func(token *oauth2.Token) { persisted = token },
+	})
+	require.NoError(t, err)
+	client.oauth2Config.Endpoint.TokenURL = server.URL
+	client.token = &oauth2.Token{AccessToken: "expired-access", RefreshToken: "refresh", Expiry: time.Now().Add(-time.Minute)}
+
+	token, err := client.RefreshToken(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, "access-1", token.AccessToken)
+	assert.Same(t, token, persisted)
+	assert.Same(t, token, client.GetToken())
+	assert.True(t, client.IsConnected())
+}
+
+func TestOAuth2AuthProvider_RefreshError(t *testing.T) {
+	provider := &oauth2AuthProvider{tokenSource: oauth2.ReuseTokenSource(nil, failingTokenSource{})}
+
+	err := provider.AuthenticateRequest(context.Background(), abstractions.NewRequestInformation(), nil)
+
+	assert.ErrorContains(t, err, "failed to get token")
+}
+
+// failingTokenSource always fails to mint a token
+type failingTokenSource struct{}
+
+func (failingTokenSource) Token() (*oauth2.Token, error) {
+	return nil, errors.New("token endpoint unavailable")
+}
@@ -2,12 +2,18 @@ package outlook
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/danielrivera/mailbridge-go/core"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/microsoft"
 )
 
+const (
+	defaultNotFoundRetries    = 3
+	defaultNotFoundRetryDelay = 500 * time.Millisecond
+)
+
 // Config holds the configuration for connecting to Microsoft Graph API (Outlook).
 type Config struct {
 	ClientID     string   // The application (client) ID from Microsoft Entra ID app registration
@@ -15,6 +21,32 @@ type Config struct {
 	TenantID     string   // The directory (tenant) ID. Use "consumers" for personal Microsoft accounts, "organizations" for work/school accounts, "common" for both, or your specific tenant ID
 	RedirectURL  string   // The redirect URL configured in Microsoft Entra ID app registration
 	Scopes       []string // The Microsoft Graph API scopes (default: Mail.Read, Mail.ReadWrite, offline_access)
+	UserAgent    string   // Application identifier sent with API requests (default: core.DefaultUserAgent)
+
+	DefaultPageSize int // MaxResults used when ListOptions.MaxResults is 0 (default: Graph's page size of 10)
+
+	NotFoundRetries    int           // Extra attempts made when GetOptions.RetryNotFound is set (default: 3; negative disables retries)
+	NotFoundRetryDelay time.Duration // Delay before the first retry, growing linearly per attempt (default: 500ms)
+
+	Clock core.Clock // Source of the current time (default: core.SystemClock)
+
+	// RetryPolicy controls retries of requests Graph throttles with 429 or 503, honoring
+	// Retry-After (default: core.DefaultRetryPolicy). Set MaxRetries to 0 to disable retries.
+	// It replaces the Graph SDK's built-in retry handler.
+	RetryPolicy *core.RetryPolicy
+
+	ResolveFolderNames bool // Report folder display names instead of folder IDs in Email.Labels
+
+	MessageCacheSize int // Messages kept by GetMessage and revalidated with their ETag (default: 0, no caching)
+
+	// ConversionHook, when set, is called with every message converted to a core.Email
+	// and may modify it (e.g. redact PII or tag internal senders). It runs once per
+	// conversion, so messages served from the message cache are not passed to it again.
+	ConversionHook func(*core.Email)
+
+	// OnTokenRefresh, when set, is called with every new token the client obtains,
+	// whether refreshed automatically on expiry or by RefreshToken, so it can be persisted.
+	OnTokenRefresh func(*oauth2.Token)
 }
 
 // Validate checks if the configuration is valid.
@@ -32,9 +64,84 @@ func (c *Config) Validate() error {
 	if c.RedirectURL == "" {
 		return &core.ConfigError{Field: "RedirectURL", Message: "RedirectURL is required"}
 	}
+	if c.DefaultPageSize < 0 {
+		return &core.ConfigError{Field: "DefaultPageSize", Message: "DefaultPageSize cannot be negative"}
+	}
+	if c.RetryPolicy != nil && c.RetryPolicy.MaxRetries < 0 {
+		return &core.ConfigError{Field: "RetryPolicy", Message: "RetryPolicy.MaxRetries cannot be negative"}
+	}
+	if c.MessageCacheSize < 0 {
+		return &core.ConfigError{Field: "MessageCacheSize", Message: "MessageCacheSize cannot be negative"}
+	}
 	return nil
 }
 
+// listOptions returns opts with MaxResults set to DefaultPageSize when the caller left it unset.
+// The caller's options are never modified.
+func (c *Config) listOptions(opts *core.ListOptions) *core.ListOptions {
+	if c == nil || c.DefaultPageSize <= 0 {
+		return opts
+	}
+	if opts == nil {
+		return &core.ListOptions{MaxResults: int64(c.DefaultPageSize)}
+	}
+	if opts.MaxResults > 0 {
+		return opts
+	}
+	withDefault := *opts
+	withDefault.MaxResults = int64(c.DefaultPageSize)
+	return &withDefault
+}
+
+// notFoundRetries returns the configured retry count for not-found responses, the
+// default when it is unset, or 0 when it is negative.
+func (c *Config) notFoundRetries() int {
+	if c == nil || c.NotFoundRetries == 0 {
+		return defaultNotFoundRetries
+	}
+	return max(c.NotFoundRetries, 0)
+}
+
+// notFoundRetryDelay returns the configured base retry delay or the default.
+func (c *Config) notFoundRetryDelay() time.Duration {
+	if c == nil || c.NotFoundRetryDelay <= 0 {
+		return defaultNotFoundRetryDelay
+	}
+	return c.NotFoundRetryDelay
+}
+
+// messageCacheSize returns the configured message cache size, 0 when caching is disabled.
+func (c *Config) messageCacheSize() int {
+	if c == nil || c.MessageCacheSize < 0 {
+		return 0
+	}
+	return c.MessageCacheSize
+}
+
+// applyConversionHook calls ConversionHook on email, if a hook is set.
+func (c *Config) applyConversionHook(email *core.Email) {
+	if c == nil || c.ConversionHook == nil {
+		return
+	}
+	c.ConversionHook(email)
+}
+
+// retryPolicy returns the configured RetryPolicy or core.DefaultRetryPolicy.
+func (c *Config) retryPolicy() *core.RetryPolicy {
+	if c == nil || c.RetryPolicy == nil {
+		return core.DefaultRetryPolicy()
+	}
+	return c.RetryPolicy
+}
+
+// clock returns the configured Clock or core.SystemClock.
+func (c *Config) clock() core.Clock {
+	if c == nil || c.Clock == nil {
+		return core.SystemClock
+	}
+	return c.Clock
+}
+
 // ToOAuth2Config converts Config to oauth2.Config.
 func (c *Config) ToOAuth2Config() *oauth2.Config {
 	scopes := c.Scopes
@@ -51,6 +158,14 @@ func (c *Config) ToOAuth2Config() *oauth2.Config {
 	}
 }
 
+// userAgent returns the configured User-Agent or the library default.
+func (c *Config) userAgent() string {
+	if c.UserAgent == "" {
+		return core.DefaultUserAgent
+	}
+	return c.UserAgent
+}
+
 // DefaultScopes returns the default Microsoft Graph API scopes for email operations.
 func DefaultScopes() []string {
 	return []string{
@@ -3,6 +3,7 @@ package outlook
 import (
 	"testing"
 
+	"github.com/danielrivera/mailbridge-go/core"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -63,6 +64,42 @@ func TestConfig_Validate(t *testing.T) {
 			wantErr: true,
 			errMsg:  "RedirectURL is required",
 		},
+		{
+			name: "negative default page size",
+			config: &Config{
+				ClientID:        "test-client-id",
+				ClientSecret:    "test-client-secret",
+				TenantID:        "consumers",
+				RedirectURL:     "http://localhost:8080/callback",
+				DefaultPageSize: -1,
+			},
+			wantErr: true,
+			errMsg:  "DefaultPageSize cannot be negative",
+		},
+		{
+			name: "negative message cache size",
+			config: &Config{
+				ClientID:         "test-client-id",
+				ClientSecret:     "test-client-secret",
+				TenantID:         "consumers",
+				RedirectURL:      "http://localhost:8080/callback",
+				MessageCacheSize: -1,
+			},
+			wantErr: true,
+			errMsg:  "MessageCacheSize cannot be negative",
+		},
+		{
+			name: "negative retry count",
+			config: &Config{
+				ClientID:     "test-client-id",
+				ClientSecret: "test-client-secret",
+				TenantID:     "consumers",
+				RedirectURL:  "http://localhost:8080/callback",
+				RetryPolicy:  &core.RetryPolicy{MaxRetries: -1},
+			},
+			wantErr: true,
+			errMsg:  "RetryPolicy.MaxRetries cannot be negative",
+		},
 	}
 
 	for _, tt := range tests {
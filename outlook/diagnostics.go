@@ -0,0 +1,39 @@
+package outlook
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/danielrivera/mailbridge-go/core"
+)
+
+// Diagnostics returns a health report for the client: connection status, token expiry,
+// granted scopes and the latency of a sample API call (fetching the user profile).
+// Token values are never included in the report.
+func (c *Client) Diagnostics(ctx context.Context) (*core.Diagnostics, error) {
+	report := &core.Diagnostics{Provider: "outlook"}
+
+	if token := c.GetToken(); token != nil {
+		report.HasToken = true
+		report.HasRefreshToken = token.RefreshToken != ""
+		report.TokenExpiry = token.Expiry
+		report.TokenExpired = !token.Expiry.IsZero() && c.config.clock().Now().After(token.Expiry)
+		report.Scopes = c.GrantedScopes()
+	}
+
+	service, err := c.getService()
+	if err != nil {
+		return report, nil
+	}
+	report.Connected = true
+
+	clock := c.config.clock()
+	start := clock.Now()
+	_, err = service.GetMeService().Get(ctx)
+	report.Latency = clock.Now().Sub(start)
+	if err != nil {
+		report.Error = handleODataError(fmt.Errorf("failed to get user profile: %w", err)).Error()
+	}
+
+	return report, nil
+}
@@ -0,0 +1,107 @@
+package outlook
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/danielrivera/mailbridge-go/core"
+	outlooktest "github.com/danielrivera/mailbridge-go/outlook/testing"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+func TestClient_Diagnostics(t *testing.T) {
+	ctx := context.Background()
+	expiry := time.Now().Add(time.Hour).Truncate(time.Second)
+	accessToken := "eyJhbGciOiJub25lIn0." +
+		base64.RawURLEncoding.EncodeToString([]byte(`{"scp":"Mail.Read Mail.ReadWrite"}`)) + ".secret-signature"
+
+	client := &Client{token: &oauth2.Token{
+		AccessToken:  accessToken,
+		RefreshToken: "super-secret-refresh",
+		Expiry:       expiry,
+	}}
+
+	mockGraphService := &outlooktest.MockGraphService{}
+	mockMeService := &outlooktest.MockMeService{}
+	mockGraphService.On("GetMeService").Return(mockMeService)
+	mockMeService.On("Get", ctx).Return(models.NewUser(), nil)
+	client.SetService(mockGraphService)
+
+	report, err := client.Diagnostics(ctx)
+
+	require.NoError(t, err)
+	assert.Equal(t, "outlook", report.Provider)
+	assert.True(t, report.Connected)
+	assert.True(t, report.HasToken)
+	assert.True(t, report.HasRefreshToken)
+	assert.False(t, report.TokenExpired)
+	assert.Equal(t, expiry, report.TokenExpiry)
+	assert.Equal(t, []string{"Mail.Read", "Mail.ReadWrite"}, report.Scopes)
+	assert.Empty(t, report.Error)
+
+	encoded, err := json.Marshal(report)
+	require.NoError(t, err)
+	assert.NotContains(t, string(encoded), accessToken)
+	assert.NotContains(t, string(encoded), "secret-signature")
+	assert.NotContains(t, string(encoded), "super-secret-refresh")
+}
+
+func TestClient_Diagnostics_RoundTripError(t *testing.T) {
+	ctx := context.Background()
+	client := &Client{}
+
+	mockGraphService := &outlooktest.MockGraphService{}
+	mockMeService := &outlooktest.MockMeService{}
+	mockGraphService.On("GetMeService").Return(mockMeService)
+	mockMeService.On("Get", ctx).Return(nil, errors.New("unauthorized"))
+	client.SetService(mockGraphService)
+
+	report, err := client.Diagnostics(ctx)
+
+	require.NoError(t, err)
+	assert.True(t, report.Connected)
+	assert.False(t, report.HasToken)
+	assert.Contains(t, report.Error, "unauthorized")
+}
+
+func TestClient_Diagnostics_NotConnected(t *testing.T) {
+	client := &Client{token: &oauth2.Token{
+		AccessToken: "opaque",
+		Expiry:      time.Now().Add(-time.Minute),
+	}}
+
+	report, err := client.Diagnostics(context.Background())
+
+	require.NoError(t, err)
+	assert.False(t, report.Connected)
+	assert.True(t, report.HasToken)
+	assert.True(t, report.TokenExpired)
+	assert.Zero(t, report.Latency)
+}
+
+func TestClient_Diagnostics_TokenExpiryWithFakeClock(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	clock := core.NewFakeClock(now)
+
+	client := &Client{
+		config: &Config{Clock: clock},
+		token:  &oauth2.Token{AccessToken: "opaque", Expiry: now.Add(time.Minute)},
+	}
+
+	report, err := client.Diagnostics(context.Background())
+	require.NoError(t, err)
+	assert.False(t, report.TokenExpired)
+
+	clock.Advance(2 * time.Minute)
+
+	report, err = client.Diagnostics(context.Background())
+	require.NoError(t, err)
+	assert.True(t, report.TokenExpired)
+}
@@ -101,7 +101,7 @@
 // to core.Label types for consistency:
 //
 //	// List all folders
-//	folders, err := client.ListFolders(ctx)
+//	folders, err := client.ListFolders(ctx, nil)
 //
 //	// List messages in a specific folder
 //	messages, err := client.ListMessagesInFolder(ctx, outlook.FolderInbox, opts)
@@ -8,37 +8,142 @@ import (
 	"github.com/microsoftgraph/msgraph-sdk-go/users"
 
 	"github.com/danielrivera/mailbridge-go/core"
+	"github.com/danielrivera/mailbridge-go/outlook/internal"
 )
 
-// ListFolders retrieves all mail folders (similar to Gmail labels).
-func (c *Client) ListFolders(ctx context.Context) ([]*core.Label, error) {
-	if !c.IsConnected() {
-		return nil, fmt.Errorf("client not connected")
+// Folder types reported in core.Label.Type and accepted by FolderListOptions.Type.
+const (
+	FolderTypeSystem = "system"
+	FolderTypeUser   = "user"
+)
+
+// FolderListOptions filters the folders returned by ListFolders.
+type FolderListOptions struct {
+	Type          string // FolderTypeSystem or FolderTypeUser; empty returns both
+	IncludeHidden bool   // Include hidden folders, which Graph omits by default
+	Recursive     bool   // Include child folders at every level; costs one request per folder with children
+}
+
+// ListFolders retrieves mail folders (similar to Gmail labels).
+// A nil opts returns all visible top-level folders. With Recursive, child folders follow
+// their parent. Each label's ParentID and Depth describe its place among the listed
+// folders; top-level folders have no ParentID.
+func (c *Client) ListFolders(ctx context.Context, opts *FolderListOptions) ([]*core.Label, error) {
+	service, err := c.getService()
+	if err != nil {
+		return nil, err
+	}
+
+	var config *users.ItemMailFoldersRequestBuilderGetRequestConfiguration
+	if opts != nil && opts.IncludeHidden {
+		includeHidden := "true"
+		config = &users.ItemMailFoldersRequestBuilderGetRequestConfiguration{
+			QueryParameters: &users.ItemMailFoldersRequestBuilderGetQueryParameters{
+				IncludeHiddenFolders: &includeHidden,
+			},
+		}
 	}
 
-	foldersService := c.service.GetMeService().GetMailFoldersService()
-	result, err := foldersService.List(ctx)
+	foldersService := service.GetMeService().GetMailFoldersService()
+	result, err := foldersService.List(ctx, config)
 	if err != nil {
 		return nil, handleODataError(fmt.Errorf("failed to list folders: %w", err))
 	}
 
-	folders := result.GetValue()
-	labels := make([]*core.Label, 0, len(folders))
+	folders, err := remainingFolderPages(ctx, foldersService, result)
+	if err != nil {
+		return nil, err
+	}
+	if opts != nil && opts.Recursive {
+		folders, err = listChildFolders(ctx, foldersService, folders, opts.IncludeHidden)
+		if err != nil {
+			return nil, err
+		}
+	}
 
+	labels := make([]*core.Label, 0, len(folders))
+	depths := make(map[string]int, len(folders))
 	for _, folder := range folders {
-		labels = append(labels, convertFolder(folder))
+		label := convertFolder(folder)
+
+		// Parents precede their children, so a listed parent's depth is already known
+		if parentDepth, ok := depths[derefString(folder.GetParentFolderId())]; ok {
+			label.ParentID = derefString(folder.GetParentFolderId())
+			label.Depth = parentDepth + 1
+		}
+		depths[label.ID] = label.Depth
+
+		if opts != nil && opts.Type != "" && label.Type != opts.Type {
+			continue
+		}
+		labels = append(labels, label)
 	}
 
 	return labels, nil
 }
 
+// listChildFolders returns folders with the descendants of each folder inserted after it,
+// fetching child folders for every folder that reports children.
+func listChildFolders(ctx context.Context, foldersService internal.MailFoldersService, folders []models.MailFolderable, includeHidden bool) ([]models.MailFolderable, error) {
+	var config *users.ItemMailFoldersItemChildFoldersRequestBuilderGetRequestConfiguration
+	if includeHidden {
+		include := "true"
+		config = &users.ItemMailFoldersItemChildFoldersRequestBuilderGetRequestConfiguration{
+			QueryParameters: &users.ItemMailFoldersItemChildFoldersRequestBuilderGetQueryParameters{
+				IncludeHiddenFolders: &include,
+			},
+		}
+	}
+
+	all := make([]models.MailFolderable, 0, len(folders))
+	for _, folder := range folders {
+		all = append(all, folder)
+		if count := folder.GetChildFolderCount(); count == nil || *count == 0 {
+			continue
+		}
+
+		folderID := derefString(folder.GetId())
+		result, err := foldersService.ListChildren(ctx, folderID, config)
+		if err != nil {
+			return nil, handleODataError(fmt.Errorf("failed to list child folders of %s: %w", folderID, err))
+		}
+		children, err := remainingFolderPages(ctx, foldersService, result)
+		if err != nil {
+			return nil, err
+		}
+		children, err = listChildFolders(ctx, foldersService, children, includeHidden)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, children...)
+	}
+	return all, nil
+}
+
+// remainingFolderPages returns the folders of result followed by those of every later
+// page, following @odata.nextLink until Graph returns none. Graph pages folder
+// listings at 10 folders by default.
+func remainingFolderPages(ctx context.Context, foldersService internal.MailFoldersService, result models.MailFolderCollectionResponseable) ([]models.MailFolderable, error) {
+	folders := result.GetValue()
+	for nextLink := derefString(result.GetOdataNextLink()); nextLink != ""; nextLink = derefString(result.GetOdataNextLink()) {
+		var err error
+		result, err = foldersService.ListNextPage(ctx, nextLink)
+		if err != nil {
+			return nil, handleODataError(fmt.Errorf("failed to list folders: %w", err))
+		}
+		folders = append(folders, result.GetValue()...)
+	}
+	return folders, nil
+}
+
 // GetFolder retrieves a specific folder by its ID.
 func (c *Client) GetFolder(ctx context.Context, folderID string) (*core.Label, error) {
-	if !c.IsConnected() {
-		return nil, fmt.Errorf("client not connected")
+	service, err := c.getService()
+	if err != nil {
+		return nil, err
 	}
 
-	foldersService := c.service.GetMeService().GetMailFoldersService()
+	foldersService := service.GetMeService().GetMailFoldersService()
 	folder, err := foldersService.Get(ctx, folderID)
 	if err != nil {
 		return nil, handleODataError(fmt.Errorf("failed to get folder %s: %w", folderID, err))
@@ -47,67 +152,261 @@ func (c *Client) GetFolder(ctx context.Context, folderID string) (*core.Label, e
 	return convertFolder(folder), nil
 }
 
+// GetMessageLabels returns the folder holding a message as a label, with its type and
+// message counts. Outlook files each message in exactly one folder, so the result holds
+// a single label; only the message's parentFolderId is fetched to find it.
+func (c *Client) GetMessageLabels(ctx context.Context, messageID string) ([]core.Label, error) {
+	service, err := c.getService()
+	if err != nil {
+		return nil, err
+	}
+
+	config := &users.ItemMessagesMessageItemRequestBuilderGetRequestConfiguration{
+		QueryParameters: &users.ItemMessagesMessageItemRequestBuilderGetQueryParameters{
+			Select: []string{"id", "parentFolderId"},
+		},
+	}
+	message, err := service.GetMeService().GetMessagesService().GetWithConfig(ctx, messageID, config)
+	if err != nil {
+		return nil, handleODataError(fmt.Errorf("failed to get message %s: %w", messageID, err))
+	}
+
+	folderID := derefString(message.GetParentFolderId())
+	if folderID == "" {
+		return []core.Label{}, nil
+	}
+
+	folder, err := service.GetMeService().GetMailFoldersService().Get(ctx, folderID)
+	if err != nil {
+		return nil, handleODataError(fmt.Errorf("failed to get folder %s: %w", folderID, err))
+	}
+	return []core.Label{*convertFolder(folder)}, nil
+}
+
+// GetFolderCounts returns the total and unread message counts of several folders,
+// fetched with $batch requests (up to 20 folders per request) instead of one GetFolder
+// call each. The result is keyed by the requested folder IDs, so well-known names
+// such as FolderInbox can be used. Duplicate IDs are fetched once. Folders Graph
+// throttles within a batch are fetched again after their Retry-After; if they stay
+// throttled, the error wraps core.ErrRateLimited.
+func (c *Client) GetFolderCounts(ctx context.Context, folderIDs []string) (map[string]core.Label, error) {
+	service, err := c.getService()
+	if err != nil {
+		return nil, err
+	}
+
+	unique := make([]string, 0, len(folderIDs))
+	seen := make(map[string]bool, len(folderIDs))
+	for _, folderID := range folderIDs {
+		if folderID == "" {
+			return nil, fmt.Errorf("folder ID cannot be empty")
+		}
+		if !seen[folderID] {
+			seen[folderID] = true
+			unique = append(unique, folderID)
+		}
+	}
+
+	counts := make(map[string]core.Label, len(unique))
+	if len(unique) == 0 {
+		return counts, nil
+	}
+
+	folders, err := service.GetMeService().GetMailFoldersService().BatchGetCounts(ctx, unique)
+	if err != nil {
+		return nil, handleODataError(fmt.Errorf("failed to get folder counts: %w", err))
+	}
+
+	for folderID, folder := range folders {
+		counts[folderID] = *convertFolder(folder)
+	}
+	return counts, nil
+}
+
 // CreateFolder creates a new mail folder.
 func (c *Client) CreateFolder(ctx context.Context, name string) (*core.Label, error) {
-	if !c.IsConnected() {
-		return nil, fmt.Errorf("client not connected")
+	service, err := c.getService()
+	if err != nil {
+		return nil, err
 	}
 
 	if name == "" {
 		return nil, fmt.Errorf("folder name cannot be empty")
 	}
 
-	foldersService := c.service.GetMeService().GetMailFoldersService()
+	foldersService := service.GetMeService().GetMailFoldersService()
 	folder, err := foldersService.Create(ctx, name)
 	if err != nil {
 		return nil, handleODataError(fmt.Errorf("failed to create folder %s: %w", name, err))
 	}
+	c.invalidateFolderNames()
+
+	return convertFolder(folder), nil
+}
+
+// CreateSearchFolder creates a search folder: a saved search whose contents are the
+// messages in sourceFolderIDs that match filter, an OData $filter expression such as
+// "contains(subject, 'invoice') and hasAttachments eq true". The folder is created
+// under the well-known Search Folders folder and stays up to date as mail arrives.
+func (c *Client) CreateSearchFolder(ctx context.Context, name string, filter string, sourceFolderIDs []string) (*core.Label, error) {
+	service, err := c.getService()
+	if err != nil {
+		return nil, err
+	}
+
+	if name == "" {
+		return nil, fmt.Errorf("folder name cannot be empty")
+	}
+	if filter == "" {
+		return nil, fmt.Errorf("search folder filter cannot be empty")
+	}
+	if len(sourceFolderIDs) == 0 {
+		return nil, fmt.Errorf("search folder needs at least one source folder")
+	}
+
+	searchFolder := models.NewMailSearchFolder()
+	searchFolder.SetDisplayName(&name)
+	searchFolder.SetFilterQuery(&filter)
+	searchFolder.SetSourceFolderIds(sourceFolderIDs)
+
+	foldersService := service.GetMeService().GetMailFoldersService()
+	folder, err := foldersService.CreateSearchFolder(ctx, FolderSearchFolders, searchFolder)
+	if err != nil {
+		return nil, handleODataError(fmt.Errorf("failed to create search folder %s: %w", name, err))
+	}
+	c.invalidateFolderNames()
 
 	return convertFolder(folder), nil
 }
 
 // UpdateFolder updates a folder's display name.
 func (c *Client) UpdateFolder(ctx context.Context, folderID, newName string) (*core.Label, error) {
-	if !c.IsConnected() {
-		return nil, fmt.Errorf("client not connected")
+	service, err := c.getService()
+	if err != nil {
+		return nil, err
 	}
 
 	if newName == "" {
 		return nil, fmt.Errorf("folder name cannot be empty")
 	}
 
-	foldersService := c.service.GetMeService().GetMailFoldersService()
+	foldersService := service.GetMeService().GetMailFoldersService()
 	folder, err := foldersService.Update(ctx, folderID, newName)
 	if err != nil {
 		return nil, handleODataError(fmt.Errorf("failed to update folder %s: %w", folderID, err))
 	}
+	c.invalidateFolderNames()
 
 	return convertFolder(folder), nil
 }
 
 // DeleteFolder deletes a mail folder.
 func (c *Client) DeleteFolder(ctx context.Context, folderID string) error {
-	if !c.IsConnected() {
-		return fmt.Errorf("client not connected")
+	service, err := c.getService()
+	if err != nil {
+		return err
 	}
 
-	foldersService := c.service.GetMeService().GetMailFoldersService()
+	foldersService := service.GetMeService().GetMailFoldersService()
 	if err := foldersService.Delete(ctx, folderID); err != nil {
 		return handleODataError(fmt.Errorf("failed to delete folder %s: %w", folderID, err))
 	}
+	c.invalidateFolderNames()
 
 	return nil
 }
 
+// resolveFolderLabels replaces folder IDs in the emails' Labels with folder display names
+// when Config.ResolveFolderNames is set. The folder map is fetched once and cached;
+// it is refreshed after folders are created, renamed or deleted through the client.
+// Resolution is best effort: IDs that cannot be resolved are left unchanged.
+func (c *Client) resolveFolderLabels(ctx context.Context, service internal.GraphService, emails ...*core.Email) {
+	if c.config == nil || !c.config.ResolveFolderNames {
+		return
+	}
+
+	names, err := c.getFolderNames(ctx, service)
+	if err != nil {
+		return
+	}
+
+	for _, email := range emails {
+		for i, label := range email.Labels {
+			if name, ok := names[label]; ok {
+				email.Labels[i] = name
+			}
+		}
+	}
+}
+
+// getFolderNames returns the cached folder ID to display name map, fetching it if needed.
+// The map covers every page of top-level folders and their child folders at every level.
+func (c *Client) getFolderNames(ctx context.Context, service internal.GraphService) (map[string]string, error) {
+	c.mu.RLock()
+	cached := c.folderNames
+	c.mu.RUnlock()
+	if cached != nil {
+		return cached, nil
+	}
+
+	foldersService := service.GetMeService().GetMailFoldersService()
+	result, err := foldersService.List(ctx, nil)
+	if err != nil {
+		return nil, handleODataError(fmt.Errorf("failed to list folders: %w", err))
+	}
+	folders, err := remainingFolderPages(ctx, foldersService, result)
+	if err != nil {
+		return nil, err
+	}
+	folders, err = listChildFolders(ctx, foldersService, folders, false)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]string, len(folders))
+	for _, folder := range folders {
+		if id, name := derefString(folder.GetId()), derefString(folder.GetDisplayName()); id != "" && name != "" {
+			names[id] = name
+		}
+	}
+
+	c.mu.Lock()
+	c.folderNames = names
+	c.mu.Unlock()
+	return names, nil
+}
+
+// invalidateFolderNames drops the cached folder map after a folder change.
+func (c *Client) invalidateFolderNames() {
+	c.mu.Lock()
+	c.folderNames = nil
+	c.mu.Unlock()
+}
+
 // ListMessagesInFolder retrieves messages from a specific folder.
 func (c *Client) ListMessagesInFolder(ctx context.Context, folderID string, opts *core.ListOptions) (*core.ListResponse, error) {
-	if !c.IsConnected() {
-		return nil, fmt.Errorf("client not connected")
+	service, err := c.getService()
+	if err != nil {
+		return nil, err
+	}
+	opts = c.config.listOptions(opts)
+	if err := validateListOptions(opts); err != nil {
+		return nil, err
 	}
 
 	config := &users.ItemMailFoldersItemMessagesRequestBuilderGetRequestConfiguration{}
 	queryParams := &users.ItemMailFoldersItemMessagesRequestBuilderGetQueryParameters{}
 
+	// Resolve the authenticated address before building filters
+	var selfAddress string
+	if opts != nil && opts.ExcludeFromSelf {
+		addr, err := c.getSelfAddress(ctx, service)
+		if err != nil {
+			return nil, err
+		}
+		selfAddress = addr
+	}
+
 	// Apply pagination
 	if opts != nil {
 		if opts.MaxResults > 0 {
@@ -125,19 +424,25 @@ func (c *Client) ListMessagesInFolder(ctx context.Context, folderID string, opts
 		if opts.Query != "" {
 			queryParams.Search = &opts.Query
 		}
+
+		// Apply structured filters
+		if filter := buildFilter(opts, selfAddress); filter != "" {
+			queryParams.Filter = &filter
+		}
+
+		// Graph restricts $orderby combined with $search or $filter; the page is sorted locally anyway
+		if orderBy := orderByClause(opts.OrderBy); orderBy != "" && queryParams.Search == nil && queryParams.Filter == nil {
+			queryParams.Orderby = []string{orderBy}
+		}
 	}
 
 	// Select fields to retrieve
-	selectFields := []string{
-		"id", "subject", "from", "toRecipients", "ccRecipients", "bccRecipients",
-		"receivedDateTime", "sentDateTime", "hasAttachments", "isRead", "body",
-		"bodyPreview", "parentFolderId",
-	}
-	queryParams.Select = selectFields
+	queryParams.Select = listSelectFields(opts)
+	queryParams.Expand = listExpandFields(opts)
 
 	config.QueryParameters = queryParams
 
-	foldersService := c.service.GetMeService().GetMailFoldersService()
+	foldersService := service.GetMeService().GetMailFoldersService()
 	result, err := foldersService.GetMessages(ctx, folderID, config)
 	if err != nil {
 		return nil, handleODataError(fmt.Errorf("failed to list messages in folder %s: %w", folderID, err))
@@ -150,6 +455,13 @@ func (c *Client) ListMessagesInFolder(ctx context.Context, folderID string, opts
 		email := c.convertMessage(msg)
 		emails = append(emails, email)
 	}
+	if opts != nil {
+		emails = core.FilterByUnsubscribe(emails, opts.HasUnsubscribe)
+	}
+	c.resolveFolderLabels(ctx, service, emails...)
+	if opts != nil {
+		core.SortEmails(emails, opts.OrderBy)
+	}
 
 	// Calculate next page token
 	var nextPageToken string
@@ -177,11 +489,11 @@ func convertFolder(folder models.MailFolderable) *core.Label {
 	// Map system folders to types
 	switch label.Name {
 	case "Inbox":
-		label.Type = "system"
+		label.Type = FolderTypeSystem
 	case "Sent Items", "Drafts", "Deleted Items", "Junk Email":
-		label.Type = "system"
+		label.Type = FolderTypeSystem
 	default:
-		label.Type = "user"
+		label.Type = FolderTypeUser
 	}
 
 	// Message counts
@@ -198,11 +510,12 @@ func convertFolder(folder models.MailFolderable) *core.Label {
 
 // Well-known folder IDs in Microsoft Graph
 const (
-	FolderInbox        = "inbox"
-	FolderDrafts       = "drafts"
-	FolderSentItems    = "sentitems"
-	FolderDeletedItems = "deleteditems"
-	FolderJunkEmail    = "junkemail"
-	FolderOutbox       = "outbox"
-	FolderArchive      = "archive"
+	FolderInbox         = "inbox"
+	FolderDrafts        = "drafts"
+	FolderSentItems     = "sentitems"
+	FolderDeletedItems  = "deleteditems"
+	FolderJunkEmail     = "junkemail"
+	FolderOutbox        = "outbox"
+	FolderArchive       = "archive"
+	FolderSearchFolders = "searchfolders" // Parent of the mailbox's search folders
 )
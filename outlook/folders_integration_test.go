@@ -2,6 +2,7 @@ package outlook
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"github.com/danielrivera/mailbridge-go/core"
@@ -10,6 +11,7 @@ import (
 	"github.com/microsoftgraph/msgraph-sdk-go/users"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // Helper to create test folder
@@ -42,7 +44,7 @@ func createTestClientForFolders() (*Client, *outlooktest.MockGraphService, *outl
 	mockMeService.On("GetMailFoldersService").Return(mockFoldersService)
 
 	// Inject mocked service
-	client.service = mockGraphService
+	client.SetService(mockGraphService)
 
 	return client, mockGraphService, mockMeService, mockFoldersService
 }
@@ -60,10 +62,10 @@ func TestClient_ListFolders(t *testing.T) {
 	}
 	mockResponse.SetValue(folders)
 
-	mockFoldersService.On("List", ctx).Return(mockResponse, nil)
+	mockFoldersService.On("List", ctx, mock.Anything).Return(mockResponse, nil)
 
 	// Test
-	result, err := client.ListFolders(ctx)
+	result, err := client.ListFolders(ctx, nil)
 
 	// Assert
 	assert.NoError(t, err)
@@ -93,11 +95,133 @@ func TestClient_ListFolders(t *testing.T) {
 	mockFoldersService.AssertExpectations(t)
 }
 
+func TestClient_ListFolders_FilterByType(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      *FolderListOptions
+		wantNames []string
+	}{
+		{"nil options", nil, []string{"Inbox", "Projects", "Drafts", "Receipts"}},
+		{"all types", &FolderListOptions{}, []string{"Inbox", "Projects", "Drafts", "Receipts"}},
+		{"system only", &FolderListOptions{Type: FolderTypeSystem}, []string{"Inbox", "Drafts"}},
+		{"user only", &FolderListOptions{Type: FolderTypeUser}, []string{"Projects", "Receipts"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, _, _, mockFoldersService := createTestClientForFolders()
+			ctx := context.Background()
+
+			mockResponse := models.NewMailFolderCollectionResponse()
+			mockResponse.SetValue([]models.MailFolderable{
+				createTestFolder("folder-1", "Inbox", 10, 1),
+				createTestFolder("folder-2", "Projects", 5, 0),
+				createTestFolder("folder-3", "Drafts", 2, 0),
+				createTestFolder("folder-4", "Receipts", 7, 3),
+			})
+			mockFoldersService.On("List", ctx, mock.Anything).Return(mockResponse, nil)
+
+			result, err := client.ListFolders(ctx, tt.opts)
+
+			assert.NoError(t, err)
+			names := make([]string, 0, len(result))
+			for _, label := range result {
+				names = append(names, label.Name)
+			}
+			assert.Equal(t, tt.wantNames, names)
+		})
+	}
+}
+
+func TestClient_ListFolders_IncludeHidden(t *testing.T) {
+	client, _, _, mockFoldersService := createTestClientForFolders()
+	ctx := context.Background()
+
+	var capturedConfig *users.ItemMailFoldersRequestBuilderGetRequestConfiguration
+	mockFoldersService.On("List", ctx, mock.Anything).
+		Run(func(args mock.Arguments) {
+			capturedConfig = args.Get(1).(*users.ItemMailFoldersRequestBuilderGetRequestConfiguration)
+		}).
+		Return(models.NewMailFolderCollectionResponse(), nil)
+
+	_, err := client.ListFolders(ctx, &FolderListOptions{IncludeHidden: true})
+
+	assert.NoError(t, err)
+	if assert.NotNil(t, capturedConfig) {
+		assert.Equal(t, "true", *capturedConfig.QueryParameters.IncludeHiddenFolders)
+	}
+}
+
+func TestClient_ListFolders_Recursive(t *testing.T) {
+	client, _, _, mockFoldersService := createTestClientForFolders()
+	ctx := context.Background()
+
+	nested := func(id, name, parentID string, children int32) models.MailFolderable {
+		folder := createTestFolder(id, name, 0, 0).(*models.MailFolder)
+		folder.SetParentFolderId(&parentID)
+		folder.SetChildFolderCount(&children)
+		return folder
+	}
+	collection := func(folders ...models.MailFolderable) models.MailFolderCollectionResponseable {
+		response := models.NewMailFolderCollectionResponse()
+		response.SetValue(folders)
+		return response
+	}
+
+	mockFoldersService.On("List", ctx, mock.Anything).Return(collection(
+		nested("inbox-id", "Inbox", "root-id", 1),
+		nested("projects-id", "Projects", "root-id", 0),
+	), nil)
+	mockFoldersService.On("ListChildren", ctx, "inbox-id", mock.Anything).Return(collection(
+		nested("clients-id", "Clients", "inbox-id", 1),
+	), nil)
+	mockFoldersService.On("ListChildren", ctx, "clients-id", mock.Anything).Return(collection(
+		nested("acme-id", "Acme", "clients-id", 0),
+	), nil)
+
+	labels, err := client.ListFolders(ctx, &FolderListOptions{Recursive: true})
+
+	assert.NoError(t, err)
+	type node struct {
+		ID, ParentID string
+		Depth        int
+	}
+	var got []node
+	for _, label := range labels {
+		got = append(got, node{label.ID, label.ParentID, label.Depth})
+	}
+	assert.Equal(t, []node{
+		{"inbox-id", "", 0},
+		{"clients-id", "inbox-id", 1},
+		{"acme-id", "clients-id", 2},
+		{"projects-id", "", 0},
+	}, got)
+	mockFoldersService.AssertNotCalled(t, "ListChildren", ctx, "projects-id", mock.Anything)
+}
+
+func TestClient_ListFolders_NotRecursiveByDefault(t *testing.T) {
+	client, _, _, mockFoldersService := createTestClientForFolders()
+	ctx := context.Background()
+
+	inbox := createTestFolder("inbox-id", "Inbox", 0, 0).(*models.MailFolder)
+	children := int32(2)
+	inbox.SetChildFolderCount(&children)
+	response := models.NewMailFolderCollectionResponse()
+	response.SetValue([]models.MailFolderable{inbox})
+	mockFoldersService.On("List", ctx, mock.Anything).Return(response, nil)
+
+	labels, err := client.ListFolders(ctx, nil)
+
+	assert.NoError(t, err)
+	assert.Len(t, labels, 1)
+	mockFoldersService.AssertNotCalled(t, "ListChildren", mock.Anything, mock.Anything, mock.Anything)
+}
+
 func TestClient_ListFolders_NotConnected(t *testing.T) {
 	client := &Client{}
 	ctx := context.Background()
 
-	result, err := client.ListFolders(ctx)
+	result, err := client.ListFolders(ctx, nil)
 
 	assert.Error(t, err)
 	assert.Nil(t, result)
@@ -127,6 +251,94 @@ func TestClient_GetFolder(t *testing.T) {
 	mockFoldersService.AssertExpectations(t)
 }
 
+func TestClient_GetMessageLabels(t *testing.T) {
+	client, _, mockMeService, mockFoldersService := createTestClientForFolders()
+	mockMessagesService := &outlooktest.MockMessagesService{}
+	mockMeService.On("GetMessagesService").Return(mockMessagesService)
+	ctx := context.Background()
+
+	message := models.NewMessage()
+	folderID := "folder-work"
+	message.SetParentFolderId(&folderID)
+
+	var config *users.ItemMessagesMessageItemRequestBuilderGetRequestConfiguration
+	mockMessagesService.On("GetWithConfig", ctx, "msg-123", mock.Anything).
+		Run(func(args mock.Arguments) {
+			config = args.Get(2).(*users.ItemMessagesMessageItemRequestBuilderGetRequestConfiguration)
+		}).
+		Return(message, nil)
+	mockFoldersService.On("Get", ctx, "folder-work").Return(createTestFolder("folder-work", "Work", 42, 7), nil)
+
+	result, err := client.GetMessageLabels(ctx, "msg-123")
+
+	require.NoError(t, err)
+	assert.Equal(t, []core.Label{
+		{ID: "folder-work", Name: "Work", Type: "user", TotalMessages: 42, UnreadMessages: 7},
+	}, result)
+	assert.Equal(t, []string{"id", "parentFolderId"}, config.QueryParameters.Select)
+	mockMessagesService.AssertNotCalled(t, "Get", mock.Anything, mock.Anything)
+}
+
+func TestClient_GetMessageLabels_Errors(t *testing.T) {
+	client, _, mockMeService, mockFoldersService := createTestClientForFolders()
+	mockMessagesService := &outlooktest.MockMessagesService{}
+	mockMeService.On("GetMessagesService").Return(mockMessagesService)
+	ctx := context.Background()
+
+	mockMessagesService.On("GetWithConfig", ctx, "msg-gone", mock.Anything).Return(nil, errors.New("not found"))
+	_, err := client.GetMessageLabels(ctx, "msg-gone")
+	assert.ErrorContains(t, err, "failed to get message msg-gone")
+
+	mockMessagesService.On("GetWithConfig", ctx, "msg-orphan", mock.Anything).Return(models.NewMessage(), nil)
+	result, err := client.GetMessageLabels(ctx, "msg-orphan")
+	require.NoError(t, err)
+	assert.Empty(t, result)
+	mockFoldersService.AssertNotCalled(t, "Get", mock.Anything, mock.Anything)
+}
+
+func TestClient_GetFolderCounts(t *testing.T) {
+	client, _, _, mockFoldersService := createTestClientForFolders()
+	ctx := context.Background()
+
+	mockFoldersService.On("BatchGetCounts", ctx, []string{"inbox", "folder-work", "folder-news"}).Return(map[string]models.MailFolderable{
+		"inbox":       createTestFolder("folder-inbox", "Inbox", 150, 20),
+		"folder-work": createTestFolder("folder-work", "Work", 42, 7),
+		"folder-news": createTestFolder("folder-news", "News", 300, 0),
+	}, nil)
+
+	counts, err := client.GetFolderCounts(ctx, []string{"inbox", "folder-work", "folder-news", "inbox"})
+
+	require.NoError(t, err)
+	require.Len(t, counts, 3)
+	assert.Equal(t, "folder-inbox", counts["inbox"].ID)
+	assert.Equal(t, 150, counts["inbox"].TotalMessages)
+	assert.Equal(t, 20, counts["inbox"].UnreadMessages)
+	assert.Equal(t, 42, counts["folder-work"].TotalMessages)
+	assert.Equal(t, 7, counts["folder-work"].UnreadMessages)
+	assert.Equal(t, 300, counts["folder-news"].TotalMessages)
+	assert.Equal(t, 0, counts["folder-news"].UnreadMessages)
+	mockFoldersService.AssertNumberOfCalls(t, "BatchGetCounts", 1)
+	mockFoldersService.AssertNotCalled(t, "Get", mock.Anything, mock.Anything)
+}
+
+func TestClient_GetFolderCounts_Errors(t *testing.T) {
+	client, _, _, mockFoldersService := createTestClientForFolders()
+	ctx := context.Background()
+
+	counts, err := client.GetFolderCounts(ctx, nil)
+	require.NoError(t, err)
+	assert.Empty(t, counts)
+
+	_, err = client.GetFolderCounts(ctx, []string{"inbox", ""})
+	assert.EqualError(t, err, "folder ID cannot be empty")
+	mockFoldersService.AssertNotCalled(t, "BatchGetCounts", mock.Anything, mock.Anything)
+
+	mockFoldersService.On("BatchGetCounts", ctx, []string{"folder-gone"}).Return(nil, errors.New("batch request failed for folders: folder-gone"))
+
+	_, err = client.GetFolderCounts(ctx, []string{"folder-gone"})
+	assert.ErrorContains(t, err, "failed to get folder counts: batch request failed for folders: folder-gone")
+}
+
 func TestClient_GetFolder_NotConnected(t *testing.T) {
 	client := &Client{}
 	ctx := context.Background()
@@ -170,6 +382,73 @@ func TestClient_CreateFolder_EmptyName(t *testing.T) {
 	assert.Contains(t, err.Error(), "folder name cannot be empty")
 }
 
+func TestClient_CreateSearchFolder(t *testing.T) {
+	client, _, _, mockFoldersService := createTestClientForFolders()
+	ctx := context.Background()
+
+	filter := "contains(subject, 'invoice') and hasAttachments eq true"
+	var created models.MailSearchFolderable
+	mockFoldersService.On("CreateSearchFolder", ctx, FolderSearchFolders, mock.Anything).
+		Run(func(args mock.Arguments) {
+			created = args.Get(2).(models.MailSearchFolderable)
+		}).
+		Return(createTestFolder("folder-search", "Invoices", 3, 1), nil)
+
+	result, err := client.CreateSearchFolder(ctx, "Invoices", filter, []string{FolderInbox, "folder-archive"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "folder-search", result.ID)
+	assert.Equal(t, "Invoices", result.Name)
+	assert.Equal(t, 3, result.TotalMessages)
+	require.NotNil(t, created)
+	assert.Equal(t, "Invoices", *created.GetDisplayName())
+	assert.Equal(t, filter, *created.GetFilterQuery())
+	assert.Equal(t, []string{"inbox", "folder-archive"}, created.GetSourceFolderIds())
+	assert.Equal(t, "#microsoft.graph.mailSearchFolder", *created.GetOdataType())
+	mockFoldersService.AssertExpectations(t)
+}
+
+func TestClient_CreateSearchFolder_Validation(t *testing.T) {
+	tests := []struct {
+		name          string
+		folderName    string
+		filter        string
+		sources       []string
+		expectedError string
+	}{
+		{"empty name", "", "isRead eq false", []string{FolderInbox}, "folder name cannot be empty"},
+		{"empty filter", "Unread", "", []string{FolderInbox}, "search folder filter cannot be empty"},
+		{"no source folders", "Unread", "isRead eq false", nil, "at least one source folder"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, _, _, mockFoldersService := createTestClientForFolders()
+
+			result, err := client.CreateSearchFolder(context.Background(), tt.folderName, tt.filter, tt.sources)
+
+			require.Error(t, err)
+			assert.Nil(t, result)
+			assert.Contains(t, err.Error(), tt.expectedError)
+			mockFoldersService.AssertNotCalled(t, "CreateSearchFolder", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestClient_CreateSearchFolder_Error(t *testing.T) {
+	client, _, _, mockFoldersService := createTestClientForFolders()
+	ctx := context.Background()
+
+	mockFoldersService.On("CreateSearchFolder", ctx, FolderSearchFolders, mock.Anything).
+		Return(nil, errors.New("invalid filter"))
+
+	result, err := client.CreateSearchFolder(ctx, "Broken", "bogus(", []string{FolderInbox})
+
+	require.Error(t, err)
+	assert.Nil(t, result)
+	assert.Contains(t, err.Error(), "failed to create search folder Broken")
+}
+
 func TestClient_CreateFolder_NotConnected(t *testing.T) {
 	client := &Client{}
 	ctx := context.Background()
@@ -341,6 +620,21 @@ func TestClient_ListMessagesInFolder_WithQuery(t *testing.T) {
 	mockFoldersService.AssertExpectations(t)
 }
 
+func TestClient_ListMessagesInFolder_QueryWithFilterRejected(t *testing.T) {
+	client, _, _, _ := createTestClientForFolders()
+	focused := true
+
+	result, err := client.ListMessagesInFolder(context.Background(), "folder-123", &core.ListOptions{
+		Query:   "invoice",
+		Focused: &focused,
+	})
+
+	assert.Nil(t, result)
+	var configErr *core.ConfigError
+	require.ErrorAs(t, err, &configErr)
+	assert.Contains(t, configErr.Message, "Query cannot be combined with Focused")
+}
+
 func TestClient_ListMessagesInFolder_NotConnected(t *testing.T) {
 	client := &Client{}
 	ctx := context.Background()
@@ -410,3 +704,100 @@ func TestFolderConstants(t *testing.T) {
 	assert.Equal(t, "outbox", FolderOutbox)
 	assert.Equal(t, "archive", FolderArchive)
 }
+
+func TestClient_GetMessage_ResolveFolderNames(t *testing.T) {
+	tests := []struct {
+		name      string
+		resolve   bool
+		wantLabel string
+	}{
+		{"resolution enabled", true, "Inbox"},
+		{"resolution disabled", false, "folder-inbox"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, _, mockMeService, mockFoldersService := createTestClientForFolders()
+			client.config.ResolveFolderNames = tt.resolve
+			ctx := context.Background()
+
+			mockMessagesService := &outlooktest.MockMessagesService{}
+			mockMeService.On("GetMessagesService").Return(mockMessagesService)
+			mockMessagesService.On("Get", ctx, "msg-123").Return(createTestMessage(), nil)
+
+			folders := models.NewMailFolderCollectionResponse()
+			folders.SetValue([]models.MailFolderable{
+				createTestFolder("folder-inbox", "Inbox", 10, 1),
+				createTestFolder("folder-archive", "Archive", 5, 0),
+			})
+			mockFoldersService.On("List", ctx, mock.Anything).Return(folders, nil)
+
+			email, err := client.GetMessage(ctx, "msg-123")
+			assert.NoError(t, err)
+			assert.Equal(t, []string{tt.wantLabel}, email.Labels)
+
+			// The folder map is cached across calls
+			_, err = client.GetMessage(ctx, "msg-123")
+			assert.NoError(t, err)
+			if tt.resolve {
+				mockFoldersService.AssertNumberOfCalls(t, "List", 1)
+			} else {
+				mockFoldersService.AssertNotCalled(t, "List", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestClient_GetMessage_ResolveFolderNames_PagedAndNested(t *testing.T) {
+	client, _, mockMeService, mockFoldersService := createTestClientForFolders()
+	client.config.ResolveFolderNames = true
+	ctx := context.Background()
+
+	// The message lives in a child of a folder on the second page
+	message := createTestMessage()
+	folderID := "folder-receipts"
+	message.SetParentFolderId(&folderID)
+	mockMessagesService := &outlooktest.MockMessagesService{}
+	mockMeService.On("GetMessagesService").Return(mockMessagesService)
+	mockMessagesService.On("Get", ctx, "msg-123").Return(message, nil)
+
+	nextLink := "https://graph.microsoft.com/v1.0/me/mailFolders?$skip=10"
+	firstPage := models.NewMailFolderCollectionResponse()
+	firstPage.SetValue([]models.MailFolderable{createTestFolder("folder-inbox", "Inbox", 10, 1)})
+	firstPage.SetOdataNextLink(&nextLink)
+
+	archive := createTestFolder("folder-archive", "Archive", 5, 0)
+	childCount := int32(1)
+	archive.(*models.MailFolder).SetChildFolderCount(&childCount)
+	secondPage := models.NewMailFolderCollectionResponse()
+	secondPage.SetValue([]models.MailFolderable{archive})
+
+	children := models.NewMailFolderCollectionResponse()
+	children.SetValue([]models.MailFolderable{createTestFolder("folder-receipts", "Receipts", 3, 0)})
+
+	mockFoldersService.On("List", ctx, mock.Anything).Return(firstPage, nil)
+	mockFoldersService.On("ListNextPage", ctx, nextLink).Return(secondPage, nil)
+	mockFoldersService.On("ListChildren", ctx, "folder-archive", mock.Anything).Return(children, nil)
+
+	email, err := client.GetMessage(ctx, "msg-123")
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Receipts"}, email.Labels)
+	mockFoldersService.AssertExpectations(t)
+}
+
+func TestClient_GetMessage_ResolveFolderNames_ListError(t *testing.T) {
+	client, _, mockMeService, mockFoldersService := createTestClientForFolders()
+	client.config.ResolveFolderNames = true
+	ctx := context.Background()
+
+	mockMessagesService := &outlooktest.MockMessagesService{}
+	mockMeService.On("GetMessagesService").Return(mockMessagesService)
+	mockMessagesService.On("Get", ctx, "msg-123").Return(createTestMessage(), nil)
+	mockFoldersService.On("List", ctx, mock.Anything).Return(nil, assert.AnError)
+
+	email, err := client.GetMessage(ctx, "msg-123")
+
+	assert.NoError(t, err, "resolution is best effort")
+	assert.Equal(t, []string{"folder-inbox"}, email.Labels)
+}
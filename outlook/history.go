@@ -0,0 +1,15 @@
+package outlook
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/danielrivera/mailbridge-go/core"
+)
+
+// GetHistory is not supported yet: Graph reports mailbox changes through delta queries,
+// which have no history IDs. It always returns core.ErrNotSupported, so provider-agnostic
+// sync code can fall back to listing messages.
+func (c *Client) GetHistory(ctx context.Context, req *core.HistoryRequest) (*core.HistoryResponse, error) {
+	return nil, fmt.Errorf("outlook mailbox history: %w", core.ErrNotSupported)
+}
@@ -2,6 +2,7 @@ package internal
 
 import (
 	"context"
+	"io"
 
 	"github.com/microsoftgraph/msgraph-sdk-go/models"
 	"github.com/microsoftgraph/msgraph-sdk-go/users"
@@ -11,10 +12,13 @@ import (
 // It provides access to user-specific services.
 type GraphService interface {
 	GetMeService() MeService
+	GetUserService(userID string) MeService
 }
 
-// MeService represents operations for the authenticated user.
+// MeService represents operations on a user's mailbox: the authenticated user's,
+// or another user's (e.g. a shared mailbox) the token has delegated access to.
 type MeService interface {
+	Get(ctx context.Context) (models.Userable, error)
 	GetMessagesService() MessagesService
 	GetMailFoldersService() MailFoldersService
 }
@@ -22,21 +26,43 @@ type MeService interface {
 // MessagesService represents operations on email messages.
 type MessagesService interface {
 	List(ctx context.Context, config *users.ItemMessagesRequestBuilderGetRequestConfiguration) (models.MessageCollectionResponseable, error)
+	ListNextPage(ctx context.Context, nextLink string) (models.MessageCollectionResponseable, error)
 	Get(ctx context.Context, messageID string) (models.Messageable, error)
+	GetWithConfig(ctx context.Context, messageID string, config *users.ItemMessagesMessageItemRequestBuilderGetRequestConfiguration) (models.Messageable, error)
+	GetIfNoneMatch(ctx context.Context, messageID, etag string) (models.Messageable, bool, error)
 	GetAttachments(ctx context.Context, messageID string) ([]models.Attachmentable, error)
 	GetAttachment(ctx context.Context, messageID, attachmentID string) (models.Attachmentable, error)
+	GetAttachmentMetadata(ctx context.Context, messageID, attachmentID string) (models.Attachmentable, error)
+	ListAttachmentMetadata(ctx context.Context, messageID string) ([]models.Attachmentable, error)
+	GetAttachmentContent(ctx context.Context, messageID, attachmentID string) (io.ReadCloser, error)
+	GetMimeContent(ctx context.Context, messageID string) ([]byte, error)
 	MarkAsRead(ctx context.Context, messageID string) error
 	MarkAsUnread(ctx context.Context, messageID string) error
+	BatchMarkAsRead(ctx context.Context, messageIDs []string) error
+	SetFocused(ctx context.Context, messageID string, focused bool) error
 	Move(ctx context.Context, messageID, destinationFolderID string) error
 	Delete(ctx context.Context, messageID string) error
+	SendMail(ctx context.Context, message models.Messageable) error
+	CreateDraft(ctx context.Context, message models.Messageable) (models.Messageable, error)
+	SendDraft(ctx context.Context, messageID string) error
+	Update(ctx context.Context, messageID string, message models.Messageable) (models.Messageable, error)
+	CreateReply(ctx context.Context, messageID string) (models.Messageable, error)
+	CreateForward(ctx context.Context, messageID string) (models.Messageable, error)
+	Forward(ctx context.Context, messageID, comment string, toRecipients []models.Recipientable) error
 }
 
 // MailFoldersService represents operations on mail folders.
 type MailFoldersService interface {
-	List(ctx context.Context) (models.MailFolderCollectionResponseable, error)
+	List(ctx context.Context, config *users.ItemMailFoldersRequestBuilderGetRequestConfiguration) (models.MailFolderCollectionResponseable, error)
 	Get(ctx context.Context, folderID string) (models.MailFolderable, error)
+	BatchGetCounts(ctx context.Context, folderIDs []string) (map[string]models.MailFolderable, error)
 	Create(ctx context.Context, name string) (models.MailFolderable, error)
+	CreateSearchFolder(ctx context.Context, parentFolderID string, folder models.MailSearchFolderable) (models.MailFolderable, error)
 	Update(ctx context.Context, folderID, newName string) (models.MailFolderable, error)
 	Delete(ctx context.Context, folderID string) error
 	GetMessages(ctx context.Context, folderID string, config *users.ItemMailFoldersItemMessagesRequestBuilderGetRequestConfiguration) (models.MessageCollectionResponseable, error)
+	ListChildren(ctx context.Context, folderID string, config *users.ItemMailFoldersItemChildFoldersRequestBuilderGetRequestConfiguration) (models.MailFolderCollectionResponseable, error)
+	ListNextPage(ctx context.Context, nextLink string) (models.MailFolderCollectionResponseable, error)
+	CreateMessage(ctx context.Context, folderID string, message models.Messageable) (models.Messageable, error)
+	ImportMessage(ctx context.Context, folderID string, mimeContent []byte) (models.Messageable, error)
 }
@@ -2,60 +2,106 @@ package internal
 
 import (
 	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
+	abstractions "github.com/microsoft/kiota-abstractions-go"
+	jsonserialization "github.com/microsoft/kiota-serialization-json-go"
 	msgraphsdk "github.com/microsoftgraph/msgraph-sdk-go"
+	msgraphcore "github.com/microsoftgraph/msgraph-sdk-go-core"
 	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/microsoftgraph/msgraph-sdk-go/models/odataerrors"
 	"github.com/microsoftgraph/msgraph-sdk-go/users"
+
+	"github.com/danielrivera/mailbridge-go/core"
 )
 
 // RealGraphService wraps the Microsoft Graph SDK client.
 type RealGraphService struct {
-	client *msgraphsdk.GraphServiceClient
+	client     *msgraphsdk.GraphServiceClient
+	httpClient *http.Client // used for raw requests the SDK cannot stream
 }
 
 // NewRealGraphService creates a new RealGraphService.
-func NewRealGraphService(client *msgraphsdk.GraphServiceClient) *RealGraphService {
-	return &RealGraphService{client: client}
+// httpClient should be the client backing the SDK's request adapter; it is used
+// for streaming downloads. If nil, http.DefaultClient is used.
+func NewRealGraphService(client *msgraphsdk.GraphServiceClient, httpClient *http.Client) *RealGraphService {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &RealGraphService{client: client, httpClient: httpClient}
 }
 
-// GetMeService returns the service for the authenticated user.
+// GetMeService returns the service for the authenticated user (/me).
 func (r *RealGraphService) GetMeService() MeService {
-	return &realMeService{client: r.client}
+	return &realMeService{client: r.client, httpClient: r.httpClient, user: r.client.Me()}
+}
+
+// GetUserService returns the service for another user's mailbox (/users/{userID}),
+// identified by ID or user principal name.
+func (r *RealGraphService) GetUserService(userID string) MeService {
+	return &realMeService{client: r.client, httpClient: r.httpClient, user: r.client.Users().ByUserId(userID)}
 }
 
 // realMeService implements MeService.
 type realMeService struct {
-	client *msgraphsdk.GraphServiceClient
+	client     *msgraphsdk.GraphServiceClient
+	httpClient *http.Client
+	user       *users.UserItemRequestBuilder
+}
+
+// Get retrieves the user's profile.
+func (r *realMeService) Get(ctx context.Context) (models.Userable, error) {
+	return r.user.Get(ctx, nil)
 }
 
 // GetMessagesService returns the messages service.
 func (r *realMeService) GetMessagesService() MessagesService {
-	return &realMessagesService{client: r.client}
+	return &realMessagesService{client: r.client, httpClient: r.httpClient, user: r.user}
 }
 
 // GetMailFoldersService returns the mail folders service.
 func (r *realMeService) GetMailFoldersService() MailFoldersService {
-	return &realMailFoldersService{client: r.client}
+	return &realMailFoldersService{client: r.client, user: r.user}
 }
 
 // realMessagesService implements MessagesService.
 type realMessagesService struct {
-	client *msgraphsdk.GraphServiceClient
+	client     *msgraphsdk.GraphServiceClient
+	httpClient *http.Client
+	user       *users.UserItemRequestBuilder
 }
 
 // List retrieves a list of messages.
 func (r *realMessagesService) List(ctx context.Context, config *users.ItemMessagesRequestBuilderGetRequestConfiguration) (models.MessageCollectionResponseable, error) {
-	return r.client.Me().Messages().Get(ctx, config)
+	return r.user.Messages().Get(ctx, config)
+}
+
+// ListNextPage retrieves the next page of a message listing from its @odata.nextLink.
+func (r *realMessagesService) ListNextPage(ctx context.Context, nextLink string) (models.MessageCollectionResponseable, error) {
+	return r.user.Messages().WithUrl(nextLink).Get(ctx, nil)
 }
 
 // Get retrieves a specific message by ID.
 func (r *realMessagesService) Get(ctx context.Context, messageID string) (models.Messageable, error) {
-	return r.client.Me().Messages().ByMessageId(messageID).Get(ctx, nil)
+	return r.user.Messages().ByMessageId(messageID).Get(ctx, nil)
+}
+
+// GetWithConfig retrieves a specific message by ID with request headers and query parameters.
+func (r *realMessagesService) GetWithConfig(ctx context.Context, messageID string, config *users.ItemMessagesMessageItemRequestBuilderGetRequestConfiguration) (models.Messageable, error) {
+	return r.user.Messages().ByMessageId(messageID).Get(ctx, config)
 }
 
 // GetAttachments retrieves all attachments for a message.
 func (r *realMessagesService) GetAttachments(ctx context.Context, messageID string) ([]models.Attachmentable, error) {
-	result, err := r.client.Me().Messages().ByMessageId(messageID).Attachments().Get(ctx, nil)
+	result, err := r.user.Messages().ByMessageId(messageID).Attachments().Get(ctx, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -64,7 +110,119 @@ func (r *realMessagesService) GetAttachments(ctx context.Context, messageID stri
 
 // GetAttachment retrieves a specific attachment.
 func (r *realMessagesService) GetAttachment(ctx context.Context, messageID, attachmentID string) (models.Attachmentable, error) {
-	return r.client.Me().Messages().ByMessageId(messageID).Attachments().ByAttachmentId(attachmentID).Get(ctx, nil)
+	return r.user.Messages().ByMessageId(messageID).Attachments().ByAttachmentId(attachmentID).Get(ctx, nil)
+}
+
+// GetAttachmentMetadata retrieves an attachment's properties without its content.
+func (r *realMessagesService) GetAttachmentMetadata(ctx context.Context, messageID, attachmentID string) (models.Attachmentable, error) {
+	config := &users.ItemMessagesItemAttachmentsAttachmentItemRequestBuilderGetRequestConfiguration{
+		QueryParameters: &users.ItemMessagesItemAttachmentsAttachmentItemRequestBuilderGetQueryParameters{
+			Select: []string{"id", "name", "contentType", "size"},
+		},
+	}
+	return r.user.Messages().ByMessageId(messageID).Attachments().ByAttachmentId(attachmentID).Get(ctx, config)
+}
+
+// GetIfNoneMatch retrieves a message unless it still matches etag. The boolean reports
+// that Graph answered 304 Not Modified, in which case the message is nil.
+func (r *realMessagesService) GetIfNoneMatch(ctx context.Context, messageID, etag string) (models.Messageable, bool, error) {
+	headers := abstractions.NewRequestHeaders()
+	headers.Add("If-None-Match", etag)
+	config := &users.ItemMessagesMessageItemRequestBuilderGetRequestConfiguration{Headers: headers}
+
+	message, err := r.user.Messages().ByMessageId(messageID).Get(ctx, config)
+	if err != nil {
+		return nil, false, err
+	}
+	// A 304 response has no body, which the request adapter returns as a nil message
+	if message == nil {
+		return nil, true, nil
+	}
+	return message, false, nil
+}
+
+// ListAttachmentMetadata retrieves the properties of all attachments of a message without their content.
+func (r *realMessagesService) ListAttachmentMetadata(ctx context.Context, messageID string) ([]models.Attachmentable, error) {
+	config := &users.ItemMessagesItemAttachmentsRequestBuilderGetRequestConfiguration{
+		QueryParameters: &users.ItemMessagesItemAttachmentsRequestBuilderGetQueryParameters{
+			Select: []string{"id", "name", "contentType", "size", "isInline"},
+		},
+	}
+	result, err := r.user.Messages().ByMessageId(messageID).Attachments().Get(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	return result.GetValue(), nil
+}
+
+// GetAttachmentContent streams an attachment's raw content from the /$value endpoint.
+// The caller must close the returned reader.
+func (r *realMessagesService) GetAttachmentContent(ctx context.Context, messageID, attachmentID string) (io.ReadCloser, error) {
+	reqInfo, err := r.user.Messages().ByMessageId(messageID).Attachments().ByAttachmentId(attachmentID).ToGetRequestInformation(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	uri, err := reqInfo.GetUri()
+	if err != nil {
+		return nil, err
+	}
+	uri.Path += "/$value"
+	reqInfo.SetUri(*uri)
+	reqInfo.Headers.Remove("Accept")
+
+	nativeReq, err := r.client.GetAdapter().ConvertToNativeRequest(ctx, reqInfo)
+	if err != nil {
+		return nil, err
+	}
+	req, ok := nativeReq.(*http.Request)
+	if !ok {
+		return nil, fmt.Errorf("unexpected request type %T", nativeReq)
+	}
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		defer resp.Body.Close()
+		return nil, downloadError(resp)
+	}
+
+	return resp.Body, nil
+}
+
+// maxErrorBodySize caps how much of an error response downloadError reads.
+const maxErrorBodySize = 64 * 1024
+
+// downloadError converts the error response of a raw download into the ODataError the
+// SDK returns for its own requests, so callers map throttling and missing items the
+// same way. A body that is not a Graph error still yields an ODataError carrying the
+// status code.
+func downloadError(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+	if node, err := jsonserialization.NewJsonParseNode(body); err == nil {
+		parsed, err := node.GetObjectValue(odataerrors.CreateODataErrorFromDiscriminatorValue)
+		if odataErr, ok := parsed.(*odataerrors.ODataError); err == nil && ok && odataErr.GetErrorEscaped() != nil {
+			odataErr.SetStatusCode(resp.StatusCode)
+			return odataErr
+		}
+	}
+
+	code := http.StatusText(resp.StatusCode)
+	message := fmt.Sprintf("unexpected status %d downloading attachment", resp.StatusCode)
+	mainErr := odataerrors.NewMainError()
+	mainErr.SetCode(&code)
+	mainErr.SetMessage(&message)
+	odataErr := odataerrors.NewODataError()
+	odataErr.SetErrorEscaped(mainErr)
+	odataErr.SetStatusCode(resp.StatusCode)
+	return odataErr
+}
+
+// GetMimeContent retrieves a message's full MIME content from the /$value endpoint.
+func (r *realMessagesService) GetMimeContent(ctx context.Context, messageID string) ([]byte, error) {
+	return r.user.Messages().ByMessageId(messageID).Content().Get(ctx, nil)
 }
 
 // MarkAsRead marks a message as read.
@@ -72,7 +230,71 @@ func (r *realMessagesService) MarkAsRead(ctx context.Context, messageID string)
 	message := models.NewMessage()
 	isRead := true
 	message.SetIsRead(&isRead)
-	_, err := r.client.Me().Messages().ByMessageId(messageID).Patch(ctx, message, nil)
+	_, err := r.user.Messages().ByMessageId(messageID).Patch(ctx, message, nil)
+	return err
+}
+
+// maxBatchSize is the maximum number of requests Graph accepts in a single $batch call.
+const maxBatchSize = 20
+
+// maxBatchThrottleRetries is how many times $batch items Graph throttles are resent.
+const maxBatchThrottleRetries = 3
+
+// batchThrottleDelay is the wait before resending throttled $batch items when Graph
+// gives no Retry-After; it doubles on each further retry.
+const batchThrottleDelay = time.Second
+
+// BatchMarkAsRead marks several messages as read using $batch requests.
+func (r *realMessagesService) BatchMarkAsRead(ctx context.Context, messageIDs []string) error {
+	message := models.NewMessage()
+	isRead := true
+	message.SetIsRead(&isRead)
+
+	adapter := r.client.GetAdapter()
+	for start := 0; start < len(messageIDs); start += maxBatchSize {
+		end := min(start+maxBatchSize, len(messageIDs))
+
+		batch := msgraphcore.NewBatchRequest(adapter)
+		itemMessageIDs := make(map[string]string, end-start)
+		for _, messageID := range messageIDs[start:end] {
+			reqInfo, err := r.user.Messages().ByMessageId(messageID).ToPatchRequestInformation(ctx, message, nil)
+			if err != nil {
+				return fmt.Errorf("failed to build request for message %s: %w", messageID, err)
+			}
+			item, err := batch.AddBatchRequestStep(*reqInfo)
+			if err != nil {
+				return fmt.Errorf("failed to add message %s to batch: %w", messageID, err)
+			}
+			itemMessageIDs[*item.GetId()] = messageID
+		}
+
+		resp, err := batch.Send(ctx, adapter)
+		if err != nil {
+			return err
+		}
+
+		if failed := resp.GetFailedResponses(); len(failed) > 0 {
+			failedIDs := make([]string, 0, len(failed))
+			for itemID := range failed {
+				failedIDs = append(failedIDs, itemMessageIDs[itemID])
+			}
+			sort.Strings(failedIDs)
+			return fmt.Errorf("batch request failed for messages: %s", strings.Join(failedIDs, ", "))
+		}
+	}
+
+	return nil
+}
+
+// SetFocused sets a message's inferenceClassification to focused or other.
+func (r *realMessagesService) SetFocused(ctx context.Context, messageID string, focused bool) error {
+	classification := models.OTHER_INFERENCECLASSIFICATIONTYPE
+	if focused {
+		classification = models.FOCUSED_INFERENCECLASSIFICATIONTYPE
+	}
+	message := models.NewMessage()
+	message.SetInferenceClassification(&classification)
+	_, err := r.user.Messages().ByMessageId(messageID).Patch(ctx, message, nil)
 	return err
 }
 
@@ -81,7 +303,7 @@ func (r *realMessagesService) MarkAsUnread(ctx context.Context, messageID string
 	message := models.NewMessage()
 	isRead := false
 	message.SetIsRead(&isRead)
-	_, err := r.client.Me().Messages().ByMessageId(messageID).Patch(ctx, message, nil)
+	_, err := r.user.Messages().ByMessageId(messageID).Patch(ctx, message, nil)
 	return err
 }
 
@@ -89,50 +311,262 @@ func (r *realMessagesService) MarkAsUnread(ctx context.Context, messageID string
 func (r *realMessagesService) Move(ctx context.Context, messageID, destinationFolderID string) error {
 	body := users.NewItemMessagesItemMovePostRequestBody()
 	body.SetDestinationId(&destinationFolderID)
-	_, err := r.client.Me().Messages().ByMessageId(messageID).Move().Post(ctx, body, nil)
+	_, err := r.user.Messages().ByMessageId(messageID).Move().Post(ctx, body, nil)
 	return err
 }
 
 // Delete deletes a message.
 func (r *realMessagesService) Delete(ctx context.Context, messageID string) error {
-	return r.client.Me().Messages().ByMessageId(messageID).Delete(ctx, nil)
+	return r.user.Messages().ByMessageId(messageID).Delete(ctx, nil)
+}
+
+// SendMail sends a message and saves a copy to Sent Items.
+func (r *realMessagesService) SendMail(ctx context.Context, message models.Messageable) error {
+	body := users.NewItemSendMailPostRequestBody()
+	body.SetMessage(message)
+	saveToSentItems := true
+	body.SetSaveToSentItems(&saveToSentItems)
+	return r.user.SendMail().Post(ctx, body, nil)
+}
+
+// CreateDraft creates a draft message in the Drafts folder.
+func (r *realMessagesService) CreateDraft(ctx context.Context, message models.Messageable) (models.Messageable, error) {
+	return r.user.Messages().Post(ctx, message, nil)
+}
+
+// SendDraft sends an existing draft message.
+func (r *realMessagesService) SendDraft(ctx context.Context, messageID string) error {
+	return r.user.Messages().ByMessageId(messageID).Send().Post(ctx, nil)
+}
+
+// Update patches the given properties of a message, typically a draft.
+func (r *realMessagesService) Update(ctx context.Context, messageID string, message models.Messageable) (models.Messageable, error) {
+	return r.user.Messages().ByMessageId(messageID).Patch(ctx, message, nil)
+}
+
+// CreateReply creates a reply draft pre-populated with the original sender and quoted body.
+func (r *realMessagesService) CreateReply(ctx context.Context, messageID string) (models.Messageable, error) {
+	body := users.NewItemMessagesItemCreateReplyPostRequestBody()
+	return r.user.Messages().ByMessageId(messageID).CreateReply().Post(ctx, body, nil)
+}
+
+// CreateForward creates a forward draft pre-populated with the quoted body and attachments.
+func (r *realMessagesService) CreateForward(ctx context.Context, messageID string) (models.Messageable, error) {
+	body := users.NewItemMessagesItemCreateForwardPostRequestBody()
+	return r.user.Messages().ByMessageId(messageID).CreateForward().Post(ctx, body, nil)
+}
+
+// Forward forwards a message with a comment; Graph keeps the original attachments.
+func (r *realMessagesService) Forward(ctx context.Context, messageID, comment string, toRecipients []models.Recipientable) error {
+	body := users.NewItemMessagesItemForwardPostRequestBody()
+	body.SetComment(&comment)
+	body.SetToRecipients(toRecipients)
+	return r.user.Messages().ByMessageId(messageID).Forward().Post(ctx, body, nil)
 }
 
 // realMailFoldersService implements MailFoldersService.
 type realMailFoldersService struct {
 	client *msgraphsdk.GraphServiceClient
+	user   *users.UserItemRequestBuilder
 }
 
 // List retrieves all mail folders.
-func (r *realMailFoldersService) List(ctx context.Context) (models.MailFolderCollectionResponseable, error) {
-	return r.client.Me().MailFolders().Get(ctx, nil)
+func (r *realMailFoldersService) List(ctx context.Context, config *users.ItemMailFoldersRequestBuilderGetRequestConfiguration) (models.MailFolderCollectionResponseable, error) {
+	return r.user.MailFolders().Get(ctx, config)
 }
 
 // Get retrieves a specific folder by ID.
 func (r *realMailFoldersService) Get(ctx context.Context, folderID string) (models.MailFolderable, error) {
-	return r.client.Me().MailFolders().ByMailFolderId(folderID).Get(ctx, nil)
+	return r.user.MailFolders().ByMailFolderId(folderID).Get(ctx, nil)
 }
 
 // Create creates a new mail folder.
 func (r *realMailFoldersService) Create(ctx context.Context, name string) (models.MailFolderable, error) {
 	folder := models.NewMailFolder()
 	folder.SetDisplayName(&name)
-	return r.client.Me().MailFolders().Post(ctx, folder, nil)
+	return r.user.MailFolders().Post(ctx, folder, nil)
+}
+
+// CreateSearchFolder creates a search folder as a child of parentFolderID.
+func (r *realMailFoldersService) CreateSearchFolder(ctx context.Context, parentFolderID string, folder models.MailSearchFolderable) (models.MailFolderable, error) {
+	return r.user.MailFolders().ByMailFolderId(parentFolderID).ChildFolders().Post(ctx, folder, nil)
 }
 
 // Update updates a folder's display name.
 func (r *realMailFoldersService) Update(ctx context.Context, folderID, newName string) (models.MailFolderable, error) {
 	folder := models.NewMailFolder()
 	folder.SetDisplayName(&newName)
-	return r.client.Me().MailFolders().ByMailFolderId(folderID).Patch(ctx, folder, nil)
+	return r.user.MailFolders().ByMailFolderId(folderID).Patch(ctx, folder, nil)
 }
 
 // Delete deletes a mail folder.
 func (r *realMailFoldersService) Delete(ctx context.Context, folderID string) error {
-	return r.client.Me().MailFolders().ByMailFolderId(folderID).Delete(ctx, nil)
+	return r.user.MailFolders().ByMailFolderId(folderID).Delete(ctx, nil)
 }
 
 // GetMessages retrieves messages from a specific folder.
 func (r *realMailFoldersService) GetMessages(ctx context.Context, folderID string, config *users.ItemMailFoldersItemMessagesRequestBuilderGetRequestConfiguration) (models.MessageCollectionResponseable, error) {
-	return r.client.Me().MailFolders().ByMailFolderId(folderID).Messages().Get(ctx, config)
+	return r.user.MailFolders().ByMailFolderId(folderID).Messages().Get(ctx, config)
+}
+
+// BatchGetCounts fetches the item counts of several folders using $batch requests.
+// The result is keyed by the requested folder IDs, which may be well-known names.
+// Graph throttles $batch items individually: items answered with 429 or 503 are sent
+// again in a new batch after their Retry-After, up to maxBatchThrottleRetries times,
+// after which an error wrapping core.ErrRateLimited is returned.
+func (r *realMailFoldersService) BatchGetCounts(ctx context.Context, folderIDs []string) (map[string]models.MailFolderable, error) {
+	folders := make(map[string]models.MailFolderable, len(folderIDs))
+	for start := 0; start < len(folderIDs); start += maxBatchSize {
+		pending := folderIDs[start:min(start+maxBatchSize, len(folderIDs))]
+		for attempt := 0; ; attempt++ {
+			throttled, retryAfter, err := r.batchGetCounts(ctx, pending, folders)
+			if err != nil {
+				return nil, err
+			}
+			if len(throttled) == 0 {
+				break
+			}
+			if attempt >= maxBatchThrottleRetries {
+				return nil, fmt.Errorf("%w: batch request throttled for folders: %s", core.ErrRateLimited, strings.Join(throttled, ", "))
+			}
+
+			delay := retryAfter
+			if delay < 0 {
+				delay = batchThrottleDelay << attempt
+			}
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(delay):
+			}
+			pending = throttled
+		}
+	}
+
+	return folders, nil
+}
+
+// batchGetCounts sends one $batch request for folderIDs and stores the folders it
+// returns in folders. It returns the folders Graph throttled, sorted, with the longest
+// Retry-After among them, or -1 when none gave one.
+func (r *realMailFoldersService) batchGetCounts(ctx context.Context, folderIDs []string, folders map[string]models.MailFolderable) ([]string, time.Duration, error) {
+	config := &users.ItemMailFoldersMailFolderItemRequestBuilderGetRequestConfiguration{
+		QueryParameters: &users.ItemMailFoldersMailFolderItemRequestBuilderGetQueryParameters{
+			Select: []string{"id", "displayName", "totalItemCount", "unreadItemCount"},
+		},
+	}
+
+	adapter := r.client.GetAdapter()
+	batch := msgraphcore.NewBatchRequest(adapter)
+	itemFolderIDs := make(map[string]string, len(folderIDs))
+	for _, folderID := range folderIDs {
+		reqInfo, err := r.user.MailFolders().ByMailFolderId(folderID).ToGetRequestInformation(ctx, config)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to build request for folder %s: %w", folderID, err)
+		}
+		item, err := batch.AddBatchRequestStep(*reqInfo)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to add folder %s to batch: %w", folderID, err)
+		}
+		itemFolderIDs[*item.GetId()] = folderID
+	}
+
+	resp, err := batch.Send(ctx, adapter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var throttled, failedIDs []string
+	retryAfter := time.Duration(-1)
+	for itemID, status := range resp.GetFailedResponses() {
+		folderID := itemFolderIDs[itemID]
+		if status != http.StatusTooManyRequests && status != http.StatusServiceUnavailable {
+			failedIDs = append(failedIDs, folderID)
+			continue
+		}
+		throttled = append(throttled, folderID)
+		if delay, ok := batchItemRetryAfter(resp.GetResponseById(itemID)); ok && delay > retryAfter {
+			retryAfter = delay
+		}
+	}
+	if len(failedIDs) > 0 {
+		sort.Strings(failedIDs)
+		return nil, 0, fmt.Errorf("batch request failed for folders: %s", strings.Join(failedIDs, ", "))
+	}
+	sort.Strings(throttled)
+
+	for itemID, folderID := range itemFolderIDs {
+		if slices.Contains(throttled, folderID) {
+			continue
+		}
+		if resp.GetResponseById(itemID) == nil {
+			return nil, 0, fmt.Errorf("batch response missing folder %s", folderID)
+		}
+		folder, err := msgraphcore.GetBatchResponseById[models.MailFolderable](resp, itemID, models.CreateMailFolderFromDiscriminatorValue)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to parse folder %s: %w", folderID, err)
+		}
+		folders[folderID] = folder
+	}
+
+	return throttled, retryAfter, nil
+}
+
+// batchItemRetryAfter returns the wait a $batch item's Retry-After header asks for,
+// given in seconds or as an HTTP date.
+func batchItemRetryAfter(item msgraphcore.BatchItem) (time.Duration, bool) {
+	if item == nil {
+		return 0, false
+	}
+	for name, value := range item.GetHeaders() {
+		if !strings.EqualFold(name, "Retry-After") {
+			continue
+		}
+		if seconds, err := strconv.Atoi(strings.TrimSpace(value)); err == nil && seconds >= 0 {
+			return time.Duration(seconds) * time.Second, true
+		}
+		if date, err := http.ParseTime(value); err == nil {
+			return max(time.Until(date), 0), true
+		}
+	}
+	return 0, false
+}
+
+// ListChildren retrieves the direct child folders of a folder.
+func (r *realMailFoldersService) ListChildren(ctx context.Context, folderID string, config *users.ItemMailFoldersItemChildFoldersRequestBuilderGetRequestConfiguration) (models.MailFolderCollectionResponseable, error) {
+	return r.user.MailFolders().ByMailFolderId(folderID).ChildFolders().Get(ctx, config)
+}
+
+// ListNextPage retrieves the next page of a folder listing from its @odata.nextLink.
+func (r *realMailFoldersService) ListNextPage(ctx context.Context, nextLink string) (models.MailFolderCollectionResponseable, error) {
+	return r.user.MailFolders().WithUrl(nextLink).Get(ctx, nil)
+}
+
+// CreateMessage creates a message in a folder.
+func (r *realMailFoldersService) CreateMessage(ctx context.Context, folderID string, message models.Messageable) (models.Messageable, error) {
+	return r.user.MailFolders().ByMailFolderId(folderID).Messages().Post(ctx, message, nil)
+}
+
+// ImportMessage creates a message in a folder from its MIME content. Graph expects
+// the MIME content base64-encoded in a text/plain body.
+func (r *realMailFoldersService) ImportMessage(ctx context.Context, folderID string, mimeContent []byte) (models.Messageable, error) {
+	reqInfo, err := r.user.MailFolders().ByMailFolderId(folderID).Messages().ToPostRequestInformation(ctx, models.NewMessage(), nil)
+	if err != nil {
+		return nil, err
+	}
+	reqInfo.Headers.Remove("Content-Type")
+	reqInfo.SetStreamContentAndContentType([]byte(base64.StdEncoding.EncodeToString(mimeContent)), "text/plain")
+
+	errorMapping := abstractions.ErrorMappings{
+		"XXX": odataerrors.CreateODataErrorFromDiscriminatorValue,
+	}
+	result, err := r.client.GetAdapter().Send(ctx, reqInfo, models.CreateMessageFromDiscriminatorValue, errorMapping)
+	if err != nil {
+		return nil, err
+	}
+	message, ok := result.(models.Messageable)
+	if !ok {
+		return nil, fmt.Errorf("unexpected response type %T", result)
+	}
+	return message, nil
 }
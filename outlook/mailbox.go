@@ -0,0 +1,46 @@
+package outlook
+
+import (
+	"github.com/danielrivera/mailbridge-go/outlook/internal"
+)
+
+// CallOption customizes a single client call.
+type CallOption func(*callOptions)
+
+// callOptions holds the settings applied by CallOptions.
+type callOptions struct {
+	mailbox string
+}
+
+// WithMailbox makes the call operate on another user's mailbox, such as a shared
+// mailbox, instead of the signed-in user's. The address may also be a user ID.
+// The token must have delegated access to the mailbox (Mail.Read.Shared or
+// Mail.ReadWrite.Shared), otherwise Graph responds with 403 or 404.
+func WithMailbox(address string) CallOption {
+	return func(o *callOptions) {
+		o.mailbox = address
+	}
+}
+
+// newCallOptions applies opts to a zero callOptions.
+func newCallOptions(opts []CallOption) *callOptions {
+	o := &callOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// userService returns the service for the mailbox the call targets: /users/{mailbox}
+// when WithMailbox was given, /me otherwise.
+func (o *callOptions) userService(service internal.GraphService) internal.MeService {
+	if o.mailbox == "" {
+		return service.GetMeService()
+	}
+	return service.GetUserService(o.mailbox)
+}
+
+// sharedMailbox reports whether the call targets a mailbox other than the signed-in user's.
+func (o *callOptions) sharedMailbox() bool {
+	return o.mailbox != ""
+}
@@ -3,26 +3,84 @@ package outlook
 import (
 	"context"
 	"encoding/base64"
+	"errors"
 	"fmt"
+	"io"
+	"net/textproto"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
+	abstractions "github.com/microsoft/kiota-abstractions-go"
 	"github.com/microsoftgraph/msgraph-sdk-go/models"
 	"github.com/microsoftgraph/msgraph-sdk-go/users"
 
 	"github.com/danielrivera/mailbridge-go/core"
+	"github.com/danielrivera/mailbridge-go/outlook/internal"
 )
 
+// messageSelectFields are the message fields requested by list operations.
+var messageSelectFields = []string{
+	"id", "conversationId", "subject", "from", "toRecipients", "ccRecipients", "bccRecipients",
+	"receivedDateTime", "sentDateTime", "hasAttachments", "isRead", "body",
+	"bodyPreview", "parentFolderId", "inferenceClassification", "internetMessageId",
+}
+
+// listExpandFields returns the relations expanded by list operations for opts: the
+// attachments smaller than ExpandSmallAttachments bytes, content included.
+func listExpandFields(opts *core.ListOptions) []string {
+	if opts == nil || opts.ExpandSmallAttachments <= 0 {
+		return nil
+	}
+	return []string{fmt.Sprintf("attachments($filter=size lt %d)", opts.ExpandSmallAttachments)}
+}
+
+// messageIDSelectFields are the message fields requested by ListOptions.IDsOnly.
+var messageIDSelectFields = []string{"id", "conversationId"}
+
+// listSelectFields returns the message fields requested by list operations for opts,
+// adding internetMessageHeaders when HasUnsubscribe needs them to filter the page.
+func listSelectFields(opts *core.ListOptions) []string {
+	if opts == nil || opts.HasUnsubscribe == nil {
+		return messageSelectFields
+	}
+	return append(append([]string{}, messageSelectFields...), "internetMessageHeaders")
+}
+
+// largeAttachmentThreshold is the size above which attachments are streamed
+// through Attachment.Reader instead of being loaded into Attachment.Data.
+const largeAttachmentThreshold = 3 * 1024 * 1024
+
+// threadPageSize is the page size used when fetching a conversation. Later pages are
+// followed through @odata.nextLink, so a conversation is never truncated.
+const threadPageSize = 250
+
 // ListMessages retrieves a list of email messages from the user's mailbox.
 // It returns provider-agnostic core.Email types.
 func (c *Client) ListMessages(ctx context.Context, opts *core.ListOptions) (*core.ListResponse, error) {
-	if !c.IsConnected() {
-		return nil, fmt.Errorf("client not connected")
+	service, err := c.getService()
+	if err != nil {
+		return nil, err
+	}
+	opts = c.config.listOptions(opts)
+	if err := validateListOptions(opts); err != nil {
+		return nil, err
 	}
 
 	config := &users.ItemMessagesRequestBuilderGetRequestConfiguration{}
 	queryParams := &users.ItemMessagesRequestBuilderGetQueryParameters{}
 
+	// Resolve the authenticated address before building filters
+	var selfAddress string
+	if opts != nil && opts.ExcludeFromSelf {
+		addr, err := c.getSelfAddress(ctx, service)
+		if err != nil {
+			return nil, err
+		}
+		selfAddress = addr
+	}
+
 	// Apply pagination
 	if opts != nil {
 		if opts.MaxResults > 0 {
@@ -44,19 +102,30 @@ func (c *Client) ListMessages(ctx context.Context, opts *core.ListOptions) (*cor
 		if opts.Query != "" {
 			queryParams.Search = &opts.Query
 		}
+
+		// Apply structured filters
+		if filter := buildFilter(opts, selfAddress); filter != "" {
+			queryParams.Filter = &filter
+		}
+
+		// Graph restricts $orderby combined with $search or $filter; the page is sorted locally anyway
+		if orderBy := orderByClause(opts.OrderBy); orderBy != "" && queryParams.Search == nil && queryParams.Filter == nil {
+			queryParams.Orderby = []string{orderBy}
+		}
 	}
 
 	// Select fields to retrieve
-	selectFields := []string{
-		"id", "subject", "from", "toRecipients", "ccRecipients", "bccRecipients",
-		"receivedDateTime", "sentDateTime", "hasAttachments", "isRead", "body",
-		"bodyPreview", "parentFolderId",
+	idsOnly := opts != nil && opts.IDsOnly
+	queryParams.Select = listSelectFields(opts)
+	queryParams.Expand = listExpandFields(opts)
+	if idsOnly {
+		queryParams.Select = messageIDSelectFields
+		queryParams.Expand = nil
 	}
-	queryParams.Select = selectFields
 
 	config.QueryParameters = queryParams
 
-	messagesService := c.service.GetMeService().GetMessagesService()
+	messagesService := service.GetMeService().GetMessagesService()
 	result, err := messagesService.List(ctx, config)
 	if err != nil {
 		return nil, handleODataError(fmt.Errorf("failed to list messages: %w", err))
@@ -65,9 +134,26 @@ func (c *Client) ListMessages(ctx context.Context, opts *core.ListOptions) (*cor
 	messages := result.GetValue()
 	emails := make([]*core.Email, 0, len(messages))
 
-	for _, msg := range messages {
-		email := c.convertMessage(msg)
-		emails = append(emails, email)
+	if idsOnly {
+		for _, msg := range messages {
+			emails = append(emails, &core.Email{
+				ID:       derefString(msg.GetId()),
+				ThreadID: derefString(msg.GetConversationId()),
+			})
+		}
+	} else {
+		for _, msg := range messages {
+			email := c.convertMessage(msg)
+			emails = append(emails, email)
+		}
+		// Graph cannot filter on headers, so HasUnsubscribe is applied to the page
+		if opts != nil {
+			emails = core.FilterByUnsubscribe(emails, opts.HasUnsubscribe)
+		}
+		c.resolveFolderLabels(ctx, service, emails...)
+		if opts != nil {
+			core.SortEmails(emails, opts.OrderBy)
+		}
 	}
 
 	// Calculate next page token
@@ -86,28 +172,502 @@ func (c *Client) ListMessages(ctx context.Context, opts *core.ListOptions) (*cor
 	}, nil
 }
 
+// MessagesIterator returns an iterator over the messages matching opts: call Next until
+// it returns core.ErrIteratorDone. The skip-based page tokens are handled internally, and
+// opts.MaxResults caps the total number of messages yielded rather than the page size.
+func (c *Client) MessagesIterator(ctx context.Context, opts *core.ListOptions) *core.MessageIterator {
+	return core.IterateMessages(ctx, c.ListMessages, opts)
+}
+
+// EachMessage pages through the messages matching opts and calls fn once per message,
+// without accumulating all emails. It stops at the first error from fn or when ctx is cancelled.
+func (c *Client) EachMessage(ctx context.Context, opts *core.ListOptions, fn func(*core.Email) error) error {
+	return core.EachMessage(ctx, c.ListMessages, c.config.listOptions(opts), fn)
+}
+
 // GetMessage retrieves a single message by its ID.
+// To read the message from a shared mailbox, use GetMessageWithOptions with WithMailbox.
+// When Config.MessageCacheSize is set, fetched messages are cached with their ETag and a
+// repeated fetch sends If-None-Match; on 304 Not Modified the cached copy is returned.
 func (c *Client) GetMessage(ctx context.Context, messageID string) (*core.Email, error) {
-	if !c.IsConnected() {
-		return nil, fmt.Errorf("client not connected")
+	return c.getMessage(ctx, messageID, &callOptions{})
+}
+
+// getMessage retrieves a message from the mailbox callOpts targets; folder names are
+// not resolved for shared mailboxes.
+func (c *Client) getMessage(ctx context.Context, messageID string, callOpts *callOptions) (*core.Email, error) {
+	service, err := c.getService()
+	if err != nil {
+		return nil, err
 	}
 
-	messagesService := c.service.GetMeService().GetMessagesService()
-	message, err := messagesService.Get(ctx, messageID)
+	messagesService := callOpts.userService(service).GetMessagesService()
+	cacheKey := messageCacheKey(callOpts.mailbox, messageID)
+
+	var message models.Messageable
+	if cached, ok := c.cachedMessage(cacheKey); ok {
+		var notModified bool
+		message, notModified, err = messagesService.GetIfNoneMatch(ctx, messageID, cached.etag)
+		if err == nil && notModified {
+			return copyEmail(cached.email), nil
+		}
+	} else {
+		message, err = messagesService.Get(ctx, messageID)
+	}
 	if err != nil {
+		if isNotFound(err) {
+			c.uncacheMessage(cacheKey)
+		}
 		return nil, handleODataError(fmt.Errorf("failed to get message %s: %w", messageID, err))
 	}
 
-	return c.convertMessage(message), nil
+	email := c.convertMessage(message)
+	if !callOpts.sharedMailbox() {
+		c.resolveFolderLabels(ctx, service, email)
+	}
+	c.cacheMessage(cacheKey, messageETag(message), email)
+	return email, nil
+}
+
+// GetMessageWithOptions retrieves a single message by its ID and applies the given options.
+// When MarkReadOnFetch is set, the message is marked as read after a successful fetch;
+// a failure to mark is ignored since the message itself was retrieved.
+// When RetryNotFound is set, a 404 is retried a few times with a short delay, since
+// Graph can briefly report a just-moved or just-imported message as missing.
+// When PreferBodyType is set, Graph converts the body to that format, e.g. plain text for indexing.
+// When ExtendedProperties is set, the named MAPI properties are returned in Email.ExtendedProperties;
+// properties the message does not have are left out.
+// When DownloadAttachments is set, the attachment list is fetched and the named attachments
+// are downloaded; attachments larger than 3 MB get a Reader instead of Data.
+// CallOptions such as WithMailbox apply to the fetch, the downloads and to marking the message as read.
+func (c *Client) GetMessageWithOptions(ctx context.Context, messageID string, opts *core.GetOptions, callOpts ...CallOption) (*core.Email, error) {
+	var email *core.Email
+	var err error
+	if opts != nil && (opts.RetryNotFound || opts.PreferBodyType != "" || len(opts.ExtendedProperties) > 0 || opts.IncludeHeaders) {
+		email, err = c.getMessageWithOptions(ctx, messageID, opts, newCallOptions(callOpts))
+	} else {
+		email, err = c.getMessage(ctx, messageID, newCallOptions(callOpts))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if opts != nil && opts.BlockRemoteContent {
+		email.Body.HTML, email.BlockedResources = core.BlockRemoteContent(email.Body.HTML)
+	}
+
+	if opts != nil && len(opts.DownloadAttachments) > 0 {
+		if err := c.downloadAttachments(ctx, email, opts, newCallOptions(callOpts)); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts != nil && opts.MarkReadOnFetch && !email.IsRead {
+		if err := c.MarkAsReadWithOptions(ctx, messageID, callOpts...); err == nil {
+			email.IsRead = true
+		}
+	}
+
+	return email, nil
+}
+
+// downloadAttachments lists the message's attachments and downloads the ones named
+// in opts.DownloadAttachments. The message itself only carries an empty attachment list.
+func (c *Client) downloadAttachments(ctx context.Context, email *core.Email, opts *core.GetOptions, callOpts *callOptions) error {
+	service, err := c.getService()
+	if err != nil {
+		return err
+	}
+
+	messagesService := callOpts.userService(service).GetMessagesService()
+	metadata, err := messagesService.ListAttachmentMetadata(ctx, email.ID)
+	if err != nil {
+		return handleODataError(fmt.Errorf("failed to list attachments for message %s: %w", email.ID, err))
+	}
+
+	attachments := make([]core.Attachment, 0, len(metadata))
+	for _, att := range metadata {
+		attachment := convertAttachment(att)
+		if opts.WantsAttachment(*attachment) {
+			attachment, err = getAttachment(ctx, messagesService, email.ID, attachment.ID)
+			if err != nil {
+				return err
+			}
+		}
+		attachments = append(attachments, *attachment)
+	}
+	email.Attachments = attachments
+	detectSecureMIME(email)
+	return nil
+}
+
+// detectSecureMIME sets email.Signed and email.Encrypted from the Content-Type header
+// when headers were returned, or else from the content type of the smime.p7m attachment
+// Graph keeps S/MIME content in (multipart/signed or application/pkcs7-mime).
+func detectSecureMIME(email *core.Email) {
+	if contentType := email.Header("Content-Type"); contentType != "" {
+		email.Signed, email.Encrypted = core.SecureMIMEType(contentType)
+		if email.Signed || email.Encrypted {
+			return
+		}
+	}
+	for _, attachment := range email.Attachments {
+		signed, encrypted := core.SecureMIMEType(attachment.MimeType)
+		email.Signed = email.Signed || signed
+		email.Encrypted = email.Encrypted || encrypted
+	}
+}
+
+// getMessageWithOptions fetches a message with the requested body type, retrying 404
+// responses caused by replication lag when RetryNotFound is set.
+func (c *Client) getMessageWithOptions(ctx context.Context, messageID string, opts *core.GetOptions, callOpts *callOptions) (*core.Email, error) {
+	config, err := bodyTypeRequestConfig(opts.PreferBodyType)
+	if err != nil {
+		return nil, err
+	}
+	config = withExtendedProperties(config, opts.ExtendedProperties)
+	if opts.IncludeHeaders {
+		config = withInternetHeaders(config)
+	}
+
+	service, err := c.getService()
+	if err != nil {
+		return nil, err
+	}
+
+	messagesService := callOpts.userService(service).GetMessagesService()
+	retries := 0
+	if opts.RetryNotFound {
+		retries = c.config.notFoundRetries()
+	}
+	delay := c.config.notFoundRetryDelay()
+
+	for attempt := 0; ; attempt++ {
+		var message models.Messageable
+		if config != nil {
+			message, err = messagesService.GetWithConfig(ctx, messageID, config)
+		} else {
+			message, err = messagesService.Get(ctx, messageID)
+		}
+		if err == nil {
+			email := c.convertMessage(message)
+			email.ExtendedProperties = extendedProperties(message, opts.ExtendedProperties)
+			if !callOpts.sharedMailbox() {
+				c.resolveFolderLabels(ctx, service, email)
+			}
+			return email, nil
+		}
+		if !isNotFound(err) || attempt >= retries {
+			return nil, handleODataError(fmt.Errorf("failed to get message %s: %w", messageID, err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay * time.Duration(attempt+1)):
+		}
+	}
+}
+
+// bodyTypeRequestConfig returns a request configuration with the Prefer header that asks
+// Graph for the given body type, or nil when no body type is requested.
+func bodyTypeRequestConfig(bodyType core.BodyType) (*users.ItemMessagesMessageItemRequestBuilderGetRequestConfiguration, error) {
+	switch bodyType {
+	case "":
+		return nil, nil
+	case core.BodyTypeHTML, core.BodyTypeText:
+	default:
+		return nil, fmt.Errorf("unsupported body type %q", bodyType)
+	}
+
+	headers := abstractions.NewRequestHeaders()
+	headers.Add("Prefer", fmt.Sprintf("outlook.body-content-type=%q", string(bodyType)))
+	return &users.ItemMessagesMessageItemRequestBuilderGetRequestConfiguration{
+		Headers: headers,
+	}, nil
+}
+
+// GetMessageSize returns the size of a message in bytes without downloading its body.
+// Graph's message resource has no size property, so the PR_MESSAGE_SIZE extended
+// property is requested instead; only the message ID and that property are returned.
+func (c *Client) GetMessageSize(ctx context.Context, messageID string) (int64, error) {
+	service, err := c.getService()
+	if err != nil {
+		return 0, err
+	}
+
+	config := &users.ItemMessagesMessageItemRequestBuilderGetRequestConfiguration{
+		QueryParameters: &users.ItemMessagesMessageItemRequestBuilderGetQueryParameters{
+			Select: []string{"id"},
+			Expand: []string{fmt.Sprintf("singleValueExtendedProperties($filter=id eq '%s')", messageSizePropertyID)},
+		},
+	}
+
+	messagesService := service.GetMeService().GetMessagesService()
+	message, err := messagesService.GetWithConfig(ctx, messageID, config)
+	if err != nil {
+		return 0, handleODataError(fmt.Errorf("failed to get size of message %s: %w", messageID, err))
+	}
+
+	for _, prop := range message.GetSingleValueExtendedProperties() {
+		if !sameExtendedPropertyID(derefString(prop.GetId()), messageSizePropertyID) {
+			continue
+		}
+		size, err := strconv.ParseInt(derefString(prop.GetValue()), 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid size of message %s: %w", messageID, err)
+		}
+		return size, nil
+	}
+	return 0, fmt.Errorf("size of message %s not returned", messageID)
+}
+
+// messageSizePropertyID is the extended property ID of PR_MESSAGE_SIZE, the size of
+// the item in bytes including attachments.
+const messageSizePropertyID = "Integer 0x0E08"
+
+// withExtendedProperties adds an $expand of the given single-value extended properties
+// to config, creating the configuration if needed. It returns config unchanged when ids is empty.
+func withExtendedProperties(config *users.ItemMessagesMessageItemRequestBuilderGetRequestConfiguration, ids []string) *users.ItemMessagesMessageItemRequestBuilderGetRequestConfiguration {
+	if len(ids) == 0 {
+		return config
+	}
+	if config == nil {
+		config = &users.ItemMessagesMessageItemRequestBuilderGetRequestConfiguration{}
+	}
+	if config.QueryParameters == nil {
+		config.QueryParameters = &users.ItemMessagesMessageItemRequestBuilderGetQueryParameters{}
+	}
+
+	clauses := make([]string, 0, len(ids))
+	for _, id := range ids {
+		clauses = append(clauses, fmt.Sprintf("id eq '%s'", escapeODataString(id)))
+	}
+	config.QueryParameters.Expand = append(config.QueryParameters.Expand,
+		fmt.Sprintf("singleValueExtendedProperties($filter=%s)", strings.Join(clauses, " or ")))
+	return config
+}
+
+// withInternetHeaders adds internetMessageHeaders to the fields requested by config.
+// Graph only returns the headers when they are selected, so the fields convertMessage
+// reads are selected along with them.
+func withInternetHeaders(config *users.ItemMessagesMessageItemRequestBuilderGetRequestConfiguration) *users.ItemMessagesMessageItemRequestBuilderGetRequestConfiguration {
+	if config == nil {
+		config = &users.ItemMessagesMessageItemRequestBuilderGetRequestConfiguration{}
+	}
+	if config.QueryParameters == nil {
+		config.QueryParameters = &users.ItemMessagesMessageItemRequestBuilderGetQueryParameters{}
+	}
+	config.QueryParameters.Select = append(append([]string{}, messageSelectFields...), "internetMessageHeaders")
+	return config
+}
+
+// rawHeaders returns the message's internet headers keyed by canonical name, keeping
+// every occurrence of repeated headers in message order.
+func rawHeaders(message models.Messageable) map[string][]string {
+	internetHeaders := message.GetInternetMessageHeaders()
+	if len(internetHeaders) == 0 {
+		return nil
+	}
+
+	headers := make(map[string][]string, len(internetHeaders))
+	for _, header := range internetHeaders {
+		if header == nil {
+			continue
+		}
+		key := textproto.CanonicalMIMEHeaderKey(derefString(header.GetName()))
+		headers[key] = append(headers[key], derefString(header.GetValue()))
+	}
+	return headers
+}
+
+// extendedProperties maps the single-value extended properties of a message to the
+// requested IDs. Graph may return an ID in a different form (e.g. "Integer 0xe07" for
+// "Integer 0x0E07"), so IDs are compared by type and property tag.
+func extendedProperties(message models.Messageable, ids []string) map[string]string {
+	if len(ids) == 0 {
+		return nil
+	}
+	properties := make(map[string]string)
+	for _, prop := range message.GetSingleValueExtendedProperties() {
+		for _, id := range ids {
+			if sameExtendedPropertyID(derefString(prop.GetId()), id) {
+				properties[id] = derefString(prop.GetValue())
+			}
+		}
+	}
+	return properties
+}
+
+// sameExtendedPropertyID reports whether two extended property IDs name the same property.
+// Tag-based IDs ("Integer 0x0E07") compare the type case-insensitively and the tag numerically;
+// other IDs (named properties) compare case-insensitively.
+func sameExtendedPropertyID(a, b string) bool {
+	typeA, tagA, okA := propertyTag(a)
+	typeB, tagB, okB := propertyTag(b)
+	if okA && okB {
+		return strings.EqualFold(typeA, typeB) && tagA == tagB
+	}
+	return strings.EqualFold(strings.TrimSpace(a), strings.TrimSpace(b))
+}
+
+// propertyTag splits a tag-based extended property ID such as "Integer 0x0E07" into
+// its type and numeric tag.
+func propertyTag(id string) (string, uint64, bool) {
+	propType, tag, ok := strings.Cut(strings.TrimSpace(id), " ")
+	if !ok || !strings.HasPrefix(strings.ToLower(tag), "0x") {
+		return "", 0, false
+	}
+	n, err := strconv.ParseUint(tag[2:], 16, 32)
+	if err != nil {
+		return "", 0, false
+	}
+	return propType, n, true
+}
+
+// GetThreadMessages retrieves all messages in a conversation, ordered by date (oldest first).
+// In Outlook the thread ID is the message's conversationId.
+func (c *Client) GetThreadMessages(ctx context.Context, threadID string) ([]*core.Email, error) {
+	service, err := c.getService()
+	if err != nil {
+		return nil, err
+	}
+
+	filter := fmt.Sprintf("conversationId eq '%s'", escapeODataString(threadID))
+	top := int32(threadPageSize)
+	config := &users.ItemMessagesRequestBuilderGetRequestConfiguration{
+		QueryParameters: &users.ItemMessagesRequestBuilderGetQueryParameters{
+			Filter: &filter,
+			Top:    &top,
+			Select: messageSelectFields,
+		},
+	}
+
+	messagesService := service.GetMeService().GetMessagesService()
+	result, err := messagesService.List(ctx, config)
+	if err != nil {
+		return nil, handleODataError(fmt.Errorf("failed to get conversation %s: %w", threadID, err))
+	}
+
+	messages, err := remainingMessagePages(ctx, messagesService, result)
+	if err != nil {
+		return nil, handleODataError(fmt.Errorf("failed to get conversation %s: %w", threadID, err))
+	}
+	emails := make([]*core.Email, 0, len(messages))
+	for _, msg := range messages {
+		emails = append(emails, c.convertMessage(msg))
+	}
+	c.resolveFolderLabels(ctx, service, emails...)
+
+	// Graph rejects $orderby combined with a conversationId filter, so sort locally
+	sort.SliceStable(emails, func(i, j int) bool {
+		return emails[i].Date.Before(emails[j].Date)
+	})
+
+	return emails, nil
+}
+
+// remainingMessagePages returns the messages of result followed by those of every
+// later page, following @odata.nextLink until Graph returns none.
+func remainingMessagePages(ctx context.Context, messagesService internal.MessagesService, result models.MessageCollectionResponseable) ([]models.Messageable, error) {
+	messages := result.GetValue()
+	for nextLink := derefString(result.GetOdataNextLink()); nextLink != ""; nextLink = derefString(result.GetOdataNextLink()) {
+		var err error
+		result, err = messagesService.ListNextPage(ctx, nextLink)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, result.GetValue()...)
+	}
+	return messages, nil
+}
+
+// GetConversation retrieves all messages sharing a conversationId, ordered by
+// receivedDateTime (oldest first). The filter runs over the whole mailbox rather than a
+// single folder, so the user's own replies in Sent Items are returned alongside the
+// messages in the Inbox. It is GetThreadMessages under Graph's name for threads.
+func (c *Client) GetConversation(ctx context.Context, conversationID string) ([]*core.Email, error) {
+	if conversationID == "" {
+		return nil, fmt.Errorf("conversation ID cannot be empty")
+	}
+	return c.GetThreadMessages(ctx, conversationID)
 }
 
 // GetAttachment retrieves a specific attachment from a message.
+// Attachments larger than 3 MB are returned with a Reader streaming the content
+// instead of Data; the caller must close it. Cancelling ctx closes the Reader.
 func (c *Client) GetAttachment(ctx context.Context, messageID, attachmentID string) (*core.Attachment, error) {
-	if !c.IsConnected() {
-		return nil, fmt.Errorf("client not connected")
+	service, err := c.getService()
+	if err != nil {
+		return nil, err
+	}
+
+	return getAttachment(ctx, service.GetMeService().GetMessagesService(), messageID, attachmentID)
+}
+
+// GetAttachmentMetadata returns an attachment's filename, MIME type and size without
+// downloading its content, e.g. to show the size before deciding to download it.
+func (c *Client) GetAttachmentMetadata(ctx context.Context, messageID, attachmentID string) (*core.Attachment, error) {
+	service, err := c.getService()
+	if err != nil {
+		return nil, err
+	}
+
+	metadata, err := service.GetMeService().GetMessagesService().GetAttachmentMetadata(ctx, messageID, attachmentID)
+	if err != nil {
+		return nil, handleODataError(fmt.Errorf("failed to get attachment %s metadata from message %s: %w", attachmentID, messageID, err))
+	}
+
+	attachment := convertAttachment(metadata)
+	attachment.Data = nil
+	return attachment, nil
+}
+
+// GetAttachmentStream streams an attachment's raw content, whatever its size, without
+// loading it into memory, and returns its metadata (filename, MIME type and size; Data
+// is nil). The caller must close the reader. Cancelling ctx closes the underlying
+// response, so a pending or later Read returns the context's error (e.g.
+// context.Canceled) instead of leaking the connection.
+func (c *Client) GetAttachmentStream(ctx context.Context, messageID, attachmentID string) (io.ReadCloser, *core.Attachment, error) {
+	service, err := c.getService()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	messagesService := service.GetMeService().GetMessagesService()
+	metadata, err := messagesService.GetAttachmentMetadata(ctx, messageID, attachmentID)
+	if err != nil {
+		return nil, nil, handleODataError(fmt.Errorf("failed to get attachment %s metadata from message %s: %w", attachmentID, messageID, err))
+	}
+
+	reader, err := messagesService.GetAttachmentContent(ctx, messageID, attachmentID)
+	if err != nil {
+		return nil, nil, handleODataError(fmt.Errorf("failed to download attachment %s from message %s: %w", attachmentID, messageID, err))
+	}
+
+	attachment := convertAttachment(metadata)
+	attachment.Data = nil
+	return newContextReadCloser(ctx, reader), attachment, nil
+}
+
+// getAttachment downloads an attachment, streaming it when it is larger than 3 MB.
+func getAttachment(ctx context.Context, messagesService internal.MessagesService, messageID, attachmentID string) (*core.Attachment, error) {
+	metadata, err := messagesService.GetAttachmentMetadata(ctx, messageID, attachmentID)
+	if err != nil {
+		return nil, handleODataError(fmt.Errorf("failed to get attachment %s from message %s: %w", attachmentID, messageID, err))
+	}
+
+	// Stream large attachments rather than buffering them in memory
+	if size := metadata.GetSize(); size != nil && int64(*size) > largeAttachmentThreshold {
+		reader, err := messagesService.GetAttachmentContent(ctx, messageID, attachmentID)
+		if err != nil {
+			return nil, handleODataError(fmt.Errorf("failed to download attachment %s from message %s: %w", attachmentID, messageID, err))
+		}
+		attachment := convertAttachment(metadata)
+		attachment.Reader = newContextReadCloser(ctx, reader)
+		return attachment, nil
 	}
 
-	messagesService := c.service.GetMeService().GetMessagesService()
 	attachment, err := messagesService.GetAttachment(ctx, messageID, attachmentID)
 	if err != nil {
 		return nil, handleODataError(fmt.Errorf("failed to get attachment %s from message %s: %w", attachmentID, messageID, err))
@@ -116,13 +676,82 @@ func (c *Client) GetAttachment(ctx context.Context, messageID, attachmentID stri
 	return convertAttachment(attachment), nil
 }
 
+// GetMessageInlineHTML returns the message's HTML body as standalone HTML, with inline
+// (cid:) images embedded as base64 data: URLs. Only attachments marked inline are
+// downloaded, and only when the body references one.
+func (c *Client) GetMessageInlineHTML(ctx context.Context, messageID string, callOpts ...CallOption) (string, error) {
+	email, err := c.getMessage(ctx, messageID, newCallOptions(callOpts))
+	if err != nil {
+		return "", err
+	}
+	if !core.HasCIDReferences(email.Body.HTML) {
+		return email.Body.HTML, nil
+	}
+
+	service, err := c.getService()
+	if err != nil {
+		return "", err
+	}
+
+	messagesService := newCallOptions(callOpts).userService(service).GetMessagesService()
+	metadata, err := messagesService.ListAttachmentMetadata(ctx, messageID)
+	if err != nil {
+		return "", handleODataError(fmt.Errorf("failed to list attachments for message %s: %w", messageID, err))
+	}
+
+	var inline []core.Attachment
+	for _, att := range metadata {
+		if isInline := att.GetIsInline(); isInline == nil || !*isInline {
+			continue
+		}
+		attachmentID := derefString(att.GetId())
+		full, err := messagesService.GetAttachment(ctx, messageID, attachmentID)
+		if err != nil {
+			return "", handleODataError(fmt.Errorf("failed to get inline attachment %s from message %s: %w", attachmentID, messageID, err))
+		}
+		inline = append(inline, *convertAttachment(full))
+	}
+
+	return core.EmbedInlineAttachments(email.Body.HTML, inline), nil
+}
+
+// GetAttachmentByIndex downloads the Nth (zero-based) attachment of a message,
+// in the order returned by the attachments list. The list only carries metadata,
+// so only the chosen attachment's content is downloaded.
+func (c *Client) GetAttachmentByIndex(ctx context.Context, messageID string, index int) (*core.Attachment, error) {
+	service, err := c.getService()
+	if err != nil {
+		return nil, err
+	}
+
+	messagesService := service.GetMeService().GetMessagesService()
+	attachments, err := messagesService.ListAttachmentMetadata(ctx, messageID)
+	if err != nil {
+		return nil, handleODataError(fmt.Errorf("failed to list attachments for message %s: %w", messageID, err))
+	}
+
+	if index < 0 || index >= len(attachments) {
+		return nil, fmt.Errorf("attachment index %d out of range: message %s has %d attachments", index, messageID, len(attachments))
+	}
+
+	return c.GetAttachment(ctx, messageID, derefString(attachments[index].GetId()))
+}
+
 // MarkAsRead marks a message as read.
+// Use MarkAsReadWithOptions with WithMailbox to update a message in a shared mailbox.
 func (c *Client) MarkAsRead(ctx context.Context, messageID string) error {
-	if !c.IsConnected() {
-		return fmt.Errorf("client not connected")
+	return c.MarkAsReadWithOptions(ctx, messageID)
+}
+
+// MarkAsReadWithOptions marks a message as read.
+// Pass WithMailbox to update a message in a shared mailbox.
+func (c *Client) MarkAsReadWithOptions(ctx context.Context, messageID string, opts ...CallOption) error {
+	service, err := c.getService()
+	if err != nil {
+		return err
 	}
 
-	messagesService := c.service.GetMeService().GetMessagesService()
+	messagesService := newCallOptions(opts).userService(service).GetMessagesService()
 	if err := messagesService.MarkAsRead(ctx, messageID); err != nil {
 		return handleODataError(fmt.Errorf("failed to mark message %s as read: %w", messageID, err))
 	}
@@ -130,13 +759,79 @@ func (c *Client) MarkAsRead(ctx context.Context, messageID string) error {
 	return nil
 }
 
+// MarkConversationAsRead marks every unread message in a conversation as read.
+// The updates are sent as $batch requests rather than one request per message.
+func (c *Client) MarkConversationAsRead(ctx context.Context, conversationID string) error {
+	service, err := c.getService()
+	if err != nil {
+		return err
+	}
+
+	filter := fmt.Sprintf("conversationId eq '%s' and isRead eq false", escapeODataString(conversationID))
+	top := int32(threadPageSize)
+	config := &users.ItemMessagesRequestBuilderGetRequestConfiguration{
+		QueryParameters: &users.ItemMessagesRequestBuilderGetQueryParameters{
+			Filter: &filter,
+			Top:    &top,
+			Select: []string{"id", "isRead"},
+		},
+	}
+
+	messagesService := service.GetMeService().GetMessagesService()
+	result, err := messagesService.List(ctx, config)
+	if err != nil {
+		return handleODataError(fmt.Errorf("failed to get conversation %s: %w", conversationID, err))
+	}
+
+	messages, err := remainingMessagePages(ctx, messagesService, result)
+	if err != nil {
+		return handleODataError(fmt.Errorf("failed to get conversation %s: %w", conversationID, err))
+	}
+
+	var unreadIDs []string
+	for _, msg := range messages {
+		if msg.GetIsRead() != nil && *msg.GetIsRead() {
+			continue
+		}
+		if id := derefString(msg.GetId()); id != "" {
+			unreadIDs = append(unreadIDs, id)
+		}
+	}
+
+	if len(unreadIDs) == 0 {
+		return nil
+	}
+
+	if err := messagesService.BatchMarkAsRead(ctx, unreadIDs); err != nil {
+		return handleODataError(fmt.Errorf("failed to mark conversation %s as read: %w", conversationID, err))
+	}
+
+	return nil
+}
+
+// SetFocused moves a message between the Focused and Other tabs of the Focused Inbox.
+func (c *Client) SetFocused(ctx context.Context, messageID string, focused bool) error {
+	service, err := c.getService()
+	if err != nil {
+		return err
+	}
+
+	messagesService := service.GetMeService().GetMessagesService()
+	if err := messagesService.SetFocused(ctx, messageID, focused); err != nil {
+		return handleODataError(fmt.Errorf("failed to set focused state of message %s: %w", messageID, err))
+	}
+
+	return nil
+}
+
 // MarkAsUnread marks a message as unread.
 func (c *Client) MarkAsUnread(ctx context.Context, messageID string) error {
-	if !c.IsConnected() {
-		return fmt.Errorf("client not connected")
+	service, err := c.getService()
+	if err != nil {
+		return err
 	}
 
-	messagesService := c.service.GetMeService().GetMessagesService()
+	messagesService := service.GetMeService().GetMessagesService()
 	if err := messagesService.MarkAsUnread(ctx, messageID); err != nil {
 		return handleODataError(fmt.Errorf("failed to mark message %s as unread: %w", messageID, err))
 	}
@@ -146,11 +841,12 @@ func (c *Client) MarkAsUnread(ctx context.Context, messageID string) error {
 
 // MoveMessage moves a message to a different folder.
 func (c *Client) MoveMessage(ctx context.Context, messageID, destinationFolderID string) error {
-	if !c.IsConnected() {
-		return fmt.Errorf("client not connected")
+	service, err := c.getService()
+	if err != nil {
+		return err
 	}
 
-	messagesService := c.service.GetMeService().GetMessagesService()
+	messagesService := service.GetMeService().GetMessagesService()
 	if err := messagesService.Move(ctx, messageID, destinationFolderID); err != nil {
 		return handleODataError(fmt.Errorf("failed to move message %s to folder %s: %w", messageID, destinationFolderID, err))
 	}
@@ -158,13 +854,228 @@ func (c *Client) MoveMessage(ctx context.Context, messageID, destinationFolderID
 	return nil
 }
 
+// RelocateMessage moves a message to a folder in another mailbox and returns its ID
+// there. Graph cannot move messages between mailboxes, so the message is created again
+// in destFolderID of destMailbox and then deleted from sourceMailbox. An empty mailbox
+// is the signed-in user's; the token needs access to both (see WithMailbox).
+//
+// The copy is created from the source's properties and file attachments, with
+// PR_MESSAGE_FLAGS, the delivery and submit times, the read state and the follow-up
+// flag set in the same request: Exchange only honours PR_MESSAGE_FLAGS before a
+// message is first saved, so this is what makes the copy a received message rather
+// than a draft. Messages whose attachments cannot be sent inline (item or reference
+// attachments, or more than maxRelocateAttachmentSize) are imported from their MIME
+// content instead; Graph creates those copies as drafts, and only their read state
+// and follow-up flag are carried over.
+//
+// If the source message cannot be deleted, or an imported copy cannot be updated, the
+// copy is deleted again so the message exists only once, in the source mailbox, and
+// the error is returned. Use MoveMessage to move a message within one mailbox.
+func (c *Client) RelocateMessage(ctx context.Context, messageID, sourceMailbox, destMailbox, destFolderID string) (string, error) {
+	service, err := c.getService()
+	if err != nil {
+		return "", err
+	}
+
+	source := (&callOptions{mailbox: sourceMailbox}).userService(service).GetMessagesService()
+	dest := (&callOptions{mailbox: destMailbox}).userService(service)
+
+	original, err := source.GetWithConfig(ctx, messageID, &users.ItemMessagesMessageItemRequestBuilderGetRequestConfiguration{
+		QueryParameters: &users.ItemMessagesMessageItemRequestBuilderGetQueryParameters{
+			Select: relocateSelectFields,
+		},
+	})
+	if err != nil {
+		return "", handleODataError(fmt.Errorf("failed to get message %s: %w", messageID, err))
+	}
+
+	var attachments []models.Attachmentable
+	if original.GetHasAttachments() != nil && *original.GetHasAttachments() {
+		attachments, err = source.GetAttachments(ctx, messageID)
+		if err != nil {
+			return "", handleODataError(fmt.Errorf("failed to get attachments for message %s: %w", messageID, err))
+		}
+	}
+
+	var newID string
+	if inline, ok := inlineAttachments(attachments); ok {
+		created, err := dest.GetMailFoldersService().CreateMessage(ctx, destFolderID, relocatedMessage(original, inline))
+		if err != nil {
+			return "", handleODataError(fmt.Errorf("failed to copy message %s to folder %s: %w", messageID, destFolderID, err))
+		}
+		newID = derefString(created.GetId())
+	} else {
+		newID, err = importRelocatedMessage(ctx, source, dest, messageID, destFolderID, original)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if err := source.Delete(ctx, messageID); err != nil {
+		return "", removeRelocatedCopy(ctx, dest, newID, handleODataError(fmt.Errorf("failed to delete message %s after copying it: %w", messageID, err)))
+	}
+	c.uncacheMessage(messageCacheKey(sourceMailbox, messageID))
+
+	return newID, nil
+}
+
+// importRelocatedMessage is RelocateMessage's fallback for messages that cannot be
+// recreated from their properties. It imports the MIME content of messageID into
+// destFolderID and copies the read state and follow-up flag of original onto it.
+// Graph creates imported messages as drafts, and the copy stays one: PR_MESSAGE_FLAGS
+// cannot be changed once the message is saved.
+func importRelocatedMessage(ctx context.Context, source internal.MessagesService, dest internal.MeService, messageID, destFolderID string, original models.Messageable) (string, error) {
+	mimeContent, err := source.GetMimeContent(ctx, messageID)
+	if err != nil {
+		return "", handleODataError(fmt.Errorf("failed to read message %s: %w", messageID, err))
+	}
+
+	copied, err := dest.GetMailFoldersService().ImportMessage(ctx, destFolderID, mimeContent)
+	if err != nil {
+		return "", handleODataError(fmt.Errorf("failed to copy message %s to folder %s: %w", messageID, destFolderID, err))
+	}
+	newID := derefString(copied.GetId())
+
+	update := models.NewMessage()
+	update.SetIsRead(original.GetIsRead())
+	update.SetFlag(original.GetFlag())
+	if _, err := dest.GetMessagesService().Update(ctx, newID, update); err != nil {
+		return "", removeRelocatedCopy(ctx, dest, newID, handleODataError(fmt.Errorf("failed to update copy of message %s: %w", messageID, err)))
+	}
+	return newID, nil
+}
+
+// removeRelocatedCopy deletes the copy made by RelocateMessage after a failure so the
+// message stays only in the source mailbox, and returns cause.
+func removeRelocatedCopy(ctx context.Context, dest internal.MeService, copyID string, cause error) error {
+	if err := dest.GetMessagesService().Delete(ctx, copyID); err != nil {
+		return errors.Join(cause, fmt.Errorf("failed to remove copy %s: %w", copyID, err))
+	}
+	return cause
+}
+
+// relocateSelectFields are the message fields RelocateMessage copies to the new mailbox.
+var relocateSelectFields = []string{
+	"id", "subject", "body", "from", "sender", "toRecipients", "ccRecipients", "bccRecipients",
+	"replyTo", "importance", "internetMessageId", "categories", "sentDateTime",
+	"receivedDateTime", "hasAttachments", "isRead", "flag",
+}
+
+// maxRelocateAttachmentSize is the largest total base64-encoded attachment size that
+// RelocateMessage sends inline; Graph rejects create requests over 4 MB.
+const maxRelocateAttachmentSize = 3 * 1024 * 1024
+
+// Extended property IDs set on relocated messages.
+const (
+	// messageFlagsPropertyID is PR_MESSAGE_FLAGS. Leaving out MSGFLAG_UNSENT (0x8)
+	// makes a new message a received one rather than a draft.
+	messageFlagsPropertyID = "Integer 0x0E07"
+	// deliveryTimePropertyID is PR_MESSAGE_DELIVERY_TIME, shown as receivedDateTime.
+	deliveryTimePropertyID = "SystemTime 0x0E06"
+	// submitTimePropertyID is PR_CLIENT_SUBMIT_TIME, shown as sentDateTime.
+	submitTimePropertyID = "SystemTime 0x0039"
+)
+
+// msgFlagRead is the MSGFLAG_READ bit of PR_MESSAGE_FLAGS.
+const msgFlagRead = 0x1
+
+// inlineAttachments returns attachments as new file attachments to create a message
+// with. It reports false if any of them is not a file attachment or if together they
+// exceed maxRelocateAttachmentSize.
+func inlineAttachments(attachments []models.Attachmentable) ([]models.Attachmentable, bool) {
+	inline := make([]models.Attachmentable, 0, len(attachments))
+	size := 0
+	for _, att := range attachments {
+		file, ok := att.(models.FileAttachmentable)
+		if !ok {
+			return nil, false
+		}
+		size += base64.StdEncoding.EncodedLen(len(file.GetContentBytes()))
+		if size > maxRelocateAttachmentSize {
+			return nil, false
+		}
+
+		odataType := "#microsoft.graph.fileAttachment"
+		copied := models.NewFileAttachment()
+		copied.SetOdataType(&odataType)
+		copied.SetName(file.GetName())
+		copied.SetContentType(file.GetContentType())
+		copied.SetContentBytes(file.GetContentBytes())
+		copied.SetContentId(file.GetContentId())
+		copied.SetIsInline(file.GetIsInline())
+		inline = append(inline, copied)
+	}
+	return inline, true
+}
+
+// relocatedMessage returns the message created in the destination mailbox for source:
+// its properties and attachments, the message flags of a received message, and the
+// source's delivery and submit times, read state and follow-up flag.
+func relocatedMessage(source models.Messageable, attachments []models.Attachmentable) models.Messageable {
+	isRead := source.GetIsRead() != nil && *source.GetIsRead()
+	flags := 0
+	if isRead {
+		flags |= msgFlagRead
+	}
+	properties := []models.SingleValueLegacyExtendedPropertyable{
+		extendedProperty(messageFlagsPropertyID, strconv.Itoa(flags)),
+	}
+	if received := source.GetReceivedDateTime(); received != nil {
+		properties = append(properties, extendedProperty(deliveryTimePropertyID, received.UTC().Format(time.RFC3339)))
+	}
+	if sent := source.GetSentDateTime(); sent != nil {
+		properties = append(properties, extendedProperty(submitTimePropertyID, sent.UTC().Format(time.RFC3339)))
+	}
+
+	message := models.NewMessage()
+	message.SetSingleValueExtendedProperties(properties)
+	message.SetSubject(source.GetSubject())
+	message.SetBody(source.GetBody())
+	message.SetFrom(source.GetFrom())
+	message.SetSender(source.GetSender())
+	message.SetToRecipients(source.GetToRecipients())
+	message.SetCcRecipients(source.GetCcRecipients())
+	message.SetBccRecipients(source.GetBccRecipients())
+	message.SetReplyTo(source.GetReplyTo())
+	message.SetImportance(source.GetImportance())
+	message.SetInternetMessageId(source.GetInternetMessageId())
+	message.SetCategories(source.GetCategories())
+	message.SetIsRead(&isRead)
+	if flag := source.GetFlag(); flag != nil {
+		message.SetFlag(flag)
+	}
+	if len(attachments) > 0 {
+		message.SetAttachments(attachments)
+	}
+	return message
+}
+
+// extendedProperty returns a single-value extended property.
+func extendedProperty(id, value string) models.SingleValueLegacyExtendedPropertyable {
+	property := models.NewSingleValueLegacyExtendedProperty()
+	property.SetId(&id)
+	property.SetValue(&value)
+	return property
+}
+
+// ReportSpam moves a message to the Junk Email folder.
+func (c *Client) ReportSpam(ctx context.Context, messageID string) error {
+	return c.MoveMessage(ctx, messageID, FolderJunkEmail)
+}
+
+// NotSpam moves a message out of the Junk Email folder back to the Inbox.
+func (c *Client) NotSpam(ctx context.Context, messageID string) error {
+	return c.MoveMessage(ctx, messageID, FolderInbox)
+}
+
 // DeleteMessage deletes a message permanently.
 func (c *Client) DeleteMessage(ctx context.Context, messageID string) error {
-	if !c.IsConnected() {
-		return fmt.Errorf("client not connected")
+	service, err := c.getService()
+	if err != nil {
+		return err
 	}
 
-	messagesService := c.service.GetMeService().GetMessagesService()
+	messagesService := service.GetMeService().GetMessagesService()
 	if err := messagesService.Delete(ctx, messageID); err != nil {
 		return handleODataError(fmt.Errorf("failed to delete message %s: %w", messageID, err))
 	}
@@ -172,69 +1083,143 @@ func (c *Client) DeleteMessage(ctx context.Context, messageID string) error {
 	return nil
 }
 
-// convertMessage converts a Microsoft Graph Message to a core.Email.
-// This is the adapter pattern implementation.
-func (c *Client) convertMessage(msg models.Messageable) *core.Email {
-	email := &core.Email{
-		ID:      derefString(msg.GetId()),
-		Subject: derefString(msg.GetSubject()),
+// validateListOptions rejects option combinations Graph cannot serve. $search cannot be
+// combined with $filter, so Query is exclusive with every option that buildFilter
+// turns into a filter clause.
+func validateListOptions(opts *core.ListOptions) error {
+	if opts == nil || opts.Query == "" {
+		return nil
+	}
+	var conflict string
+	switch {
+	case opts.HasAttachments != nil:
+		conflict = "HasAttachments"
+	case opts.ExcludeFromSelf:
+		conflict = "ExcludeFromSelf"
+	case opts.Focused != nil:
+		conflict = "Focused"
+	case opts.HasCalendarInvite != nil:
+		conflict = "HasCalendarInvite"
+	case opts.Filter != "":
+		conflict = "Filter"
+	default:
+		return nil
+	}
+	return &core.ConfigError{
+		Field:   "Query",
+		Message: fmt.Sprintf("Query cannot be combined with %s: Graph does not allow $search together with $filter, so use either a search query or structured filters", conflict),
 	}
+}
 
-	// From
-	if from := msg.GetFrom(); from != nil {
-		if emailAddr := from.GetEmailAddress(); emailAddr != nil {
-			email.From = core.EmailAddress{
-				Name:  derefString(emailAddr.GetName()),
-				Email: derefString(emailAddr.GetAddress()),
-			}
+// buildFilter translates the structured list options into an OData $filter expression.
+// selfAddress is only used when ExcludeFromSelf is set.
+// Returns an empty string when no structured filter is set.
+func buildFilter(opts *core.ListOptions, selfAddress string) string {
+	var clauses []string
+	if opts.HasAttachments != nil {
+		clauses = append(clauses, fmt.Sprintf("hasAttachments eq %t", *opts.HasAttachments))
+	}
+	if opts.ExcludeFromSelf && selfAddress != "" {
+		clauses = append(clauses, fmt.Sprintf("from/emailAddress/address ne '%s'", escapeODataString(selfAddress)))
+	}
+	if opts.Focused != nil {
+		classification := models.OTHER_INFERENCECLASSIFICATIONTYPE
+		if *opts.Focused {
+			classification = models.FOCUSED_INFERENCECLASSIFICATIONTYPE
 		}
+		clauses = append(clauses, fmt.Sprintf("inferenceClassification eq '%s'", classification.String()))
 	}
-
-	// To recipients
-	if toRecipients := msg.GetToRecipients(); toRecipients != nil {
-		email.To = make([]core.EmailAddress, 0, len(toRecipients))
-		for _, recipient := range toRecipients {
-			if emailAddr := recipient.GetEmailAddress(); emailAddr != nil {
-				email.To = append(email.To, core.EmailAddress{
-					Name:  derefString(emailAddr.GetName()),
-					Email: derefString(emailAddr.GetAddress()),
-				})
-			}
+	if opts.HasCalendarInvite != nil {
+		operator := "ne"
+		if *opts.HasCalendarInvite {
+			operator = "eq"
 		}
+		clauses = append(clauses, fmt.Sprintf("singleValueExtendedProperties/Any(ep: ep/id eq '%s' and ep/value %s '%s')",
+			messageClassPropertyID, operator, meetingRequestMessageClass))
+	}
+	if opts.Filter != "" {
+		clauses = append(clauses, "("+opts.Filter+")")
 	}
+	return strings.Join(clauses, " and ")
+}
 
-	// CC recipients
-	if ccRecipients := msg.GetCcRecipients(); ccRecipients != nil {
-		email.Cc = make([]core.EmailAddress, 0, len(ccRecipients))
-		for _, recipient := range ccRecipients {
-			if emailAddr := recipient.GetEmailAddress(); emailAddr != nil {
-				email.Cc = append(email.Cc, core.EmailAddress{
-					Name:  derefString(emailAddr.GetName()),
-					Email: derefString(emailAddr.GetAddress()),
-				})
-			}
-		}
+// messageClassPropertyID is the extended property ID of PR_MESSAGE_CLASS, which
+// holds the item's message class (e.g. IPM.Note for mail, IPM.Schedule.Meeting.Request
+// for meeting invitations).
+const messageClassPropertyID = "String 0x001A"
+
+// meetingRequestMessageClass is the message class of meeting invitations.
+const meetingRequestMessageClass = "IPM.Schedule.Meeting.Request"
+
+// orderByClause returns the $orderby expression for the given order, or "" for the
+// provider default. It orders by receivedDateTime, which convertMessage uses for Email.Date.
+func orderByClause(order core.OrderBy) string {
+	switch order {
+	case core.OrderByDateDesc:
+		return "receivedDateTime desc"
+	case core.OrderByDateAsc:
+		return "receivedDateTime asc"
 	}
+	return ""
+}
 
-	// BCC recipients
-	if bccRecipients := msg.GetBccRecipients(); bccRecipients != nil {
-		email.Bcc = make([]core.EmailAddress, 0, len(bccRecipients))
-		for _, recipient := range bccRecipients {
-			if emailAddr := recipient.GetEmailAddress(); emailAddr != nil {
-				email.Bcc = append(email.Bcc, core.EmailAddress{
-					Name:  derefString(emailAddr.GetName()),
-					Email: derefString(emailAddr.GetAddress()),
-				})
-			}
+// searchScore returns the relevance Graph annotates $search results with
+// (@search.score), or 0 when the message carries none.
+func searchScore(msg models.Messageable) float64 {
+	switch score := msg.GetAdditionalData()["@search.score"].(type) {
+	case *float64:
+		if score != nil {
+			return *score
+		}
+	case float64:
+		return score
+	case *float32:
+		if score != nil {
+			return float64(*score)
+		}
+	case *int64:
+		if score != nil {
+			return float64(*score)
+		}
+	case *int32:
+		if score != nil {
+			return float64(*score)
 		}
 	}
+	return 0
+}
 
-	// Dates
-	if receivedTime := msg.GetReceivedDateTime(); receivedTime != nil {
-		email.Date = *receivedTime
+// escapeODataString escapes single quotes in an OData string literal.
+func escapeODataString(s string) string {
+	return strings.ReplaceAll(s, "'", "''")
+}
+
+// convertMessage converts a Microsoft Graph Message to a core.Email.
+// This is the adapter pattern implementation.
+func (c *Client) convertMessage(msg models.Messageable) *core.Email {
+	email := &core.Email{
+		ID:        derefString(msg.GetId()),
+		ThreadID:  derefString(msg.GetConversationId()),
+		MessageID: derefString(msg.GetInternetMessageId()),
+		Subject:   derefString(msg.GetSubject()),
 	}
+
+	// From and recipients; entries without an email address are skipped
+	if from, ok := convertRecipient(msg.GetFrom()); ok {
+		email.From = from
+	}
+	email.To = convertRecipients(msg.GetToRecipients())
+	email.Cc = convertRecipients(msg.GetCcRecipients())
+	email.Bcc = convertRecipients(msg.GetBccRecipients())
+
+	// Dates: Date is the received time, falling back to the sent time (e.g. for drafts)
 	if sentTime := msg.GetSentDateTime(); sentTime != nil {
-		email.Date = *sentTime // Use sent time if available
+		email.SentDate = *sentTime
+		email.Date = *sentTime
+	}
+	if receivedTime := msg.GetReceivedDateTime(); receivedTime != nil {
+		email.ReceivedDate = *receivedTime
+		email.Date = *receivedTime
 	}
 
 	// Read status
@@ -242,6 +1227,11 @@ func (c *Client) convertMessage(msg models.Messageable) *core.Email {
 		email.IsRead = *isRead
 	}
 
+	// Focused Inbox classification
+	if classification := msg.GetInferenceClassification(); classification != nil {
+		email.Focused = *classification == models.FOCUSED_INFERENCECLASSIFICATIONTYPE
+	}
+
 	// Body
 	if body := msg.GetBody(); body != nil {
 		content := derefString(body.GetContent())
@@ -263,12 +1253,33 @@ func (c *Client) convertMessage(msg models.Messageable) *core.Email {
 		// Users need to call GetAttachment to download
 		email.Attachments = []core.Attachment{}
 	}
+	// Attachments expanded with the message, e.g. by ListOptions.ExpandSmallAttachments
+	for _, att := range msg.GetAttachments() {
+		if att != nil {
+			email.Attachments = append(email.Attachments, *convertAttachment(att))
+		}
+	}
+
+	// Internet headers (only returned when explicitly selected)
+	email.RawHeaders = rawHeaders(msg)
+
+	// S/MIME messages keep their signed or encrypted content in an smime.p7m attachment
+	detectSecureMIME(email)
+
+	// Meeting requests, cancellations and responses carry their event as structured data
+	if eventMessage, ok := msg.(models.EventMessageable); ok {
+		email.Event = convertEventMessage(eventMessage, email)
+	}
 
 	// Labels (folder ID in Outlook)
 	if folderID := msg.GetParentFolderId(); folderID != nil {
 		email.Labels = []string{*folderID}
 	}
 
+	email.Score = searchScore(msg)
+
+	c.config.applyConversionHook(email)
+
 	return email
 }
 
@@ -285,16 +1296,49 @@ func convertAttachment(att models.Attachmentable) *core.Attachment {
 		attachment.Size = int64(*size)
 	}
 
-	// Data (only for FileAttachment)
+	// Data and Content-ID (only for FileAttachment)
 	if fileAtt, ok := att.(models.FileAttachmentable); ok {
 		if contentBytes := fileAtt.GetContentBytes(); contentBytes != nil {
 			attachment.Data = contentBytes
 		}
+		attachment.ContentID = core.NormalizeContentID(derefString(fileAtt.GetContentId()))
 	}
 
 	return attachment
 }
 
+// convertRecipient converts a Graph recipient to a core.EmailAddress. It reports false
+// for a missing recipient or one without an email address, which Graph may return for
+// partially populated messages.
+func convertRecipient(recipient models.Recipientable) (core.EmailAddress, bool) {
+	if recipient == nil {
+		return core.EmailAddress{}, false
+	}
+	emailAddr := recipient.GetEmailAddress()
+	if emailAddr == nil {
+		return core.EmailAddress{}, false
+	}
+	return core.EmailAddress{
+		Name:  derefString(emailAddr.GetName()),
+		Email: derefString(emailAddr.GetAddress()),
+	}, true
+}
+
+// convertRecipients converts Graph recipients to core.EmailAddress values, skipping
+// those convertRecipient rejects. A nil list stays nil.
+func convertRecipients(recipients []models.Recipientable) []core.EmailAddress {
+	if recipients == nil {
+		return nil
+	}
+	addresses := make([]core.EmailAddress, 0, len(recipients))
+	for _, recipient := range recipients {
+		if address, ok := convertRecipient(recipient); ok {
+			addresses = append(addresses, address)
+		}
+	}
+	return addresses
+}
+
 // Helper functions
 
 func derefString(s *string) string {
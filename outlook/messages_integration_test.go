@@ -2,15 +2,23 @@ package outlook
 
 import (
 	"context"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/danielrivera/mailbridge-go/core"
 	outlooktest "github.com/danielrivera/mailbridge-go/outlook/testing"
 	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/microsoftgraph/msgraph-sdk-go/models/odataerrors"
 	"github.com/microsoftgraph/msgraph-sdk-go/users"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // Helper to create test client with mocked service
@@ -33,7 +41,7 @@ func createTestClient() (*Client, *outlooktest.MockGraphService, *outlooktest.Mo
 	mockMeService.On("GetMessagesService").Return(mockMessagesService)
 
 	// Inject mocked service
-	client.service = mockGraphService
+	client.SetService(mockGraphService)
 
 	return client, mockGraphService, mockMessagesService
 }
@@ -129,6 +137,115 @@ func TestClient_ListMessages(t *testing.T) {
 	mockMessagesService.AssertExpectations(t)
 }
 
+func TestClient_ListMessages_IDsOnly(t *testing.T) {
+	client, _, mockMessagesService := createTestClient()
+	client.config.ResolveFolderNames = true
+	ctx := context.Background()
+
+	message := createTestMessage()
+	conversationID := "conv-1"
+	message.SetConversationId(&conversationID)
+	mockResponse := models.NewMessageCollectionResponse()
+	mockResponse.SetValue([]models.Messageable{message})
+
+	var capturedConfig *users.ItemMessagesRequestBuilderGetRequestConfiguration
+	mockMessagesService.On("List", ctx, mock.Anything).
+		Run(func(args mock.Arguments) {
+			capturedConfig = args.Get(1).(*users.ItemMessagesRequestBuilderGetRequestConfiguration)
+		}).
+		Return(mockResponse, nil)
+
+	result, err := client.ListMessages(ctx, &core.ListOptions{MaxResults: 1, IDsOnly: true})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"id", "conversationId"}, capturedConfig.QueryParameters.Select)
+	require.Len(t, result.Emails, 1)
+	assert.Equal(t, &core.Email{ID: "msg-123", ThreadID: "conv-1"}, result.Emails[0])
+	assert.Equal(t, "1", result.NextPageToken)
+}
+
+func TestClient_ListMessages_ExpandSmallAttachments(t *testing.T) {
+	client, _, mockMessagesService := createTestClient()
+	ctx := context.Background()
+
+	newAttachment := func(id, name string, size int32, content []byte) models.Attachmentable {
+		att := models.NewFileAttachment()
+		att.SetId(&id)
+		att.SetName(&name)
+		att.SetSize(&size)
+		att.SetContentBytes(content)
+		return att
+	}
+	// The message also has a 5 MB attachment, which the $expand filter leaves out
+	message := createTestMessage()
+	hasAttachments := true
+	message.SetHasAttachments(&hasAttachments)
+	message.SetAttachments([]models.Attachmentable{newAttachment("att-small", "note.txt", 5, []byte("hello"))})
+	mockResponse := models.NewMessageCollectionResponse()
+	mockResponse.SetValue([]models.Messageable{message})
+
+	var capturedConfig *users.ItemMessagesRequestBuilderGetRequestConfiguration
+	mockMessagesService.On("List", ctx, mock.Anything).
+		Run(func(args mock.Arguments) {
+			capturedConfig = args.Get(1).(*users.ItemMessagesRequestBuilderGetRequestConfiguration)
+		}).
+		Return(mockResponse, nil)
+
+	result, err := client.ListMessages(ctx, &core.ListOptions{MaxResults: 1, ExpandSmallAttachments: 64 * 1024})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"attachments($filter=size lt 65536)"}, capturedConfig.QueryParameters.Expand)
+	assert.Contains(t, capturedConfig.QueryParameters.Select, "internetMessageId", "StableKey needs the Message-ID")
+	require.Len(t, result.Emails, 1)
+	require.Len(t, result.Emails[0].Attachments, 1)
+	assert.Equal(t, "att-small", result.Emails[0].Attachments[0].ID)
+	assert.Equal(t, []byte("hello"), result.Emails[0].Attachments[0].Data)
+
+	t.Run("not requested", func(t *testing.T) {
+		_, err := client.ListMessages(ctx, &core.ListOptions{MaxResults: 1})
+		require.NoError(t, err)
+		assert.Nil(t, capturedConfig.QueryParameters.Expand)
+	})
+
+	t.Run("ignored with IDsOnly", func(t *testing.T) {
+		_, err := client.ListMessages(ctx, &core.ListOptions{MaxResults: 1, IDsOnly: true, ExpandSmallAttachments: 1024})
+		require.NoError(t, err)
+		assert.Nil(t, capturedConfig.QueryParameters.Expand)
+	})
+}
+
+func TestClient_ListMessages_ConversionHook(t *testing.T) {
+	client, _, mockMessagesService := createTestClient()
+	ctx := context.Background()
+
+	calls := 0
+	client.config.ConversionHook = func(email *core.Email) {
+		calls++
+		if strings.HasSuffix(email.From.Email, "@example.com") {
+			email.Subject = "[internal] " + email.Subject
+		}
+	}
+
+	mockResponse := models.NewMessageCollectionResponse()
+	mockResponse.SetValue([]models.Messageable{createTestMessage()})
+	mockMessagesService.On("List", ctx, mock.Anything).Return(mockResponse, nil)
+
+	result, err := client.ListMessages(ctx, &core.ListOptions{MaxResults: 10})
+
+	require.NoError(t, err)
+	require.Len(t, result.Emails, 1)
+	assert.Equal(t, "[internal] Test Subject", result.Emails[0].Subject)
+	assert.Equal(t, 1, calls)
+}
+
+func TestClient_ConvertMessage_NilConfig(t *testing.T) {
+	client := &Client{}
+
+	email := client.convertMessage(createTestMessage())
+
+	assert.Equal(t, "Test Subject", email.Subject)
+}
+
 func TestClient_ListMessages_WithPagination(t *testing.T) {
 	client, mockGraphService, mockMessagesService := createTestClient()
 	ctx := context.Background()
@@ -158,6 +275,106 @@ func TestClient_ListMessages_WithPagination(t *testing.T) {
 	mockMessagesService.AssertExpectations(t)
 }
 
+func TestClient_EachMessage(t *testing.T) {
+	ctx := context.Background()
+	setup := func() (*Client, *outlooktest.MockMessagesService) {
+		client, _, mockMessagesService := createTestClient()
+
+		firstPage := models.NewMessageCollectionResponse()
+		firstPage.SetValue([]models.Messageable{createTestMessage(), createTestMessage()})
+		secondPage := models.NewMessageCollectionResponse()
+		secondPage.SetValue([]models.Messageable{createTestMessage()})
+
+		isPage := func(skip int32) interface{} {
+			return mock.MatchedBy(func(config *users.ItemMessagesRequestBuilderGetRequestConfiguration) bool {
+				actual := int32(0)
+				if config.QueryParameters.Skip != nil {
+					actual = *config.QueryParameters.Skip
+				}
+				return actual == skip
+			})
+		}
+		mockMessagesService.On("List", ctx, isPage(0)).Return(firstPage, nil)
+		mockMessagesService.On("List", ctx, isPage(2)).Return(secondPage, nil)
+		return client, mockMessagesService
+	}
+
+	t.Run("visits every message across pages", func(t *testing.T) {
+		client, mockMessagesService := setup()
+		calls := 0
+
+		err := client.EachMessage(ctx, &core.ListOptions{MaxResults: 2}, func(email *core.Email) error {
+			calls++
+			return nil
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, 3, calls)
+		mockMessagesService.AssertNumberOfCalls(t, "List", 2)
+	})
+
+	t.Run("fn error stops iteration", func(t *testing.T) {
+		client, mockMessagesService := setup()
+		stop := errors.New("stop")
+		calls := 0
+
+		err := client.EachMessage(ctx, &core.ListOptions{MaxResults: 2}, func(email *core.Email) error {
+			calls++
+			return stop
+		})
+
+		assert.ErrorIs(t, err, stop)
+		assert.Equal(t, 1, calls)
+		mockMessagesService.AssertNumberOfCalls(t, "List", 1)
+	})
+}
+
+func TestClient_MessagesIterator(t *testing.T) {
+	client, _, mockMessagesService := createTestClient()
+	ctx := context.Background()
+
+	newPage := func(prefix string, size int) models.MessageCollectionResponseable {
+		messages := make([]models.Messageable, 0, size)
+		for i := range size {
+			msg := createTestMessage()
+			id := prefix + "-" + strconv.Itoa(i)
+			msg.SetId(&id)
+			messages = append(messages, msg)
+		}
+		page := models.NewMessageCollectionResponse()
+		page.SetValue(messages)
+		return page
+	}
+	isPage := func(skip int32) interface{} {
+		return mock.MatchedBy(func(config *users.ItemMessagesRequestBuilderGetRequestConfiguration) bool {
+			actual := int32(0)
+			if config.QueryParameters.Skip != nil {
+				actual = *config.QueryParameters.Skip
+			}
+			return actual == skip && *config.QueryParameters.Top == core.DefaultEachPageSize
+		})
+	}
+	mockMessagesService.On("List", mock.Anything, isPage(0)).Return(newPage("first", 100), nil)
+	mockMessagesService.On("List", mock.Anything, isPage(100)).Return(newPage("second", 100), nil)
+
+	it := client.MessagesIterator(ctx, &core.ListOptions{MaxResults: 150})
+	var ids []string
+	for {
+		email, err := it.Next(ctx)
+		if errors.Is(err, core.ErrIteratorDone) {
+			break
+		}
+		require.NoError(t, err)
+		ids = append(ids, email.ID)
+	}
+
+	require.Len(t, ids, 150, "MaxResults caps the total, not the page size")
+	assert.Equal(t, "first-0", ids[0])
+	assert.Equal(t, "second-0", ids[100])
+	assert.Equal(t, "second-49", ids[149])
+	mockMessagesService.AssertNumberOfCalls(t, "List", 2)
+}
+
 func TestClient_ListMessages_WithQuery(t *testing.T) {
 	client, mockGraphService, mockMessagesService := createTestClient()
 	ctx := context.Background()
@@ -188,197 +405,1511 @@ func TestClient_ListMessages_WithQuery(t *testing.T) {
 	mockMessagesService.AssertExpectations(t)
 }
 
-func TestClient_ListMessages_NotConnected(t *testing.T) {
-	client := &Client{}
-	ctx := context.Background()
+func TestClient_ListMessages_QueryWithFilterRejected(t *testing.T) {
+	yes := true
+	tests := []struct {
+		name     string
+		opts     *core.ListOptions
+		conflict string
+	}{
+		{"has attachments", &core.ListOptions{Query: "invoice", HasAttachments: &yes}, "HasAttachments"},
+		{"exclude from self", &core.ListOptions{Query: "invoice", ExcludeFromSelf: true}, "ExcludeFromSelf"},
+		{"focused", &core.ListOptions{Query: "invoice", Focused: &yes}, "Focused"},
+		{"calendar invite", &core.ListOptions{Query: "invoice", HasCalendarInvite: &yes}, "HasCalendarInvite"},
+		{"raw filter", &core.ListOptions{Query: "invoice", Filter: "receivedDateTime ge 2024-03-01T00:00:00Z"}, "Filter"},
+	}
 
-	result, err := client.ListMessages(ctx, nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, _, mockMessagesService := createTestClient()
 
-	assert.Error(t, err)
-	assert.Nil(t, result)
-	assert.Contains(t, err.Error(), "client not connected")
+			result, err := client.ListMessages(context.Background(), tt.opts)
+
+			assert.Nil(t, result)
+			var configErr *core.ConfigError
+			require.ErrorAs(t, err, &configErr)
+			assert.Equal(t, "Query", configErr.Field)
+			assert.Contains(t, configErr.Message, "Query cannot be combined with "+tt.conflict)
+			assert.Contains(t, configErr.Message, "$search together with $filter")
+			mockMessagesService.AssertNotCalled(t, "List", mock.Anything, mock.Anything)
+		})
+	}
 }
 
-func TestClient_GetMessage(t *testing.T) {
+func TestClient_ListMessages_HasAttachmentsFilter(t *testing.T) {
 	client, mockGraphService, mockMessagesService := createTestClient()
 	ctx := context.Background()
 
-	mockMessage := createTestMessage()
-	mockMessagesService.On("Get", ctx, "msg-123").Return(mockMessage, nil)
+	mockResponse := models.NewMessageCollectionResponse()
+	mockResponse.SetValue([]models.Messageable{createTestMessage()})
 
-	// Test
-	result, err := client.GetMessage(ctx, "msg-123")
+	var capturedConfig *users.ItemMessagesRequestBuilderGetRequestConfiguration
+	mockMessagesService.On("List", ctx, mock.AnythingOfType("*users.ItemMessagesRequestBuilderGetRequestConfiguration")).
+		Run(func(args mock.Arguments) {
+			capturedConfig = args.Get(1).(*users.ItemMessagesRequestBuilderGetRequestConfiguration)
+		}).
+		Return(mockResponse, nil)
+
+	hasAttachments := true
+	_, err := client.ListMessages(ctx, &core.ListOptions{
+		HasAttachments: &hasAttachments,
+	})
 
-	// Assert
 	assert.NoError(t, err)
-	assert.NotNil(t, result)
-	assert.Equal(t, "msg-123", result.ID)
-	assert.Equal(t, "Test Subject", result.Subject)
-	assert.Equal(t, "<p>Test body content</p>", result.Body.HTML)
+	assert.NotNil(t, capturedConfig.QueryParameters.Filter)
+	assert.Equal(t, "hasAttachments eq true", *capturedConfig.QueryParameters.Filter)
 
 	mockGraphService.AssertExpectations(t)
 	mockMessagesService.AssertExpectations(t)
 }
 
-func TestClient_GetMessage_NotConnected(t *testing.T) {
-	client := &Client{}
+func TestClient_ListMessages_HasUnsubscribe(t *testing.T) {
+	client, _, mockMessagesService := createTestClient()
 	ctx := context.Background()
 
-	result, err := client.GetMessage(ctx, "msg-123")
+	withHeader := func(id, name, value string) models.Messageable {
+		msg := createTestMessage()
+		msg.SetId(&id)
+		header := models.NewInternetMessageHeader()
+		header.SetName(&name)
+		header.SetValue(&value)
+		msg.SetInternetMessageHeaders([]models.InternetMessageHeaderable{header})
+		return msg
+	}
+	mockResponse := models.NewMessageCollectionResponse()
+	mockResponse.SetValue([]models.Messageable{
+		withHeader("msg-newsletter", "List-Unsubscribe", "<https://news.example.com/unsub>"),
+		withHeader("msg-personal", "Subject", "Lunch?"),
+		withHeader("msg-promo", "list-unsubscribe", "<mailto:unsub@promo.example.com>"),
+	})
 
-	assert.Error(t, err)
-	assert.Nil(t, result)
-	assert.Contains(t, err.Error(), "client not connected")
+	var capturedConfig *users.ItemMessagesRequestBuilderGetRequestConfiguration
+	mockMessagesService.On("List", ctx, mock.AnythingOfType("*users.ItemMessagesRequestBuilderGetRequestConfiguration")).
+		Run(func(args mock.Arguments) {
+			capturedConfig = args.Get(1).(*users.ItemMessagesRequestBuilderGetRequestConfiguration)
+		}).
+		Return(mockResponse, nil)
+
+	hasUnsubscribe := true
+	result, err := client.ListMessages(ctx, &core.ListOptions{HasUnsubscribe: &hasUnsubscribe})
+
+	require.NoError(t, err)
+	require.Len(t, result.Emails, 2)
+	assert.Equal(t, "msg-newsletter", result.Emails[0].ID)
+	assert.Equal(t, "msg-promo", result.Emails[1].ID)
+	assert.Contains(t, capturedConfig.QueryParameters.Select, "internetMessageHeaders")
+	assert.Nil(t, capturedConfig.QueryParameters.Filter, "the filter is applied client-side")
+	assert.NotContains(t, messageSelectFields, "internetMessageHeaders")
 }
 
-func TestClient_GetAttachment(t *testing.T) {
+func TestClient_ListMessages_NoFilterWhenHasAttachmentsNil(t *testing.T) {
 	client, mockGraphService, mockMessagesService := createTestClient()
 	ctx := context.Background()
 
-	// Create mock attachment
-	mockAttachment := models.NewFileAttachment()
-	attID := "att-123"
-	attName := "test.pdf"
-	attContentType := "application/pdf"
-	attSize := int32(1024)
-	attData := []byte("test data")
-
-	mockAttachment.SetId(&attID)
-	mockAttachment.SetName(&attName)
-	mockAttachment.SetContentType(&attContentType)
-	mockAttachment.SetSize(&attSize)
-	mockAttachment.SetContentBytes(attData)
+	mockResponse := models.NewMessageCollectionResponse()
+	mockResponse.SetValue([]models.Messageable{})
 
-	mockMessagesService.On("GetAttachment", ctx, "msg-123", "att-123").Return(mockAttachment, nil)
+	var capturedConfig *users.ItemMessagesRequestBuilderGetRequestConfiguration
+	mockMessagesService.On("List", ctx, mock.AnythingOfType("*users.ItemMessagesRequestBuilderGetRequestConfiguration")).
+		Run(func(args mock.Arguments) {
+			capturedConfig = args.Get(1).(*users.ItemMessagesRequestBuilderGetRequestConfiguration)
+		}).
+		Return(mockResponse, nil)
 
-	// Test
-	result, err := client.GetAttachment(ctx, "msg-123", "att-123")
+	_, err := client.ListMessages(ctx, &core.ListOptions{MaxResults: 10})
 
-	// Assert
 	assert.NoError(t, err)
-	assert.NotNil(t, result)
-	assert.Equal(t, "att-123", result.ID)
-	assert.Equal(t, "test.pdf", result.Filename)
-	assert.Equal(t, "application/pdf", result.MimeType)
-	assert.Equal(t, int64(1024), result.Size)
-	assert.Equal(t, attData, result.Data)
+	assert.Nil(t, capturedConfig.QueryParameters.Filter)
 
 	mockGraphService.AssertExpectations(t)
 	mockMessagesService.AssertExpectations(t)
 }
 
-func TestClient_GetAttachment_NotConnected(t *testing.T) {
+func TestClient_ListMessages_ExcludeFromSelf(t *testing.T) {
 	client := &Client{}
 	ctx := context.Background()
 
-	result, err := client.GetAttachment(ctx, "msg-123", "att-123")
+	mockGraphService := &outlooktest.MockGraphService{}
+	mockMeService := &outlooktest.MockMeService{}
+	mockMessagesService := &outlooktest.MockMessagesService{}
+	mockGraphService.On("GetMeService").Return(mockMeService)
+	mockMeService.On("GetMessagesService").Return(mockMessagesService)
+	client.SetService(mockGraphService)
 
-	assert.Error(t, err)
-	assert.Nil(t, result)
-	assert.Contains(t, err.Error(), "client not connected")
+	user := models.NewUser()
+	mail := "me@example.com"
+	user.SetMail(&mail)
+	mockMeService.On("Get", ctx).Return(user, nil).Once()
+
+	mockResponse := models.NewMessageCollectionResponse()
+	mockResponse.SetValue([]models.Messageable{})
+
+	var capturedConfig *users.ItemMessagesRequestBuilderGetRequestConfiguration
+	mockMessagesService.On("List", ctx, mock.AnythingOfType("*users.ItemMessagesRequestBuilderGetRequestConfiguration")).
+		Run(func(args mock.Arguments) {
+			capturedConfig = args.Get(1).(*users.ItemMessagesRequestBuilderGetRequestConfiguration)
+		}).
+		Return(mockResponse, nil)
+
+	opts := &core.ListOptions{ExcludeFromSelf: true}
+
+	_, err := client.ListMessages(ctx, opts)
+	assert.NoError(t, err)
+	assert.NotNil(t, capturedConfig.QueryParameters.Filter)
+	assert.Equal(t, "from/emailAddress/address ne 'me@example.com'", *capturedConfig.QueryParameters.Filter)
+
+	// Second call uses the cached address
+	_, err = client.ListMessages(ctx, opts)
+	assert.NoError(t, err)
+
+	mockMeService.AssertNumberOfCalls(t, "Get", 1)
+	mockMessagesService.AssertNumberOfCalls(t, "List", 2)
 }
 
-func TestClient_MarkAsRead(t *testing.T) {
-	client, mockGraphService, mockMessagesService := createTestClient()
+func TestClient_ListMessages_ExcludeFromSelf_ProfileError(t *testing.T) {
+	client := &Client{}
 	ctx := context.Background()
 
-	mockMessagesService.On("MarkAsRead", ctx, "msg-123").Return(nil)
+	mockGraphService := &outlooktest.MockGraphService{}
+	mockMeService := &outlooktest.MockMeService{}
+	mockGraphService.On("GetMeService").Return(mockMeService)
+	mockMeService.On("Get", ctx).Return(nil, errors.New("forbidden"))
+	client.SetService(mockGraphService)
 
-	// Test
-	err := client.MarkAsRead(ctx, "msg-123")
+	result, err := client.ListMessages(ctx, &core.ListOptions{ExcludeFromSelf: true})
 
-	// Assert
-	assert.NoError(t, err)
-	mockGraphService.AssertExpectations(t)
-	mockMessagesService.AssertExpectations(t)
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	assert.Contains(t, err.Error(), "failed to get user profile")
 }
 
-func TestClient_MarkAsRead_NotConnected(t *testing.T) {
+func TestClient_ListMessages_NotConnected(t *testing.T) {
 	client := &Client{}
 	ctx := context.Background()
 
-	err := client.MarkAsRead(ctx, "msg-123")
+	result, err := client.ListMessages(ctx, nil)
 
 	assert.Error(t, err)
+	assert.Nil(t, result)
 	assert.Contains(t, err.Error(), "client not connected")
 }
 
-func TestClient_MarkAsUnread(t *testing.T) {
+func TestClient_GetMessage(t *testing.T) {
 	client, mockGraphService, mockMessagesService := createTestClient()
 	ctx := context.Background()
 
-	mockMessagesService.On("MarkAsUnread", ctx, "msg-123").Return(nil)
+	mockMessage := createTestMessage()
+	mockMessagesService.On("Get", ctx, "msg-123").Return(mockMessage, nil)
 
 	// Test
-	err := client.MarkAsUnread(ctx, "msg-123")
+	result, err := client.GetMessage(ctx, "msg-123")
 
 	// Assert
 	assert.NoError(t, err)
+	assert.NotNil(t, result)
+	assert.Equal(t, "msg-123", result.ID)
+	assert.Equal(t, "Test Subject", result.Subject)
+	assert.Equal(t, "<p>Test body content</p>", result.Body.HTML)
+
 	mockGraphService.AssertExpectations(t)
 	mockMessagesService.AssertExpectations(t)
 }
 
-func TestClient_MarkAsUnread_NotConnected(t *testing.T) {
-	client := &Client{}
-	ctx := context.Background()
+func TestClient_GetMessage_ETagCache(t *testing.T) {
+	newMessage := func(etag, subject string) models.Messageable {
+		msg := createTestMessage()
+		msg.SetSubject(&subject)
+		msg.SetAdditionalData(map[string]interface{}{"@odata.etag": &etag})
+		return msg
+	}
 
-	err := client.MarkAsUnread(ctx, "msg-123")
+	t.Run("not modified returns cached copy", func(t *testing.T) {
+		client, _, mockMessagesService := createTestClient()
+		client.config.MessageCacheSize = 10
+		ctx := context.Background()
 
-	assert.Error(t, err)
-	assert.Contains(t, err.Error(), "client not connected")
-}
+		mockMessagesService.On("Get", ctx, "msg-123").Return(newMessage(`W/"v1"`, "Original"), nil).Once()
+		mockMessagesService.On("GetIfNoneMatch", ctx, "msg-123", `W/"v1"`).Return(nil, true, nil)
 
-func TestClient_MoveMessage(t *testing.T) {
-	client, mockGraphService, mockMessagesService := createTestClient()
-	ctx := context.Background()
+		first, err := client.GetMessage(ctx, "msg-123")
+		require.NoError(t, err)
+		first.IsRead = true // changes to a returned email do not leak into the cache
 
-	mockMessagesService.On("Move", ctx, "msg-123", "folder-archive").Return(nil)
+		second, err := client.GetMessage(ctx, "msg-123")
 
-	// Test
-	err := client.MoveMessage(ctx, "msg-123", "folder-archive")
+		require.NoError(t, err)
+		assert.Equal(t, "Original", second.Subject)
+		assert.False(t, second.IsRead)
+		mockMessagesService.AssertNumberOfCalls(t, "Get", 1)
+		mockMessagesService.AssertNumberOfCalls(t, "GetIfNoneMatch", 1)
+	})
 
-	// Assert
-	assert.NoError(t, err)
-	mockGraphService.AssertExpectations(t)
-	mockMessagesService.AssertExpectations(t)
+	t.Run("mutating a returned email leaves the cache intact", func(t *testing.T) {
+		client, _, mockMessagesService := createTestClient()
+		client.config.MessageCacheSize = 10
+		ctx := context.Background()
+
+		msg := newMessage(`W/"v1"`, "Original")
+		name, value := "X-Custom", "original"
+		header := models.NewInternetMessageHeader()
+		header.SetName(&name)
+		header.SetValue(&value)
+		msg.SetInternetMessageHeaders([]models.InternetMessageHeaderable{header})
+		mockMessagesService.On("Get", ctx, "msg-123").Return(msg, nil).Once()
+		mockMessagesService.On("GetIfNoneMatch", ctx, "msg-123", `W/"v1"`).Return(nil, true, nil)
+
+		first, err := client.GetMessage(ctx, "msg-123")
+		require.NoError(t, err)
+		require.NotEmpty(t, first.To)
+		require.NotEmpty(t, first.Labels)
+		first.To[0].Email = "changed@example.com"
+		first.Labels[0] = "changed"
+		first.RawHeaders["X-Custom"][0] = "changed"
+		first.RawHeaders["X-Other"] = []string{"added"}
+
+		second, err := client.GetMessage(ctx, "msg-123")
+
+		require.NoError(t, err)
+		assert.NotEqual(t, "changed@example.com", second.To[0].Email)
+		assert.NotEqual(t, "changed", second.Labels[0])
+		assert.Equal(t, []string{"original"}, second.RawHeaders["X-Custom"])
+		assert.NotContains(t, second.RawHeaders, "X-Other")
+	})
+
+	t.Run("modified message replaces cached copy", func(t *testing.T) {
+		client, _, mockMessagesService := createTestClient()
+		client.config.MessageCacheSize = 10
+		ctx := context.Background()
+
+		mockMessagesService.On("Get", ctx, "msg-123").Return(newMessage(`W/"v1"`, "Original"), nil).Once()
+		mockMessagesService.On("GetIfNoneMatch", ctx, "msg-123", `W/"v1"`).Return(newMessage(`W/"v2"`, "Edited"), false, nil).Once()
+		mockMessagesService.On("GetIfNoneMatch", ctx, "msg-123", `W/"v2"`).Return(nil, true, nil).Once()
+
+		_, err := client.GetMessage(ctx, "msg-123")
+		require.NoError(t, err)
+		edited, err := client.GetMessage(ctx, "msg-123")
+		require.NoError(t, err)
+		cached, err := client.GetMessage(ctx, "msg-123")
+		require.NoError(t, err)
+
+		assert.Equal(t, "Edited", edited.Subject)
+		assert.Equal(t, "Edited", cached.Subject)
+		mockMessagesService.AssertExpectations(t)
+	})
+
+	t.Run("oldest entry is evicted", func(t *testing.T) {
+		client, _, mockMessagesService := createTestClient()
+		client.config.MessageCacheSize = 1
+		ctx := context.Background()
+
+		mockMessagesService.On("Get", ctx, "msg-1").Return(newMessage(`W/"a"`, "One"), nil)
+		mockMessagesService.On("Get", ctx, "msg-2").Return(newMessage(`W/"b"`, "Two"), nil)
+
+		for _, id := range []string{"msg-1", "msg-2", "msg-1"} {
+			_, err := client.GetMessage(ctx, id)
+			require.NoError(t, err)
+		}
+
+		mockMessagesService.AssertNumberOfCalls(t, "Get", 3)
+		mockMessagesService.AssertNotCalled(t, "GetIfNoneMatch", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		client, _, mockMessagesService := createTestClient()
+		ctx := context.Background()
+
+		mockMessagesService.On("Get", ctx, "msg-123").Return(newMessage(`W/"v1"`, "Original"), nil)
+
+		for i := 0; i < 2; i++ {
+			_, err := client.GetMessage(ctx, "msg-123")
+			require.NoError(t, err)
+		}
+
+		mockMessagesService.AssertNumberOfCalls(t, "Get", 2)
+	})
 }
 
-func TestClient_MoveMessage_NotConnected(t *testing.T) {
+func TestClient_GetMessage_NotConnected(t *testing.T) {
 	client := &Client{}
 	ctx := context.Background()
 
-	err := client.MoveMessage(ctx, "msg-123", "folder-archive")
+	result, err := client.GetMessage(ctx, "msg-123")
 
 	assert.Error(t, err)
+	assert.Nil(t, result)
 	assert.Contains(t, err.Error(), "client not connected")
 }
 
-func TestClient_DeleteMessage(t *testing.T) {
+func TestClient_GetMessageWithOptions_MarkReadOnFetch(t *testing.T) {
 	client, mockGraphService, mockMessagesService := createTestClient()
 	ctx := context.Background()
 
-	mockMessagesService.On("Delete", ctx, "msg-123").Return(nil)
+	mockMessagesService.On("Get", ctx, "msg-123").Return(createTestMessage(), nil)
+	mockMessagesService.On("MarkAsRead", ctx, "msg-123").Return(nil)
 
-	// Test
-	err := client.DeleteMessage(ctx, "msg-123")
+	email, err := client.GetMessageWithOptions(ctx, "msg-123", &core.GetOptions{MarkReadOnFetch: true})
 
-	// Assert
 	assert.NoError(t, err)
+	assert.True(t, email.IsRead)
+
 	mockGraphService.AssertExpectations(t)
 	mockMessagesService.AssertExpectations(t)
 }
 
-func TestClient_DeleteMessage_NotConnected(t *testing.T) {
-	client := &Client{}
+func TestClient_GetMessageWithOptions_MarkFailureIgnored(t *testing.T) {
+	client, _, mockMessagesService := createTestClient()
 	ctx := context.Background()
 
-	err := client.DeleteMessage(ctx, "msg-123")
+	mockMessagesService.On("Get", ctx, "msg-123").Return(createTestMessage(), nil)
+	mockMessagesService.On("MarkAsRead", ctx, "msg-123").Return(errors.New("patch failed"))
 
-	assert.Error(t, err)
-	assert.Contains(t, err.Error(), "client not connected")
-}
+	email, err := client.GetMessageWithOptions(ctx, "msg-123", &core.GetOptions{MarkReadOnFetch: true})
 
-func TestClient_ConvertMessage_CompleteConversion(t *testing.T) {
+	assert.NoError(t, err)
+	assert.False(t, email.IsRead)
+	mockMessagesService.AssertExpectations(t)
+}
+
+func TestClient_GetMessageWithOptions_OptionNotSet(t *testing.T) {
+	client, _, mockMessagesService := createTestClient()
+	ctx := context.Background()
+
+	mockMessagesService.On("Get", ctx, "msg-123").Return(createTestMessage(), nil)
+
+	email, err := client.GetMessageWithOptions(ctx, "msg-123", nil)
+
+	assert.NoError(t, err)
+	assert.False(t, email.IsRead)
+	mockMessagesService.AssertNotCalled(t, "MarkAsRead", mock.Anything, mock.Anything)
+}
+
+func TestClient_GetMessageWithOptions_BlockRemoteContent(t *testing.T) {
+	client, _, mockMessagesService := createTestClient()
+	ctx := context.Background()
+
+	message := createTestMessage()
+	body := models.NewItemBody()
+	contentType := models.HTML_BODYTYPE
+	body.SetContentType(&contentType)
+	content := `<div style="background-image: url(https://cdn.example.com/bg.png)"><img src="https://cdn.example.com/logo.png"></div>`
+	body.SetContent(&content)
+	message.SetBody(body)
+	mockMessagesService.On("Get", ctx, "msg-123").Return(message, nil)
+
+	email, err := client.GetMessageWithOptions(ctx, "msg-123", &core.GetOptions{BlockRemoteContent: true})
+
+	require.NoError(t, err)
+	assert.NotContains(t, email.Body.HTML, "cdn.example.com")
+	assert.Contains(t, email.Body.HTML, core.RemoteContentPlaceholder)
+	assert.Equal(t, []string{"https://cdn.example.com/logo.png", "https://cdn.example.com/bg.png"}, email.BlockedResources)
+}
+
+func TestClient_GetMessageWithOptions_DownloadAttachments(t *testing.T) {
+	client, _, mockMessagesService := createTestClient()
+	ctx := context.Background()
+
+	newAttachment := func(id, name string, size int32) *models.FileAttachment {
+		att := models.NewFileAttachment()
+		att.SetId(&id)
+		att.SetName(&name)
+		att.SetSize(&size)
+		return att
+	}
+	invite := newAttachment("att-1", "invite.ics", 5)
+	report := newAttachment("att-2", "report.pdf", 4)
+	downloaded := newAttachment("att-1", "invite.ics", 5)
+	downloaded.SetContentBytes([]byte("BEGIN"))
+
+	mockMessagesService.On("Get", ctx, "msg-123").Return(createTestMessage(), nil)
+	mockMessagesService.On("ListAttachmentMetadata", ctx, "msg-123").
+		Return([]models.Attachmentable{invite, report}, nil)
+	mockMessagesService.On("GetAttachmentMetadata", ctx, "msg-123", "att-1").Return(invite, nil)
+	mockMessagesService.On("GetAttachment", ctx, "msg-123", "att-1").Return(downloaded, nil)
+
+	email, err := client.GetMessageWithOptions(ctx, "msg-123", &core.GetOptions{DownloadAttachments: []string{"att-1"}})
+
+	require.NoError(t, err)
+	require.Len(t, email.Attachments, 2)
+	assert.Equal(t, []byte("BEGIN"), email.Attachments[0].Data)
+	assert.Equal(t, "report.pdf", email.Attachments[1].Filename)
+	assert.Nil(t, email.Attachments[1].Data)
+	mockMessagesService.AssertNotCalled(t, "GetAttachment", ctx, "msg-123", "att-2")
+}
+
+func TestClient_GetMessageWithOptions_DownloadAttachmentsError(t *testing.T) {
+	client, _, mockMessagesService := createTestClient()
+	ctx := context.Background()
+
+	mockMessagesService.On("Get", ctx, "msg-123").Return(createTestMessage(), nil)
+	mockMessagesService.On("ListAttachmentMetadata", ctx, "msg-123").Return(nil, errors.New("boom"))
+
+	email, err := client.GetMessageWithOptions(ctx, "msg-123", &core.GetOptions{DownloadAttachments: []string{"a.pdf"}})
+
+	assert.Nil(t, email)
+	assert.ErrorContains(t, err, "failed to list attachments for message msg-123")
+}
+
+func TestClient_GetMessageWithOptions_ExtendedProperties(t *testing.T) {
+	client, _, mockMessagesService := createTestClient()
+	ctx := context.Background()
+
+	message := createTestMessage().(*models.Message)
+	prop := models.NewSingleValueLegacyExtendedProperty()
+	propID := "Integer 0xe07"
+	propValue := "1"
+	prop.SetId(&propID)
+	prop.SetValue(&propValue)
+	message.SetSingleValueExtendedProperties([]models.SingleValueLegacyExtendedPropertyable{prop})
+
+	var config *users.ItemMessagesMessageItemRequestBuilderGetRequestConfiguration
+	mockMessagesService.On("GetWithConfig", ctx, "msg-123", mock.Anything).
+		Run(func(args mock.Arguments) {
+			config = args.Get(2).(*users.ItemMessagesMessageItemRequestBuilderGetRequestConfiguration)
+		}).
+		Return(message, nil)
+
+	opts := &core.GetOptions{ExtendedProperties: []string{"Integer 0x0E07", "String 0x001A"}}
+	email, err := client.GetMessageWithOptions(ctx, "msg-123", opts)
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"Integer 0x0E07": "1"}, email.ExtendedProperties)
+	assert.Equal(t, []string{"singleValueExtendedProperties($filter=id eq 'Integer 0x0E07' or id eq 'String 0x001A')"},
+		config.QueryParameters.Expand)
+	mockMessagesService.AssertNotCalled(t, "Get", mock.Anything, mock.Anything)
+}
+
+func TestClient_GetMessageWithOptions_IncludeHeaders(t *testing.T) {
+	client, _, mockMessagesService := createTestClient()
+	ctx := context.Background()
+
+	message := createTestMessage().(*models.Message)
+	newHeader := func(name, value string) models.InternetMessageHeaderable {
+		header := models.NewInternetMessageHeader()
+		header.SetName(&name)
+		header.SetValue(&value)
+		return header
+	}
+	message.SetInternetMessageHeaders([]models.InternetMessageHeaderable{
+		newHeader("Received", "from mx2.example.com"),
+		newHeader("Received", "from mx1.example.com"),
+		newHeader("x-mailer", "Outlook"),
+	})
+
+	var config *users.ItemMessagesMessageItemRequestBuilderGetRequestConfiguration
+	mockMessagesService.On("GetWithConfig", ctx, "msg-123", mock.Anything).
+		Run(func(args mock.Arguments) {
+			config = args.Get(2).(*users.ItemMessagesMessageItemRequestBuilderGetRequestConfiguration)
+		}).
+		Return(message, nil)
+
+	email, err := client.GetMessageWithOptions(ctx, "msg-123", &core.GetOptions{IncludeHeaders: true})
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{
+		"Received": {"from mx2.example.com", "from mx1.example.com"},
+		"X-Mailer": {"Outlook"},
+	}, email.RawHeaders)
+	assert.Contains(t, config.QueryParameters.Select, "internetMessageHeaders")
+	assert.Contains(t, config.QueryParameters.Select, "body")
+	assert.NotContains(t, messageSelectFields, "internetMessageHeaders", "list selection must not change")
+	mockMessagesService.AssertNumberOfCalls(t, "GetWithConfig", 1)
+}
+
+func TestClient_GetMessageWithOptions_HeadersNotRequested(t *testing.T) {
+	client, _, mockMessagesService := createTestClient()
+	ctx := context.Background()
+
+	// Graph only returns internetMessageHeaders when they are selected
+	mockMessagesService.On("Get", ctx, "msg-123").Return(createTestMessage(), nil)
+
+	email, err := client.GetMessageWithOptions(ctx, "msg-123", &core.GetOptions{})
+
+	require.NoError(t, err)
+	assert.Nil(t, email.RawHeaders)
+	mockMessagesService.AssertNotCalled(t, "GetWithConfig", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestClient_GetMessageWithOptions_NoMarkOnFetchError(t *testing.T) {
+	client, _, mockMessagesService := createTestClient()
+	ctx := context.Background()
+
+	mockMessagesService.On("Get", ctx, "msg-123").Return(nil, errors.New("not found"))
+
+	email, err := client.GetMessageWithOptions(ctx, "msg-123", &core.GetOptions{MarkReadOnFetch: true})
+
+	assert.Error(t, err)
+	assert.Nil(t, email)
+	mockMessagesService.AssertNotCalled(t, "MarkAsRead", mock.Anything, mock.Anything)
+}
+
+func TestClient_GetThreadMessages(t *testing.T) {
+	client, mockGraphService, mockMessagesService := createTestClient()
+	ctx := context.Background()
+
+	newMessage := func(id string, sent time.Time) models.Messageable {
+		msg := models.NewMessage()
+		conversationID := "conv-1"
+		msg.SetId(&id)
+		msg.SetConversationId(&conversationID)
+		msg.SetSentDateTime(&sent)
+		return msg
+	}
+
+	base := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	mockResponse := models.NewMessageCollectionResponse()
+	mockResponse.SetValue([]models.Messageable{
+		newMessage("msg-3", base.Add(2*time.Hour)),
+		newMessage("msg-1", base),
+		newMessage("msg-2", base.Add(time.Hour)),
+	})
+
+	var capturedConfig *users.ItemMessagesRequestBuilderGetRequestConfiguration
+	mockMessagesService.On("List", ctx, mock.AnythingOfType("*users.ItemMessagesRequestBuilderGetRequestConfiguration")).
+		Run(func(args mock.Arguments) {
+			capturedConfig = args.Get(1).(*users.ItemMessagesRequestBuilderGetRequestConfiguration)
+		}).
+		Return(mockResponse, nil)
+
+	emails, err := client.GetThreadMessages(ctx, "conv-1")
+
+	assert.NoError(t, err)
+	assert.Len(t, emails, 3)
+	assert.Equal(t, "msg-1", emails[0].ID)
+	assert.Equal(t, "msg-2", emails[1].ID)
+	assert.Equal(t, "msg-3", emails[2].ID)
+	assert.Equal(t, "conv-1", emails[0].ThreadID)
+	assert.Equal(t, "conversationId eq 'conv-1'", *capturedConfig.QueryParameters.Filter)
+
+	mockGraphService.AssertExpectations(t)
+	mockMessagesService.AssertExpectations(t)
+}
+
+func TestClient_GetThreadMessages_FollowsNextLink(t *testing.T) {
+	client, _, mockMessagesService := createTestClient()
+	ctx := context.Background()
+
+	newMessage := func(id string, sent time.Time) models.Messageable {
+		msg := models.NewMessage()
+		msg.SetId(&id)
+		msg.SetSentDateTime(&sent)
+		return msg
+	}
+
+	base := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	nextLink := "https://graph.microsoft.com/v1.0/me/messages?$skiptoken=page2"
+	firstPage := models.NewMessageCollectionResponse()
+	firstPage.SetValue([]models.Messageable{newMessage("msg-2", base.Add(time.Hour))})
+	firstPage.SetOdataNextLink(&nextLink)
+	secondPage := models.NewMessageCollectionResponse()
+	secondPage.SetValue([]models.Messageable{newMessage("msg-1", base)})
+
+	mockMessagesService.On("List", ctx, mock.Anything).Return(firstPage, nil)
+	mockMessagesService.On("ListNextPage", ctx, nextLink).Return(secondPage, nil)
+
+	emails, err := client.GetThreadMessages(ctx, "conv-1")
+
+	require.NoError(t, err)
+	require.Len(t, emails, 2)
+	assert.Equal(t, "msg-1", emails[0].ID)
+	assert.Equal(t, "msg-2", emails[1].ID)
+	mockMessagesService.AssertExpectations(t)
+}
+
+func TestClient_GetThreadMessages_NextPageError(t *testing.T) {
+	client, _, mockMessagesService := createTestClient()
+	ctx := context.Background()
+
+	nextLink := "https://graph.microsoft.com/v1.0/me/messages?$skiptoken=page2"
+	firstPage := models.NewMessageCollectionResponse()
+	firstPage.SetOdataNextLink(&nextLink)
+
+	mockMessagesService.On("List", ctx, mock.Anything).Return(firstPage, nil)
+	mockMessagesService.On("ListNextPage", ctx, nextLink).Return(nil, errors.New("boom"))
+
+	emails, err := client.GetThreadMessages(ctx, "conv-1")
+
+	assert.Nil(t, emails)
+	assert.ErrorContains(t, err, "failed to get conversation conv-1")
+}
+
+func TestClient_GetThreadMessages_Error(t *testing.T) {
+	client, _, mockMessagesService := createTestClient()
+	ctx := context.Background()
+
+	mockMessagesService.On("List", ctx, mock.Anything).Return(nil, errors.New("boom"))
+
+	emails, err := client.GetThreadMessages(ctx, "conv-1")
+
+	assert.Error(t, err)
+	assert.Nil(t, emails)
+	assert.Contains(t, err.Error(), "failed to get conversation")
+}
+
+func TestClient_GetThreadMessages_NotConnected(t *testing.T) {
+	client := &Client{}
+
+	emails, err := client.GetThreadMessages(context.Background(), "conv-1")
+
+	assert.Error(t, err)
+	assert.Nil(t, emails)
+	assert.Contains(t, err.Error(), "client not connected")
+}
+
+func TestClient_GetConversation(t *testing.T) {
+	client, _, mockMessagesService := createTestClient()
+	ctx := context.Background()
+
+	newMessage := func(id, folderID string, sent, received time.Time) models.Messageable {
+		msg := models.NewMessage()
+		conversationID := "AAQk'conv=="
+		msg.SetId(&id)
+		msg.SetConversationId(&conversationID)
+		msg.SetParentFolderId(&folderID)
+		msg.SetSentDateTime(&sent)
+		msg.SetReceivedDateTime(&received)
+		return msg
+	}
+
+	base := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	mockResponse := models.NewMessageCollectionResponse()
+	mockResponse.SetValue([]models.Messageable{
+		// Sent first but delivered last, so it must sort last
+		newMessage("msg-delayed", "inbox-id", base, base.Add(3*time.Hour)),
+		newMessage("msg-reply", "sentitems-id", base.Add(2*time.Hour), base.Add(2*time.Hour)),
+		newMessage("msg-original", "inbox-id", base.Add(time.Hour), base.Add(time.Hour)),
+	})
+
+	var capturedConfig *users.ItemMessagesRequestBuilderGetRequestConfiguration
+	mockMessagesService.On("List", ctx, mock.Anything).
+		Run(func(args mock.Arguments) {
+			capturedConfig = args.Get(1).(*users.ItemMessagesRequestBuilderGetRequestConfiguration)
+		}).
+		Return(mockResponse, nil)
+
+	emails, err := client.GetConversation(ctx, "AAQk'conv==")
+
+	require.NoError(t, err)
+	require.Len(t, emails, 3)
+	assert.Equal(t, "msg-original", emails[0].ID)
+	assert.Equal(t, "msg-reply", emails[1].ID)
+	assert.Equal(t, "msg-delayed", emails[2].ID)
+	assert.Equal(t, []string{"sentitems-id"}, emails[1].Labels)
+	assert.Equal(t, []string{"inbox-id"}, emails[0].Labels)
+	for _, email := range emails {
+		assert.Equal(t, "AAQk'conv==", email.ThreadID)
+	}
+	assert.Equal(t, "conversationId eq 'AAQk''conv=='", *capturedConfig.QueryParameters.Filter)
+	assert.Contains(t, capturedConfig.QueryParameters.Select, "conversationId")
+	assert.Nil(t, capturedConfig.QueryParameters.Orderby)
+}
+
+func TestClient_GetConversation_EmptyID(t *testing.T) {
+	client, _, mockMessagesService := createTestClient()
+
+	emails, err := client.GetConversation(context.Background(), "")
+
+	assert.EqualError(t, err, "conversation ID cannot be empty")
+	assert.Nil(t, emails)
+	mockMessagesService.AssertNotCalled(t, "List", mock.Anything, mock.Anything)
+}
+
+func TestClient_ConvertMessage_ConversationID(t *testing.T) {
+	client := &Client{}
+	msg := models.NewMessage()
+	conversationID := "conv-42"
+	msg.SetConversationId(&conversationID)
+
+	assert.Equal(t, "conv-42", client.convertMessage(msg).ThreadID)
+	assert.Empty(t, client.convertMessage(models.NewMessage()).ThreadID)
+}
+
+func TestClient_GetMessageSize(t *testing.T) {
+	client, _, mockMessagesService := createTestClient()
+	ctx := context.Background()
+
+	message := models.NewMessage()
+	prop := models.NewSingleValueLegacyExtendedProperty()
+	propID := "Integer 0x0e08"
+	propValue := "48213"
+	prop.SetId(&propID)
+	prop.SetValue(&propValue)
+	message.SetSingleValueExtendedProperties([]models.SingleValueLegacyExtendedPropertyable{prop})
+
+	var config *users.ItemMessagesMessageItemRequestBuilderGetRequestConfiguration
+	mockMessagesService.On("GetWithConfig", ctx, "msg-123", mock.Anything).
+		Run(func(args mock.Arguments) {
+			config = args.Get(2).(*users.ItemMessagesMessageItemRequestBuilderGetRequestConfiguration)
+		}).
+		Return(message, nil)
+
+	size, err := client.GetMessageSize(ctx, "msg-123")
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(48213), size)
+	assert.Equal(t, []string{"id"}, config.QueryParameters.Select)
+	assert.Equal(t, []string{"singleValueExtendedProperties($filter=id eq 'Integer 0x0E08')"}, config.QueryParameters.Expand)
+	mockMessagesService.AssertNotCalled(t, "Get", mock.Anything, mock.Anything)
+}
+
+func TestClient_GetMessageSize_Missing(t *testing.T) {
+	client, _, mockMessagesService := createTestClient()
+	ctx := context.Background()
+
+	mockMessagesService.On("GetWithConfig", ctx, "msg-123", mock.Anything).Return(models.NewMessage(), nil)
+
+	_, err := client.GetMessageSize(ctx, "msg-123")
+
+	assert.ErrorContains(t, err, "size of message msg-123 not returned")
+}
+
+func TestClient_GetAttachment(t *testing.T) {
+	client, mockGraphService, mockMessagesService := createTestClient()
+	ctx := context.Background()
+
+	// Create mock attachment
+	mockAttachment := models.NewFileAttachment()
+	attID := "att-123"
+	attName := "test.pdf"
+	attContentType := "application/pdf"
+	attSize := int32(1024)
+	attData := []byte("test data")
+
+	mockAttachment.SetId(&attID)
+	mockAttachment.SetName(&attName)
+	mockAttachment.SetContentType(&attContentType)
+	mockAttachment.SetSize(&attSize)
+	mockAttachment.SetContentBytes(attData)
+
+	mockMessagesService.On("GetAttachmentMetadata", ctx, "msg-123", "att-123").Return(mockAttachment, nil)
+	mockMessagesService.On("GetAttachment", ctx, "msg-123", "att-123").Return(mockAttachment, nil)
+
+	// Test
+	result, err := client.GetAttachment(ctx, "msg-123", "att-123")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NotNil(t, result)
+	assert.Equal(t, "att-123", result.ID)
+	assert.Equal(t, "test.pdf", result.Filename)
+	assert.Equal(t, "application/pdf", result.MimeType)
+	assert.Equal(t, int64(1024), result.Size)
+	assert.Equal(t, attData, result.Data)
+
+	mockGraphService.AssertExpectations(t)
+	mockMessagesService.AssertExpectations(t)
+}
+
+func TestClient_GetAttachment_LargeStreams(t *testing.T) {
+	client, _, mockMessagesService := createTestClient()
+	ctx := context.Background()
+
+	metadata := models.NewFileAttachment()
+	attID := "att-big"
+	attName := "video.mp4"
+	attSize := int32(largeAttachmentThreshold + 1)
+	metadata.SetId(&attID)
+	metadata.SetName(&attName)
+	metadata.SetSize(&attSize)
+
+	content := io.NopCloser(strings.NewReader("streamed content"))
+	mockMessagesService.On("GetAttachmentMetadata", ctx, "msg-123", "att-big").Return(metadata, nil)
+	mockMessagesService.On("GetAttachmentContent", ctx, "msg-123", "att-big").Return(content, nil)
+
+	result, err := client.GetAttachment(ctx, "msg-123", "att-big")
+
+	require.NoError(t, err)
+	require.NotNil(t, result.Reader)
+	assert.Nil(t, result.Data)
+	assert.Equal(t, "video.mp4", result.Filename)
+	assert.Equal(t, int64(attSize), result.Size)
+
+	data, err := io.ReadAll(result.Reader)
+	require.NoError(t, err)
+	assert.Equal(t, "streamed content", string(data))
+	assert.NoError(t, result.Reader.Close())
+	mockMessagesService.AssertNotCalled(t, "GetAttachment", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestClient_GetAttachment_SmallReturnsData(t *testing.T) {
+	client, _, mockMessagesService := createTestClient()
+	ctx := context.Background()
+
+	attachment := models.NewFileAttachment()
+	attID := "att-small"
+	attSize := int32(4)
+	attachment.SetId(&attID)
+	attachment.SetSize(&attSize)
+	attachment.SetContentBytes([]byte("tiny"))
+
+	mockMessagesService.On("GetAttachmentMetadata", ctx, "msg-123", "att-small").Return(attachment, nil)
+	mockMessagesService.On("GetAttachment", ctx, "msg-123", "att-small").Return(attachment, nil)
+
+	result, err := client.GetAttachment(ctx, "msg-123", "att-small")
+
+	require.NoError(t, err)
+	assert.Nil(t, result.Reader)
+	assert.Equal(t, []byte("tiny"), result.Data)
+	mockMessagesService.AssertNotCalled(t, "GetAttachmentContent", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestClient_GetAttachment_NotConnected(t *testing.T) {
+	client := &Client{}
+	ctx := context.Background()
+
+	result, err := client.GetAttachment(ctx, "msg-123", "att-123")
+
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	assert.Contains(t, err.Error(), "client not connected")
+}
+
+func TestClient_GetMessageInlineHTML(t *testing.T) {
+	client, _, mockMessagesService := createTestClient()
+	ctx := context.Background()
+
+	message := createTestMessage()
+	body := models.NewItemBody()
+	contentType := models.HTML_BODYTYPE
+	body.SetContentType(&contentType)
+	content := `<p>Hi</p><img src="cid:image001.png@01DA0000">`
+	body.SetContent(&content)
+	message.SetBody(body)
+
+	newAttachment := func(id string, inline bool) *models.FileAttachment {
+		att := models.NewFileAttachment()
+		att.SetId(&id)
+		att.SetIsInline(&inline)
+		return att
+	}
+	logo := newAttachment("att-1", true)
+	report := newAttachment("att-2", false)
+	downloaded := newAttachment("att-1", true)
+	contentID := "<image001.png@01DA0000>"
+	mimeType := "image/png"
+	downloaded.SetContentId(&contentID)
+	downloaded.SetContentType(&mimeType)
+	downloaded.SetContentBytes([]byte("png"))
+
+	mockMessagesService.On("Get", ctx, "msg-123").Return(message, nil)
+	mockMessagesService.On("ListAttachmentMetadata", ctx, "msg-123").
+		Return([]models.Attachmentable{logo, report}, nil)
+	mockMessagesService.On("GetAttachment", ctx, "msg-123", "att-1").Return(downloaded, nil)
+
+	result, err := client.GetMessageInlineHTML(ctx, "msg-123")
+
+	require.NoError(t, err)
+	assert.Equal(t, `<p>Hi</p><img src="data:image/png;base64,cG5n">`, result)
+	mockMessagesService.AssertNotCalled(t, "GetAttachment", ctx, "msg-123", "att-2")
+}
+
+func TestClient_GetMessageInlineHTML_NoCIDReferences(t *testing.T) {
+	client, _, mockMessagesService := createTestClient()
+	ctx := context.Background()
+
+	mockMessagesService.On("Get", ctx, "msg-123").Return(createTestMessage(), nil)
+
+	_, err := client.GetMessageInlineHTML(ctx, "msg-123")
+
+	require.NoError(t, err)
+	mockMessagesService.AssertNotCalled(t, "ListAttachmentMetadata", mock.Anything, mock.Anything)
+}
+
+func TestClient_GetMessageInlineHTML_AttachmentError(t *testing.T) {
+	client, _, mockMessagesService := createTestClient()
+	ctx := context.Background()
+
+	message := createTestMessage()
+	body := models.NewItemBody()
+	content := `<img src="cid:logo">`
+	body.SetContent(&content)
+	message.SetBody(body)
+
+	inline := true
+	id := "att-1"
+	logo := models.NewFileAttachment()
+	logo.SetId(&id)
+	logo.SetIsInline(&inline)
+
+	mockMessagesService.On("Get", ctx, "msg-123").Return(message, nil)
+	mockMessagesService.On("ListAttachmentMetadata", ctx, "msg-123").Return([]models.Attachmentable{logo}, nil)
+	mockMessagesService.On("GetAttachment", ctx, "msg-123", "att-1").Return(nil, errors.New("boom"))
+
+	result, err := client.GetMessageInlineHTML(ctx, "msg-123")
+
+	assert.Empty(t, result)
+	assert.ErrorContains(t, err, "failed to get inline attachment att-1 from message msg-123")
+}
+
+func TestClient_GetAttachmentMetadata(t *testing.T) {
+	client, _, mockMessagesService := createTestClient()
+	ctx := context.Background()
+
+	metadata := models.NewFileAttachment()
+	attID := "att-big"
+	attName := "video.mp4"
+	attType := "video/mp4"
+	attSize := int32(52428800)
+	metadata.SetId(&attID)
+	metadata.SetName(&attName)
+	metadata.SetContentType(&attType)
+	metadata.SetSize(&attSize)
+	mockMessagesService.On("GetAttachmentMetadata", ctx, "msg-123", "att-big").Return(metadata, nil)
+
+	result, err := client.GetAttachmentMetadata(ctx, "msg-123", "att-big")
+
+	require.NoError(t, err)
+	assert.Equal(t, "att-big", result.ID)
+	assert.Equal(t, "video.mp4", result.Filename)
+	assert.Equal(t, "video/mp4", result.MimeType)
+	assert.Equal(t, int64(52428800), result.Size)
+	assert.Empty(t, result.Data)
+	assert.Nil(t, result.Reader)
+	mockMessagesService.AssertNotCalled(t, "GetAttachment", mock.Anything, mock.Anything, mock.Anything)
+	mockMessagesService.AssertNotCalled(t, "GetAttachmentContent", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestClient_GetAttachmentMetadata_Error(t *testing.T) {
+	client, _, mockMessagesService := createTestClient()
+	ctx := context.Background()
+
+	mockMessagesService.On("GetAttachmentMetadata", ctx, "msg-123", "att-123").Return(nil, errors.New("not found"))
+
+	result, err := client.GetAttachmentMetadata(ctx, "msg-123", "att-123")
+
+	assert.Nil(t, result)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to get attachment att-123 metadata from message msg-123")
+}
+
+// slowBody is a response body whose Read blocks until it is closed, like a stalled download.
+type slowBody struct {
+	closed    chan struct{}
+	closeOnce sync.Once
+	closes    int32
+}
+
+func newSlowBody() *slowBody {
+	return &slowBody{closed: make(chan struct{})}
+}
+
+func (b *slowBody) Read(p []byte) (int, error) {
+	<-b.closed
+	return 0, errors.New("read on closed body")
+}
+
+func (b *slowBody) Close() error {
+	atomic.AddInt32(&b.closes, 1)
+	b.closeOnce.Do(func() { close(b.closed) })
+	return nil
+}
+
+// lazyBody is a response body that generates size bytes on demand and records how
+// many were read, so tests can check a stream is not read ahead or buffered.
+type lazyBody struct {
+	size int64
+	read int64
+}
+
+func (b *lazyBody) Read(p []byte) (int, error) {
+	remaining := b.size - b.read
+	if remaining <= 0 {
+		return 0, io.EOF
+	}
+	n := int64(len(p))
+	if n > remaining {
+		n = remaining
+	}
+	for i := range p[:n] {
+		p[i] = 'x'
+	}
+	b.read += n
+	return int(n), nil
+}
+
+func (b *lazyBody) Close() error { return nil }
+
+func newStreamMetadata(id, name, contentType string, size int32) models.FileAttachmentable {
+	metadata := models.NewFileAttachment()
+	metadata.SetId(&id)
+	metadata.SetName(&name)
+	metadata.SetContentType(&contentType)
+	metadata.SetSize(&size)
+	return metadata
+}
+
+func TestClient_GetAttachmentStream(t *testing.T) {
+	client, _, mockMessagesService := createTestClient()
+	ctx := context.Background()
+
+	content := io.NopCloser(strings.NewReader("streamed content"))
+	mockMessagesService.On("GetAttachmentMetadata", ctx, "msg-123", "att-123").
+		Return(newStreamMetadata("att-123", "notes.txt", "text/plain", 16), nil)
+	mockMessagesService.On("GetAttachmentContent", ctx, "msg-123", "att-123").Return(content, nil)
+
+	reader, attachment, err := client.GetAttachmentStream(ctx, "msg-123", "att-123")
+
+	require.NoError(t, err)
+	assert.Equal(t, "att-123", attachment.ID)
+	assert.Equal(t, "notes.txt", attachment.Filename)
+	assert.Equal(t, "text/plain", attachment.MimeType)
+	assert.Equal(t, int64(16), attachment.Size)
+	assert.Nil(t, attachment.Data)
+	assert.Nil(t, attachment.Reader)
+	data, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, "streamed content", string(data))
+	assert.NoError(t, reader.Close())
+	mockMessagesService.AssertNotCalled(t, "GetAttachment", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestClient_GetAttachmentStream_DoesNotBuffer(t *testing.T) {
+	client, _, mockMessagesService := createTestClient()
+	ctx := context.Background()
+
+	body := &lazyBody{size: 64 << 20}
+	mockMessagesService.On("GetAttachmentMetadata", ctx, "msg-123", "att-big").
+		Return(newStreamMetadata("att-big", "backup.zip", "application/zip", 64<<20), nil)
+	mockMessagesService.On("GetAttachmentContent", ctx, "msg-123", "att-big").Return(body, nil)
+
+	reader, attachment, err := client.GetAttachmentStream(ctx, "msg-123", "att-big")
+	require.NoError(t, err)
+	assert.Equal(t, int64(64<<20), attachment.Size)
+	assert.Zero(t, body.read, "nothing is read before the caller reads")
+
+	n, err := io.CopyN(io.Discard, reader, 1024)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1024), n)
+	assert.Equal(t, int64(1024), body.read, "only what the caller reads is pulled from the body")
+
+	n, err = io.Copy(io.Discard, reader)
+	require.NoError(t, err)
+	assert.Equal(t, int64(64<<20-1024), n)
+	assert.NoError(t, reader.Close())
+}
+
+func TestClient_GetAttachmentStream_CancelClosesBody(t *testing.T) {
+	client, _, mockMessagesService := createTestClient()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	body := newSlowBody()
+	mockMessagesService.On("GetAttachmentMetadata", mock.Anything, "msg-123", "att-123").
+		Return(newStreamMetadata("att-123", "notes.txt", "text/plain", 16), nil)
+	mockMessagesService.On("GetAttachmentContent", mock.Anything, "msg-123", "att-123").Return(body, nil)
+
+	reader, _, err := client.GetAttachmentStream(ctx, "msg-123", "att-123")
+	require.NoError(t, err)
+
+	readErr := make(chan error, 1)
+	go func() {
+		_, err := reader.Read(make([]byte, 16))
+		readErr <- err
+	}()
+
+	cancel()
+
+	select {
+	case err := <-readErr:
+		assert.ErrorIs(t, err, context.Canceled)
+	case <-time.After(2 * time.Second):
+		t.Fatal("Read did not return after cancellation")
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&body.closes))
+
+	_, err = reader.Read(make([]byte, 16))
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.NoError(t, reader.Close())
+	assert.Equal(t, int32(1), atomic.LoadInt32(&body.closes), "body must be closed exactly once")
+}
+
+func TestClient_GetAttachmentStream_MetadataError(t *testing.T) {
+	client, _, mockMessagesService := createTestClient()
+	ctx := context.Background()
+
+	mockMessagesService.On("GetAttachmentMetadata", ctx, "msg-123", "att-123").Return(nil, errors.New("not found"))
+
+	reader, attachment, err := client.GetAttachmentStream(ctx, "msg-123", "att-123")
+
+	assert.Nil(t, reader)
+	assert.Nil(t, attachment)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to get attachment att-123 metadata from message msg-123")
+	mockMessagesService.AssertNotCalled(t, "GetAttachmentContent", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestClient_GetAttachmentStream_Error(t *testing.T) {
+	client, _, mockMessagesService := createTestClient()
+	ctx := context.Background()
+
+	mockMessagesService.On("GetAttachmentMetadata", ctx, "msg-123", "att-123").
+		Return(newStreamMetadata("att-123", "notes.txt", "text/plain", 16), nil)
+	mockMessagesService.On("GetAttachmentContent", ctx, "msg-123", "att-123").Return(nil, errors.New("boom"))
+
+	reader, attachment, err := client.GetAttachmentStream(ctx, "msg-123", "att-123")
+
+	assert.Nil(t, reader)
+	assert.Nil(t, attachment)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to download attachment att-123 from message msg-123")
+}
+
+func TestClient_GetAttachmentStream_NotConnected(t *testing.T) {
+	client := &Client{}
+
+	reader, attachment, err := client.GetAttachmentStream(context.Background(), "msg-123", "att-123")
+
+	assert.Nil(t, reader)
+	assert.Nil(t, attachment)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "client not connected")
+}
+
+func TestClient_MarkAsRead(t *testing.T) {
+	client, mockGraphService, mockMessagesService := createTestClient()
+	ctx := context.Background()
+
+	mockMessagesService.On("MarkAsRead", ctx, "msg-123").Return(nil)
+
+	// Test
+	err := client.MarkAsRead(ctx, "msg-123")
+
+	// Assert
+	assert.NoError(t, err)
+	mockGraphService.AssertExpectations(t)
+	mockMessagesService.AssertExpectations(t)
+}
+
+func TestClient_MarkAsRead_NotConnected(t *testing.T) {
+	client := &Client{}
+	ctx := context.Background()
+
+	err := client.MarkAsRead(ctx, "msg-123")
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "client not connected")
+}
+
+func TestClient_MarkAsUnread(t *testing.T) {
+	client, mockGraphService, mockMessagesService := createTestClient()
+	ctx := context.Background()
+
+	mockMessagesService.On("MarkAsUnread", ctx, "msg-123").Return(nil)
+
+	// Test
+	err := client.MarkAsUnread(ctx, "msg-123")
+
+	// Assert
+	assert.NoError(t, err)
+	mockGraphService.AssertExpectations(t)
+	mockMessagesService.AssertExpectations(t)
+}
+
+func TestClient_MarkAsUnread_NotConnected(t *testing.T) {
+	client := &Client{}
+	ctx := context.Background()
+
+	err := client.MarkAsUnread(ctx, "msg-123")
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "client not connected")
+}
+
+func TestClient_MoveMessage(t *testing.T) {
+	client, mockGraphService, mockMessagesService := createTestClient()
+	ctx := context.Background()
+
+	mockMessagesService.On("Move", ctx, "msg-123", "folder-archive").Return(nil)
+
+	// Test
+	err := client.MoveMessage(ctx, "msg-123", "folder-archive")
+
+	// Assert
+	assert.NoError(t, err)
+	mockGraphService.AssertExpectations(t)
+	mockMessagesService.AssertExpectations(t)
+}
+
+func TestClient_MoveMessage_NotConnected(t *testing.T) {
+	client := &Client{}
+	ctx := context.Background()
+
+	err := client.MoveMessage(ctx, "msg-123", "folder-archive")
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "client not connected")
+}
+
+// setupRelocate wires a source and a destination shared mailbox into a test client.
+func setupRelocate() (*Client, *outlooktest.MockMessagesService, *outlooktest.MockMessagesService, *outlooktest.MockMailFoldersService) {
+	client, mockGraphService, _ := createTestClient()
+
+	sourceMe := &outlooktest.MockMeService{}
+	sourceMessages := &outlooktest.MockMessagesService{}
+	destMe := &outlooktest.MockMeService{}
+	destMessages := &outlooktest.MockMessagesService{}
+	destFolders := &outlooktest.MockMailFoldersService{}
+
+	mockGraphService.On("GetUserService", "source@example.com").Return(sourceMe)
+	mockGraphService.On("GetUserService", "dest@example.com").Return(destMe)
+	sourceMe.On("GetMessagesService").Return(sourceMessages)
+	destMe.On("GetMessagesService").Return(destMessages)
+	destMe.On("GetMailFoldersService").Return(destFolders)
+
+	// The source message has an attachment, is read and is flagged for follow-up
+	source := createTestMessage()
+	isRead := true
+	flagStatus := models.FLAGGED_FOLLOWUPFLAGSTATUS
+	flag := models.NewFollowupFlag()
+	flag.SetFlagStatus(&flagStatus)
+	source.SetIsRead(&isRead)
+	source.SetFlag(flag)
+	sourceMessages.On("GetWithConfig", mock.Anything, "msg-123", mock.Anything).Return(source, nil)
+
+	return client, sourceMessages, destMessages, destFolders
+}
+
+// newRelocateFileAttachment returns a file attachment with content of the given size.
+func newRelocateFileAttachment(name string, size int) models.Attachmentable {
+	att := models.NewFileAttachment()
+	id := "att-" + name
+	att.SetId(&id)
+	att.SetName(&name)
+	att.SetContentBytes(make([]byte, size))
+	return att
+}
+
+// createdMessage returns the message passed to the destination folder's CreateMessage.
+func createdMessage(t *testing.T, folders *outlooktest.MockMailFoldersService) models.Messageable {
+	t.Helper()
+	for _, call := range folders.Calls {
+		if call.Method == "CreateMessage" {
+			return call.Arguments.Get(2).(models.Messageable)
+		}
+	}
+	t.Fatal("CreateMessage was not called")
+	return nil
+}
+
+func TestClient_RelocateMessage(t *testing.T) {
+	client, sourceMessages, destMessages, destFolders := setupRelocate()
+	ctx := context.Background()
+
+	copied := models.NewMessage()
+	copiedID := "msg-copy"
+	copied.SetId(&copiedID)
+
+	sourceMessages.On("GetAttachments", ctx, "msg-123").Return([]models.Attachmentable{newRelocateFileAttachment("report.pdf", 1024)}, nil)
+	destFolders.On("CreateMessage", ctx, "folder-archive", mock.Anything).Return(copied, nil)
+	sourceMessages.On("Delete", ctx, "msg-123").Return(nil)
+
+	newID, err := client.RelocateMessage(ctx, "msg-123", "source@example.com", "dest@example.com", "folder-archive")
+
+	require.NoError(t, err)
+	assert.Equal(t, "msg-copy", newID)
+	sourceMessages.AssertExpectations(t)
+	sourceMessages.AssertNotCalled(t, "GetMimeContent", mock.Anything, mock.Anything)
+	destFolders.AssertNotCalled(t, "ImportMessage", mock.Anything, mock.Anything, mock.Anything)
+	destMessages.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+	destMessages.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+
+	// The copy is created as a received (not draft) message with the source's state,
+	// since Exchange ignores PR_MESSAGE_FLAGS once a message is saved
+	created := createdMessage(t, destFolders)
+	properties := map[string]string{}
+	for _, property := range created.GetSingleValueExtendedProperties() {
+		properties[*property.GetId()] = *property.GetValue()
+	}
+	assert.Equal(t, "1", properties["Integer 0x0E07"], "MSGFLAG_READ without MSGFLAG_UNSENT")
+	assert.Contains(t, properties, "SystemTime 0x0E06")
+	assert.Equal(t, "Test Subject", *created.GetSubject())
+	assert.Equal(t, "john@example.com", *created.GetFrom().GetEmailAddress().GetAddress())
+	assert.True(t, *created.GetIsRead())
+	assert.Equal(t, models.FLAGGED_FOLLOWUPFLAGSTATUS, *created.GetFlag().GetFlagStatus())
+	require.Len(t, created.GetAttachments(), 1)
+	assert.Equal(t, "report.pdf", *created.GetAttachments()[0].GetName())
+	assert.Nil(t, created.GetAttachments()[0].GetId())
+}
+
+func TestClient_RelocateMessage_ImportsUninlinableAttachments(t *testing.T) {
+	tests := []struct {
+		name        string
+		attachments []models.Attachmentable
+	}{
+		{"item attachment", []models.Attachmentable{models.NewItemAttachment()}},
+		{"too large", []models.Attachmentable{newRelocateFileAttachment("a.bin", 2*1024*1024), newRelocateFileAttachment("b.bin", 1024*1024)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, sourceMessages, destMessages, destFolders := setupRelocate()
+			ctx := context.Background()
+
+			mimeContent := []byte("Subject: Hello\r\n\r\nBody")
+			copied := models.NewMessage()
+			copiedID := "msg-copy"
+			copied.SetId(&copiedID)
+
+			sourceMessages.On("GetAttachments", ctx, "msg-123").Return(tt.attachments, nil)
+			sourceMessages.On("GetMimeContent", ctx, "msg-123").Return(mimeContent, nil)
+			destFolders.On("ImportMessage", ctx, "folder-archive", mimeContent).Return(copied, nil)
+			destMessages.On("Update", ctx, "msg-copy", mock.Anything).Return(models.NewMessage(), nil)
+			sourceMessages.On("Delete", ctx, "msg-123").Return(nil)
+
+			newID, err := client.RelocateMessage(ctx, "msg-123", "source@example.com", "dest@example.com", "folder-archive")
+
+			require.NoError(t, err)
+			assert.Equal(t, "msg-copy", newID)
+			destFolders.AssertNotCalled(t, "CreateMessage", mock.Anything, mock.Anything, mock.Anything)
+
+			// The imported copy stays a draft: only the read state and flag are updated
+			update := destMessages.Calls[0].Arguments.Get(2).(models.Messageable)
+			assert.Empty(t, update.GetSingleValueExtendedProperties())
+			assert.True(t, *update.GetIsRead())
+			assert.Equal(t, models.FLAGGED_FOLLOWUPFLAGSTATUS, *update.GetFlag().GetFlagStatus())
+		})
+	}
+}
+
+func TestClient_RelocateMessage_UpdateFailsRollsBack(t *testing.T) {
+	client, sourceMessages, destMessages, destFolders := setupRelocate()
+	ctx := context.Background()
+
+	copied := models.NewMessage()
+	copiedID := "msg-copy"
+	copied.SetId(&copiedID)
+
+	sourceMessages.On("GetAttachments", ctx, "msg-123").Return([]models.Attachmentable{models.NewItemAttachment()}, nil)
+	sourceMessages.On("GetMimeContent", ctx, "msg-123").Return([]byte("mime"), nil)
+	destFolders.On("ImportMessage", ctx, "folder-archive", []byte("mime")).Return(copied, nil)
+	destMessages.On("Update", ctx, "msg-copy", mock.Anything).Return(nil, errors.New("invalid property"))
+	destMessages.On("Delete", ctx, "msg-copy").Return(nil)
+
+	_, err := client.RelocateMessage(ctx, "msg-123", "source@example.com", "dest@example.com", "folder-archive")
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to update copy of message msg-123")
+	destMessages.AssertExpectations(t)
+	sourceMessages.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+}
+
+func TestClient_RelocateMessage_DeleteFailsRollsBack(t *testing.T) {
+	client, sourceMessages, destMessages, destFolders := setupRelocate()
+	ctx := context.Background()
+
+	copied := models.NewMessage()
+	copiedID := "msg-copy"
+	copied.SetId(&copiedID)
+
+	sourceMessages.On("GetAttachments", ctx, "msg-123").Return([]models.Attachmentable{}, nil)
+	destFolders.On("CreateMessage", ctx, "folder-archive", mock.Anything).Return(copied, nil)
+	sourceMessages.On("Delete", ctx, "msg-123").Return(errors.New("access denied"))
+	destMessages.On("Delete", ctx, "msg-copy").Return(nil)
+
+	newID, err := client.RelocateMessage(ctx, "msg-123", "source@example.com", "dest@example.com", "folder-archive")
+
+	require.Error(t, err)
+	assert.Empty(t, newID)
+	assert.Contains(t, err.Error(), "failed to delete message msg-123 after copying it")
+	destMessages.AssertExpectations(t)
+	// The source message was never removed
+	sourceMessages.AssertNumberOfCalls(t, "Delete", 1)
+}
+
+func TestClient_RelocateMessage_RollbackFails(t *testing.T) {
+	client, sourceMessages, destMessages, destFolders := setupRelocate()
+	ctx := context.Background()
+
+	copied := models.NewMessage()
+	copiedID := "msg-copy"
+	copied.SetId(&copiedID)
+
+	sourceMessages.On("GetAttachments", ctx, "msg-123").Return([]models.Attachmentable{}, nil)
+	destFolders.On("CreateMessage", ctx, "folder-archive", mock.Anything).Return(copied, nil)
+	sourceMessages.On("Delete", ctx, "msg-123").Return(errors.New("access denied"))
+	destMessages.On("Delete", ctx, "msg-copy").Return(errors.New("throttled"))
+
+	_, err := client.RelocateMessage(ctx, "msg-123", "source@example.com", "dest@example.com", "folder-archive")
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to delete message msg-123 after copying it")
+	assert.Contains(t, err.Error(), "failed to remove copy msg-copy")
+}
+
+func TestClient_RelocateMessage_CreateFails(t *testing.T) {
+	client, sourceMessages, _, destFolders := setupRelocate()
+	ctx := context.Background()
+
+	sourceMessages.On("GetAttachments", ctx, "msg-123").Return([]models.Attachmentable{}, nil)
+	destFolders.On("CreateMessage", ctx, "folder-archive", mock.Anything).Return(nil, errors.New("quota exceeded"))
+
+	_, err := client.RelocateMessage(ctx, "msg-123", "source@example.com", "dest@example.com", "folder-archive")
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to copy message msg-123 to folder folder-archive")
+	sourceMessages.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+}
+
+func TestClient_RelocateMessage_NotConnected(t *testing.T) {
+	client := &Client{}
+
+	_, err := client.RelocateMessage(context.Background(), "msg-123", "", "dest@example.com", "folder-archive")
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "client not connected")
+}
+
+func TestClient_ReportSpam(t *testing.T) {
+	client, mockGraphService, mockMessagesService := createTestClient()
+	ctx := context.Background()
+
+	mockMessagesService.On("Move", ctx, "msg-123", "junkemail").Return(nil)
+
+	err := client.ReportSpam(ctx, "msg-123")
+
+	assert.NoError(t, err)
+	mockGraphService.AssertExpectations(t)
+	mockMessagesService.AssertExpectations(t)
+}
+
+func TestClient_NotSpam(t *testing.T) {
+	client, mockGraphService, mockMessagesService := createTestClient()
+	ctx := context.Background()
+
+	mockMessagesService.On("Move", ctx, "msg-123", "inbox").Return(nil)
+
+	err := client.NotSpam(ctx, "msg-123")
+
+	assert.NoError(t, err)
+	mockGraphService.AssertExpectations(t)
+	mockMessagesService.AssertExpectations(t)
+}
+
+func TestClient_DeleteMessage(t *testing.T) {
+	client, mockGraphService, mockMessagesService := createTestClient()
+	ctx := context.Background()
+
+	mockMessagesService.On("Delete", ctx, "msg-123").Return(nil)
+
+	// Test
+	err := client.DeleteMessage(ctx, "msg-123")
+
+	// Assert
+	assert.NoError(t, err)
+	mockGraphService.AssertExpectations(t)
+	mockMessagesService.AssertExpectations(t)
+}
+
+func TestClient_DeleteMessage_NotConnected(t *testing.T) {
+	client := &Client{}
+	ctx := context.Background()
+
+	err := client.DeleteMessage(ctx, "msg-123")
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "client not connected")
+}
+
+func TestClient_ConvertMessage_CompleteConversion(t *testing.T) {
 	client := &Client{service: &outlooktest.MockGraphService{}}
 
 	// Create a fully populated message
@@ -398,6 +1929,8 @@ func TestClient_ConvertMessage_CompleteConversion(t *testing.T) {
 	msg.SetIsRead(&isRead)
 	msg.SetHasAttachments(&hasAttachments)
 	msg.SetParentFolderId(&parentFolderID)
+	internetMessageID := "<CAB123@mail.gmail.com>"
+	msg.SetInternetMessageId(&internetMessageID)
 
 	// Plain text body
 	body := models.NewItemBody()
@@ -437,12 +1970,16 @@ func TestClient_ConvertMessage_CompleteConversion(t *testing.T) {
 
 	// Assert complete conversion
 	assert.Equal(t, "msg-456", email.ID)
+	assert.Equal(t, "<CAB123@mail.gmail.com>", email.MessageID)
+	assert.Equal(t, "mid:CAB123@mail.gmail.com", email.StableKey(), "matches the Gmail copy of the message")
 	assert.Equal(t, "Complete Test", email.Subject)
 	assert.Equal(t, "Plain text body", email.Body.Text)
 	assert.Equal(t, "", email.Body.HTML) // Should be empty for text body
 	assert.Equal(t, "Preview text", email.Snippet)
 	assert.True(t, email.IsRead)
-	assert.Equal(t, sentTime, email.Date) // Should use sent time
+	assert.Equal(t, receivedTime, email.Date) // Date is the received time
+	assert.Equal(t, sentTime, email.SentDate)
+	assert.Equal(t, receivedTime, email.ReceivedDate)
 	assert.Len(t, email.Cc, 1)
 	assert.Equal(t, "CC User", email.Cc[0].Name)
 	assert.Len(t, email.Bcc, 1)
@@ -496,3 +2033,746 @@ func TestClient_ConvertMessage_EmptyFields(t *testing.T) {
 	assert.False(t, email.IsRead)
 	assert.Empty(t, email.Labels)
 }
+
+func TestClient_ConvertMessage_PartialRecipients(t *testing.T) {
+	client := &Client{service: &outlooktest.MockGraphService{}}
+
+	// Graph may return recipients and headers without their nested values
+	msg := models.NewMessage()
+	msg.SetFrom(models.NewRecipient())
+	msg.SetToRecipients([]models.Recipientable{models.NewRecipient(), nil})
+	msg.SetCcRecipients(append(buildRecipients([]core.EmailAddress{{Name: "Ann", Email: "ann@example.com"}}), models.NewRecipient()))
+	msg.SetBccRecipients([]models.Recipientable{nil})
+	msg.SetReplyTo([]models.Recipientable{models.NewRecipient()})
+	msg.SetInternetMessageHeaders([]models.InternetMessageHeaderable{nil})
+
+	var email *core.Email
+	require.NotPanics(t, func() { email = client.convertMessage(msg) })
+
+	assert.Equal(t, core.EmailAddress{}, email.From)
+	assert.Empty(t, email.To)
+	assert.Equal(t, []core.EmailAddress{{Name: "Ann", Email: "ann@example.com"}}, email.Cc)
+	assert.Empty(t, email.Bcc)
+	assert.Empty(t, email.RawHeaders)
+	require.NotPanics(t, func() { assert.Empty(t, client.convertDraft(msg).ReplyTo) })
+}
+
+func TestClient_ConvertMessage_InternetHeaders(t *testing.T) {
+	client := &Client{service: &outlooktest.MockGraphService{}}
+
+	msg := models.NewMessage()
+	header := models.NewInternetMessageHeader()
+	name := "list-unsubscribe"
+	value := "<https://example.com/unsub>"
+	header.SetName(&name)
+	header.SetValue(&value)
+	msg.SetInternetMessageHeaders([]models.InternetMessageHeaderable{header})
+
+	email := client.convertMessage(msg)
+
+	assert.Equal(t, []string{"<https://example.com/unsub>"}, email.RawHeaders["List-Unsubscribe"])
+	links, _ := email.UnsubscribeLinks()
+	assert.Equal(t, []string{"https://example.com/unsub"}, links)
+}
+
+func TestClient_ConvertMessage_SignedMessage(t *testing.T) {
+	client := &Client{service: &outlooktest.MockGraphService{}}
+
+	msg := createTestMessage().(*models.Message)
+	header := models.NewInternetMessageHeader()
+	name := "Content-Type"
+	value := `multipart/signed; protocol="application/pkcs7-signature"; micalg=sha-256; boundary="b1"`
+	header.SetName(&name)
+	header.SetValue(&value)
+	msg.SetInternetMessageHeaders([]models.InternetMessageHeaderable{header})
+
+	email := client.convertMessage(msg)
+
+	assert.True(t, email.Signed)
+	assert.False(t, email.Encrypted)
+	assert.Equal(t, "<p>Test body content</p>", email.Body.HTML)
+}
+
+func TestClient_ConvertMessage_SecureMIMEAttachment(t *testing.T) {
+	client := &Client{service: &outlooktest.MockGraphService{}}
+
+	tests := []struct {
+		name        string
+		contentType string
+		signed      bool
+		encrypted   bool
+	}{
+		{name: "signed", contentType: "multipart/signed", signed: true},
+		{name: "encrypted", contentType: "application/pkcs7-mime", encrypted: true},
+		{name: "plain attachment", contentType: "application/pdf"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := createTestMessage().(*models.Message)
+			attachment := models.NewFileAttachment()
+			name := "smime.p7m"
+			attachment.SetName(&name)
+			attachment.SetContentType(&tt.contentType)
+			msg.SetAttachments([]models.Attachmentable{attachment})
+
+			email := client.convertMessage(msg)
+
+			assert.Empty(t, email.RawHeaders, "no headers were selected")
+			assert.Equal(t, tt.signed, email.Signed)
+			assert.Equal(t, tt.encrypted, email.Encrypted)
+		})
+	}
+}
+
+func TestClient_ConvertMessage_EventMessage(t *testing.T) {
+	client := &Client{service: &outlooktest.MockGraphService{}}
+
+	newEventMessage := func(messageType models.MeetingMessageType) *models.EventMessage {
+		msg := models.NewEventMessage()
+		subject := "Quarterly review"
+		msg.SetSubject(&subject)
+		msg.SetFrom(buildRecipients([]core.EmailAddress{{Name: "John Doe", Email: "john@example.com"}})[0])
+		msg.SetToRecipients(buildRecipients([]core.EmailAddress{{Name: "Jane Smith", Email: "jane@example.com"}}))
+		msg.SetMeetingMessageType(&messageType)
+
+		start, end, zone := "2024-03-01T09:30:00.0000000", "2024-03-01T10:00:00.0000000", "UTC"
+		startTime := models.NewDateTimeTimeZone()
+		startTime.SetDateTime(&start)
+		startTime.SetTimeZone(&zone)
+		endTime := models.NewDateTimeTimeZone()
+		endTime.SetDateTime(&end)
+		endTime.SetTimeZone(&zone)
+		msg.SetStartDateTime(startTime)
+		msg.SetEndDateTime(endTime)
+
+		room := "Room 4"
+		location := models.NewLocation()
+		location.SetDisplayName(&room)
+		msg.SetLocation(location)
+		return msg
+	}
+
+	t.Run("request", func(t *testing.T) {
+		email := client.convertMessage(newEventMessage(models.MEETINGREQUEST_MEETINGMESSAGETYPE))
+
+		event, err := email.CalendarEvent()
+		require.NoError(t, err)
+		require.NotNil(t, event)
+		assert.Equal(t, core.CalendarMethodRequest, event.Method)
+		assert.Equal(t, "Quarterly review", event.Summary)
+		assert.Equal(t, "Room 4", event.Location)
+		assert.Equal(t, time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC), event.Start)
+		assert.Equal(t, time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC), event.End)
+		assert.Equal(t, "john@example.com", event.Organizer.Email)
+		require.Len(t, event.Attendees, 1)
+		assert.Equal(t, "jane@example.com", event.Attendees[0].Email)
+	})
+
+	t.Run("response", func(t *testing.T) {
+		email := client.convertMessage(newEventMessage(models.MEETINGDECLINED_MEETINGMESSAGETYPE))
+
+		require.NotNil(t, email.Event)
+		assert.Equal(t, core.CalendarMethodReply, email.Event.Method)
+		assert.Equal(t, "jane@example.com", email.Event.Organizer.Email)
+		require.Len(t, email.Event.Attendees, 1)
+		assert.Equal(t, "john@example.com", email.Event.Attendees[0].Email)
+		assert.Equal(t, "DECLINED", email.Event.Attendees[0].Status)
+	})
+
+	t.Run("plain message", func(t *testing.T) {
+		email := client.convertMessage(createTestMessage())
+
+		assert.Nil(t, email.Event)
+	})
+}
+
+func TestClient_ConvertMessage_Focused(t *testing.T) {
+	client := &Client{service: &outlooktest.MockGraphService{}}
+	focused := models.FOCUSED_INFERENCECLASSIFICATIONTYPE
+	other := models.OTHER_INFERENCECLASSIFICATIONTYPE
+
+	tests := []struct {
+		name           string
+		classification *models.InferenceClassificationType
+		expected       bool
+	}{
+		{name: "focused", classification: &focused, expected: true},
+		{name: "other", classification: &other, expected: false},
+		{name: "not returned", classification: nil, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := models.NewMessage()
+			msg.SetInferenceClassification(tt.classification)
+
+			email := client.convertMessage(msg)
+
+			assert.Equal(t, tt.expected, email.Focused)
+		})
+	}
+}
+
+func TestClient_SetFocused(t *testing.T) {
+	for _, focused := range []bool{true, false} {
+		client, mockGraphService, mockMessagesService := createTestClient()
+		ctx := context.Background()
+
+		mockMessagesService.On("SetFocused", ctx, "msg-123", focused).Return(nil)
+
+		err := client.SetFocused(ctx, "msg-123", focused)
+
+		assert.NoError(t, err)
+		mockGraphService.AssertExpectations(t)
+		mockMessagesService.AssertExpectations(t)
+	}
+}
+
+func TestClient_SetFocused_Error(t *testing.T) {
+	client, _, mockMessagesService := createTestClient()
+	ctx := context.Background()
+
+	mockMessagesService.On("SetFocused", ctx, "msg-123", true).Return(errors.New("boom"))
+
+	err := client.SetFocused(ctx, "msg-123", true)
+
+	assert.ErrorContains(t, err, "failed to set focused state of message msg-123")
+}
+
+func TestClient_SetFocused_NotConnected(t *testing.T) {
+	client := &Client{}
+
+	err := client.SetFocused(context.Background(), "msg-123", true)
+
+	assert.ErrorContains(t, err, "client not connected")
+}
+
+func TestClient_MarkConversationAsRead(t *testing.T) {
+	client, mockGraphService, mockMessagesService := createTestClient()
+	ctx := context.Background()
+
+	newMessage := func(id string, isRead bool) models.Messageable {
+		msg := models.NewMessage()
+		msg.SetId(&id)
+		msg.SetIsRead(&isRead)
+		return msg
+	}
+
+	mockResponse := models.NewMessageCollectionResponse()
+	mockResponse.SetValue([]models.Messageable{
+		newMessage("msg-1", false),
+		newMessage("msg-2", true),
+		newMessage("msg-3", false),
+	})
+
+	var capturedConfig *users.ItemMessagesRequestBuilderGetRequestConfiguration
+	mockMessagesService.On("List", ctx, mock.AnythingOfType("*users.ItemMessagesRequestBuilderGetRequestConfiguration")).
+		Run(func(args mock.Arguments) {
+			capturedConfig = args.Get(1).(*users.ItemMessagesRequestBuilderGetRequestConfiguration)
+		}).
+		Return(mockResponse, nil)
+	mockMessagesService.On("BatchMarkAsRead", ctx, []string{"msg-1", "msg-3"}).Return(nil)
+
+	err := client.MarkConversationAsRead(ctx, "conv-1")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "conversationId eq 'conv-1' and isRead eq false", *capturedConfig.QueryParameters.Filter)
+	mockGraphService.AssertExpectations(t)
+	mockMessagesService.AssertExpectations(t)
+}
+
+func TestClient_MarkConversationAsRead_FollowsNextLink(t *testing.T) {
+	client, _, mockMessagesService := createTestClient()
+	ctx := context.Background()
+
+	newMessage := func(id string) models.Messageable {
+		msg := models.NewMessage()
+		msg.SetId(&id)
+		return msg
+	}
+
+	nextLink := "https://graph.microsoft.com/v1.0/me/messages?$skiptoken=page2"
+	firstPage := models.NewMessageCollectionResponse()
+	firstPage.SetValue([]models.Messageable{newMessage("msg-1")})
+	firstPage.SetOdataNextLink(&nextLink)
+	secondPage := models.NewMessageCollectionResponse()
+	secondPage.SetValue([]models.Messageable{newMessage("msg-2")})
+
+	mockMessagesService.On("List", ctx, mock.Anything).Return(firstPage, nil)
+	mockMessagesService.On("ListNextPage", ctx, nextLink).Return(secondPage, nil)
+	mockMessagesService.On("BatchMarkAsRead", ctx, []string{"msg-1", "msg-2"}).Return(nil)
+
+	err := client.MarkConversationAsRead(ctx, "conv-1")
+
+	assert.NoError(t, err)
+	mockMessagesService.AssertExpectations(t)
+}
+
+func TestClient_MarkConversationAsRead_AllRead(t *testing.T) {
+	client, _, mockMessagesService := createTestClient()
+	ctx := context.Background()
+
+	mockMessagesService.On("List", ctx, mock.Anything).Return(models.NewMessageCollectionResponse(), nil)
+
+	err := client.MarkConversationAsRead(ctx, "conv-1")
+
+	assert.NoError(t, err)
+	mockMessagesService.AssertNotCalled(t, "BatchMarkAsRead", mock.Anything, mock.Anything)
+}
+
+func TestClient_MarkConversationAsRead_BatchError(t *testing.T) {
+	client, _, mockMessagesService := createTestClient()
+	ctx := context.Background()
+
+	id := "msg-1"
+	msg := models.NewMessage()
+	msg.SetId(&id)
+	mockResponse := models.NewMessageCollectionResponse()
+	mockResponse.SetValue([]models.Messageable{msg})
+
+	mockMessagesService.On("List", ctx, mock.Anything).Return(mockResponse, nil)
+	mockMessagesService.On("BatchMarkAsRead", ctx, []string{"msg-1"}).Return(errors.New("boom"))
+
+	err := client.MarkConversationAsRead(ctx, "conv-1")
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to mark conversation conv-1 as read")
+}
+
+func TestClient_ListMessages_DefaultPageSize(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     *core.ListOptions
+		expected int32
+	}{
+		{name: "nil options", opts: nil, expected: 25},
+		{name: "unset max results", opts: &core.ListOptions{Query: "invoice"}, expected: 25},
+		{name: "explicit max results", opts: &core.ListOptions{MaxResults: 5}, expected: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, _, mockMessagesService := createTestClient()
+			client.config.DefaultPageSize = 25
+			ctx := context.Background()
+
+			var capturedConfig *users.ItemMessagesRequestBuilderGetRequestConfiguration
+			mockMessagesService.On("List", ctx, mock.AnythingOfType("*users.ItemMessagesRequestBuilderGetRequestConfiguration")).
+				Run(func(args mock.Arguments) {
+					capturedConfig = args.Get(1).(*users.ItemMessagesRequestBuilderGetRequestConfiguration)
+				}).
+				Return(models.NewMessageCollectionResponse(), nil)
+
+			_, err := client.ListMessages(ctx, tt.opts)
+
+			require.NoError(t, err)
+			require.NotNil(t, capturedConfig.QueryParameters.Top)
+			assert.Equal(t, tt.expected, *capturedConfig.QueryParameters.Top)
+		})
+	}
+}
+
+func TestClient_GetAttachmentByIndex(t *testing.T) {
+	newAttachment := func(id, name string) models.Attachmentable {
+		att := models.NewFileAttachment()
+		att.SetId(&id)
+		att.SetName(&name)
+		return att
+	}
+	listed := []models.Attachmentable{
+		newAttachment("att-1", "first.txt"),
+		newAttachment("att-2", "second.pdf"),
+	}
+
+	t.Run("valid index", func(t *testing.T) {
+		client, _, mockMessagesService := createTestClient()
+		ctx := context.Background()
+
+		full := newAttachment("att-2", "second.pdf").(*models.FileAttachment)
+		full.SetContentBytes([]byte("%PDF"))
+
+		mockMessagesService.On("ListAttachmentMetadata", ctx, "msg-123").Return(listed, nil)
+		mockMessagesService.On("GetAttachmentMetadata", ctx, "msg-123", "att-2").Return(listed[1], nil)
+		mockMessagesService.On("GetAttachment", ctx, "msg-123", "att-2").Return(full, nil)
+
+		result, err := client.GetAttachmentByIndex(ctx, "msg-123", 1)
+
+		require.NoError(t, err)
+		assert.Equal(t, "att-2", result.ID)
+		assert.Equal(t, "second.pdf", result.Filename)
+		assert.Equal(t, []byte("%PDF"), result.Data)
+		mockMessagesService.AssertExpectations(t)
+		mockMessagesService.AssertNotCalled(t, "GetAttachments", mock.Anything, mock.Anything)
+	})
+
+	t.Run("out of range index", func(t *testing.T) {
+		for _, index := range []int{-1, 2} {
+			client, _, mockMessagesService := createTestClient()
+			ctx := context.Background()
+
+			mockMessagesService.On("ListAttachmentMetadata", ctx, "msg-123").Return(listed, nil)
+
+			result, err := client.GetAttachmentByIndex(ctx, "msg-123", index)
+
+			assert.Nil(t, result)
+			assert.ErrorContains(t, err, "out of range")
+			mockMessagesService.AssertNotCalled(t, "GetAttachment", mock.Anything, mock.Anything, mock.Anything)
+		}
+	})
+}
+
+func TestClient_GetMessageWithOptions_RetryNotFound(t *testing.T) {
+	newNotFound := func() error {
+		mainErr := odataerrors.NewMainError()
+		code := "ErrorItemNotFound"
+		mainErr.SetCode(&code)
+		odataErr := odataerrors.NewODataError()
+		odataErr.SetErrorEscaped(mainErr)
+		odataErr.ResponseStatusCode = 404
+		return odataErr
+	}
+
+	t.Run("succeeds after a transient 404", func(t *testing.T) {
+		client, _, mockMessagesService := createTestClient()
+		client.config.NotFoundRetryDelay = time.Millisecond
+		ctx := context.Background()
+
+		msg := models.NewMessage()
+		id := "msg-123"
+		msg.SetId(&id)
+
+		mockMessagesService.On("Get", ctx, "msg-123").Return(nil, newNotFound()).Once()
+		mockMessagesService.On("Get", ctx, "msg-123").Return(msg, nil).Once()
+
+		email, err := client.GetMessageWithOptions(ctx, "msg-123", &core.GetOptions{RetryNotFound: true})
+
+		require.NoError(t, err)
+		assert.Equal(t, "msg-123", email.ID)
+		mockMessagesService.AssertNumberOfCalls(t, "Get", 2)
+	})
+
+	t.Run("gives up after configured retries", func(t *testing.T) {
+		client, _, mockMessagesService := createTestClient()
+		client.config.NotFoundRetries = 2
+		client.config.NotFoundRetryDelay = time.Millisecond
+		ctx := context.Background()
+
+		mockMessagesService.On("Get", ctx, "msg-123").Return(nil, newNotFound())
+
+		email, err := client.GetMessageWithOptions(ctx, "msg-123", &core.GetOptions{RetryNotFound: true})
+
+		assert.Nil(t, email)
+		assert.ErrorContains(t, err, "ErrorItemNotFound")
+		mockMessagesService.AssertNumberOfCalls(t, "Get", 3)
+	})
+
+	t.Run("negative retries disable retrying", func(t *testing.T) {
+		client, _, mockMessagesService := createTestClient()
+		client.config.NotFoundRetries = -1
+		ctx := context.Background()
+
+		mockMessagesService.On("Get", ctx, "msg-123").Return(nil, newNotFound())
+
+		_, err := client.GetMessageWithOptions(ctx, "msg-123", &core.GetOptions{RetryNotFound: true})
+
+		assert.ErrorContains(t, err, "ErrorItemNotFound")
+		mockMessagesService.AssertNumberOfCalls(t, "Get", 1)
+	})
+
+	t.Run("other errors are not retried", func(t *testing.T) {
+		client, _, mockMessagesService := createTestClient()
+		client.config.NotFoundRetryDelay = time.Millisecond
+		ctx := context.Background()
+
+		mockMessagesService.On("Get", ctx, "msg-123").Return(nil, errors.New("boom"))
+
+		_, err := client.GetMessageWithOptions(ctx, "msg-123", &core.GetOptions{RetryNotFound: true})
+
+		assert.Error(t, err)
+		mockMessagesService.AssertNumberOfCalls(t, "Get", 1)
+	})
+
+	t.Run("404 is not retried without the option", func(t *testing.T) {
+		client, _, mockMessagesService := createTestClient()
+		ctx := context.Background()
+
+		mockMessagesService.On("Get", ctx, "msg-123").Return(nil, newNotFound())
+
+		_, err := client.GetMessageWithOptions(ctx, "msg-123", nil)
+
+		assert.Error(t, err)
+		mockMessagesService.AssertNumberOfCalls(t, "Get", 1)
+	})
+}
+
+func TestClient_GetMessageWithOptions_PreferBodyType(t *testing.T) {
+	client, _, mockMessagesService := createTestClient()
+	ctx := context.Background()
+
+	// Graph returns the body converted to the preferred type
+	message := createTestMessage()
+	content := "Test body content"
+	contentType := models.TEXT_BODYTYPE
+	body := models.NewItemBody()
+	body.SetContent(&content)
+	body.SetContentType(&contentType)
+	message.SetBody(body)
+
+	var capturedConfig *users.ItemMessagesMessageItemRequestBuilderGetRequestConfiguration
+	mockMessagesService.On("GetWithConfig", ctx, "msg-123", mock.Anything).
+		Run(func(args mock.Arguments) {
+			capturedConfig = args.Get(2).(*users.ItemMessagesMessageItemRequestBuilderGetRequestConfiguration)
+		}).
+		Return(message, nil)
+
+	email, err := client.GetMessageWithOptions(ctx, "msg-123", &core.GetOptions{PreferBodyType: core.BodyTypeText})
+
+	require.NoError(t, err)
+	require.NotNil(t, capturedConfig)
+	assert.Equal(t, []string{`outlook.body-content-type="text"`}, capturedConfig.Headers.Get("Prefer"))
+	assert.Equal(t, "Test body content", email.Body.Text)
+	assert.Empty(t, email.Body.HTML)
+	mockMessagesService.AssertNotCalled(t, "Get", mock.Anything, mock.Anything)
+}
+
+func TestClient_GetMessageWithOptions_UnsupportedBodyType(t *testing.T) {
+	client, _, mockMessagesService := createTestClient()
+
+	_, err := client.GetMessageWithOptions(context.Background(), "msg-123", &core.GetOptions{PreferBodyType: "markdown"})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported body type")
+	mockMessagesService.AssertNotCalled(t, "GetWithConfig", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestClient_GetMessageWithOptions_WithMailboxOnly(t *testing.T) {
+	client, mockGraphService, mockMessagesService := createTestClient()
+	client.config.ResolveFolderNames = true
+	ctx := context.Background()
+
+	sharedMeService := &outlooktest.MockMeService{}
+	sharedMessagesService := &outlooktest.MockMessagesService{}
+	mockGraphService.On("GetUserService", "shared@example.com").Return(sharedMeService)
+	sharedMeService.On("GetMessagesService").Return(sharedMessagesService)
+	sharedMessagesService.On("Get", ctx, "msg-123").Return(createTestMessage(), nil)
+
+	result, err := client.GetMessageWithOptions(ctx, "msg-123", nil, WithMailbox("shared@example.com"))
+
+	require.NoError(t, err)
+	assert.Equal(t, "msg-123", result.ID)
+	sharedMessagesService.AssertExpectations(t)
+	mockGraphService.AssertNotCalled(t, "GetMeService")
+	mockMessagesService.AssertNotCalled(t, "Get", mock.Anything, mock.Anything)
+}
+
+func TestClient_MarkAsReadWithOptions_WithMailbox(t *testing.T) {
+	client, mockGraphService, mockMessagesService := createTestClient()
+	ctx := context.Background()
+
+	sharedMeService := &outlooktest.MockMeService{}
+	sharedMessagesService := &outlooktest.MockMessagesService{}
+	mockGraphService.On("GetUserService", "shared@example.com").Return(sharedMeService)
+	sharedMeService.On("GetMessagesService").Return(sharedMessagesService)
+	sharedMessagesService.On("MarkAsRead", ctx, "msg-123").Return(nil)
+
+	err := client.MarkAsReadWithOptions(ctx, "msg-123", WithMailbox("shared@example.com"))
+
+	require.NoError(t, err)
+	sharedMessagesService.AssertExpectations(t)
+	mockMessagesService.AssertNotCalled(t, "MarkAsRead", mock.Anything, mock.Anything)
+}
+
+func TestClient_GetMessageWithOptions_WithMailbox(t *testing.T) {
+	client, mockGraphService, mockMessagesService := createTestClient()
+	ctx := context.Background()
+
+	sharedMeService := &outlooktest.MockMeService{}
+	sharedMessagesService := &outlooktest.MockMessagesService{}
+	mockGraphService.On("GetUserService", "shared@example.com").Return(sharedMeService)
+	sharedMeService.On("GetMessagesService").Return(sharedMessagesService)
+	sharedMessagesService.On("GetWithConfig", ctx, "msg-123", mock.Anything).Return(createTestMessage(), nil)
+	sharedMessagesService.On("MarkAsRead", ctx, "msg-123").Return(nil)
+
+	email, err := client.GetMessageWithOptions(ctx, "msg-123",
+		&core.GetOptions{PreferBodyType: core.BodyTypeText, MarkReadOnFetch: true},
+		WithMailbox("shared@example.com"))
+
+	require.NoError(t, err)
+	assert.True(t, email.IsRead)
+	sharedMessagesService.AssertExpectations(t)
+	mockMessagesService.AssertNotCalled(t, "MarkAsRead", mock.Anything, mock.Anything)
+}
+
+func TestClient_ConvertMessage_SentAndReceivedDates(t *testing.T) {
+	client := &Client{}
+
+	tests := []struct {
+		name             string
+		sent             *time.Time
+		received         *time.Time
+		expectedDate     time.Time
+		expectedSent     time.Time
+		expectedReceived time.Time
+	}{
+		{
+			name:             "delayed delivery",
+			sent:             ptrTime(time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)),
+			received:         ptrTime(time.Date(2024, 5, 1, 11, 45, 0, 0, time.UTC)),
+			expectedDate:     time.Date(2024, 5, 1, 11, 45, 0, 0, time.UTC),
+			expectedSent:     time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC),
+			expectedReceived: time.Date(2024, 5, 1, 11, 45, 0, 0, time.UTC),
+		},
+		{
+			name:         "draft without received time",
+			sent:         ptrTime(time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)),
+			expectedDate: time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC),
+			expectedSent: time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := models.NewMessage()
+			msg.SetSentDateTime(tt.sent)
+			msg.SetReceivedDateTime(tt.received)
+
+			email := client.convertMessage(msg)
+
+			assert.Equal(t, tt.expectedDate, email.Date)
+			assert.Equal(t, tt.expectedSent, email.SentDate)
+			assert.Equal(t, tt.expectedReceived, email.ReceivedDate)
+		})
+	}
+}
+
+func ptrTime(t time.Time) *time.Time {
+	return &t
+}
+
+func TestClient_ListMessages_OrderBy(t *testing.T) {
+	newMessage := func(id string, received time.Time) models.Messageable {
+		msg := models.NewMessage()
+		msg.SetId(&id)
+		msg.SetReceivedDateTime(&received)
+		return msg
+	}
+	base := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name            string
+		opts            *core.ListOptions
+		expectedOrderBy []string
+		expectedIDs     []string
+	}{
+		{
+			name:            "newest first",
+			opts:            &core.ListOptions{OrderBy: core.OrderByDateDesc},
+			expectedOrderBy: []string{"receivedDateTime desc"},
+			expectedIDs:     []string{"msg-new", "msg-mid", "msg-old"},
+		},
+		{
+			name:            "oldest first",
+			opts:            &core.ListOptions{OrderBy: core.OrderByDateAsc},
+			expectedOrderBy: []string{"receivedDateTime asc"},
+			expectedIDs:     []string{"msg-old", "msg-mid", "msg-new"},
+		},
+		{
+			name:        "with search sorts locally only",
+			opts:        &core.ListOptions{OrderBy: core.OrderByDateDesc, Query: "subject:report"},
+			expectedIDs: []string{"msg-new", "msg-mid", "msg-old"},
+		},
+		{
+			name:        "no order keeps provider order",
+			opts:        &core.ListOptions{},
+			expectedIDs: []string{"msg-mid", "msg-old", "msg-new"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, _, mockMessagesService := createTestClient()
+			ctx := context.Background()
+
+			mockResponse := models.NewMessageCollectionResponse()
+			mockResponse.SetValue([]models.Messageable{
+				newMessage("msg-mid", base.Add(time.Hour)),
+				newMessage("msg-old", base),
+				newMessage("msg-new", base.Add(2*time.Hour)),
+			})
+
+			var capturedConfig *users.ItemMessagesRequestBuilderGetRequestConfiguration
+			mockMessagesService.On("List", ctx, mock.Anything).
+				Run(func(args mock.Arguments) {
+					capturedConfig = args.Get(1).(*users.ItemMessagesRequestBuilderGetRequestConfiguration)
+				}).
+				Return(mockResponse, nil)
+
+			result, err := client.ListMessages(ctx, tt.opts)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedOrderBy, capturedConfig.QueryParameters.Orderby)
+			var ids []string
+			for _, email := range result.Emails {
+				ids = append(ids, email.ID)
+			}
+			assert.Equal(t, tt.expectedIDs, ids)
+		})
+	}
+}
+
+func TestClient_ListMessages_OrderByRelevance(t *testing.T) {
+	client, _, mockMessagesService := createTestClient()
+	ctx := context.Background()
+
+	newScoredMessage := func(id string, score float64) models.Messageable {
+		msg := models.NewMessage()
+		msg.SetId(&id)
+		msg.SetAdditionalData(map[string]any{"@search.score": &score})
+		return msg
+	}
+	mockResponse := models.NewMessageCollectionResponse()
+	mockResponse.SetValue([]models.Messageable{
+		newScoredMessage("msg-partial", 2.5),
+		newScoredMessage("msg-best", 7.75),
+		newScoredMessage("msg-weak", 0.5),
+	})
+
+	var capturedConfig *users.ItemMessagesRequestBuilderGetRequestConfiguration
+	mockMessagesService.On("List", ctx, mock.Anything).
+		Run(func(args mock.Arguments) {
+			capturedConfig = args.Get(1).(*users.ItemMessagesRequestBuilderGetRequestConfiguration)
+		}).
+		Return(mockResponse, nil)
+
+	result, err := client.ListMessages(ctx, &core.ListOptions{Query: "quarterly report", OrderBy: core.OrderByRelevance})
+
+	require.NoError(t, err)
+	assert.Nil(t, capturedConfig.QueryParameters.Orderby)
+	require.Len(t, result.Emails, 3)
+	assert.Equal(t, "msg-best", result.Emails[0].ID)
+	assert.Equal(t, 7.75, result.Emails[0].Score)
+	assert.Equal(t, "msg-partial", result.Emails[1].ID)
+	assert.Equal(t, 2.5, result.Emails[1].Score)
+	assert.Equal(t, "msg-weak", result.Emails[2].ID)
+	assert.Equal(t, 0.5, result.Emails[2].Score)
+}
+
+func TestClient_ConvertMessage_NoSearchScore(t *testing.T) {
+	client := &Client{}
+
+	email := client.convertMessage(createTestMessage())
+
+	assert.Zero(t, email.Score)
+}
+
+func TestClient_GetHistory_NotSupported(t *testing.T) {
+	client, mockGraphService, _ := createTestClient()
+
+	resp, err := client.GetHistory(context.Background(), &core.HistoryRequest{StartHistoryID: "1"})
+
+	assert.Nil(t, resp)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, core.ErrNotSupported))
+	mockGraphService.AssertNotCalled(t, "GetMeService")
+}
@@ -148,3 +148,87 @@ func TestFormatDate(t *testing.T) {
 func stringPtr(s string) *string {
 	return &s
 }
+
+func TestBuildFilter(t *testing.T) {
+	hasAttachments := true
+	noAttachments := false
+	focused := true
+	other := false
+
+	tests := []struct {
+		name     string
+		opts     *core.ListOptions
+		self     string
+		expected string
+	}{
+		{
+			name:     "no filters",
+			opts:     &core.ListOptions{},
+			expected: "",
+		},
+		{
+			name:     "with attachments",
+			opts:     &core.ListOptions{HasAttachments: &hasAttachments},
+			expected: "hasAttachments eq true",
+		},
+		{
+			name:     "without attachments",
+			opts:     &core.ListOptions{HasAttachments: &noAttachments},
+			expected: "hasAttachments eq false",
+		},
+		{
+			name:     "exclude from self",
+			opts:     &core.ListOptions{ExcludeFromSelf: true},
+			self:     "me@example.com",
+			expected: "from/emailAddress/address ne 'me@example.com'",
+		},
+		{
+			name:     "exclude from self escapes quotes",
+			opts:     &core.ListOptions{ExcludeFromSelf: true},
+			self:     "o'brien@example.com",
+			expected: "from/emailAddress/address ne 'o''brien@example.com'",
+		},
+		{
+			name:     "focused",
+			opts:     &core.ListOptions{Focused: &focused},
+			expected: "inferenceClassification eq 'focused'",
+		},
+		{
+			name:     "other",
+			opts:     &core.ListOptions{Focused: &other},
+			expected: "inferenceClassification eq 'other'",
+		},
+		{
+			name:     "with calendar invite",
+			opts:     &core.ListOptions{HasCalendarInvite: &hasAttachments},
+			expected: "singleValueExtendedProperties/Any(ep: ep/id eq 'String 0x001A' and ep/value eq 'IPM.Schedule.Meeting.Request')",
+		},
+		{
+			name:     "without calendar invite",
+			opts:     &core.ListOptions{HasCalendarInvite: &noAttachments},
+			expected: "singleValueExtendedProperties/Any(ep: ep/id eq 'String 0x001A' and ep/value ne 'IPM.Schedule.Meeting.Request')",
+		},
+		{
+			name:     "raw filter",
+			opts:     &core.ListOptions{Filter: "receivedDateTime ge 2024-03-01T00:00:00Z"},
+			expected: "(receivedDateTime ge 2024-03-01T00:00:00Z)",
+		},
+		{
+			name:     "raw filter combined",
+			opts:     &core.ListOptions{HasAttachments: &hasAttachments, Filter: "receivedDateTime ge 2024-03-01T00:00:00Z"},
+			expected: "hasAttachments eq true and (receivedDateTime ge 2024-03-01T00:00:00Z)",
+		},
+		{
+			name:     "combined",
+			opts:     &core.ListOptions{HasAttachments: &hasAttachments, ExcludeFromSelf: true},
+			self:     "me@example.com",
+			expected: "hasAttachments eq true and from/emailAddress/address ne 'me@example.com'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, buildFilter(tt.opts, tt.self))
+		})
+	}
+}
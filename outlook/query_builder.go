@@ -0,0 +1,133 @@
+package outlook
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/danielrivera/mailbridge-go/core"
+)
+
+// QueryBuilder helps construct Microsoft Graph message queries. Most conditions become
+// KQL terms for $search, which Build returns for core.ListOptions.Query. Date ranges
+// cannot be expressed in $search and become $filter clauses instead, which BuildFilter
+// returns for core.ListOptions.Filter. Graph does not allow $search together with
+// $filter, so a builder holding both kinds of conditions cannot be built.
+type QueryBuilder struct {
+	terms   []string
+	filters []string
+}
+
+// NewQueryBuilder creates a new QueryBuilder instance.
+func NewQueryBuilder() *QueryBuilder {
+	return &QueryBuilder{}
+}
+
+// Build returns the $search string to pass in core.ListOptions.Query. Graph expects
+// the whole $search value in double quotes, so the joined terms are wrapped and the
+// quotes and backslashes inside them escaped; an empty builder returns "".
+// It fails if the builder holds $filter conditions, such as Received.
+func (qb *QueryBuilder) Build() (string, error) {
+	if len(qb.filters) > 0 {
+		return "", qb.buildError("date ranges are $filter conditions; use BuildFilter and core.ListOptions.Filter")
+	}
+	if len(qb.terms) == 0 {
+		return "", nil
+	}
+	return `"` + escapeKQL(strings.Join(qb.terms, " AND ")) + `"`, nil
+}
+
+// BuildFilter returns the $filter expression to pass in core.ListOptions.Filter.
+// It fails if the builder holds $search terms.
+func (qb *QueryBuilder) BuildFilter() (string, error) {
+	if len(qb.terms) > 0 {
+		return "", qb.buildError("search terms need $search; use Build and core.ListOptions.Query")
+	}
+	return strings.Join(qb.filters, " and "), nil
+}
+
+// buildError explains why the requested form cannot be built. A builder holding both
+// kinds of conditions gets a dedicated message, since no form can express it.
+func (qb *QueryBuilder) buildError(message string) error {
+	if len(qb.terms) > 0 && len(qb.filters) > 0 {
+		message = "search terms cannot be combined with date ranges: Graph does not allow $search together with $filter, so split them into separate queries"
+	}
+	return &core.ConfigError{Field: "Query", Message: message}
+}
+
+// Reset clears all conditions.
+func (qb *QueryBuilder) Reset() *QueryBuilder {
+	qb.terms = nil
+	qb.filters = nil
+	return qb
+}
+
+// From adds a sender condition.
+func (qb *QueryBuilder) From(email string) *QueryBuilder {
+	return qb.term("from", email)
+}
+
+// To adds a recipient condition.
+func (qb *QueryBuilder) To(email string) *QueryBuilder {
+	return qb.term("to", email)
+}
+
+// Subject adds a subject condition.
+func (qb *QueryBuilder) Subject(subject string) *QueryBuilder {
+	return qb.term("subject", subject)
+}
+
+// Body adds a condition on the message body text.
+func (qb *QueryBuilder) Body(text string) *QueryBuilder {
+	return qb.term("body", text)
+}
+
+// HasAttachments filters for messages with attachments.
+func (qb *QueryBuilder) HasAttachments() *QueryBuilder {
+	qb.terms = append(qb.terms, "hasAttachments:true")
+	return qb
+}
+
+// IsRead filters for read messages.
+func (qb *QueryBuilder) IsRead() *QueryBuilder {
+	qb.terms = append(qb.terms, "isRead:true")
+	return qb
+}
+
+// IsUnread filters for unread messages.
+func (qb *QueryBuilder) IsUnread() *QueryBuilder {
+	qb.terms = append(qb.terms, "isRead:false")
+	return qb
+}
+
+// Received filters for messages received in [after, before]. A zero time leaves
+// that end of the range open. The range becomes a $filter on receivedDateTime.
+func (qb *QueryBuilder) Received(after, before time.Time) *QueryBuilder {
+	if !after.IsZero() {
+		qb.filters = append(qb.filters, "receivedDateTime ge "+after.UTC().Format(time.RFC3339))
+	}
+	if !before.IsZero() {
+		qb.filters = append(qb.filters, "receivedDateTime le "+before.UTC().Format(time.RFC3339))
+	}
+	return qb
+}
+
+// term adds a property:value KQL term, quoting values that are not a single word.
+func (qb *QueryBuilder) term(property, value string) *QueryBuilder {
+	qb.terms = append(qb.terms, fmt.Sprintf("%s:%s", property, quoteKQL(value)))
+	return qb
+}
+
+// quoteKQL returns value as a KQL token: bare when it is a single word, otherwise
+// wrapped in double quotes with embedded quotes and backslashes escaped.
+func quoteKQL(value string) string {
+	if value != "" && !strings.ContainsAny(value, " \t\"\\():") {
+		return value
+	}
+	return `"` + escapeKQL(value) + `"`
+}
+
+// escapeKQL escapes backslashes and double quotes for use inside a quoted KQL string.
+func escapeKQL(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value)
+}
@@ -0,0 +1,196 @@
+package outlook
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/danielrivera/mailbridge-go/core"
+)
+
+func TestQueryBuilder_Build(t *testing.T) {
+	tests := []struct {
+		name     string
+		builder  func() *QueryBuilder
+		expected string
+	}{
+		{
+			name:     "empty",
+			builder:  NewQueryBuilder,
+			expected: "",
+		},
+		{
+			name: "from",
+			builder: func() *QueryBuilder {
+				return NewQueryBuilder().From("boss@company.com")
+			},
+			expected: `"from:boss@company.com"`,
+		},
+		{
+			name: "to",
+			builder: func() *QueryBuilder {
+				return NewQueryBuilder().To("team@company.com")
+			},
+			expected: `"to:team@company.com"`,
+		},
+		{
+			name: "single word subject",
+			builder: func() *QueryBuilder {
+				return NewQueryBuilder().Subject("invoice")
+			},
+			expected: `"subject:invoice"`,
+		},
+		{
+			name: "multi-word subject is quoted",
+			builder: func() *QueryBuilder {
+				return NewQueryBuilder().Subject("quarterly report")
+			},
+			expected: `"subject:\"quarterly report\""`,
+		},
+		{
+			name: "embedded quotes are escaped",
+			builder: func() *QueryBuilder {
+				return NewQueryBuilder().Subject(`the "big" launch`)
+			},
+			expected: `"subject:\"the \\\"big\\\" launch\""`,
+		},
+		{
+			name: "backslashes are escaped",
+			builder: func() *QueryBuilder {
+				return NewQueryBuilder().Body(`C:\temp`)
+			},
+			expected: `"body:\"C:\\\\temp\""`,
+		},
+		{
+			name: "body",
+			builder: func() *QueryBuilder {
+				return NewQueryBuilder().Body("out of office")
+			},
+			expected: `"body:\"out of office\""`,
+		},
+		{
+			name: "flags",
+			builder: func() *QueryBuilder {
+				return NewQueryBuilder().HasAttachments().IsUnread()
+			},
+			expected: `"hasAttachments:true AND isRead:false"`,
+		},
+		{
+			name: "read",
+			builder: func() *QueryBuilder {
+				return NewQueryBuilder().IsRead()
+			},
+			expected: `"isRead:true"`,
+		},
+		{
+			name: "combined terms",
+			builder: func() *QueryBuilder {
+				return NewQueryBuilder().From("john@example.com").Subject("budget review").HasAttachments()
+			},
+			expected: `"from:john@example.com AND subject:\"budget review\" AND hasAttachments:true"`,
+		},
+		{
+			name: "multi-word value with a second term",
+			builder: func() *QueryBuilder {
+				return NewQueryBuilder().Subject("quarterly report").IsUnread()
+			},
+			expected: `"subject:\"quarterly report\" AND isRead:false"`,
+		},
+		{
+			name: "reset",
+			builder: func() *QueryBuilder {
+				return NewQueryBuilder().From("old@example.com").Received(time.Now(), time.Time{}).Reset().To("new@example.com")
+			},
+			expected: `"to:new@example.com"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := tt.builder().Build()
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, query)
+		})
+	}
+}
+
+func TestQueryBuilder_BuildFilter(t *testing.T) {
+	after := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	before := time.Date(2024, 3, 31, 23, 59, 59, 0, time.FixedZone("CET", 3600))
+
+	tests := []struct {
+		name     string
+		builder  *QueryBuilder
+		expected string
+	}{
+		{
+			name:     "range",
+			builder:  NewQueryBuilder().Received(after, before),
+			expected: "receivedDateTime ge 2024-03-01T00:00:00Z and receivedDateTime le 2024-03-31T22:59:59Z",
+		},
+		{
+			name:     "open end",
+			builder:  NewQueryBuilder().Received(after, time.Time{}),
+			expected: "receivedDateTime ge 2024-03-01T00:00:00Z",
+		},
+		{
+			name:     "open start",
+			builder:  NewQueryBuilder().Received(time.Time{}, before),
+			expected: "receivedDateTime le 2024-03-31T22:59:59Z",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := tt.builder.BuildFilter()
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, filter)
+		})
+	}
+}
+
+func TestQueryBuilder_Errors(t *testing.T) {
+	received := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		build    func() (string, error)
+		contains string
+	}{
+		{
+			name:     "search with date range",
+			build:    NewQueryBuilder().Subject("report").Received(received, time.Time{}).Build,
+			contains: "Graph does not allow $search together with $filter",
+		},
+		{
+			name:     "filter with search terms",
+			build:    NewQueryBuilder().Received(received, time.Time{}).IsUnread().BuildFilter,
+			contains: "Graph does not allow $search together with $filter",
+		},
+		{
+			name:     "date range built as search",
+			build:    NewQueryBuilder().Received(received, time.Time{}).Build,
+			contains: "use BuildFilter",
+		},
+		{
+			name:     "search terms built as filter",
+			build:    NewQueryBuilder().From("john@example.com").BuildFilter,
+			contains: "use Build",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.build()
+
+			assert.Empty(t, result)
+			var configErr *core.ConfigError
+			require.ErrorAs(t, err, &configErr)
+			assert.Contains(t, configErr.Message, tt.contains)
+		})
+	}
+}
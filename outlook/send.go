@@ -0,0 +1,534 @@
+package outlook
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"html"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/microsoftgraph/msgraph-sdk-go/users"
+
+	"github.com/danielrivera/mailbridge-go/core"
+	"github.com/danielrivera/mailbridge-go/outlook/internal"
+)
+
+// SendLimits returns Outlook's size limits for messages sent by SendMessage.
+// Attachments are sent inline and base64-encoded, and Graph rejects requests over
+// 4 MB, so a single encoded attachment is limited to 3 MB and the body plus the
+// encoded attachments to 4 MB. Encoding grows an attachment by a third.
+func SendLimits() core.SendLimits {
+	return core.SendLimits{
+		MaxAttachmentSize: 3 * 1024 * 1024,
+		MaxTotalSize:      4 * 1024 * 1024,
+		Base64Encoded:     true,
+	}
+}
+
+// SendMessage sends an email.
+// Graph's sendMail does not return the sent message, so the response ID is empty
+// unless opts.ReturnSentID is set. In that case the message is created as a draft,
+// sent, and then looked up in Sent Items by its internetMessageId; this costs extra
+// requests. If the sent copy does not appear within the configured not-found retries,
+// the message has still been sent and the response carries an empty ID.
+//
+// Graph only sends custom headers whose names start with "X-", so draft.Headers and
+// opts.CustomHeaders may not contain other names; a core.ConfigError naming the
+// header is returned before any request is made.
+//
+// When opts sets an IdempotencyKey, the message's internetMessageId is derived from
+// the key, and Sent Items is checked for that ID first: if a message with it was
+// already sent, it is not sent again and the response describes the earlier send.
+func (c *Client) SendMessage(ctx context.Context, draft *core.Draft, opts *core.SendOptions) (*core.SendResponse, error) {
+	if err := core.ValidateDraft(draft, SendLimits()); err != nil {
+		return nil, fmt.Errorf("invalid draft: %w", err)
+	}
+	if err := validateHeaders("Headers", draft.Headers); err != nil {
+		return nil, fmt.Errorf("invalid draft: %w", err)
+	}
+	if err := core.ValidateSendOptions(opts); err != nil {
+		return nil, fmt.Errorf("invalid send options: %w", err)
+	}
+	if opts != nil {
+		if err := validateHeaders("CustomHeaders", opts.CustomHeaders); err != nil {
+			return nil, fmt.Errorf("invalid send options: %w", err)
+		}
+	}
+
+	service, err := c.getService()
+	if err != nil {
+		return nil, err
+	}
+
+	var idempotentID string
+	if opts != nil && opts.IdempotencyKey != "" {
+		idempotentID = messageIDFromKey(opts.IdempotencyKey)
+		previous, err := c.findSentMessage(ctx, idempotentID, 0)
+		if err != nil {
+			return nil, err
+		}
+		if previous != nil {
+			return &core.SendResponse{
+				ID:       derefString(previous.GetId()),
+				ThreadID: derefString(previous.GetConversationId()),
+			}, nil
+		}
+	}
+
+	message := buildMessage(draft, opts)
+	if idempotentID != "" {
+		message.SetInternetMessageId(&idempotentID)
+	}
+	messagesService := service.GetMeService().GetMessagesService()
+	if err := attachMessages(ctx, messagesService, message, draft.AttachedMessages); err != nil {
+		return nil, err
+	}
+
+	if opts == nil || !opts.ReturnSentID {
+		if err := messagesService.SendMail(ctx, message); err != nil {
+			return nil, handleODataError(fmt.Errorf("failed to send message: %w", err))
+		}
+		return &core.SendResponse{}, nil
+	}
+
+	created, err := messagesService.CreateDraft(ctx, message)
+	if err != nil {
+		return nil, handleODataError(fmt.Errorf("failed to create draft: %w", err))
+	}
+
+	if err := messagesService.SendDraft(ctx, derefString(created.GetId())); err != nil {
+		return nil, handleODataError(fmt.Errorf("failed to send message: %w", err))
+	}
+
+	response := &core.SendResponse{ThreadID: derefString(created.GetConversationId())}
+	internetMessageID := derefString(created.GetInternetMessageId())
+	if internetMessageID == "" {
+		internetMessageID = idempotentID
+	}
+	if internetMessageID == "" {
+		return response, nil
+	}
+
+	sent, err := c.findSentMessage(ctx, internetMessageID, c.config.notFoundRetries())
+	if err != nil {
+		return nil, err
+	}
+	if sent != nil {
+		response.ID = derefString(sent.GetId())
+		if conversationID := derefString(sent.GetConversationId()); conversationID != "" {
+			response.ThreadID = conversationID
+		}
+	}
+
+	return response, nil
+}
+
+// findSentMessage looks up a sent message in Sent Items by its internetMessageId.
+// Sending is asynchronous, so the lookup is retried up to retries more times with the
+// not-found retry delay. It returns nil without an error if the message has not
+// appeared yet.
+func (c *Client) findSentMessage(ctx context.Context, internetMessageID string, retries int) (models.Messageable, error) {
+	service, err := c.getService()
+	if err != nil {
+		return nil, err
+	}
+
+	filter := fmt.Sprintf("internetMessageId eq '%s'", escapeODataString(internetMessageID))
+	top := int32(1)
+	config := &users.ItemMailFoldersItemMessagesRequestBuilderGetRequestConfiguration{
+		QueryParameters: &users.ItemMailFoldersItemMessagesRequestBuilderGetQueryParameters{
+			Filter: &filter,
+			Top:    &top,
+			Select: []string{"id", "conversationId"},
+		},
+	}
+
+	foldersService := service.GetMeService().GetMailFoldersService()
+	delay := c.config.notFoundRetryDelay()
+
+	for attempt := 0; ; attempt++ {
+		result, err := foldersService.GetMessages(ctx, FolderSentItems, config)
+		if err != nil {
+			return nil, handleODataError(fmt.Errorf("failed to look up sent message: %w", err))
+		}
+		if messages := result.GetValue(); len(messages) > 0 {
+			return messages[0], nil
+		}
+		if attempt >= retries {
+			return nil, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay * time.Duration(attempt+1)):
+		}
+	}
+}
+
+// messageIDFromKey derives a deterministic internetMessageId from an idempotency key.
+// It matches the Message-ID the Gmail provider derives from the same key.
+func messageIDFromKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return fmt.Sprintf("<%x@mailbridge.local>", sum[:16])
+}
+
+// validateHeaders checks that every header in headers can be sent as an internet
+// message header. Graph only accepts custom headers whose names start with "X-" and
+// rejects the whole message otherwise, so other names are reported as a
+// core.ConfigError for field before anything is sent.
+func validateHeaders(field string, headers map[string]string) error {
+	for _, name := range slices.Sorted(maps.Keys(headers)) {
+		if len(name) < 2 || !strings.EqualFold(name[:2], "X-") {
+			return &core.ConfigError{
+				Field:   field,
+				Message: fmt.Sprintf("header %q is not supported: Outlook only sends custom headers starting with X-", name),
+			}
+		}
+	}
+	return nil
+}
+
+// buildMessage converts a core.Draft into a Graph message.
+// The HTML body is preferred over plain text when both are set.
+func buildMessage(draft *core.Draft, opts *core.SendOptions) models.Messageable {
+	message := models.NewMessage()
+
+	subject := draft.Subject
+	message.SetSubject(&subject)
+
+	body := models.NewItemBody()
+	content := draft.Body.Text
+	contentType := models.TEXT_BODYTYPE
+	if draft.Body.HTML != "" {
+		content = draft.Body.HTML
+		contentType = models.HTML_BODYTYPE
+	}
+	body.SetContent(&content)
+	body.SetContentType(&contentType)
+	message.SetBody(body)
+
+	message.SetToRecipients(buildRecipients(draft.To))
+	if len(draft.Cc) > 0 {
+		message.SetCcRecipients(buildRecipients(draft.Cc))
+	}
+	if len(draft.Bcc) > 0 {
+		message.SetBccRecipients(buildRecipients(draft.Bcc))
+	}
+	if len(draft.ReplyTo) > 0 {
+		message.SetReplyTo(buildRecipients(draft.ReplyTo))
+	}
+
+	var headers []models.InternetMessageHeaderable
+	addHeaders := func(values map[string]string) {
+		for name, value := range values {
+			header := models.NewInternetMessageHeader()
+			header.SetName(&name)
+			header.SetValue(&value)
+			headers = append(headers, header)
+		}
+	}
+	addHeaders(draft.Headers)
+	if opts != nil {
+		addHeaders(opts.CustomHeaders)
+	}
+	if len(headers) > 0 {
+		message.SetInternetMessageHeaders(headers)
+	}
+
+	if len(draft.Attachments) > 0 {
+		attachments := make([]models.Attachmentable, 0, len(draft.Attachments))
+		for _, a := range draft.Attachments {
+			attachment := models.NewFileAttachment()
+			name := a.Filename
+			mimeType := a.MimeType
+			attachment.SetName(&name)
+			attachment.SetContentType(&mimeType)
+			attachment.SetContentBytes(a.Data)
+			attachments = append(attachments, attachment)
+		}
+		message.SetAttachments(attachments)
+	}
+
+	return message
+}
+
+// attachMessages fetches the messages named in messageIDs and adds each to message as
+// an item attachment. The embedded copy carries the original's addressing, dates and
+// body; the original's own attachments are not included.
+func attachMessages(ctx context.Context, messagesService internal.MessagesService, message models.Messageable, messageIDs []string) error {
+	if len(messageIDs) == 0 {
+		return nil
+	}
+
+	attachments := message.GetAttachments()
+	for _, messageID := range messageIDs {
+		if messageID == "" {
+			return fmt.Errorf("invalid draft: attached message ID cannot be empty")
+		}
+		original, err := messagesService.Get(ctx, messageID)
+		if err != nil {
+			return handleODataError(fmt.Errorf("failed to get attached message %s: %w", messageID, err))
+		}
+
+		name := derefString(original.GetSubject())
+		if name == "" {
+			name = "message"
+		}
+		attachment := models.NewItemAttachment()
+		attachment.SetName(&name)
+		attachment.SetItem(embeddedMessage(original))
+		attachments = append(attachments, attachment)
+	}
+	message.SetAttachments(attachments)
+	return nil
+}
+
+// embeddedMessage copies the content of a fetched message into a new message for an
+// item attachment, leaving out read-only properties such as its ID and folder.
+func embeddedMessage(original models.Messageable) models.Messageable {
+	embedded := models.NewMessage()
+	embedded.SetSubject(original.GetSubject())
+	embedded.SetBody(original.GetBody())
+	embedded.SetFrom(original.GetFrom())
+	embedded.SetSender(original.GetSender())
+	embedded.SetToRecipients(original.GetToRecipients())
+	embedded.SetCcRecipients(original.GetCcRecipients())
+	embedded.SetReplyTo(original.GetReplyTo())
+	embedded.SetSentDateTime(original.GetSentDateTime())
+	embedded.SetReceivedDateTime(original.GetReceivedDateTime())
+	embedded.SetInternetMessageId(original.GetInternetMessageId())
+	return embedded
+}
+
+// buildRecipients converts core addresses into Graph recipients.
+func buildRecipients(addresses []core.EmailAddress) []models.Recipientable {
+	recipients := make([]models.Recipientable, 0, len(addresses))
+	for _, addr := range addresses {
+		emailAddress := models.NewEmailAddress()
+		address := addr.Email
+		emailAddress.SetAddress(&address)
+		if addr.Name != "" {
+			name := addr.Name
+			emailAddress.SetName(&name)
+		}
+		recipient := models.NewRecipient()
+		recipient.SetEmailAddress(emailAddress)
+		recipients = append(recipients, recipient)
+	}
+	return recipients
+}
+
+// CreateReplyDraft creates a reply to a message in the Drafts folder.
+// Graph pre-populates the draft with the original sender as recipient, a "RE:" subject
+// and the quoted original body. It returns the draft's message ID, which can be edited
+// and sent, and the draft's content.
+func (c *Client) CreateReplyDraft(ctx context.Context, messageID string) (string, *core.Draft, error) {
+	service, err := c.getService()
+	if err != nil {
+		return "", nil, err
+	}
+
+	message, err := service.GetMeService().GetMessagesService().CreateReply(ctx, messageID)
+	if err != nil {
+		return "", nil, handleODataError(fmt.Errorf("failed to create reply draft for message %s: %w", messageID, err))
+	}
+
+	return derefString(message.GetId()), c.convertDraft(message), nil
+}
+
+// ReplyToMessage sends a reply to a message. The reply is created with Graph's createReply,
+// which carries over the original conversationId and conversationIndex (and sets
+// In-Reply-To/References), so it threads in the recipients' Outlook; a message sent with
+// SendMessage would start a new conversation. The draft's body is placed above the quoted
+// original. Recipients default to the original sender; To, Cc, Bcc, ReplyTo and Subject
+// override the defaults when set. Attachments and custom headers are not supported.
+// If the draft cannot be updated or sent, it is deleted again so it does not stay in
+// the Drafts folder.
+// The response's ThreadID is the conversation ID of the original message.
+func (c *Client) ReplyToMessage(ctx context.Context, messageID string, draft *core.Draft) (*core.SendResponse, error) {
+	if err := validateReply(draft); err != nil {
+		return nil, fmt.Errorf("invalid reply: %w", err)
+	}
+
+	service, err := c.getService()
+	if err != nil {
+		return nil, err
+	}
+
+	messagesService := service.GetMeService().GetMessagesService()
+	reply, err := messagesService.CreateReply(ctx, messageID)
+	if err != nil {
+		return nil, handleODataError(fmt.Errorf("failed to create reply to message %s: %w", messageID, err))
+	}
+	replyID := derefString(reply.GetId())
+
+	// Only patch content fields; the conversation properties set by createReply are kept
+	patch := models.NewMessage()
+	patch.SetBody(replyBody(reply.GetBody(), draft.Body))
+	if draft.Subject != "" {
+		subject := draft.Subject
+		patch.SetSubject(&subject)
+	}
+	if len(draft.To) > 0 {
+		patch.SetToRecipients(buildRecipients(draft.To))
+	}
+	if len(draft.Cc) > 0 {
+		patch.SetCcRecipients(buildRecipients(draft.Cc))
+	}
+	if len(draft.Bcc) > 0 {
+		patch.SetBccRecipients(buildRecipients(draft.Bcc))
+	}
+	if len(draft.ReplyTo) > 0 {
+		patch.SetReplyTo(buildRecipients(draft.ReplyTo))
+	}
+
+	// discard removes the reply draft after a failure so it does not linger in Drafts
+	discard := func(cause error) error {
+		if err := messagesService.Delete(ctx, replyID); err != nil {
+			return errors.Join(cause, fmt.Errorf("failed to remove reply draft %s: %w", replyID, err))
+		}
+		return cause
+	}
+
+	if _, err := messagesService.Update(ctx, replyID, patch); err != nil {
+		return nil, discard(handleODataError(fmt.Errorf("failed to update reply draft %s: %w", replyID, err)))
+	}
+
+	if err := messagesService.SendDraft(ctx, replyID); err != nil {
+		return nil, discard(handleODataError(fmt.Errorf("failed to send reply to message %s: %w", messageID, err)))
+	}
+
+	return &core.SendResponse{ThreadID: derefString(reply.GetConversationId())}, nil
+}
+
+// ForwardMessage forwards a message to the given recipients with Graph's forward action,
+// which quotes the original and always keeps its attachments, so opts.IncludeAttachments
+// has no effect. The draft is optional: its body (HTML, or else text) becomes the comment
+// placed above the original. Other draft fields are not supported by the forward action.
+func (c *Client) ForwardMessage(ctx context.Context, messageID string, to []core.EmailAddress, draft *core.Draft, _ *core.ForwardOptions) (*core.SendResponse, error) {
+	if len(to) == 0 {
+		return nil, fmt.Errorf("invalid forward: at least one recipient is required")
+	}
+	if draft != nil && (len(draft.Attachments) > 0 || len(draft.AttachedMessages) > 0) {
+		return nil, fmt.Errorf("invalid forward: attachments are not supported in forwards")
+	}
+
+	service, err := c.getService()
+	if err != nil {
+		return nil, err
+	}
+
+	comment := ""
+	if draft != nil {
+		comment = draft.Body.HTML
+		if comment == "" {
+			comment = draft.Body.Text
+		}
+	}
+
+	if err := service.GetMeService().GetMessagesService().Forward(ctx, messageID, comment, buildRecipients(to)); err != nil {
+		return nil, handleODataError(fmt.Errorf("failed to forward message %s: %w", messageID, err))
+	}
+
+	return &core.SendResponse{}, nil
+}
+
+// validateReply checks a reply draft: a body is required, recipients are optional
+// but must be valid, and attachments and custom headers are not supported.
+func validateReply(draft *core.Draft) error {
+	if draft == nil {
+		return fmt.Errorf("draft is nil")
+	}
+	if draft.Body.Text == "" && draft.Body.HTML == "" {
+		return fmt.Errorf("email body required (text or html)")
+	}
+	if err := core.ValidateAddresses(draft.To, draft.Cc, draft.Bcc, draft.ReplyTo); err != nil {
+		return err
+	}
+	if len(draft.Attachments) > 0 || len(draft.AttachedMessages) > 0 {
+		return fmt.Errorf("attachments are not supported in replies")
+	}
+	// Graph only sets internet message headers when a message is created, and
+	// createReply creates the draft without them
+	if len(draft.Headers) > 0 {
+		return fmt.Errorf("custom headers are not supported in replies")
+	}
+	return nil
+}
+
+// replyBody places the reply content above the quoted original body of a reply draft.
+// A text reply is HTML-escaped when the draft body is HTML.
+func replyBody(quoted models.ItemBodyable, reply core.EmailBody) models.ItemBodyable {
+	var quotedContent string
+	quotedType := models.HTML_BODYTYPE
+	if quoted != nil {
+		quotedContent = derefString(quoted.GetContent())
+		if contentType := quoted.GetContentType(); contentType != nil {
+			quotedType = *contentType
+		}
+	}
+
+	var content string
+	if quotedType == models.TEXT_BODYTYPE {
+		text := reply.Text
+		if text == "" {
+			text = reply.HTML
+		}
+		content = text + "\r\n\r\n" + quotedContent
+	} else {
+		replyHTML := reply.HTML
+		if replyHTML == "" {
+			replyHTML = "<p>" + strings.ReplaceAll(html.EscapeString(reply.Text), "\n", "<br>") + "</p>"
+		}
+		content = replyHTML + quotedContent
+	}
+
+	body := models.NewItemBody()
+	body.SetContent(&content)
+	body.SetContentType(&quotedType)
+	return body
+}
+
+// CreateForwardDraft creates a forward of a message in the Drafts folder.
+// Graph pre-populates the draft with a "FW:" subject, the quoted original body and the
+// original attachments; recipients are left empty. It returns the draft's message ID
+// and the draft's content.
+func (c *Client) CreateForwardDraft(ctx context.Context, messageID string) (string, *core.Draft, error) {
+	service, err := c.getService()
+	if err != nil {
+		return "", nil, err
+	}
+
+	message, err := service.GetMeService().GetMessagesService().CreateForward(ctx, messageID)
+	if err != nil {
+		return "", nil, handleODataError(fmt.Errorf("failed to create forward draft for message %s: %w", messageID, err))
+	}
+
+	return derefString(message.GetId()), c.convertDraft(message), nil
+}
+
+// convertDraft converts a Graph draft message to a core.Draft.
+func (c *Client) convertDraft(msg models.Messageable) *core.Draft {
+	email := c.convertMessage(msg)
+	draft := &core.Draft{
+		To:      email.To,
+		Cc:      email.Cc,
+		Bcc:     email.Bcc,
+		Subject: email.Subject,
+		Body:    email.Body,
+	}
+
+	for _, recipient := range msg.GetReplyTo() {
+		if address, ok := convertRecipient(recipient); ok {
+			draft.ReplyTo = append(draft.ReplyTo, address)
+		}
+	}
+
+	return draft
+}
@@ -0,0 +1,595 @@
+package outlook
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/danielrivera/mailbridge-go/core"
+	outlooktest "github.com/danielrivera/mailbridge-go/outlook/testing"
+	"github.com/microsoftgraph/msgraph-sdk-go/models"
+	"github.com/microsoftgraph/msgraph-sdk-go/users"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// Helper to create a test client for send operations
+func createTestClientForSend() (*Client, *outlooktest.MockMessagesService, *outlooktest.MockMailFoldersService) {
+	client := &Client{
+		config: &Config{
+			ClientID:           "test-client-id",
+			ClientSecret:       "test-secret",
+			TenantID:           "consumers",
+			RedirectURL:        "http://localhost:8080/callback",
+			NotFoundRetryDelay: time.Millisecond,
+		},
+	}
+
+	mockGraphService := &outlooktest.MockGraphService{}
+	mockMeService := &outlooktest.MockMeService{}
+	mockMessagesService := &outlooktest.MockMessagesService{}
+	mockFoldersService := &outlooktest.MockMailFoldersService{}
+
+	mockGraphService.On("GetMeService").Return(mockMeService)
+	mockMeService.On("GetMessagesService").Return(mockMessagesService)
+	mockMeService.On("GetMailFoldersService").Return(mockFoldersService)
+
+	client.SetService(mockGraphService)
+
+	return client, mockMessagesService, mockFoldersService
+}
+
+func newTestDraft() *core.Draft {
+	return &core.Draft{
+		To:      []core.EmailAddress{{Email: "recipient@example.com", Name: "Recipient"}},
+		Subject: "Hello",
+		Body:    core.EmailBody{Text: "plain", HTML: "<p>html</p>"},
+		Headers: map[string]string{"X-Test": "1"},
+	}
+}
+
+func TestClient_SendMessage(t *testing.T) {
+	client, mockMessagesService, _ := createTestClientForSend()
+	ctx := context.Background()
+
+	var sent models.Messageable
+	mockMessagesService.On("SendMail", ctx, mock.Anything).
+		Run(func(args mock.Arguments) {
+			sent = args.Get(1).(models.Messageable)
+		}).
+		Return(nil)
+
+	resp, err := client.SendMessage(ctx, newTestDraft(), nil)
+
+	require.NoError(t, err)
+	assert.Empty(t, resp.ID)
+	require.NotNil(t, sent)
+	assert.Equal(t, "Hello", *sent.GetSubject())
+	assert.Equal(t, "<p>html</p>", *sent.GetBody().GetContent())
+	assert.Equal(t, models.HTML_BODYTYPE, *sent.GetBody().GetContentType())
+	require.Len(t, sent.GetToRecipients(), 1)
+	assert.Equal(t, "recipient@example.com", *sent.GetToRecipients()[0].GetEmailAddress().GetAddress())
+	require.Len(t, sent.GetInternetMessageHeaders(), 1)
+	assert.Equal(t, "X-Test", *sent.GetInternetMessageHeaders()[0].GetName())
+	mockMessagesService.AssertNotCalled(t, "CreateDraft", mock.Anything, mock.Anything)
+}
+
+func TestClient_SendMessage_ReturnSentID(t *testing.T) {
+	client, mockMessagesService, mockFoldersService := createTestClientForSend()
+	ctx := context.Background()
+
+	draftID := "draft-1"
+	conversationID := "conv-1"
+	internetMessageID := "<abc@example.com>"
+	created := models.NewMessage()
+	created.SetId(&draftID)
+	created.SetConversationId(&conversationID)
+	created.SetInternetMessageId(&internetMessageID)
+
+	mockMessagesService.On("CreateDraft", ctx, mock.Anything).Return(created, nil)
+	mockMessagesService.On("SendDraft", ctx, "draft-1").Return(nil)
+
+	// The sent copy appears on the second lookup
+	sentID := "sent-1"
+	sentMessage := models.NewMessage()
+	sentMessage.SetId(&sentID)
+	sentMessage.SetConversationId(&conversationID)
+	found := models.NewMessageCollectionResponse()
+	found.SetValue([]models.Messageable{sentMessage})
+
+	var capturedConfig *users.ItemMailFoldersItemMessagesRequestBuilderGetRequestConfiguration
+	mockFoldersService.On("GetMessages", ctx, FolderSentItems, mock.Anything).
+		Return(models.NewMessageCollectionResponse(), nil).Once()
+	mockFoldersService.On("GetMessages", ctx, FolderSentItems, mock.Anything).
+		Run(func(args mock.Arguments) {
+			capturedConfig = args.Get(2).(*users.ItemMailFoldersItemMessagesRequestBuilderGetRequestConfiguration)
+		}).
+		Return(found, nil).Once()
+
+	resp, err := client.SendMessage(ctx, newTestDraft(), &core.SendOptions{ReturnSentID: true})
+
+	require.NoError(t, err)
+	assert.Equal(t, "sent-1", resp.ID)
+	assert.Equal(t, "conv-1", resp.ThreadID)
+	require.NotNil(t, capturedConfig)
+	assert.Equal(t, "internetMessageId eq '<abc@example.com>'", *capturedConfig.QueryParameters.Filter)
+	mockMessagesService.AssertNotCalled(t, "SendMail", mock.Anything, mock.Anything)
+	mockFoldersService.AssertNumberOfCalls(t, "GetMessages", 2)
+}
+
+func TestClient_SendMessage_ReturnSentID_NotFound(t *testing.T) {
+	client, mockMessagesService, mockFoldersService := createTestClientForSend()
+	client.config.NotFoundRetries = 1
+	ctx := context.Background()
+
+	draftID := "draft-1"
+	conversationID := "conv-1"
+	internetMessageID := "<abc@example.com>"
+	created := models.NewMessage()
+	created.SetId(&draftID)
+	created.SetConversationId(&conversationID)
+	created.SetInternetMessageId(&internetMessageID)
+
+	mockMessagesService.On("CreateDraft", ctx, mock.Anything).Return(created, nil)
+	mockMessagesService.On("SendDraft", ctx, "draft-1").Return(nil)
+	mockFoldersService.On("GetMessages", ctx, FolderSentItems, mock.Anything).
+		Return(models.NewMessageCollectionResponse(), nil)
+
+	resp, err := client.SendMessage(ctx, newTestDraft(), &core.SendOptions{ReturnSentID: true})
+
+	require.NoError(t, err, "the message was sent even though the sent copy was not found")
+	assert.Empty(t, resp.ID)
+	assert.Equal(t, "conv-1", resp.ThreadID)
+	mockFoldersService.AssertNumberOfCalls(t, "GetMessages", 2)
+}
+
+func TestClient_SendMessage_IdempotencyKey(t *testing.T) {
+	client, mockMessagesService, mockFoldersService := createTestClientForSend()
+	ctx := context.Background()
+
+	var lookup *users.ItemMailFoldersItemMessagesRequestBuilderGetRequestConfiguration
+	mockFoldersService.On("GetMessages", ctx, FolderSentItems, mock.Anything).
+		Run(func(args mock.Arguments) {
+			lookup = args.Get(2).(*users.ItemMailFoldersItemMessagesRequestBuilderGetRequestConfiguration)
+		}).
+		Return(models.NewMessageCollectionResponse(), nil)
+
+	var sent models.Messageable
+	mockMessagesService.On("SendMail", ctx, mock.Anything).
+		Run(func(args mock.Arguments) {
+			sent = args.Get(1).(models.Messageable)
+		}).
+		Return(nil)
+
+	_, err := client.SendMessage(ctx, newTestDraft(), &core.SendOptions{IdempotencyKey: "order-42"})
+
+	require.NoError(t, err)
+	expectedID := messageIDFromKey("order-42")
+	require.NotNil(t, sent)
+	assert.Equal(t, expectedID, *sent.GetInternetMessageId())
+	require.NotNil(t, lookup)
+	assert.Equal(t, "internetMessageId eq '"+expectedID+"'", *lookup.QueryParameters.Filter)
+	mockFoldersService.AssertNumberOfCalls(t, "GetMessages", 1)
+}
+
+func TestClient_SendMessage_IdempotencyKey_AlreadySent(t *testing.T) {
+	client, mockMessagesService, mockFoldersService := createTestClientForSend()
+	ctx := context.Background()
+
+	sentID := "sent-1"
+	conversationID := "conv-1"
+	previous := models.NewMessage()
+	previous.SetId(&sentID)
+	previous.SetConversationId(&conversationID)
+	found := models.NewMessageCollectionResponse()
+	found.SetValue([]models.Messageable{previous})
+	mockFoldersService.On("GetMessages", ctx, FolderSentItems, mock.Anything).Return(found, nil)
+
+	resp, err := client.SendMessage(ctx, newTestDraft(), &core.SendOptions{IdempotencyKey: "order-42"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "sent-1", resp.ID)
+	assert.Equal(t, "conv-1", resp.ThreadID)
+	mockMessagesService.AssertNotCalled(t, "SendMail", mock.Anything, mock.Anything)
+	mockMessagesService.AssertNotCalled(t, "CreateDraft", mock.Anything, mock.Anything)
+}
+
+func TestMessageIDFromKey(t *testing.T) {
+	assert.Equal(t, messageIDFromKey("order-42"), messageIDFromKey("order-42"))
+	assert.NotEqual(t, messageIDFromKey("order-42"), messageIDFromKey("order-43"))
+	assert.Regexp(t, `^<[0-9a-f]{32}@mailbridge\.local>$`, messageIDFromKey("order-42"))
+}
+
+func TestClient_SendMessage_AttachedMessages(t *testing.T) {
+	client, mockMessagesService, _ := createTestClientForSend()
+	ctx := context.Background()
+
+	original := models.NewMessage()
+	originalID := "orig-1"
+	parentFolderID := "inbox-id"
+	subject := "Q3 report"
+	original.SetId(&originalID)
+	original.SetParentFolderId(&parentFolderID)
+	original.SetSubject(&subject)
+	original.SetToRecipients(buildRecipients([]core.EmailAddress{{Email: "me@example.com"}}))
+	mockMessagesService.On("Get", ctx, "orig-1").Return(original, nil)
+
+	var sent models.Messageable
+	mockMessagesService.On("SendMail", ctx, mock.Anything).
+		Run(func(args mock.Arguments) {
+			sent = args.Get(1).(models.Messageable)
+		}).
+		Return(nil)
+
+	draft := newTestDraft()
+	draft.Attachments = []core.Attachment{{Filename: "notes.txt", MimeType: "text/plain", Data: []byte("notes")}}
+	draft.AttachedMessages = []string{"orig-1"}
+	_, err := client.SendMessage(ctx, draft, nil)
+
+	require.NoError(t, err)
+	require.Len(t, sent.GetAttachments(), 2)
+	assert.IsType(t, models.NewFileAttachment(), sent.GetAttachments()[0])
+
+	item, ok := sent.GetAttachments()[1].(models.ItemAttachmentable)
+	require.True(t, ok, "the original is attached as an itemAttachment")
+	assert.Equal(t, "Q3 report", *item.GetName())
+	embedded, ok := item.GetItem().(models.Messageable)
+	require.True(t, ok)
+	assert.Equal(t, "Q3 report", *embedded.GetSubject())
+	assert.Equal(t, "me@example.com", *embedded.GetToRecipients()[0].GetEmailAddress().GetAddress())
+	assert.Nil(t, embedded.GetId(), "read-only properties are not copied")
+	assert.Nil(t, embedded.GetParentFolderId())
+}
+
+func TestClient_SendMessage_AttachedMessageErrors(t *testing.T) {
+	client, mockMessagesService, _ := createTestClientForSend()
+	ctx := context.Background()
+
+	mockMessagesService.On("Get", ctx, "gone").Return(nil, errors.New("not found"))
+	draft := newTestDraft()
+	draft.AttachedMessages = []string{"gone"}
+	_, err := client.SendMessage(ctx, draft, nil)
+	assert.ErrorContains(t, err, "failed to get attached message gone")
+
+	_, err = client.ReplyToMessage(ctx, "msg-1", &core.Draft{Body: core.EmailBody{Text: "hi"}, AttachedMessages: []string{"orig-1"}})
+	assert.ErrorContains(t, err, "attachments are not supported in replies")
+
+	_, err = client.ForwardMessage(ctx, "msg-1", []core.EmailAddress{{Email: "bob@example.com"}}, &core.Draft{AttachedMessages: []string{"orig-1"}}, nil)
+	assert.ErrorContains(t, err, "attachments are not supported in forwards")
+
+	mockMessagesService.AssertNotCalled(t, "SendMail", mock.Anything, mock.Anything)
+}
+
+func TestClient_SendMessage_InvalidDraft(t *testing.T) {
+	client, mockMessagesService, _ := createTestClientForSend()
+
+	_, err := client.SendMessage(context.Background(), &core.Draft{Subject: "No recipients"}, nil)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "recipient")
+	mockMessagesService.AssertNotCalled(t, "SendMail", mock.Anything, mock.Anything)
+}
+
+func TestClient_SendMessage_UnsupportedHeaders(t *testing.T) {
+	tests := []struct {
+		name  string
+		draft func(*core.Draft)
+		opts  *core.SendOptions
+		field string
+	}{
+		{
+			name:  "draft header",
+			draft: func(d *core.Draft) { d.Headers["List-Unsubscribe"] = "<mailto:unsubscribe@example.com>" },
+			field: "Headers",
+		},
+		{
+			name:  "custom header with ReturnSentID",
+			draft: func(*core.Draft) {},
+			opts:  &core.SendOptions{ReturnSentID: true, CustomHeaders: map[string]string{"Auto-Submitted": "auto-generated"}},
+			field: "CustomHeaders",
+		},
+		{
+			name:  "custom header with IdempotencyKey",
+			draft: func(*core.Draft) {},
+			opts:  &core.SendOptions{IdempotencyKey: "key-1", CustomHeaders: map[string]string{"X-Ok": "1", "Precedence": "bulk"}},
+			field: "CustomHeaders",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, mockMessagesService, mockFoldersService := createTestClientForSend()
+			draft := newTestDraft()
+			tt.draft(draft)
+
+			_, err := client.SendMessage(context.Background(), draft, tt.opts)
+
+			var configErr *core.ConfigError
+			require.ErrorAs(t, err, &configErr)
+			assert.Equal(t, tt.field, configErr.Field)
+			assert.NotContains(t, configErr.Message, "X-Ok")
+			assert.Empty(t, mockMessagesService.Calls)
+			assert.Empty(t, mockFoldersService.Calls)
+		})
+	}
+}
+
+func TestClient_SendMessage_LowercaseCustomHeader(t *testing.T) {
+	client, mockMessagesService, _ := createTestClientForSend()
+	ctx := context.Background()
+
+	mockMessagesService.On("SendMail", ctx, mock.Anything).Return(nil)
+
+	_, err := client.SendMessage(ctx, newTestDraft(), &core.SendOptions{CustomHeaders: map[string]string{"x-campaign": "spring"}})
+
+	require.NoError(t, err)
+	mockMessagesService.AssertExpectations(t)
+}
+
+func TestClient_CreateReplyDraft(t *testing.T) {
+	client, mockMessagesService, _ := createTestClientForSend()
+	ctx := context.Background()
+
+	draftID := "reply-draft-1"
+	subject := "RE: Hello"
+	content := "<p>Thanks!</p><hr><p>Original message</p>"
+	contentType := models.HTML_BODYTYPE
+	address := "sender@example.com"
+	name := "Sender"
+
+	reply := models.NewMessage()
+	reply.SetId(&draftID)
+	reply.SetSubject(&subject)
+	body := models.NewItemBody()
+	body.SetContent(&content)
+	body.SetContentType(&contentType)
+	reply.SetBody(body)
+	emailAddress := models.NewEmailAddress()
+	emailAddress.SetAddress(&address)
+	emailAddress.SetName(&name)
+	recipient := models.NewRecipient()
+	recipient.SetEmailAddress(emailAddress)
+	reply.SetToRecipients([]models.Recipientable{recipient})
+
+	mockMessagesService.On("CreateReply", ctx, "msg-1").Return(reply, nil)
+
+	id, draft, err := client.CreateReplyDraft(ctx, "msg-1")
+
+	require.NoError(t, err)
+	assert.Equal(t, "reply-draft-1", id)
+	assert.Equal(t, "RE: Hello", draft.Subject)
+	assert.Equal(t, []core.EmailAddress{{Email: "sender@example.com", Name: "Sender"}}, draft.To)
+	assert.Contains(t, draft.Body.HTML, "Original message")
+	mockMessagesService.AssertExpectations(t)
+}
+
+func TestClient_ReplyToMessage_KeepsConversation(t *testing.T) {
+	client, mockMessagesService, _ := createTestClientForSend()
+	ctx := context.Background()
+
+	// The original message and the reply draft created from it share the conversation
+	conversationID := "conv-original"
+	original := models.NewMessage()
+	original.SetConversationId(&conversationID)
+
+	draftID := "reply-draft-1"
+	quoted := "<hr><p>Original message</p>"
+	contentType := models.HTML_BODYTYPE
+	reply := models.NewMessage()
+	reply.SetId(&draftID)
+	reply.SetConversationId(original.GetConversationId())
+	quotedBody := models.NewItemBody()
+	quotedBody.SetContent(&quoted)
+	quotedBody.SetContentType(&contentType)
+	reply.SetBody(quotedBody)
+
+	var patch models.Messageable
+	mockMessagesService.On("CreateReply", ctx, "msg-1").Return(reply, nil)
+	mockMessagesService.On("Update", ctx, "reply-draft-1", mock.Anything).
+		Run(func(args mock.Arguments) { patch = args.Get(2).(models.Messageable) }).
+		Return(reply, nil)
+	mockMessagesService.On("SendDraft", ctx, "reply-draft-1").Return(nil)
+
+	resp, err := client.ReplyToMessage(ctx, "msg-1", &core.Draft{Body: core.EmailBody{Text: "Thanks <3"}})
+
+	require.NoError(t, err)
+	assert.Equal(t, *original.GetConversationId(), resp.ThreadID)
+	assert.Nil(t, patch.GetConversationId(), "the patch must not touch the conversation")
+	assert.Nil(t, patch.GetConversationIndex())
+	assert.Nil(t, patch.GetToRecipients(), "recipients default to the original sender")
+	assert.Equal(t, "<p>Thanks &lt;3</p><hr><p>Original message</p>", *patch.GetBody().GetContent())
+	mockMessagesService.AssertNotCalled(t, "SendMail", mock.Anything, mock.Anything)
+	mockMessagesService.AssertExpectations(t)
+}
+
+func TestClient_ReplyToMessage_Validation(t *testing.T) {
+	client, mockMessagesService, _ := createTestClientForSend()
+	ctx := context.Background()
+
+	_, err := client.ReplyToMessage(ctx, "msg-1", &core.Draft{})
+	assert.ErrorContains(t, err, "email body required")
+
+	_, err = client.ReplyToMessage(ctx, "msg-1", &core.Draft{
+		Body:        core.EmailBody{Text: "see attached"},
+		Attachments: []core.Attachment{{Filename: "a.txt", MimeType: "text/plain", Data: []byte("a")}},
+	})
+	assert.ErrorContains(t, err, "attachments are not supported")
+
+	_, err = client.ReplyToMessage(ctx, "msg-1", &core.Draft{
+		Body: core.EmailBody{Text: "looping in"},
+		Cc:   []core.EmailAddress{{Email: "not-an-address"}},
+	})
+	assert.ErrorContains(t, err, "invalid email address: not-an-address")
+
+	_, err = client.ReplyToMessage(ctx, "msg-1", &core.Draft{
+		Body:    core.EmailBody{Text: "thanks"},
+		Headers: map[string]string{"X-Ticket": "42"},
+	})
+	assert.ErrorContains(t, err, "custom headers are not supported")
+	mockMessagesService.AssertNotCalled(t, "CreateReply", mock.Anything, mock.Anything)
+}
+
+func TestClient_ReplyToMessage_FailureDeletesDraft(t *testing.T) {
+	tests := []struct {
+		name      string
+		updateErr error
+		sendErr   error
+		deleteErr error
+		message   string
+	}{
+		{name: "update fails", updateErr: errors.New("invalid recipient"), message: "failed to update reply draft reply-draft-1"},
+		{name: "send fails", sendErr: errors.New("mailbox full"), message: "failed to send reply to message msg-1"},
+		{name: "delete fails too", sendErr: errors.New("mailbox full"), deleteErr: errors.New("throttled"), message: "failed to remove reply draft reply-draft-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, mockMessagesService, _ := createTestClientForSend()
+			ctx := context.Background()
+
+			draftID := "reply-draft-1"
+			reply := models.NewMessage()
+			reply.SetId(&draftID)
+
+			mockMessagesService.On("CreateReply", ctx, "msg-1").Return(reply, nil)
+			mockMessagesService.On("Update", ctx, "reply-draft-1", mock.Anything).Return(reply, tt.updateErr)
+			mockMessagesService.On("SendDraft", ctx, "reply-draft-1").Return(tt.sendErr)
+			mockMessagesService.On("Delete", ctx, "reply-draft-1").Return(tt.deleteErr)
+
+			_, err := client.ReplyToMessage(ctx, "msg-1", &core.Draft{Body: core.EmailBody{Text: "Thanks"}})
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.message)
+			mockMessagesService.AssertCalled(t, "Delete", ctx, "reply-draft-1")
+		})
+	}
+}
+
+func TestClient_CreateForwardDraft(t *testing.T) {
+	client, mockMessagesService, _ := createTestClientForSend()
+	ctx := context.Background()
+
+	draftID := "forward-draft-1"
+	subject := "FW: Hello"
+	content := "Original message"
+	contentType := models.TEXT_BODYTYPE
+
+	forward := models.NewMessage()
+	forward.SetId(&draftID)
+	forward.SetSubject(&subject)
+	body := models.NewItemBody()
+	body.SetContent(&content)
+	body.SetContentType(&contentType)
+	forward.SetBody(body)
+
+	mockMessagesService.On("CreateForward", ctx, "msg-1").Return(forward, nil)
+
+	id, draft, err := client.CreateForwardDraft(ctx, "msg-1")
+
+	require.NoError(t, err)
+	assert.Equal(t, "forward-draft-1", id)
+	assert.Equal(t, "FW: Hello", draft.Subject)
+	assert.Empty(t, draft.To)
+	assert.Equal(t, "Original message", draft.Body.Text)
+}
+
+func TestClient_ForwardMessage(t *testing.T) {
+	client, mockMessagesService, _ := createTestClientForSend()
+	ctx := context.Background()
+
+	var recipients []models.Recipientable
+	mockMessagesService.On("Forward", ctx, "msg-1", "<p>FYI</p>", mock.Anything).
+		Run(func(args mock.Arguments) { recipients = args.Get(3).([]models.Recipientable) }).
+		Return(nil)
+
+	resp, err := client.ForwardMessage(ctx, "msg-1", []core.EmailAddress{{Email: "bob@example.com", Name: "Bob"}},
+		&core.Draft{Body: core.EmailBody{Text: "FYI", HTML: "<p>FYI</p>"}}, &core.ForwardOptions{IncludeAttachments: true})
+
+	require.NoError(t, err)
+	assert.NotNil(t, resp)
+	require.Len(t, recipients, 1)
+	assert.Equal(t, "bob@example.com", *recipients[0].GetEmailAddress().GetAddress())
+	assert.Equal(t, "Bob", *recipients[0].GetEmailAddress().GetName())
+	mockMessagesService.AssertNotCalled(t, "CreateForward", mock.Anything, mock.Anything)
+	mockMessagesService.AssertExpectations(t)
+}
+
+func TestClient_ForwardMessage_Errors(t *testing.T) {
+	client, mockMessagesService, _ := createTestClientForSend()
+	ctx := context.Background()
+	to := []core.EmailAddress{{Email: "bob@example.com"}}
+
+	_, err := client.ForwardMessage(ctx, "msg-1", nil, nil, nil)
+	assert.ErrorContains(t, err, "at least one recipient is required")
+
+	_, err = client.ForwardMessage(ctx, "msg-1", to, &core.Draft{
+		Attachments: []core.Attachment{{Filename: "a.txt", MimeType: "text/plain", Data: []byte("a")}},
+	}, nil)
+	assert.ErrorContains(t, err, "attachments are not supported")
+	mockMessagesService.AssertNotCalled(t, "Forward", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	mockMessagesService.On("Forward", ctx, "msg-1", "", mock.Anything).Return(assert.AnError)
+
+	_, err = client.ForwardMessage(ctx, "msg-1", to, nil, nil)
+	assert.ErrorContains(t, err, "failed to forward message msg-1")
+}
+
+func TestClient_CreateReplyDraft_Error(t *testing.T) {
+	client, mockMessagesService, _ := createTestClientForSend()
+	ctx := context.Background()
+
+	mockMessagesService.On("CreateReply", ctx, "msg-1").Return(nil, assert.AnError)
+
+	_, _, err := client.CreateReplyDraft(ctx, "msg-1")
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to create reply draft")
+}
+
+func TestClient_SendMessage_AttachmentTooLarge(t *testing.T) {
+	client, mockMessagesService, _ := createTestClientForSend()
+
+	draft := newTestDraft()
+	draft.Attachments = []core.Attachment{
+		{Filename: "big.bin", MimeType: "application/octet-stream", Data: make([]byte, 4*1024*1024)},
+	}
+
+	_, err := client.SendMessage(context.Background(), draft, nil)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "exceeds 3MB limit")
+	mockMessagesService.AssertNotCalled(t, "SendMail", mock.Anything, mock.Anything)
+}
+
+func TestClient_SendMessage_EncodedAttachmentTooLarge(t *testing.T) {
+	client, mockMessagesService, _ := createTestClientForSend()
+
+	// 2.5 MB grows to about 3.3 MB once base64-encoded
+	draft := newTestDraft()
+	draft.Attachments = []core.Attachment{
+		{Filename: "big.bin", MimeType: "application/octet-stream", Data: make([]byte, 5*512*1024)},
+	}
+
+	_, err := client.SendMessage(context.Background(), draft, nil)
+
+	assert.ErrorContains(t, err, "exceeds 3MB limit")
+	mockMessagesService.AssertNotCalled(t, "SendMail", mock.Anything, mock.Anything)
+}
+
+func TestClient_SendMessage_TotalSizeTooLarge(t *testing.T) {
+	client, mockMessagesService, _ := createTestClientForSend()
+
+	draft := newTestDraft()
+	draft.Attachments = []core.Attachment{
+		{Filename: "a.bin", MimeType: "application/octet-stream", Data: make([]byte, 1536*1024)},
+		{Filename: "b.bin", MimeType: "application/octet-stream", Data: make([]byte, 1536*1024)},
+	}
+
+	_, err := client.SendMessage(context.Background(), draft, nil)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "exceeds 4MB total size limit")
+	mockMessagesService.AssertNotCalled(t, "SendMail", mock.Anything, mock.Anything)
+}
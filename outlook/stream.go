@@ -0,0 +1,53 @@
+package outlook
+
+import (
+	"context"
+	"io"
+	"sync"
+)
+
+// contextReadCloser ties a streamed response body to a context. When the context is
+// cancelled the body is closed, which unblocks a pending Read and releases the
+// connection, and reads return the context's error instead of a network error.
+type contextReadCloser struct {
+	ctx       context.Context
+	rc        io.ReadCloser
+	stop      func() bool // stops the cancellation hook
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// newContextReadCloser wraps rc so it is closed when ctx is done.
+func newContextReadCloser(ctx context.Context, rc io.ReadCloser) *contextReadCloser {
+	r := &contextReadCloser{ctx: ctx, rc: rc}
+	r.stop = context.AfterFunc(ctx, func() { _ = r.closeBody() })
+	return r
+}
+
+// Read reads from the body, reporting the context's error once it is done.
+func (r *contextReadCloser) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	n, err := r.rc.Read(p)
+	if err != nil && err != io.EOF {
+		if ctxErr := r.ctx.Err(); ctxErr != nil {
+			return n, ctxErr
+		}
+	}
+	return n, err
+}
+
+// Close closes the body. It is safe to call more than once and after cancellation.
+func (r *contextReadCloser) Close() error {
+	r.stop()
+	return r.closeBody()
+}
+
+// closeBody closes the underlying body exactly once.
+func (r *contextReadCloser) closeBody() error {
+	r.closeOnce.Do(func() {
+		r.closeErr = r.rc.Close()
+	})
+	return r.closeErr
+}
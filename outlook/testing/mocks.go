@@ -2,6 +2,7 @@ package testing
 
 import (
 	"context"
+	"io"
 
 	"github.com/danielrivera/mailbridge-go/outlook/internal"
 	"github.com/microsoftgraph/msgraph-sdk-go/models"
@@ -9,6 +10,13 @@ import (
 	"github.com/stretchr/testify/mock"
 )
 
+// GraphService is the interface accepted by outlook.Client.SetService.
+// It is re-exported here so code outside this module can refer to it when building mocks.
+type GraphService = internal.GraphService
+
+// Compile-time check that the mock satisfies the service interface.
+var _ GraphService = (*MockGraphService)(nil)
+
 // MockGraphService is a mock for GraphService
 type MockGraphService struct {
 	mock.Mock
@@ -19,11 +27,24 @@ func (m *MockGraphService) GetMeService() internal.MeService {
 	return args.Get(0).(internal.MeService)
 }
 
+func (m *MockGraphService) GetUserService(userID string) internal.MeService {
+	args := m.Called(userID)
+	return args.Get(0).(internal.MeService)
+}
+
 // MockMeService is a mock for MeService
 type MockMeService struct {
 	mock.Mock
 }
 
+func (m *MockMeService) Get(ctx context.Context) (models.Userable, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(models.Userable), args.Error(1)
+}
+
 func (m *MockMeService) GetMessagesService() internal.MessagesService {
 	args := m.Called()
 	return args.Get(0).(internal.MessagesService)
@@ -47,6 +68,14 @@ func (m *MockMessagesService) List(ctx context.Context, config *users.ItemMessag
 	return args.Get(0).(models.MessageCollectionResponseable), args.Error(1)
 }
 
+func (m *MockMessagesService) ListNextPage(ctx context.Context, nextLink string) (models.MessageCollectionResponseable, error) {
+	args := m.Called(ctx, nextLink)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(models.MessageCollectionResponseable), args.Error(1)
+}
+
 func (m *MockMessagesService) Get(ctx context.Context, messageID string) (models.Messageable, error) {
 	args := m.Called(ctx, messageID)
 	if args.Get(0) == nil {
@@ -55,6 +84,14 @@ func (m *MockMessagesService) Get(ctx context.Context, messageID string) (models
 	return args.Get(0).(models.Messageable), args.Error(1)
 }
 
+func (m *MockMessagesService) GetWithConfig(ctx context.Context, messageID string, config *users.ItemMessagesMessageItemRequestBuilderGetRequestConfiguration) (models.Messageable, error) {
+	args := m.Called(ctx, messageID, config)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(models.Messageable), args.Error(1)
+}
+
 func (m *MockMessagesService) GetAttachments(ctx context.Context, messageID string) ([]models.Attachmentable, error) {
 	args := m.Called(ctx, messageID)
 	if args.Get(0) == nil {
@@ -71,16 +108,66 @@ func (m *MockMessagesService) GetAttachment(ctx context.Context, messageID, atta
 	return args.Get(0).(models.Attachmentable), args.Error(1)
 }
 
+func (m *MockMessagesService) GetAttachmentMetadata(ctx context.Context, messageID, attachmentID string) (models.Attachmentable, error) {
+	args := m.Called(ctx, messageID, attachmentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(models.Attachmentable), args.Error(1)
+}
+
+func (m *MockMessagesService) GetIfNoneMatch(ctx context.Context, messageID, etag string) (models.Messageable, bool, error) {
+	args := m.Called(ctx, messageID, etag)
+	if args.Get(0) == nil {
+		return nil, args.Bool(1), args.Error(2)
+	}
+	return args.Get(0).(models.Messageable), args.Bool(1), args.Error(2)
+}
+
+func (m *MockMessagesService) ListAttachmentMetadata(ctx context.Context, messageID string) ([]models.Attachmentable, error) {
+	args := m.Called(ctx, messageID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Attachmentable), args.Error(1)
+}
+
+func (m *MockMessagesService) GetAttachmentContent(ctx context.Context, messageID, attachmentID string) (io.ReadCloser, error) {
+	args := m.Called(ctx, messageID, attachmentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(io.ReadCloser), args.Error(1)
+}
+
+func (m *MockMessagesService) GetMimeContent(ctx context.Context, messageID string) ([]byte, error) {
+	args := m.Called(ctx, messageID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]byte), args.Error(1)
+}
+
 func (m *MockMessagesService) MarkAsRead(ctx context.Context, messageID string) error {
 	args := m.Called(ctx, messageID)
 	return args.Error(0)
 }
 
+func (m *MockMessagesService) SetFocused(ctx context.Context, messageID string, focused bool) error {
+	args := m.Called(ctx, messageID, focused)
+	return args.Error(0)
+}
+
 func (m *MockMessagesService) MarkAsUnread(ctx context.Context, messageID string) error {
 	args := m.Called(ctx, messageID)
 	return args.Error(0)
 }
 
+func (m *MockMessagesService) BatchMarkAsRead(ctx context.Context, messageIDs []string) error {
+	args := m.Called(ctx, messageIDs)
+	return args.Error(0)
+}
+
 func (m *MockMessagesService) Move(ctx context.Context, messageID, destinationFolderID string) error {
 	args := m.Called(ctx, messageID, destinationFolderID)
 	return args.Error(0)
@@ -91,13 +178,60 @@ func (m *MockMessagesService) Delete(ctx context.Context, messageID string) erro
 	return args.Error(0)
 }
 
+func (m *MockMessagesService) SendMail(ctx context.Context, message models.Messageable) error {
+	args := m.Called(ctx, message)
+	return args.Error(0)
+}
+
+func (m *MockMessagesService) CreateDraft(ctx context.Context, message models.Messageable) (models.Messageable, error) {
+	args := m.Called(ctx, message)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(models.Messageable), args.Error(1)
+}
+
+func (m *MockMessagesService) SendDraft(ctx context.Context, messageID string) error {
+	args := m.Called(ctx, messageID)
+	return args.Error(0)
+}
+
+func (m *MockMessagesService) Update(ctx context.Context, messageID string, message models.Messageable) (models.Messageable, error) {
+	args := m.Called(ctx, messageID, message)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(models.Messageable), args.Error(1)
+}
+
+func (m *MockMessagesService) CreateReply(ctx context.Context, messageID string) (models.Messageable, error) {
+	args := m.Called(ctx, messageID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(models.Messageable), args.Error(1)
+}
+
+func (m *MockMessagesService) CreateForward(ctx context.Context, messageID string) (models.Messageable, error) {
+	args := m.Called(ctx, messageID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(models.Messageable), args.Error(1)
+}
+
+func (m *MockMessagesService) Forward(ctx context.Context, messageID, comment string, toRecipients []models.Recipientable) error {
+	args := m.Called(ctx, messageID, comment, toRecipients)
+	return args.Error(0)
+}
+
 // MockMailFoldersService is a mock for MailFoldersService
 type MockMailFoldersService struct {
 	mock.Mock
 }
 
-func (m *MockMailFoldersService) List(ctx context.Context) (models.MailFolderCollectionResponseable, error) {
-	args := m.Called(ctx)
+func (m *MockMailFoldersService) List(ctx context.Context, config *users.ItemMailFoldersRequestBuilderGetRequestConfiguration) (models.MailFolderCollectionResponseable, error) {
+	args := m.Called(ctx, config)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -120,6 +254,14 @@ func (m *MockMailFoldersService) Create(ctx context.Context, name string) (model
 	return args.Get(0).(models.MailFolderable), args.Error(1)
 }
 
+func (m *MockMailFoldersService) CreateSearchFolder(ctx context.Context, parentFolderID string, folder models.MailSearchFolderable) (models.MailFolderable, error) {
+	args := m.Called(ctx, parentFolderID, folder)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(models.MailFolderable), args.Error(1)
+}
+
 func (m *MockMailFoldersService) Update(ctx context.Context, folderID, newName string) (models.MailFolderable, error) {
 	args := m.Called(ctx, folderID, newName)
 	if args.Get(0) == nil {
@@ -140,3 +282,43 @@ func (m *MockMailFoldersService) GetMessages(ctx context.Context, folderID strin
 	}
 	return args.Get(0).(models.MessageCollectionResponseable), args.Error(1)
 }
+
+func (m *MockMailFoldersService) ListChildren(ctx context.Context, folderID string, config *users.ItemMailFoldersItemChildFoldersRequestBuilderGetRequestConfiguration) (models.MailFolderCollectionResponseable, error) {
+	args := m.Called(ctx, folderID, config)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(models.MailFolderCollectionResponseable), args.Error(1)
+}
+
+func (m *MockMailFoldersService) ListNextPage(ctx context.Context, nextLink string) (models.MailFolderCollectionResponseable, error) {
+	args := m.Called(ctx, nextLink)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(models.MailFolderCollectionResponseable), args.Error(1)
+}
+
+func (m *MockMailFoldersService) BatchGetCounts(ctx context.Context, folderIDs []string) (map[string]models.MailFolderable, error) {
+	args := m.Called(ctx, folderIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]models.MailFolderable), args.Error(1)
+}
+
+func (m *MockMailFoldersService) CreateMessage(ctx context.Context, folderID string, message models.Messageable) (models.Messageable, error) {
+	args := m.Called(ctx, folderID, message)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(models.Messageable), args.Error(1)
+}
+
+func (m *MockMailFoldersService) ImportMessage(ctx context.Context, folderID string, mimeContent []byte) (models.Messageable, error) {
+	args := m.Called(ctx, folderID, mimeContent)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(models.Messageable), args.Error(1)
+}